@@ -0,0 +1,73 @@
+package wiimote
+
+import "math"
+
+// FVec3 represents a 3D floating point vector to X, Y and Z.
+type FVec3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// Magnitude returns the length of v.
+func (v FVec3) Magnitude() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+// Roll returns the rotation around the Y axis (the length of the remote) in radians, assuming
+// v is the gravity. The roll is 0 if the remote lies flat with buttons up.
+func (v FVec3) Roll() float64 {
+	return math.Atan2(v.X, v.Z)
+}
+
+// Pitch returns the rotation around the X axis in radians, assuming v is the gravity. The pitch
+// is 0 if the remote lies flat and positive if the remote points upwards.
+func (v FVec3) Pitch() float64 {
+	return math.Atan2(v.Y, math.Hypot(v.X, v.Z))
+}
+
+// AccelCalibration holds the values of the accelerometer at 0g and at 1g on each axis, in the
+// units of EventAccel. The accelerometer covers roughly ±3g.
+type AccelCalibration struct {
+	Zero Vec3 `json:"zero"`
+	One  Vec3 `json:"one"`
+}
+
+// NominalAccel is the nominal calibration of the accelerometer, used if no calibration of
+// the device is known.
+var NominalAccel = AccelCalibration{
+	Zero: Vec3{X: 0, Y: 0, Z: 0},
+	One:  Vec3{X: 100, Y: 100, Z: 100},
+}
+
+// NominalNunchukAccel is the nominal calibration of the accelerometer of the Nunchuk, which has
+// twice the resolution of the accelerometer of the remote.
+var NominalNunchukAccel = AccelCalibration{
+	One: Vec3{X: 200, Y: 200, Z: 200},
+}
+
+func scaleAxis(v, zero, one int32) float64 {
+	if one == zero {
+		return 0
+	}
+	return float64(v-zero) / float64(one-zero)
+}
+
+// G converts the raw acceleration v to g.
+func (c AccelCalibration) G(v Vec3) FVec3 {
+	return FVec3{
+		X: scaleAxis(v.X, c.Zero.X, c.One.X),
+		Y: scaleAxis(v.Y, c.Zero.Y, c.One.Y),
+		Z: scaleAxis(v.Z, c.Zero.Z, c.One.Z),
+	}
+}
+
+// G returns the acceleration in g using the nominal calibration, see NominalAccel.
+func (ev *EventAccel) G() FVec3 {
+	return NominalAccel.G(ev.Accel)
+}
+
+// GWith returns the acceleration in g using the calibration of the device.
+func (ev *EventAccel) GWith(cal AccelCalibration) FVec3 {
+	return cal.G(ev.Accel)
+}
@@ -0,0 +1,42 @@
+package wiimote
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAccelG(t *testing.T) {
+	cal := AccelCalibration{
+		Zero: Vec3{X: -4, Y: 2, Z: 0},
+		One:  Vec3{X: 96, Y: 104, Z: 100},
+	}
+	g := cal.G(Vec3{X: -4, Y: 53, Z: 200})
+	if g != (FVec3{X: 0, Y: 0.5, Z: 2}) {
+		t.Errorf("expected (0, 0.5, 2), got %v", g)
+	}
+
+	ev := EventAccel{Accel: Vec3{X: 0, Y: 0, Z: 100}}
+	if g := ev.G(); g.Magnitude() != 1 {
+		t.Errorf("expected 1g at rest, got %v", g.Magnitude())
+	}
+}
+
+func TestAccelRollPitch(t *testing.T) {
+	tests := []struct {
+		g           FVec3
+		roll, pitch float64
+	}{
+		{FVec3{Z: 1}, 0, 0},
+		{FVec3{X: 1}, math.Pi / 2, 0},
+		{FVec3{Y: 1}, 0, math.Pi / 2},
+		{FVec3{X: -1}, -math.Pi / 2, 0},
+	}
+	for _, tc := range tests {
+		if roll := tc.g.Roll(); math.Abs(roll-tc.roll) > 1e-9 {
+			t.Errorf("roll of %v: expected %v, got %v", tc.g, tc.roll, roll)
+		}
+		if pitch := tc.g.Pitch(); math.Abs(pitch-tc.pitch) > 1e-9 {
+			t.Errorf("pitch of %v: expected %v, got %v", tc.g, tc.pitch, pitch)
+		}
+	}
+}
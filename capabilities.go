@@ -0,0 +1,48 @@
+package wiimote
+
+// Capabilities describes what a device supports, see Device.Capabilities.
+type Capabilities struct {
+	// DevType is the device type, see Device.DevType
+	DevType string
+	// Available holds the features which are available at this time, see Device.Available
+	Available FeatureKind
+
+	// IR is set if the device has an IR camera
+	IR bool
+	// Accel is set if the device has an accelerometer
+	Accel bool
+	// MotionPlus is set if the device has a built-in Motion Plus or one is connected
+	MotionPlus bool
+	// BalanceBoard and ProController are set if the device is a balance board respectively a
+	// Wii U Pro Controller instead of a Wii Remote
+	BalanceBoard, ProController bool
+	// Rumble is set if the device has a rumble motor
+	Rumble bool
+	// LEDs is the number of LEDs, see Device.SetLED
+	LEDs int
+}
+
+// CapabilitiesOf returns the capabilities of a device of devtype with the available features.
+// Unknown device types are described by the available features, as Wii Remote if the core
+// feature is available.
+func CapabilitiesOf(devtype string, available FeatureKind) Capabilities {
+	c := Capabilities{DevType: devtype, Available: available}
+	switch typ := ParseDevType(devtype); typ {
+	case DevTypeGen10, DevTypeGen20:
+		c.IR, c.Accel, c.Rumble, c.LEDs = true, true, true, 4
+		// the second generation has a built-in Motion Plus
+		c.MotionPlus = typ == DevTypeGen20
+	case DevTypeBalanceBoard:
+		c.BalanceBoard, c.LEDs = true, 1
+	case DevTypeProController:
+		c.ProController, c.Rumble, c.LEDs = true, true, 4
+	default:
+		c.BalanceBoard = available&FeatureBalanceBoard != 0
+		c.ProController = available&FeatureProController != 0
+		if available&FeatureCore != 0 {
+			c.IR, c.Accel, c.Rumble, c.LEDs = true, true, true, 4
+		}
+	}
+	c.MotionPlus = c.MotionPlus || available&FeatureMotionPlus != 0
+	return c
+}
@@ -0,0 +1,24 @@
+package wiimote
+
+import "testing"
+
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		devtype   string
+		available FeatureKind
+		expect    Capabilities
+	}{
+		{"gen10", FeatureSetCore, Capabilities{IR: true, Accel: true, Rumble: true, LEDs: 4}},
+		{"gen20", FeatureSetCore, Capabilities{IR: true, Accel: true, MotionPlus: true, Rumble: true, LEDs: 4}},
+		{"gen10", FeatureSetCore | FeatureMotionPlus, Capabilities{IR: true, Accel: true, MotionPlus: true, Rumble: true, LEDs: 4}},
+		{"balanceboard", FeatureBalanceBoard, Capabilities{BalanceBoard: true, LEDs: 1}},
+		{"procontroller", FeatureProController, Capabilities{ProController: true, Rumble: true, LEDs: 4}},
+		{"", FeatureBalanceBoard, Capabilities{BalanceBoard: true}},
+	}
+	for _, tc := range tests {
+		tc.expect.DevType, tc.expect.Available = tc.devtype, tc.available
+		if got := CapabilitiesOf(tc.devtype, tc.available); got != tc.expect {
+			t.Errorf("%s: expected %+v, got %+v", tc.devtype, tc.expect, got)
+		}
+	}
+}
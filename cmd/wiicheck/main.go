@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/pkg/diag"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+)
+
+var (
+	version = flag.Bool("version", false, "Print version information and exit")
+	rules   = flag.String("rules", "", "Write udev rules to this file (e.g. "+diag.RulesPath+", - for stdout) and exit")
+	group   = flag.String("group", "input", "Group granted access by -rules")
+)
+
+func writeRules(path string) error {
+	if path == "-" {
+		return diag.WriteRules(os.Stdout, *group)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := diag.WriteRules(f, *group); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("rules written to %s, reload using: udevadm control --reload && udevadm trigger\n", path)
+	return nil
+}
+
+// listDevices prints the connected devices with their available features and battery.
+func listDevices() {
+	devs, err := discover.IterDevices()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to enumerate devices: %v\n", err)
+		return
+	}
+	for info := range devs {
+		dev, err := driver.NewDevice(info.Device, driver.BackendKernel)
+		if err != nil {
+			fmt.Printf("%s: %v\n", info.Syspath, err)
+			continue
+		}
+		var features []string
+		for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+			if dev.Available(kind) {
+				features = append(features, kind.String())
+			}
+		}
+		var battery string
+		if bat, err := dev.Battery(); err == nil {
+			battery = fmt.Sprintf("%d%%", bat)
+		} else {
+			battery = err.Error()
+		}
+		fmt.Printf("%s (%s, extension %s)\n    features: %s\n    battery: %s\n",
+			info.Uniq, info.DevType, info.Extension, strings.Join(features, ", "), battery)
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *rules != "" {
+		if err := writeRules(*rules); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	results := diag.Check()
+	for _, r := range results {
+		fmt.Println(r)
+	}
+	fmt.Println()
+	listDevices()
+	if diag.AnyFailed(results) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/pkg/bluez"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/pairing"
+	"github.com/friedelschoen/go-wiimote/pkg/systemdutil"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+var (
+	systemBus = flag.Bool("system", false, "Connect to the system bus instead of the session bus")
+	version   = flag.Bool("version", false, "Print version information and exit")
+	debug     = flag.Bool("debug", false, "Log debug messages of the driver")
+	unit      = flag.Bool("unit", false, "Print a systemd user unit running the service with the given flags and exit")
+	pair      = flag.Bool("pair", false, "Pair remotes of which the red sync button is pressed")
+	idle      = flag.Duration("idle", 0, "Disconnect remotes after no events for the given duration, 0 to never disconnect")
+)
+
+// features which are opened on every remote to report key events
+const keyFeatures = wiimote.FeatureCore | wiimote.FeatureNunchuck | wiimote.FeatureClassicController | wiimote.FeatureProController
+
+func serveRemote(conn *dbus.Conn, mgr *manager, r *remote) {
+	defer func() {
+		mgr.mu.Lock()
+		delete(mgr.remotes, r.path)
+		mgr.mu.Unlock()
+		conn.Export(nil, r.path, remoteIface)
+		conn.Export(nil, r.path, "org.freedesktop.DBus.Introspectable")
+		conn.Emit(rootPath, managerIface+".Removed", r.path)
+		r.close()
+		r.dev.Cleanup()
+		log.Printf("removed %s\n", r.path)
+	}()
+
+	r.dev.AutoReopen(true)
+	r.dev.SetErrorPolicy(wiimote.ErrorClose)
+	// extensions which are not connected yet are opened once available
+	if err := r.dev.OpenFeatures(keyFeatures, true); err != nil {
+		log.Printf("%s: unable to open features: %v\n", r.path, err)
+	}
+
+	lastEvent := time.Now()
+	for {
+		ctx, cancel := r.runCalls()
+		if mgr.idle > 0 {
+			ctx, cancel = withDeadline(ctx, cancel, lastEvent.Add(mgr.idle))
+		}
+		ev, err := r.dev.WaitContext(ctx)
+		cancel()
+		if errors.Is(err, context.Canceled) {
+			// interrupted by a method call
+			continue
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Printf("%s: idle for %v, disconnecting\n", r.path, mgr.idle)
+			if err := r.dev.Disconnect(); err != nil {
+				log.Printf("%s: unable to disconnect: %v\n", r.path, err)
+			}
+			// the remote reports EventGone once it is disconnected
+			lastEvent = time.Now()
+			continue
+		}
+		if err != nil {
+			log.Printf("%s: error while polling: %v\n", r.path, err)
+			return
+		}
+		lastEvent = time.Now()
+		switch ev := ev.(type) {
+		case *wiimote.EventGone:
+			return
+		case *wiimote.EventKey:
+			conn.Emit(r.path, remoteIface+".Key", wiimote.FeatureCore.String(), ev.Code.String(), ev.Pressed)
+		case *wiimote.EventNunchukKey:
+			conn.Emit(r.path, remoteIface+".Key", wiimote.FeatureNunchuck.String(), ev.Code.String(), ev.Pressed)
+		case *wiimote.EventClassicControllerKey:
+			conn.Emit(r.path, remoteIface+".Key", wiimote.FeatureClassicController.String(), ev.Code.String(), ev.Pressed)
+		case *wiimote.EventProControllerKey:
+			conn.Emit(r.path, remoteIface+".Key", wiimote.FeatureProController.String(), ev.Code.String(), ev.Pressed)
+		case *wiimote.EventFeature:
+			ext, _ := r.dev.Extension()
+			conn.Emit(r.path, remoteIface+".Extension", ext)
+		}
+	}
+}
+
+// runPairing pairs remotes in the background, the players are assigned by the monitor.
+func runPairing() {
+	d, err := pairing.NewDaemon()
+	if err != nil {
+		log.Printf("unable to pair remotes: %v\n", err)
+		return
+	}
+	d.Paired = func(dev bluez.Device, err error) {
+		if err != nil {
+			log.Printf("unable to pair %s: %v\n", dev.Address, err)
+			return
+		}
+		log.Printf("paired %s\n", dev.Address)
+	}
+	if err := d.Run(context.Background()); err != nil {
+		log.Printf("unable to pair remotes: %v\n", err)
+	}
+}
+
+// withDeadline returns a context derived from ctx which is also cancelled at deadline, cancel
+// cancels both.
+func withDeadline(ctx context.Context, cancel context.CancelFunc, deadline time.Time) (context.Context, context.CancelFunc) {
+	ctx, cancelDeadline := context.WithDeadline(ctx, deadline)
+	return ctx, func() {
+		cancelDeadline()
+		cancel()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *unit {
+		u, err := systemdutil.NewUnit("Wii remote D-Bus service", systemdutil.FlagArgs(flag.CommandLine, "unit")...)
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		u.Watchdog = 30 * time.Second
+		fmt.Print(u)
+		return
+	}
+	if *debug {
+		wiimote.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	defer driver.Shutdown()
+	driver.CleanupOnSignal()
+
+	connect := dbus.ConnectSessionBus
+	if *systemBus {
+		connect = dbus.ConnectSystemBus
+	}
+	conn, err := connect()
+	if err != nil {
+		log.Fatalln("error: unable to connect to bus:", err)
+	}
+	defer conn.Close()
+
+	mgr := &manager{remotes: make(map[dbus.ObjectPath]*remote), idle: *idle}
+	conn.Export(mgr, rootPath, managerIface)
+	conn.Export(introspect.Introspectable(managerIntro), rootPath, "org.freedesktop.DBus.Introspectable")
+
+	reply, err := conn.RequestName(busName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		log.Fatalln("error: unable to request name:", err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		log.Fatalf("error: name %s already taken\n", busName)
+	}
+
+	monitor, err := discover.NewWiimoteMonitor()
+	if err != nil {
+		log.Fatalln("error: ", err)
+	}
+	monitor.AssignPlayers(true)
+
+	// the watchdog restarts the service if the connection to the bus is lost
+	go systemdutil.RunWatchdog(context.Background(), conn.Connected)
+	if err := systemdutil.Ready(); err != nil {
+		log.Printf("unable to notify systemd: %v\n", err)
+	}
+
+	if *pair {
+		go runPairing()
+	}
+
+	index := 0
+	for {
+		info, err := monitor.Wait(-1)
+		if err != nil || info == nil {
+			log.Printf("error while polling: %v\n", err)
+			continue
+		}
+		dev, err := driver.NewDevice(info.Device, driver.BackendKernel)
+		if err != nil {
+			log.Printf("error creating device: %v\n", err)
+			continue
+		}
+		if info.Player != 0 {
+			dev.SetPlayerLED(info.Player)
+		}
+
+		index++
+		r := &remote{dev: dev, path: objectPath(info.Uniq, index)}
+		conn.Export(r, r.path, remoteIface)
+		conn.Export(introspect.Introspectable(remoteIntro), r.path, "org.freedesktop.DBus.Introspectable")
+		mgr.mu.Lock()
+		mgr.remotes[r.path] = r
+		mgr.mu.Unlock()
+		conn.Emit(rootPath, managerIface+".Added", r.path)
+		log.Printf("added %s\n", r.path)
+
+		go serveRemote(conn, mgr, r)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+const (
+	busName      = "org.xwiimote"
+	rootPath     = dbus.ObjectPath("/org/xwiimote")
+	managerIface = "org.xwiimote.Manager"
+	remoteIface  = "org.xwiimote.Remote"
+)
+
+const remoteIntro = `
+<node>
+	<interface name="` + remoteIface + `">
+		<method name="Battery">
+			<arg direction="out" type="u"/>
+		</method>
+		<method name="LED">
+			<arg direction="out" type="y"/>
+		</method>
+		<method name="SetLED">
+			<arg direction="in" type="y"/>
+		</method>
+		<method name="Rumble">
+			<arg direction="in" type="b"/>
+		</method>
+		<method name="Extension">
+			<arg direction="out" type="s"/>
+		</method>
+		<method name="DevType">
+			<arg direction="out" type="s"/>
+		</method>
+		<method name="UniqueID">
+			<arg direction="out" type="s"/>
+		</method>
+		<method name="Disconnect"/>
+		<signal name="Key">
+			<arg name="feature" type="s"/>
+			<arg name="key" type="s"/>
+			<arg name="pressed" type="b"/>
+		</signal>
+		<signal name="Extension">
+			<arg name="extension" type="s"/>
+		</signal>
+	</interface>` + introspect.IntrospectDataString + `</node>`
+
+const managerIntro = `
+<node>
+	<interface name="` + managerIface + `">
+		<method name="List">
+			<arg direction="out" type="ao"/>
+		</method>
+		<signal name="Added">
+			<arg name="remote" type="o"/>
+		</signal>
+		<signal name="Removed">
+			<arg name="remote" type="o"/>
+		</signal>
+	</interface>` + introspect.IntrospectDataString + `</node>`
+
+// dbusError converts err to a D-Bus error, nil if err is nil.
+func dbusError(err error) *dbus.Error {
+	if err == nil {
+		return nil
+	}
+	return dbus.MakeFailedError(err)
+}
+
+// objectPath returns the object path of a remote with unique id uniq, the index is used if
+// the unique id is unknown.
+func objectPath(uniq string, index int) dbus.ObjectPath {
+	if uniq == "" {
+		return rootPath + dbus.ObjectPath(fmt.Sprintf("/remote%d", index))
+	}
+	return rootPath + "/" + dbus.ObjectPath(strings.ReplaceAll(strings.ToLower(uniq), ":", "_"))
+}
+
+// errGone is returned by the methods of a remote which is removed.
+var errGone = errors.New("remote is gone")
+
+// remote is the D-Bus object of a connected remote. The methods are called concurrently by
+// the D-Bus connection, they are run by the goroutine polling the device, see do.
+type remote struct {
+	dev  wiimote.Device
+	path dbus.ObjectPath
+
+	mu sync.Mutex
+	// calls queued by do, interrupt cancels the wait of serveRemote
+	calls     []func()
+	interrupt context.CancelFunc
+	gone      bool
+}
+
+// do runs fn on the goroutine polling the device and waits until it returned. It returns
+// errGone if the remote is removed.
+func (r *remote) do(fn func()) error {
+	done := make(chan struct{})
+	r.mu.Lock()
+	if r.gone {
+		r.mu.Unlock()
+		return errGone
+	}
+	r.calls = append(r.calls, func() {
+		fn()
+		close(done)
+	})
+	if r.interrupt != nil {
+		r.interrupt()
+	}
+	r.mu.Unlock()
+	<-done
+	return nil
+}
+
+// runCalls runs the queued calls and returns a context which is cancelled by the next call.
+func (r *remote) runCalls() (context.Context, context.CancelFunc) {
+	for {
+		r.mu.Lock()
+		calls := r.calls
+		r.calls = nil
+		if len(calls) == 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			r.interrupt = cancel
+			r.mu.Unlock()
+			return ctx, cancel
+		}
+		r.interrupt = nil
+		r.mu.Unlock()
+		for _, fn := range calls {
+			fn()
+		}
+	}
+}
+
+// close marks the remote as removed and runs the calls queued in the meantime.
+func (r *remote) close() {
+	r.mu.Lock()
+	r.gone = true
+	calls := r.calls
+	r.calls, r.interrupt = nil, nil
+	r.mu.Unlock()
+	for _, fn := range calls {
+		fn()
+	}
+}
+
+func (r *remote) Battery() (bat uint32, derr *dbus.Error) {
+	if err := r.do(func() {
+		b, err := r.dev.Battery()
+		bat, derr = uint32(b), dbusError(err)
+	}); err != nil {
+		return 0, dbusError(err)
+	}
+	return bat, derr
+}
+
+func (r *remote) LED() (leds byte, derr *dbus.Error) {
+	if err := r.do(func() {
+		l, err := r.dev.LED()
+		leds, derr = byte(l), dbusError(err)
+	}); err != nil {
+		return 0, dbusError(err)
+	}
+	return leds, derr
+}
+
+func (r *remote) SetLED(leds byte) (derr *dbus.Error) {
+	if err := r.do(func() {
+		derr = dbusError(r.dev.SetLED(wiimote.Led(leds)))
+	}); err != nil {
+		return dbusError(err)
+	}
+	return derr
+}
+
+func (r *remote) Rumble(state bool) (derr *dbus.Error) {
+	if err := r.do(func() {
+		rf, ok := r.dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature)
+		if !ok {
+			derr = dbus.MakeFailedError(wiimote.ErrNotOpened)
+			return
+		}
+		derr = dbusError(rf.Rumble(state))
+	}); err != nil {
+		return dbusError(err)
+	}
+	return derr
+}
+
+func (r *remote) Extension() (ext string, derr *dbus.Error) {
+	if err := r.do(func() {
+		e, err := r.dev.Extension()
+		ext, derr = e, dbusError(err)
+	}); err != nil {
+		return "", dbusError(err)
+	}
+	return ext, derr
+}
+
+func (r *remote) DevType() (typ string, derr *dbus.Error) {
+	if err := r.do(func() {
+		t, err := r.dev.DevType()
+		typ, derr = t, dbusError(err)
+	}); err != nil {
+		return "", dbusError(err)
+	}
+	return typ, derr
+}
+
+func (r *remote) UniqueID() (uniq string, derr *dbus.Error) {
+	if err := r.do(func() {
+		u, err := r.dev.UniqueID()
+		uniq, derr = u, dbusError(err)
+	}); err != nil {
+		return "", dbusError(err)
+	}
+	return uniq, derr
+}
+
+func (r *remote) Disconnect() (derr *dbus.Error) {
+	if err := r.do(func() {
+		derr = dbusError(r.dev.Disconnect())
+	}); err != nil {
+		return dbusError(err)
+	}
+	return derr
+}
+
+// manager is the D-Bus object listing all remotes.
+type manager struct {
+	mu      sync.Mutex
+	remotes map[dbus.ObjectPath]*remote
+	// remotes without events for idle are disconnected, 0 disables the timeout
+	idle time.Duration
+}
+
+func (m *manager) List() ([]dbus.ObjectPath, *dbus.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	paths := make([]dbus.ObjectPath, 0, len(m.remotes))
+	for path := range m.remotes {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -1,34 +1,31 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/friedelschoen/go-wiimote"
 	"github.com/friedelschoen/go-wiimote/driver"
 	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/ndjson"
+	"github.com/friedelschoen/go-wiimote/pkg/profile"
 )
 
 var (
-	openIf = flag.String("features", "", "features to use")
+	openIf  = flag.String("features", "", "features to use")
+	version = flag.Bool("version", false, "Print version information and exit")
+	debug   = flag.Bool("debug", false, "Log debug messages of the driver")
+	output  = flag.String("output", "-", "Write events as NDJSON to a file, unix:PATH, tcp:HOST:PORT or - for stdout")
 )
 
-type eventBlock struct {
-	Type      string        `json:"type"`
-	Event     wiimote.Event `json:"event"`
-	Id        string        `json:"id"`
-	Timestamp time.Time     `json:"timestamp"`
-	Feature   string        `json:"feature"`
-}
-
-func watchDevice(dev wiimote.Device, mu *sync.Mutex) {
-	fmt.Printf("new device: %s\n", dev.String())
+func watchDevice(dev wiimote.Device, out *ndjson.Writer) {
+	defer dev.Cleanup()
+	fmt.Fprintf(os.Stderr, "new device: %s\n", dev.String())
 	time.Sleep(100 * time.Millisecond)
 	var ifs wiimote.FeatureKind
 	ifs |= wiimote.FeatureCore
@@ -57,56 +54,61 @@ func watchDevice(dev wiimote.Device, mu *sync.Mutex) {
 	if err := dev.OpenFeatures(ifs, true); err != nil {
 		fmt.Fprintf(os.Stderr, "error: unable to open device: %s", err)
 	}
+	if settings, err := profile.Load(dev); err == nil {
+		profile.Apply(dev, settings)
+	}
 
-	var block eventBlock
 	for {
 		ev, err := dev.Wait(-1)
 		if err != nil {
 			log.Printf("unable to poll event: %v\n", err)
+			return
 		}
 		if _, ok := ev.(*wiimote.EventGone); ok {
 			return
 		}
-
-		block.Type = fmt.Sprintf("%T", ev)
-		block.Event = ev
-		block.Id = dev.Syspath()
-		block.Timestamp = ev.Timestamp()
-		if ev.Feature() != nil {
-			block.Feature = ev.Feature().Kind().String()
-		}
-		b, err := json.Marshal(block)
-		if err != nil {
-			log.Printf("unable to encode event: %v\n", b)
+		if err := out.Write(ev); err != nil {
+			log.Printf("unable to write event: %v\n", err)
 		}
-		mu.Lock()
-		os.Stdout.Write(b)
-		os.Stdout.WriteString("\n")
-		mu.Unlock()
 	}
 }
 
 func main() {
 	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *debug {
+		wiimote.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	defer driver.Shutdown()
+	driver.CleanupOnSignal()
 
 	monitor, err := discover.NewWiimoteMonitor()
 	if err != nil {
 		log.Fatalln("error: ", err)
 	}
 
-	fmt.Println("waiting for devices...")
-	var mu sync.Mutex
+	file, err := ndjson.Open(*output)
+	if err != nil {
+		log.Fatalln("error: ", err)
+	}
+	defer file.Close()
+	out := ndjson.NewWriter(file)
+
+	fmt.Fprintln(os.Stderr, "waiting for devices...")
 	for {
 		dev, err := monitor.Wait(-1)
 		if err != nil || dev == nil {
 			log.Printf("error while polling: %v\n", err)
 			continue
 		}
-		d, err := driver.NewDevice(dev, driver.BackendKernel)
+		d, err := driver.NewDevice(dev.Device, driver.BackendKernel)
 		if err != nil {
 			log.Printf("error creating device: %v\n", err)
 			continue
 		}
-		go watchDevice(d, &mu)
+		go watchDevice(d, out)
 	}
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/mapper"
+	"github.com/friedelschoen/go-wiimote/pkg/systemdutil"
+)
+
+// mappingDir returns the default directory of the mappings used in daemon mode.
+func mappingDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-wiimote", "mappings"), nil
+}
+
+// mappingNames returns the files which may contain the mapping of info, in order of preference:
+// the unique ID without colons (e.g. 001f32aabbcc.map), the devtype (e.g. gen20.map) and
+// default.map.
+func mappingNames(info *discover.DeviceInfo) []string {
+	var names []string
+	if info.Uniq != "" {
+		names = append(names, strings.ReplaceAll(strings.ToLower(info.Uniq), ":", "")+".map")
+	}
+	if info.DevType != "" {
+		names = append(names, info.DevType+".map")
+	}
+	return append(names, "default.map")
+}
+
+// loadMapping loads the first mapping of mappingNames in dir. It returns fs.ErrNotExist if the
+// device has no mapping, invalid lines are logged and skipped.
+func loadMapping(dir string, info *discover.DeviceInfo) (*mapper.Mapping, error) {
+	for _, name := range mappingNames(info) {
+		file, err := os.Open(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		mapping, err := mapper.Parse(file)
+		file.Close()
+		if err != nil {
+			log.Printf("%s: %v\n", name, err)
+		}
+		fmt.Printf("using mapping %s for %s\n", name, info.Syspath)
+		return mapping, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+// runDaemon maps all devices with a mapping in dir concurrently until SIGINT or SIGTERM is
+// received. It returns after all virtual devices are released. When run as a systemd service,
+// readiness is notified once devices are monitored and the watchdog is pinged.
+func runDaemon(dir string) error {
+	if dir == "" {
+		var err error
+		if dir, err = mappingDir(); err != nil {
+			return err
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	monitor, err := discover.NewWiimoteMonitor()
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer systemdutil.Stopping()
+	go systemdutil.RunWatchdog(ctx, nil)
+	if err := systemdutil.Ready(); err != nil {
+		log.Printf("unable to notify systemd: %v\n", err)
+	}
+	fmt.Printf("waiting for devices, mappings are read from %s\n", dir)
+	for {
+		info, err := monitor.WaitContext(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil || info == nil {
+			log.Printf("error while polling: %v\n", err)
+			continue
+		}
+		mapping, err := loadMapping(dir, info)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("no mapping for %s, ignoring device\n", info.Syspath)
+			continue
+		} else if err != nil {
+			log.Printf("unable to load mapping: %v\n", err)
+			continue
+		}
+		dev, err := driver.NewDevice(info.Device, driver.BackendKernel)
+		if err != nil {
+			log.Printf("error creating device: %v\n", err)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer dev.Cleanup()
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			reload, err := watchMapping(ctx, dir, info)
+			if err != nil {
+				log.Printf("unable to watch mappings, changes are not reloaded: %v\n", err)
+			}
+			watchDevice(ctx, dev, mapping, reload)
+		}()
+	}
+}
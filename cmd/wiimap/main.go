@@ -1,71 +1,122 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"flag"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"os"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/friedelschoen/go-uinput"
 	"github.com/friedelschoen/go-wiimote"
 	"github.com/friedelschoen/go-wiimote/driver"
 	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/mapper"
+	"github.com/friedelschoen/go-wiimote/pkg/profile"
+	"github.com/friedelschoen/go-wiimote/pkg/rumble"
+	"github.com/friedelschoen/go-wiimote/pkg/systemdutil"
+	"github.com/friedelschoen/go-wiimote/pkg/vinput"
 )
 
 var (
-	kbname = flag.String("name", "wiimote-virtual", "Name to use")
+	kbname   = flag.String("name", "wiimote-virtual", "Name to use")
+	version  = flag.Bool("version", false, "Print version information and exit")
+	debug    = flag.Bool("debug", false, "Log debug messages of the driver")
+	record   = flag.String("record", "", "Record mappings by example and append them to this file")
+	keyboard = flag.String("keyboard", "", "Keyboard event-device (/dev/input/eventX) to read keys from in record mode")
+	outkind  = flag.String("output", "keyboard", "Output device to create, either keyboard or gamepad (e.g. \"KEY_A -> BTN_SOUTH\")")
+	daemon   = flag.Bool("daemon", false, "Map all devices concurrently using the mappings of -config instead of reading a mapping from stdin, changed mappings are reloaded")
+	config   = flag.String("config", "", "Directory of the mappings in daemon mode, the user configuration directory by default")
+	unit     = flag.Bool("unit", false, "Print a systemd user unit running the daemon with the given flags and exit")
+	repdelay = flag.Duration("repeat-delay", 0, "Repeat held keys of the keyboard output after this delay, 0 disables key repeat")
+	reprate  = flag.Float64("repeat-rate", 25, "Key repeats per second if -repeat-delay is set")
 )
 
-func loadMapping(r io.Reader) map[wiimote.Key]uinput.Key {
-	mapping := make(map[wiimote.Key]uinput.Key)
-	scan := bufio.NewScanner(r)
-	for scan.Scan() {
-		line := scan.Text()
-		wiibuttonstr, realkeystr, ok := strings.Cut(line, "->")
-		if !ok {
-			fmt.Fprintf(os.Stderr, "error: missing delimiter: %s\n", line)
-			continue
-		}
-		wiibutton, ok := wiimote.LookupKey(strings.TrimSpace(wiibuttonstr))
-		if !ok {
-			fmt.Fprintf(os.Stderr, "error: unknown button: %s\n", wiibuttonstr)
-			continue
-		}
-		realkey, ok := uinput.LookupKey(strings.TrimSpace(realkeystr))
-		if !ok {
-			fmt.Fprintf(os.Stderr, "error: unknown key: %s\n", realkeystr)
-			continue
-		}
-		mapping[wiibutton] = realkey
-	}
-	return mapping
-}
-
-func watchDevice(dev wiimote.Device, mapping map[wiimote.Key]uinput.Key) {
+// watchDevice maps the keys of dev until it is gone or ctx is done. The mapping is swapped by
+// the mappings received from reload, which may be nil.
+func watchDevice(ctx context.Context, dev wiimote.Device, mapping *mapper.Mapping, reload <-chan *mapper.Mapping) {
 	fmt.Printf("new device: %s\n", dev.String())
 	time.Sleep(100 * time.Millisecond)
-	if err := dev.OpenFeatures(wiimote.FeatureCore, true); err != nil {
+	if err := dev.OpenFeatures(wiimote.FeatureCore|wiimote.FeatureClassicController|wiimote.FeatureProController, true); err != nil {
 		fmt.Fprintf(os.Stderr, "error: unable to open device: %s", err)
 	}
+	dev.AutoReopen(true)
+	dev.SetErrorPolicy(wiimote.ErrorClose)
+
+	var opts []vinput.Option
+	if *repdelay > 0 && *reprate > 0 {
+		// held keys are repeated by the kernel
+		opts = append(opts, vinput.WithRepeat(*repdelay, time.Duration(float64(time.Second) / *reprate)))
+	}
+	out, err := createOutput(*outkind, *kbname, mapping, opts...)
+	if err != nil {
+		log.Printf("unable to create output: %v\n", err)
+		return
+	}
+	defer out.Close()
+	// the bridge switches the motor while it is polled, so the device is only used by this goroutine
+	var src wiimote.Poller[wiimote.Event] = dev
+	if pad, ok := out.(gamepadOutput); ok {
+		bridge, err := rumble.NewBridge(dev)
+		if err != nil {
+			log.Printf("unable to create rumble bridge: %v\n", err)
+		} else {
+			src = bridge
+			defer bridge.Stop()
+			go func() {
+				if err := pad.ReadEvents(bridge.Feedback()); err != nil {
+					log.Printf("unable to read force-feedback: %v\n", err)
+				}
+			}()
+		}
+	}
+	live := &liveMapping{exec: mapper.NewExecutor(out), mapping: mapping}
+	defer live.Stop()
+	if reload != nil {
+		keys := mapping.Keys()
+		go func() {
+			for mapping := range reload {
+				if _, ok := out.(gamepadOutput); ok && slices.ContainsFunc(mapping.Keys(), func(key uinput.Key) bool { return !slices.Contains(keys, key) }) {
+					log.Printf("reloaded mapping of %s uses new buttons, restart to add them to the gamepad\n", dev.String())
+				}
+				live.Swap(mapping)
+				fmt.Printf("reloaded mapping of %s\n", dev.String())
+			}
+		}()
+	}
 
-	kb, err := uinput.CreateKeyboard(*kbname)
+	settings, err := profile.Load(dev)
 	if err != nil {
-		panic(err)
+		log.Printf("unable to load profile: %v\n", err)
+		settings = &profile.Settings{}
+	}
+	if err := profile.Apply(dev, settings); err != nil {
+		log.Printf("unable to apply profile: %v\n", err)
 	}
-	defer kb.Close()
 	var leds wiimote.Led
+	if settings.LED != nil {
+		leds = *settings.LED
+	}
 
-	rumbleif := dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature)
+	rumbleif, _ := dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature)
 	for {
-		ev, err := dev.Wait(-1)
+		ev, err := src.WaitContext(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
+			// would-block is retried by the poller, other errors persist
 			log.Printf("unable to poll event: %v\n", err)
+			return
 		}
 		switch ev := ev.(type) {
+		case *wiimote.EventClassicControllerMove:
+			out.Stick(wiimote.ClassicControllerStick.Normalize(ev.StickLeft))
+		case *wiimote.EventProControllerMove:
+			out.Stick(ev.Normalized()[0])
 		case *wiimote.EventKey:
 			if ev.Code == wiimote.KeyHome {
 				if rumbleif != nil {
@@ -78,15 +129,25 @@ func watchDevice(dev wiimote.Device, mapping map[wiimote.Key]uinput.Key) {
 					leds %= 16
 
 					fmt.Println(dev.SetLED(leds))
+					settings.LED = &leds
+					if err := profile.Save(dev, settings); err != nil {
+						log.Printf("unable to save profile: %v\n", err)
+					}
 					continue
 				}
 			}
 
-			realkey, ok := mapping[ev.Code]
-			if !ok {
-				continue
+			live.Apply(ev)
+		case *wiimote.EventClassicControllerKey, *wiimote.EventProControllerKey:
+			live.Apply(ev)
+		case *wiimote.EventFeatureOpened:
+			if ev.Kind == wiimote.FeatureCore {
+				rumbleif, _ = dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature)
 			}
-			kb.Key(realkey, ev.Pressed)
+		case *wiimote.EventExtensionConnected:
+			fmt.Printf("extension connected: %s\n", ev.Type)
+		case *wiimote.EventExtensionDisconnected:
+			fmt.Printf("extension disconnected: %s\n", ev.Type)
 		case *wiimote.EventGone:
 			return
 		}
@@ -95,8 +156,44 @@ func watchDevice(dev wiimote.Device, mapping map[wiimote.Key]uinput.Key) {
 
 func main() {
 	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *debug {
+		wiimote.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	if *unit {
+		u, err := systemdutil.NewUnit("Wii remote mapper", append(systemdutil.FlagArgs(flag.CommandLine, "unit", "daemon"), "-daemon")...)
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		u.Watchdog = 30 * time.Second
+		fmt.Print(u)
+		return
+	}
+	defer driver.Shutdown()
+	if *daemon {
+		// devices are released before exiting, see runDaemon
+		if err := runDaemon(*config); err != nil {
+			log.Fatalln("error: ", err)
+		}
+		return
+	}
+	driver.CleanupOnSignal()
+
+	if *record != "" && *keyboard == "" {
+		log.Fatalln("error: -record requires -keyboard")
+	}
 
-	mapping := loadMapping(os.Stdin)
+	var mapping *mapper.Mapping
+	if *record == "" {
+		var err error
+		mapping, err = mapper.Parse(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
 
 	monitor, err := discover.NewWiimoteMonitor()
 	if err != nil {
@@ -110,11 +207,16 @@ func main() {
 			log.Printf("error while polling: %v\n", err)
 			continue
 		}
-		d, err := driver.NewDevice(dev, driver.BackendKernel)
+		d, err := driver.NewDevice(dev.Device, driver.BackendKernel)
 		if err != nil {
 			log.Printf("error creating device: %v\n", err)
 			continue
 		}
-		watchDevice(d, mapping)
+		if *record != "" {
+			recordMapping(d, *keyboard, *record)
+			return
+		}
+		watchDevice(context.Background(), d, mapping, nil)
+		d.Cleanup()
 	}
 }
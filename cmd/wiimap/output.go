@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/friedelschoen/go-uinput"
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/pkg/mapper"
+	"github.com/friedelschoen/go-wiimote/pkg/vinput"
+)
+
+// axisMax is the range of the gamepad axes.
+const axisMax = 32767
+
+// output is a virtual device receiving the mapped keys.
+type output interface {
+	Key(key uinput.Key, pressed bool) error
+	// Stick sets the position of the analog stick in the range -1..1
+	Stick(pos wiimote.FVec2) error
+	Close() error
+}
+
+type keyboardOutput struct {
+	*vinput.Keyboard
+}
+
+func (k keyboardOutput) Key(key uinput.Key, pressed bool) error {
+	return k.Keyboard.Key(vinput.Key(key), pressed)
+}
+
+func (keyboardOutput) Stick(wiimote.FVec2) error { return nil }
+
+// gamepadOutput is a virtual gamepad with the mapped keys as buttons and the left stick of
+// the Classic or Pro Controller as absolute axes. Rumble effects of games are played on the
+// device, see rumble.Bridge.
+type gamepadOutput struct {
+	*vinput.Gamepad
+}
+
+func (g gamepadOutput) Key(key uinput.Key, pressed bool) error {
+	return g.Gamepad.Key(vinput.Key(key), pressed)
+}
+
+func (g gamepadOutput) Stick(pos wiimote.FVec2) error {
+	return g.Gamepad.Stick(vinput.AxisLeftX, vinput.AxisLeftY, int32(math.Round(pos.X*axisMax)), int32(math.Round(-pos.Y*axisMax)))
+}
+
+// createOutput creates the output device of kind. The options are only applied to keyboards, as
+// gamepad buttons are not repeated.
+func createOutput(kind, name string, mapping *mapper.Mapping, opts ...vinput.Option) (output, error) {
+	switch kind {
+	case "keyboard":
+		kb, err := vinput.CreateKeyboard(name, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return keyboardOutput{kb}, nil
+	case "gamepad":
+		axis := vinput.Range{Min: -axisMax, Max: axisMax, Flat: axisMax / 16}
+		axes := map[vinput.Axis]vinput.Range{vinput.AxisLeftX: axis, vinput.AxisLeftY: axis}
+		var keys []vinput.Key
+		for _, key := range mapping.Keys() {
+			keys = append(keys, vinput.Key(key))
+		}
+		pad, err := vinput.CreateGamepad(name, axes, keys, vinput.WithForceFeedback(16))
+		if err != nil {
+			return nil, err
+		}
+		return gamepadOutput{pad}, nil
+	}
+	return nil, fmt.Errorf("unknown output: %s", kind)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/friedelschoen/go-uinput"
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// inputEvent is struct input_event of linux/input.h
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+const evKey = 0x01
+
+// readKeyPress blocks until a key is pressed on the keyboard kbd after since
+// and returns its key-code.
+func readKeyPress(kbd *os.File, since time.Time) (uinput.Key, error) {
+	for {
+		var ev inputEvent
+		if err := binary.Read(kbd, binary.NativeEndian, &ev); err != nil {
+			return 0, err
+		}
+		if ev.Type != evKey || ev.Value != 1 {
+			continue
+		}
+		// skip events which were buffered before we asked for a key
+		if time.Unix(ev.Time.Unix()).Before(since) {
+			continue
+		}
+		return uinput.Key(ev.Code), nil
+	}
+}
+
+// recordMapping asks for a wiimote button and then for a key on the keyboard kbdpath
+// and appends the resulting mapping to outpath until the program is interrupted.
+func recordMapping(dev wiimote.Device, kbdpath, outpath string) {
+	kbd, err := os.Open(kbdpath)
+	if err != nil {
+		log.Fatalf("error: unable to open keyboard: %v", err)
+	}
+	defer kbd.Close()
+
+	out, err := os.OpenFile(outpath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		log.Fatalf("error: unable to open mapping: %v", err)
+	}
+	defer out.Close()
+
+	if err := dev.OpenFeatures(wiimote.FeatureCore, false); err != nil {
+		log.Fatalf("error: unable to open device: %v", err)
+	}
+
+	for {
+		fmt.Println("press a button on the wiimote...")
+		var button wiimote.Key
+	wait:
+		for {
+			ev, err := dev.Wait(-1)
+			if err != nil {
+				log.Printf("unable to poll event: %v\n", err)
+				return
+			}
+			switch ev := ev.(type) {
+			case *wiimote.EventKey:
+				if ev.Pressed {
+					button = ev.Code
+					break wait
+				}
+			case *wiimote.EventGone:
+				return
+			}
+		}
+
+		fmt.Printf("press the key on the keyboard to map %v to...\n", button)
+		key, err := readKeyPress(kbd, time.Now())
+		if err != nil {
+			log.Fatalf("error: unable to read keyboard: %v", err)
+		}
+
+		fmt.Printf("%v -> %v\n", button, key)
+		if _, err := fmt.Fprintf(out, "%v -> %v\n", button, key); err != nil {
+			log.Fatalf("error: unable to write mapping: %v", err)
+		}
+	}
+}
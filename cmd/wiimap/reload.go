@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/mapper"
+	"golang.org/x/sys/unix"
+)
+
+// liveMapping is a mapping which may be swapped while keys are mapped.
+type liveMapping struct {
+	exec *mapper.Executor
+
+	mu      sync.Mutex
+	mapping *mapper.Mapping
+}
+
+// Apply applies ev to the mapping and runs the resulting actions.
+func (l *liveMapping) Apply(ev wiimote.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, oa := range l.mapping.Apply(ev) {
+		l.exec.Run(oa)
+	}
+}
+
+// Swap replaces the mapping by mapping. Held keys, toggles and macros of the old mapping are
+// released first.
+func (l *liveMapping) Swap(mapping *mapper.Mapping) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exec.Stop()
+	l.mapping.Reset()
+	l.mapping = mapping
+}
+
+// Stop releases all keys of the mapping.
+func (l *liveMapping) Stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exec.Stop()
+	l.mapping.Reset()
+}
+
+// watchMapping watches dir and sends the mapping of info to the returned channel whenever one of
+// its mapping files (see mappingNames) is changed, until ctx is done. If a mapping file is
+// removed, the next mapping is sent; if none is left, nothing is sent.
+func watchMapping(ctx context.Context, dir string, info *discover.DeviceInfo) (<-chan *mapper.Mapping, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// editors either rewrite the file or replace it by renaming
+	if _, err := unix.InotifyAddWatch(fd, dir, unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO|unix.IN_MOVED_FROM|unix.IN_DELETE); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	// file is non-blocking, so Close interrupts Read
+	file := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		<-ctx.Done()
+		file.Close()
+	}()
+
+	names := mappingNames(info)
+	reload := make(chan *mapper.Mapping)
+	go func() {
+		defer close(reload)
+		var buf [4096]byte
+		for {
+			n, err := file.Read(buf[:])
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Printf("unable to watch mappings: %v\n", err)
+				return
+			}
+			changed := false
+			for off := 0; off+unix.SizeofInotifyEvent <= n; {
+				ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+				name := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(ev.Len)]
+				if i := slices.Index(name, 0); i >= 0 {
+					name = name[:i]
+				}
+				if slices.Contains(names, string(name)) {
+					changed = true
+				}
+				off += unix.SizeofInotifyEvent + int(ev.Len)
+			}
+			if !changed {
+				continue
+			}
+			mapping, err := loadMapping(dir, info)
+			if errors.Is(err, fs.ErrNotExist) {
+				log.Printf("no mapping left for %s, keeping the current mapping\n", info.Syspath)
+				continue
+			} else if err != nil {
+				log.Printf("unable to reload mapping: %v\n", err)
+				continue
+			}
+			select {
+			case reload <- mapping:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return reload, nil
+}
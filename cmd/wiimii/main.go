@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"time"
 
@@ -13,7 +14,11 @@ import (
 	"github.com/friedelschoen/go-wiimote/pkg/eeprom"
 )
 
+var version = flag.Bool("version", false, "Print version information and exit")
+var debug = flag.Bool("debug", false, "Log debug messages of the driver")
+
 func watchDevice(dev wiimote.Device) {
+	defer dev.Cleanup()
 	fmt.Printf("new device: %s\n", dev.String())
 	time.Sleep(100 * time.Millisecond)
 
@@ -41,6 +46,15 @@ func watchDevice(dev wiimote.Device) {
 
 func main() {
 	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *debug {
+		wiimote.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	defer driver.Shutdown()
+	driver.CleanupOnSignal()
 
 	monitor, err := discover.NewWiimoteMonitor()
 	if err != nil {
@@ -53,7 +67,7 @@ func main() {
 		log.Printf("error while polling: %v\n", err)
 		return
 	}
-	d, err := driver.NewDevice(dev, driver.BackendHID)
+	d, err := driver.NewDevice(dev.Device, driver.BackendHID)
 	if err != nil {
 		log.Printf("error creating device: %v\n", err)
 		return
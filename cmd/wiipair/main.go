@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/pkg/bluez"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/pairing"
+	"github.com/friedelschoen/go-wiimote/pkg/systemdutil"
+)
+
+var (
+	version = flag.Bool("version", false, "Print version information and exit")
+	players = flag.Bool("players", false, "Show a player number on the LEDs of connected remotes")
+	rescan  = flag.Duration("rescan", pairing.DefaultRescan, "Interval in which scanning is restarted")
+	unit    = flag.Bool("unit", false, "Print a systemd user unit running the service with the given flags and exit")
+)
+
+func main() {
+	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *unit {
+		u, err := systemdutil.NewUnit("Wii remote pairing service", systemdutil.FlagArgs(flag.CommandLine, "unit")...)
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		fmt.Print(u)
+		return
+	}
+	defer driver.Shutdown()
+
+	d, err := pairing.NewDaemon()
+	if err != nil {
+		log.Fatalln("error: unable to connect to bluez:", err)
+	}
+	d.Rescan = *rescan
+	d.Players = *players
+	d.Paired = func(dev bluez.Device, err error) {
+		if err != nil {
+			log.Printf("unable to pair %s (%s): %v\n", dev.Address, dev.Name, err)
+			return
+		}
+		log.Printf("paired %s (%s)\n", dev.Address, dev.Name)
+	}
+	d.Connected = func(info *discover.DeviceInfo, err error) {
+		if err != nil {
+			log.Printf("unable to assign player %d to %s: %v\n", info.Player, info.Uniq, err)
+			return
+		}
+		log.Printf("assigned player %d to %s\n", info.Player, info.Uniq)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := systemdutil.Ready(); err != nil {
+		log.Printf("unable to notify systemd: %v\n", err)
+	}
+	log.Printf("press the red sync button of a remote to pair it with %s\n", d.Client.Adapter())
+	if err := d.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatalln("error: ", err)
+	}
+}
@@ -4,51 +4,163 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/friedelschoen/go-uinput"
 	"github.com/friedelschoen/go-wiimote"
 	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
 	"github.com/friedelschoen/go-wiimote/pkg/discover"
 	"github.com/friedelschoen/go-wiimote/pkg/irpointer"
+	"github.com/friedelschoen/go-wiimote/pkg/pointerctl"
+	"github.com/friedelschoen/go-wiimote/pkg/profile"
+	"github.com/friedelschoen/go-wiimote/pkg/vinput"
 )
 
 var ScrollSpeed = flag.Float64("scrollspeed", 0.01, "Set the vertical scrollspeed")
 var HorizScrollSpeed = flag.Float64("hscrollspeed", 0.01, "Set the horizontal scrollspeed")
+var Simulate = flag.Bool("sim", false, "Use a simulated device instead of connected wiimotes")
+var ShowVersion = flag.Bool("version", false, "Print version information and exit")
+var Debug = flag.Bool("debug", false, "Log debug messages of the driver")
+var Tablet = flag.Bool("tablet", false, "Report the pointer as an absolute pen tablet instead of a mouse, A touches the surface")
+var Scenario = flag.String("scenario", "", "Scenario file to drive the simulated device, implies -sim")
+var SensorBar = flag.String("sensorbar", "auto", "Placement of the sensor bar: above, below or auto to detect it while aiming")
+var Screen = flag.String("screen", "auto", "Monitor to point at: WxH[+X+Y], the name of an output or auto for the primary monitor")
+var Wide = flag.Bool("wide", false, "Map a wider area around the sensor bar onto the screen")
+var Curve = flag.String("curve", "linear", "Sensitivity curve: linear[:GAIN], power:EXPONENT[:GAIN] or points:X,Y X,Y ...")
+var Recenter = flag.String("recenter", "", "Button which moves the current aim to the center of the screen, e.g. home")
+var MotionPlus = flag.Bool("motionplus", false, "Continue pointing with the Motion Plus while the sensor bar is out of view")
+var IgnoreSlots = flag.String("ignoreslots", "", "Comma-separated IR slots (0-3) to ignore, for sensor bars producing ghost dots")
+var Relative = flag.Bool("relative", false, "Move the cursor by the movement of the pointer, the curve accelerates fast movements")
+var SensX = flag.Float64("sens-x", 1, "Scale the horizontal movement of the cursor")
+var SensY = flag.Float64("sens-y", 1, "Scale the vertical movement of the cursor")
+var InvertX = flag.Bool("invert-x", false, "Invert the horizontal axis")
+var InvertY = flag.Bool("invert-y", false, "Invert the vertical axis, e.g. for a sensor bar mounted upside down")
+var TouchMode = flag.Bool("touch", false, "Emulate a touch screen, a quick A press taps and B drags, implies -tablet")
+var TapMax = flag.Duration("tap-max", pointerctl.DefaultTouchOptions().TapMax, "Longest A press producing a tap in -touch mode, longer presses hold the touch")
+var DragHold = flag.Duration("drag-hold", 0, "Time B is held before dragging in -touch mode")
 
 func watchDevice(dev wiimote.Device) {
+	defer dev.Cleanup()
 	bat, _ := dev.Battery()
 	fmt.Printf("new wiimote at %s with %d%% battery, cap=%v\n", dev.Syspath(), bat, dev.Available(wiimote.FeatureIR))
 
-	mouse, err := uinput.CreateMouse("wiimote-mouse",
-		uinput.Range{Min: -340, Max: 340, Res: 72},
-		uinput.Range{Min: -92, Max: 290, Res: 72}, []uinput.Key{
-			uinput.ButtonLeft,
-			uinput.ButtonRight,
-			uinput.KeyLeftmeta,
-			uinput.ButtonBack,
-			uinput.ButtonForward,
-			uinput.KeyVolumedown,
-			uinput.KeyVolumeup,
-			uinput.KeyPlaypause,
-			uinput.KeyNext,
-		})
+	target, desktop, err := selectScreen(*Screen)
+	if err != nil {
+		log.Printf("unable to detect screen, assuming 1920x1080: %v\n", err)
+		target = screen{Width: 1920, Height: 1080}
+		desktop = target
+	}
+	fmt.Printf("pointing at %v of %dx%d desktop\n", target, desktop.Width, desktop.Height)
+
+	// absolute devices span the whole desktop
+	xrange := vinput.Range{Min: 0, Max: desktop.Width - 1}
+	yrange := vinput.Range{Min: 0, Max: desktop.Height - 1}
+
+	var placement irpointer.Placement
+	switch *SensorBar {
+	case "above":
+		placement = irpointer.PlacementAbove
+	case "below":
+		placement = irpointer.PlacementBelow
+	}
+	coverage := irpointer.CoverageSafe
+	if *Wide {
+		coverage = irpointer.CoverageWide
+	}
+	screenFilter := irpointer.NewScreenFilter(placement, coverage)
+	screenFilter.Destination = irpointer.FRect{
+		Min: irpointer.FVec2{X: float64(target.X), Y: float64(target.Y)},
+		Max: irpointer.FVec2{X: float64(target.X + target.Width - 1), Y: float64(target.Y + target.Height - 1)},
+	}
+
+	curve, err := irpointer.ParseCurve(*Curve)
+	if err != nil {
+		log.Fatalf("error: invalid curve: %v", err)
+	}
+	// the sensitivity is applied in pointer space, the relative cursor moves in screen space
+	var sensitivity irpointer.Filter = irpointer.NewSensitivityFilter(curve)
+	var relative *irpointer.RelativeFilter
+	if *Relative {
+		sensitivity, relative = nil, irpointer.NewRelativeFilter(curve, screenFilter.Destination)
+	}
+	recenter := irpointer.NewRecenterFilter(screenFilter.Destination)
+	adjust := pointerctl.Options{SensX: *SensX, SensY: *SensY, InvertX: *InvertX, InvertY: *InvertY}
+	if err := adjust.Validate(); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	recenterKey, hasRecenter := wiimote.ParseKey(*Recenter)
+	if *Recenter != "" && !hasRecenter {
+		log.Fatalf("error: unknown button %q", *Recenter)
+	}
+
+	var touch *pointerctl.Touch
+	if *TouchMode {
+		opts := pointerctl.DefaultTouchOptions()
+		opts.TapMax, opts.DragHold = *TapMax, *DragHold
+		touch = pointerctl.NewTouch(opts)
+	}
+
+	var tablet *vinput.Tablet
+	if *Tablet || touch != nil {
+		tablet, err = vinput.CreateTablet("wiimote-tablet", xrange, yrange)
+		if err != nil {
+			log.Fatalf("error: unable to create tablet: %v", err)
+		}
+		defer tablet.Close()
+		// the mouse only reports buttons and scrolling
+		xrange, yrange = vinput.Range{}, vinput.Range{}
+	}
+
+	mouse, err := vinput.CreateMouse("wiimote-mouse", xrange, yrange, []vinput.Key{
+		vinput.Key(uinput.ButtonLeft),
+		vinput.Key(uinput.ButtonRight),
+		vinput.Key(uinput.KeyLeftmeta),
+		vinput.Key(uinput.ButtonBack),
+		vinput.Key(uinput.ButtonForward),
+		vinput.Key(uinput.KeyVolumedown),
+		vinput.Key(uinput.KeyVolumeup),
+		vinput.Key(uinput.KeyPlaypause),
+		vinput.Key(uinput.KeyNext),
+	})
 	if err != nil {
 		log.Fatalf("error: unable to create mouse: %v", err)
 	}
 	defer mouse.Close()
 
-	if err := dev.OpenFeatures(wiimote.FeatureCore|wiimote.FeatureAccel|wiimote.FeatureIR, true); err != nil {
+	// nothing is written to the features, the LEDs are written through sysfs
+	features := wiimote.FeatureCore | wiimote.FeatureAccel | wiimote.FeatureIR
+	if *MotionPlus {
+		features |= wiimote.FeatureMotionPlus
+	}
+	if err := wiimote.Open(dev, features); err != nil {
 		log.Fatalf("error: unable to open device: %v", err)
 	}
+	dev.SetErrorPolicy(wiimote.ErrorClose)
 
-	pointer := irpointer.NewIRPointer()
-	process := irpointer.FilterChain{
-		irpointer.NewErrorFilter(),
-		irpointer.NewGlitchFilter(),
-		irpointer.NewOneEuroSmoothing(),
-		irpointer.NewRepeatFilter(),
+	var opts []irpointer.Option
+	if settings, err := profile.Load(dev); err != nil {
+		log.Printf("unable to load profile: %v\n", err)
+	} else if settings.IR != nil {
+		opts = append(opts, irpointer.WithParams(settings.IR))
+	}
+	if *IgnoreSlots != "" {
+		var mask wiimote.IRMask
+		for _, field := range strings.Split(*IgnoreSlots, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || n < 0 || n > 3 {
+				log.Fatalf("error: invalid IR slot %q", field)
+			}
+			mask |= wiimote.IRSlots(n)
+		}
+		opts = append(opts, irpointer.WithIgnoreSlots(mask))
 	}
+	pointer := irpointer.NewIRPointer(opts...)
+	process := irpointer.NewDefaultFilters()
 
 	holdSmooth := irpointer.NewOneEuroSmoothing()
 	holdSmooth.MinCutoff = 0.15
@@ -61,6 +173,10 @@ func watchDevice(dev wiimote.Device) {
 		irpointer.NewRepeatFilter(),
 	}
 
+	// the goroutines stop with the device, so it is released
+	done := make(chan struct{})
+	defer close(done)
+
 	var frame irpointer.Frame
 	go func() {
 		blink := false
@@ -100,7 +216,11 @@ func watchDevice(dev wiimote.Device) {
 			}
 
 			dev.SetLED(leds)
-			time.Sleep(500 * time.Millisecond)
+			select {
+			case <-done:
+				return
+			case <-time.After(500 * time.Millisecond):
+			}
 		}
 	}()
 
@@ -108,7 +228,11 @@ func watchDevice(dev wiimote.Device) {
 	go func() {
 		for {
 			if scroll == nil {
-				time.Sleep(100 * time.Millisecond)
+				select {
+				case <-done:
+					return
+				case <-time.After(100 * time.Millisecond):
+				}
 				continue
 			}
 
@@ -117,49 +241,112 @@ func watchDevice(dev wiimote.Device) {
 			scrollx, scrolly := int32(*HorizScrollSpeed*dx), int32(*ScrollSpeed*dy)
 			fmt.Printf("[%v] scroll to (%d %d) at %.2fcm distance\n", frame.Health, scrollx, scrolly, frame.Distance)
 			mouse.Scroll(scrollx, scrolly)
-			time.Sleep(50 * time.Millisecond)
+			select {
+			case <-done:
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
 		}
 	}()
 
-	var lastIR *wiimote.EventIR
-	var lastAccel *wiimote.EventAccel
 	var hold time.Time
+	var chain irpointer.FilterChain
+	if *MotionPlus {
+		chain = append(chain, irpointer.NewHybridFilter())
+	}
+	chain = append(chain, &holdFilter{normal: process, hold: holdProcess, since: &hold})
+	if sensitivity != nil {
+		chain = append(chain, sensitivity)
+	}
+	chain = append(chain, screenFilter)
+	// the cursor is adjusted in screen space, before it is moved relatively
+	if relative != nil {
+		chain = append(chain, adjust.Filters(recenter.Center)...)
+		chain = append(chain, relative)
+	} else {
+		chain = append(chain, recenter)
+		chain = append(chain, adjust.Filters(recenter.Center)...)
+	}
+	pipeline := irpointer.NewPipeline(pointer, chain, func(f irpointer.Frame) {
+		frame = f
+		// frames of the Motion Plus keep the health of the IR
+		if frame.Valid && (frame.Health >= irpointer.IRGood || *MotionPlus) && scroll == nil {
+			x, y := frame.Position.X, frame.Position.Y
+			fmt.Printf("[%v] pointer at (%.2f %.2f) at %.2fcm distance\n", frame.Health, x, y, frame.Distance)
+			if tablet != nil {
+				tablet.Set(int32(x), int32(y))
+			} else {
+				mouse.Set(int32(x), int32(y))
+			}
+		} else if !frame.Valid && tablet != nil {
+			tablet.Leave()
+		}
+	})
 	for {
 		ev, err := dev.Wait(-1)
 		if err != nil {
 			log.Printf("unable to poll event: %v\n", err)
+			return
+		}
+		// the pointer is frozen shortly after pressing a button, to not move while clicking
+		if hold.IsZero() || time.Since(hold) > 500*time.Millisecond {
+			pipeline.Handle(ev)
+		}
+		if touch != nil {
+			for _, down := range touch.Tick(time.Now()) {
+				tablet.Touch(down)
+			}
 		}
 		switch ev := ev.(type) {
-		case *wiimote.EventIR:
-			lastIR = ev
-		case *wiimote.EventAccel:
-			lastAccel = ev
+		case *wiimote.EventGone:
+			return
 		case *wiimote.EventKey:
+			if hasRecenter && ev.Code == recenterKey {
+				if ev.Pressed {
+					fmt.Println("recentering pointer")
+					if relative != nil {
+						relative.Recenter()
+					} else {
+						recenter.Recenter()
+					}
+				}
+				continue
+			}
 			if ev.Code != wiimote.KeyDown {
 				hold = time.Time{}
 				if ev.Pressed {
 					hold = time.Now()
 				}
 			}
+			if touch != nil && (ev.Code == touch.TapKey || ev.Code == touch.DragKey) {
+				for _, down := range touch.Key(ev.Code, ev.Pressed, time.Now()) {
+					tablet.Touch(down)
+				}
+				continue
+			}
 			switch ev.Code {
 			case wiimote.KeyA:
-				mouse.Key(uinput.ButtonLeft, ev.Pressed)
+				if tablet != nil {
+					tablet.Touch(ev.Pressed)
+				} else {
+					mouse.Key(vinput.Key(uinput.ButtonLeft), ev.Pressed)
+				}
 			case wiimote.KeyB:
-				mouse.Key(uinput.ButtonRight, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.ButtonRight), ev.Pressed)
 			case wiimote.KeyHome:
-				mouse.Key(uinput.KeyLeftmeta, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.KeyLeftmeta), ev.Pressed)
 			case wiimote.KeyLeft:
-				mouse.Key(uinput.ButtonBack, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.ButtonBack), ev.Pressed)
 			case wiimote.KeyRight:
-				mouse.Key(uinput.ButtonForward, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.ButtonForward), ev.Pressed)
 			case wiimote.KeyMinus:
-				mouse.Key(uinput.KeyVolumedown, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.KeyVolumedown), ev.Pressed)
 			case wiimote.KeyPlus:
-				mouse.Key(uinput.KeyVolumeup, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.KeyVolumeup), ev.Pressed)
 			case wiimote.KeyTwo:
-				mouse.Key(uinput.KeyPlaypause, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.KeyPlaypause), ev.Pressed)
 			case wiimote.KeyOne:
-				mouse.Key(uinput.KeyNext, ev.Pressed)
+				mouse.Key(vinput.Key(uinput.KeyNext), ev.Pressed)
 			case wiimote.KeyDown:
 				if ev.Pressed {
 					if frame.Valid {
@@ -173,35 +360,72 @@ func watchDevice(dev wiimote.Device) {
 				}
 			}
 		}
-		if lastIR != nil && lastAccel != nil && (hold.IsZero() || time.Since(hold) > 500*time.Millisecond) {
-			frame = pointer.Step(lastIR.Slots, lastAccel.Accel)
-			holdframe := holdProcess.Apply(frame)
-			regframe := process.Apply(frame)
-			if !hold.IsZero() {
-				frame = holdframe
-			} else {
-				frame = regframe
-			}
-			lastIR = nil
-			lastAccel = nil
-		}
-		if frame.Valid && frame.Health >= irpointer.IRGood {
-			x, y := frame.Position.X, frame.Position.Y
-			if scroll == nil {
-				fmt.Printf("[%v] pointer at (%.2f %.2f) at %.2fcm distance\n", frame.Health, x, y, frame.Distance)
-				mouse.Set(int32(x), int32(y))
-			}
+	}
+}
+
+// holdFilter applies the hold-chain while a button is held and the normal chain otherwise.
+// Both chains process every frame to keep their state up to date.
+type holdFilter struct {
+	normal irpointer.FilterChain
+	hold   irpointer.FilterChain
+	since  *time.Time
+}
+
+func (f *holdFilter) Reset() {
+	for _, chain := range []irpointer.FilterChain{f.normal, f.hold} {
+		for _, filter := range chain {
+			filter.Reset()
 		}
 	}
 }
 
+func (f *holdFilter) Apply(frame irpointer.Frame) irpointer.Frame {
+	holdframe := f.hold.Apply(frame)
+	regframe := f.normal.Apply(frame)
+	if !f.since.IsZero() {
+		return holdframe
+	}
+	return regframe
+}
+
 func main() {
 	flag.Parse()
+	if *ShowVersion {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *Debug {
+		wiimote.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	defer driver.Shutdown()
+	driver.CleanupOnSignal()
+
+	if *Simulate || *Scenario != "" {
+		cfg := sim.DefaultConfig()
+		if *Scenario != "" {
+			file, err := os.Open(*Scenario)
+			if err != nil {
+				log.Fatalln("error: ", err)
+			}
+			cfg.Scenario, err = sim.ParseScenario(file)
+			file.Close()
+			if err != nil {
+				log.Fatalln("error: ", err)
+			}
+		}
+		d, err := sim.NewDevice(cfg)
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		watchDevice(d)
+		return
+	}
 
 	monitor, err := discover.NewWiimoteMonitor()
 	if err != nil {
 		log.Fatalln("error: ", err)
 	}
+	monitor.AssignPlayers(true)
 
 	fmt.Println("waiting for devices...")
 	for {
@@ -210,11 +434,16 @@ func main() {
 			log.Printf("error while polling: %v\n", err)
 			continue
 		}
-		d, err := driver.NewDevice(dev, driver.BackendKernel)
+		d, err := driver.NewDevice(dev.Device, driver.BackendKernel)
 		if err != nil {
 			log.Printf("error creating device: %v\n", err)
 			continue
 		}
+		if dev.Player != 0 {
+			if err := d.SetPlayerLED(dev.Player); err != nil {
+				log.Printf("unable to set player led: %v\n", err)
+			}
+		}
 		go watchDevice(d)
 	}
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// screen is the geometry of a monitor in the virtual desktop in pixels.
+type screen struct {
+	Name          string
+	Width, Height int
+	X, Y          int
+	Primary       bool
+}
+
+func (s screen) String() string {
+	return fmt.Sprintf("%dx%d+%d+%d", s.Width, s.Height, s.X, s.Y)
+}
+
+// parseGeometry parses WxH[+X+Y] as used by X11.
+func parseGeometry(str string) (screen, error) {
+	var s screen
+	n, _ := fmt.Sscanf(str, "%dx%d+%d+%d", &s.Width, &s.Height, &s.X, &s.Y)
+	if n != 2 && n != 4 || s.Width <= 0 || s.Height <= 0 {
+		return screen{}, fmt.Errorf("invalid geometry %q, expected WxH[+X+Y]", str)
+	}
+	return s, nil
+}
+
+// detectScreens returns the connected monitors using xrandr, which also works with XWayland.
+// If xrandr is not available, the preferred modes of connected DRM outputs are used, placed
+// side by side.
+func detectScreens() ([]screen, error) {
+	if out, err := exec.Command("xrandr", "--current").Output(); err == nil {
+		if screens := parseXrandr(out); len(screens) > 0 {
+			return screens, nil
+		}
+	}
+	return drmScreens()
+}
+
+// parseXrandr parses lines like "HDMI-1 connected primary 1920x1080+0+0 (normal ...)".
+func parseXrandr(out []byte) []screen {
+	var screens []screen
+	scan := bufio.NewScanner(bytes.NewReader(out))
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) < 3 || fields[1] != "connected" {
+			continue
+		}
+		primary := fields[2] == "primary"
+		for _, f := range fields[2:] {
+			if s, err := parseGeometry(f); err == nil && strings.Contains(f, "+") {
+				s.Name = fields[0]
+				s.Primary = primary
+				screens = append(screens, s)
+				break
+			}
+		}
+	}
+	return screens
+}
+
+func drmScreens() ([]screen, error) {
+	outputs, _ := filepath.Glob("/sys/class/drm/card*-*")
+	var screens []screen
+	x := 0
+	for _, out := range outputs {
+		status, err := os.ReadFile(filepath.Join(out, "status"))
+		if err != nil || strings.TrimSpace(string(status)) != "connected" {
+			continue
+		}
+		modes, err := os.ReadFile(filepath.Join(out, "modes"))
+		if err != nil {
+			continue
+		}
+		// the first mode is the preferred one
+		mode, _, _ := strings.Cut(string(modes), "\n")
+		s, err := parseGeometry(strings.TrimSpace(mode))
+		if err != nil {
+			continue
+		}
+		s.Name = strings.SplitN(filepath.Base(out), "-", 2)[1]
+		s.X = x
+		x += s.Width
+		screens = append(screens, s)
+	}
+	if len(screens) == 0 {
+		return nil, fmt.Errorf("no connected monitors found")
+	}
+	return screens, nil
+}
+
+// selectScreen returns the monitor described by arg and the size of the virtual desktop. arg is
+// either a geometry, the name of an output or "auto" for the primary monitor.
+func selectScreen(arg string) (target screen, desktop screen, err error) {
+	screens, detectErr := detectScreens()
+	for _, s := range screens {
+		desktop.Width = max(desktop.Width, s.X+s.Width)
+		desktop.Height = max(desktop.Height, s.Y+s.Height)
+	}
+
+	switch {
+	case arg == "auto":
+		if detectErr != nil {
+			return screen{}, screen{}, detectErr
+		}
+		target = screens[0]
+		for _, s := range screens {
+			if s.Primary {
+				target = s
+				break
+			}
+		}
+	case arg != "" && (arg[0] < '0' || arg[0] > '9'):
+		if detectErr != nil {
+			return screen{}, screen{}, detectErr
+		}
+		found := false
+		for _, s := range screens {
+			if s.Name == arg {
+				target, found = s, true
+				break
+			}
+		}
+		if !found {
+			return screen{}, screen{}, fmt.Errorf("no connected monitor %q", arg)
+		}
+	default:
+		if target, err = parseGeometry(arg); err != nil {
+			return screen{}, screen{}, err
+		}
+	}
+	desktop.Width = max(desktop.Width, target.X+target.Width)
+	desktop.Height = max(desktop.Height, target.Y+target.Height)
+	return target, desktop, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/remote"
+	"github.com/friedelschoen/go-wiimote/pkg/systemdutil"
+)
+
+var (
+	listen  = flag.String("listen", "localhost:9250", "Address to listen on unless the socket is passed by systemd, the protocol is not authenticated so other hosts must be allowed explicitly (e.g. \":9250\")")
+	network = flag.String("network", "tcp", "Network to listen on, tcp or unix")
+	simdev  = flag.Bool("sim", false, "Serve a simulated device instead of real devices")
+	version = flag.Bool("version", false, "Print version information and exit")
+)
+
+func main() {
+	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	defer driver.Shutdown()
+	driver.CleanupOnSignal()
+
+	// the socket is passed by systemd if the server is socket activated
+	ln, err := systemdutil.Listen(*network, *listen)
+	if err != nil {
+		log.Fatalln("error: ", err)
+	}
+	srv := remote.NewServer()
+	go func() {
+		log.Fatalln("error: ", srv.Serve(ln))
+	}()
+	if err := systemdutil.Ready(); err != nil {
+		log.Printf("unable to notify systemd: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "serving on %s\n", ln.Addr())
+
+	if *simdev {
+		dev, err := sim.NewDevice(sim.DefaultConfig())
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		fmt.Fprintf(os.Stderr, "serving %s as %s\n", dev, srv.Add(dev))
+		select {}
+	}
+
+	monitor, err := discover.NewWiimoteMonitor()
+	if err != nil {
+		log.Fatalln("error: ", err)
+	}
+	for {
+		info, err := monitor.Wait(-1)
+		if err != nil || info == nil {
+			log.Printf("error while polling: %v\n", err)
+			continue
+		}
+		dev, err := driver.NewDevice(info.Device, driver.BackendKernel)
+		if err != nil {
+			log.Printf("error creating device: %v\n", err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "serving %s as %s\n", dev, srv.Add(dev))
+	}
+}
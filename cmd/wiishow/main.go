@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+)
+
+var (
+	index    = flag.Int("device", 0, "Index of the device to show, see wiicheck")
+	simulate = flag.Bool("sim", false, "Use a simulated device instead of connected wiimotes")
+	version  = flag.Bool("version", false, "Print version information and exit")
+)
+
+// irWidth and irHeight are the size of the IR view in characters
+const (
+	irWidth  = 48
+	irHeight = 12
+)
+
+func openDevice() (wiimote.Device, error) {
+	if *simulate {
+		return sim.NewDevice(sim.DefaultConfig())
+	}
+	devs, err := discover.IterDevices()
+	if err != nil {
+		return nil, err
+	}
+	i := 0
+	for info := range devs {
+		if i == *index {
+			return driver.NewDevice(info.Device, driver.BackendKernel)
+		}
+		i++
+	}
+	return nil, fmt.Errorf("no device %d found, %d devices connected", *index, i)
+}
+
+func keyNames(keys wiimote.KeySet) string {
+	var names []string
+	for k := wiimote.KeyLeft; k <= wiimote.KeyFretFarLow; k++ {
+		if keys.Has(k) {
+			names = append(names, strings.TrimPrefix(wiimote.KeyName(k), "KEY_"))
+		}
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, " ")
+}
+
+func drawIR(t *terminal, slots [4]wiimote.IRSlot) {
+	var grid [irHeight][irWidth]byte
+	for y := range grid {
+		for x := range grid[y] {
+			grid[y][x] = '.'
+		}
+	}
+	for i, slot := range slots {
+		if !slot.Valid() {
+			continue
+		}
+		// the camera reports 0,0 at the bottom left
+		x := clamp(int(slot.X)*irWidth/1024, 0, irWidth-1)
+		y := clamp(irHeight-1-int(slot.Y)*irHeight/768, 0, irHeight-1)
+		grid[y][x] = byte('1' + i)
+	}
+	for _, row := range grid {
+		t.Printf("  %s", row[:])
+	}
+}
+
+// snapshot is the state of the device shown by draw. It is taken by the goroutine polling the
+// device, so the device is not used concurrently.
+type snapshot struct {
+	name    string
+	battery uint
+	batErr  error
+	leds    wiimote.Led
+	rumble  bool
+	ext     string
+	state   wiimote.State
+	opened  wiimote.FeatureKind
+}
+
+func takeSnapshot(dev wiimote.Device, rumble bool) snapshot {
+	snap := snapshot{name: dev.String(), rumble: rumble, state: dev.State()}
+	snap.battery, snap.batErr = dev.Battery()
+	snap.leds, _ = dev.LED()
+	snap.ext, _ = dev.Extension()
+	for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+		if dev.Feature(kind) != nil {
+			snap.opened |= kind
+		}
+	}
+	return snap
+}
+
+func draw(t *terminal, snap snapshot) {
+	st := snap.state
+
+	t.Printf("%s", snap.name)
+	if snap.batErr != nil {
+		t.Printf("battery:    unknown (%v)", snap.batErr)
+	} else {
+		t.Printf("battery:    %3d%% %s", snap.battery, bar(int32(snap.battery), 0, 100, 20))
+	}
+	var ledstr strings.Builder
+	for i, led := range []wiimote.Led{wiimote.Led1, wiimote.Led2, wiimote.Led3, wiimote.Led4} {
+		if snap.leds&led != 0 {
+			fmt.Fprintf(&ledstr, "[%d] ", i+1)
+		} else {
+			fmt.Fprintf(&ledstr, " %d  ", i+1)
+		}
+	}
+	t.Printf("leds:       %s", ledstr.String())
+	t.Printf("rumble:     %v", snap.rumble)
+	t.Printf("extension:  %s", snap.ext)
+	t.Printf("")
+
+	t.Printf("keys:       %s", keyNames(st.Keys))
+	t.Printf("ext. keys:  %s", keyNames(st.ExtensionKeys))
+	g := wiimote.NominalAccel.G(st.Accel)
+	t.Printf("accel x:    %s %+.2fg", bar(st.Accel.X, -300, 300, 30), g.X)
+	t.Printf("accel y:    %s %+.2fg", bar(st.Accel.Y, -300, 300, 30), g.Y)
+	t.Printf("accel z:    %s %+.2fg", bar(st.Accel.Z, -300, 300, 30), g.Z)
+	t.Printf("")
+
+	t.Printf("ir:")
+	drawIR(t, st.IR)
+	t.Printf("")
+
+	if snap.opened&wiimote.FeatureMotionPlus != 0 {
+		deg := wiimote.MPDegrees(st.MotionPlus)
+		t.Printf("motion+:    %+7.1f %+7.1f %+7.1f deg/s", deg.X, deg.Y, deg.Z)
+	}
+	if snap.opened&wiimote.FeatureBalanceBoard != 0 {
+		t.Printf("weights:    %v", st.Weights)
+	}
+	if snap.opened&(wiimote.FeatureNunchuck|wiimote.FeatureClassicController|wiimote.FeatureProController|wiimote.FeatureDrums|wiimote.FeatureGuitar) != 0 {
+		t.Printf("sticks:     %v %v", st.Sticks[0], st.Sticks[1])
+		t.Printf("shoulders:  %v", st.Shoulders)
+		t.Printf("nunchuk:    accel %v", st.NunchukAccel)
+	}
+	t.Printf("")
+	t.Printf("1-4: toggle led, r: toggle rumble, q: quit")
+	t.Flush()
+}
+
+// pollDevice polls dev until it is gone, runs the commands of the keys received from cmds and
+// sends a snapshot to snaps every frame.
+func pollDevice(dev wiimote.Device, cmds <-chan byte, snaps chan<- snapshot) error {
+	const frame = time.Second / 30
+	rumble := false
+	next := time.Now()
+	for {
+		ev, err := dev.Wait(max(time.Until(next), 0))
+		if _, ok := ev.(*wiimote.EventGone); ok {
+			return nil
+		} else if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			return err
+		}
+		for pending := true; pending; {
+			select {
+			case key := <-cmds:
+				switch key {
+				case '1', '2', '3', '4':
+					on, _ := dev.LEDState(int(key - '1'))
+					dev.SetLEDState(int(key-'1'), !on)
+				case 'r':
+					if f, ok := dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature); ok {
+						rumble = !rumble
+						f.Rumble(rumble)
+					}
+				}
+			default:
+				pending = false
+			}
+		}
+		if !time.Now().Before(next) {
+			next = time.Now().Add(frame)
+			select {
+			case snaps <- takeSnapshot(dev, rumble):
+			default:
+				// the terminal did not draw the last snapshot yet
+			}
+		}
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	defer driver.Shutdown()
+	driver.CleanupOnSignal()
+
+	dev, err := openDevice()
+	if err != nil {
+		log.Fatalln("error: ", err)
+	}
+	if err := wiimote.Open(dev, wiimote.FeatureCore.Writable(), wiimote.FeatureSetCore&^wiimote.FeatureCore); err != nil {
+		log.Printf("unable to open features: %v\n", err)
+	}
+	dev.SetOpenPolicy(wiimote.OpenPolicy{Kinds: wiimote.PolicyAllAvailable.Kinds, Writable: true})
+
+	term, err := openTerminal()
+	if err != nil {
+		log.Fatalln("error: ", err)
+	}
+	defer term.Close()
+
+	var once sync.Once
+	done := make(chan struct{})
+	quit := func() { once.Do(func() { close(done) }) }
+
+	// the device is only used while polling
+	cmds := make(chan byte, 8)
+	snaps := make(chan snapshot, 1)
+	go func() {
+		if err := pollDevice(dev, cmds, snaps); err != nil {
+			log.Printf("unable to poll event: %v\n", err)
+		}
+		quit()
+	}()
+
+	keys := make(chan byte)
+	go func() {
+		for {
+			key, err := term.ReadKey()
+			if err != nil {
+				quit()
+				return
+			}
+			keys <- key
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case key := <-keys:
+			if key == 'q' || key == 3 { // ctrl-c
+				return
+			}
+			select {
+			case cmds <- key:
+			default:
+			}
+		case snap := <-snaps:
+			draw(term, snap)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminal puts the terminal into raw mode and draws whole frames using ANSI escapes.
+type terminal struct {
+	fd    int
+	saved *unix.Termios
+	buf   strings.Builder
+}
+
+func openTerminal() (*terminal, error) {
+	fd := int(os.Stdin.Fd())
+	saved, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return nil, fmt.Errorf("stdin is not a terminal: %w", err)
+	}
+	raw := *saved
+	raw.Lflag &^= unix.ECHO | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Iflag &^= unix.IXON | unix.ICRNL
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+	// alternate screen, hide cursor
+	os.Stdout.WriteString("\x1b[?1049h\x1b[?25l")
+	return &terminal{fd: fd, saved: saved}, nil
+}
+
+func (t *terminal) Close() error {
+	os.Stdout.WriteString("\x1b[?25h\x1b[?1049l")
+	return unix.IoctlSetTermios(t.fd, unix.TCSETS, t.saved)
+}
+
+// ReadKey blocks until a key is pressed.
+func (t *terminal) ReadKey() (byte, error) {
+	var b [1]byte
+	_, err := os.Stdin.Read(b[:])
+	return b[0], err
+}
+
+func (t *terminal) Printf(format string, args ...any) {
+	fmt.Fprintf(&t.buf, format, args...)
+	t.buf.WriteString("\x1b[K\r\n")
+}
+
+// Flush draws the lines printed since the last flush.
+func (t *terminal) Flush() {
+	os.Stdout.WriteString("\x1b[H" + t.buf.String() + "\x1b[J")
+	t.buf.Reset()
+}
+
+// bar renders v in min..max as a bar of width characters.
+func bar(v, min, max int32, width int) string {
+	if max <= min {
+		return strings.Repeat(" ", width)
+	}
+	n := int(int64(v-min) * int64(width) / int64(max-min))
+	n = clamp(n, 0, width)
+	return "[" + strings.Repeat("#", n) + strings.Repeat(" ", width-n) + "]"
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
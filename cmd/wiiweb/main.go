@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+	"github.com/friedelschoen/go-wiimote/pkg/systemdutil"
+)
+
+var (
+	listen   = flag.String("listen", "localhost:8080", "Address to serve the dashboard on")
+	simulate = flag.Bool("sim", false, "Use a simulated device instead of connected wiimotes")
+	version  = flag.Bool("version", false, "Print version information and exit")
+	debug    = flag.Bool("debug", false, "Log debug messages of the driver")
+	unit     = flag.Bool("unit", false, "Print a socket-activated systemd user unit serving the dashboard with the given flags and exit")
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// remote is a connected device and the dashboards subscribed to its events. The device is only
+// used by the goroutine polling it, handlers use do.
+type remote struct {
+	id     string
+	dev    wiimote.Device
+	rumble bool
+
+	mu   sync.Mutex
+	subs []chan []byte
+	// calls queued by do, interrupt cancels the wait of watch
+	calls     []func()
+	interrupt context.CancelFunc
+	gone      bool
+}
+
+// do runs fn on the goroutine polling the device and waits until it returned. It returns false
+// if the device is gone.
+func (r *remote) do(fn func()) bool {
+	done := make(chan struct{})
+	r.mu.Lock()
+	if r.gone {
+		r.mu.Unlock()
+		return false
+	}
+	r.calls = append(r.calls, func() {
+		fn()
+		close(done)
+	})
+	if r.interrupt != nil {
+		r.interrupt()
+	}
+	r.mu.Unlock()
+	<-done
+	return true
+}
+
+// runCalls runs the queued calls and returns a context which is cancelled by the next call.
+func (r *remote) runCalls() (context.Context, context.CancelFunc) {
+	for {
+		r.mu.Lock()
+		calls := r.calls
+		r.calls = nil
+		if len(calls) == 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			r.interrupt = cancel
+			r.mu.Unlock()
+			return ctx, cancel
+		}
+		r.interrupt = nil
+		r.mu.Unlock()
+		for _, fn := range calls {
+			fn()
+		}
+	}
+}
+
+type remoteInfo struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Battery   uint        `json:"battery"`
+	LEDs      wiimote.Led `json:"leds"`
+	Rumble    bool        `json:"rumble"`
+	Extension string      `json:"extension"`
+}
+
+func (r *remote) info() remoteInfo {
+	bat, _ := r.dev.Battery()
+	leds, _ := r.dev.LED()
+	ext, _ := r.dev.Extension()
+	return remoteInfo{ID: r.id, Name: r.dev.String(), Battery: bat, LEDs: leds, Rumble: r.rumble, Extension: ext}
+}
+
+func (r *remote) subscribe() chan []byte {
+	ch := make(chan []byte, 64)
+	r.mu.Lock()
+	r.subs = append(r.subs, ch)
+	r.mu.Unlock()
+	return ch
+}
+
+func (r *remote) unsubscribe(ch chan []byte) {
+	r.mu.Lock()
+	r.subs = slices.DeleteFunc(r.subs, func(c chan []byte) bool { return c == ch })
+	r.mu.Unlock()
+}
+
+// publish sends msg to all subscribers, slow subscribers miss the message.
+func (r *remote) publish(msg []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ch := range r.subs {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// close closes all subscribers and runs the calls queued in the meantime.
+func (r *remote) close() {
+	r.mu.Lock()
+	for _, ch := range r.subs {
+		close(ch)
+	}
+	r.subs = nil
+	r.gone = true
+	calls := r.calls
+	r.calls, r.interrupt = nil, nil
+	r.mu.Unlock()
+	for _, fn := range calls {
+		fn()
+	}
+}
+
+type server struct {
+	mu      sync.Mutex
+	remotes map[string]*remote
+	nextID  int
+}
+
+func (s *server) add(dev wiimote.Device) {
+	s.mu.Lock()
+	s.nextID++
+	r := &remote{id: strconv.Itoa(s.nextID), dev: dev}
+	s.remotes[r.id] = r
+	s.mu.Unlock()
+
+	fmt.Printf("new device %s: %s\n", r.id, dev)
+	go s.watch(r)
+}
+
+func (s *server) watch(r *remote) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.remotes, r.id)
+		s.mu.Unlock()
+		r.close()
+	}()
+
+	if err := wiimote.Open(r.dev, wiimote.FeatureCore.Writable(), wiimote.FeatureSetCore&^wiimote.FeatureCore); err != nil {
+		log.Printf("unable to open features: %v\n", err)
+	}
+	r.dev.SetOpenPolicy(wiimote.OpenPolicy{Kinds: wiimote.PolicyAllAvailable.Kinds, Writable: true})
+	r.dev.SetErrorPolicy(wiimote.ErrorClose)
+
+	for {
+		ctx, cancel := r.runCalls()
+		ev, err := r.dev.WaitContext(ctx)
+		cancel()
+		if errors.Is(err, context.Canceled) {
+			// interrupted by a handler
+			continue
+		}
+		if err != nil {
+			log.Printf("unable to poll event: %v\n", err)
+			return
+		}
+		if _, ok := ev.(*wiimote.EventGone); ok {
+			return
+		}
+		b, err := json.Marshal(ev)
+		if err != nil {
+			log.Printf("unable to encode event: %v\n", err)
+			continue
+		}
+		r.publish(b)
+	}
+}
+
+func (s *server) lookup(w http.ResponseWriter, req *http.Request) *remote {
+	s.mu.Lock()
+	r := s.remotes[req.PathValue("id")]
+	s.mu.Unlock()
+	if r == nil {
+		http.Error(w, "no such device", http.StatusNotFound)
+	}
+	return r
+}
+
+func (s *server) handleDevices(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	remotes := make([]*remote, 0, len(s.remotes))
+	for _, r := range s.remotes {
+		remotes = append(remotes, r)
+	}
+	s.mu.Unlock()
+	infos := make([]remoteInfo, 0, len(remotes))
+	for _, r := range remotes {
+		var info remoteInfo
+		if r.do(func() { info = r.info() }) {
+			infos = append(infos, info)
+		}
+	}
+	slices.SortFunc(infos, func(a, b remoteInfo) int {
+		x, _ := strconv.Atoi(a.ID)
+		y, _ := strconv.Atoi(b.ID)
+		return x - y
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+func (s *server) handleEvents(w http.ResponseWriter, req *http.Request) {
+	r := s.lookup(w, req)
+	if r == nil {
+		return
+	}
+	ws, err := upgradeWebsocket(w, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer ws.Close()
+	ch := r.subscribe()
+	defer r.unsubscribe(ch)
+	for msg := range ch {
+		if err := ws.WriteText(msg); err != nil {
+			return
+		}
+	}
+}
+
+func (s *server) handleLED(w http.ResponseWriter, req *http.Request) {
+	r := s.lookup(w, req)
+	if r == nil {
+		return
+	}
+	n, err := strconv.Atoi(req.PathValue("n"))
+	if err != nil || n < 1 || n > 4 {
+		http.Error(w, "led must be 1 to 4", http.StatusBadRequest)
+		return
+	}
+	var info remoteInfo
+	if !r.do(func() {
+		var on bool
+		if on, err = r.dev.LEDState(n - 1); err == nil {
+			err = r.dev.SetLEDState(n-1, !on)
+		}
+		info = r.info()
+	}) {
+		http.Error(w, "no such device", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+func (s *server) handleRumble(w http.ResponseWriter, req *http.Request) {
+	r := s.lookup(w, req)
+	if r == nil {
+		return
+	}
+	var info remoteInfo
+	var err error
+	if !r.do(func() {
+		f, ok := r.dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature)
+		if !ok {
+			err = wiimote.ErrFeatureUnavailable
+			return
+		}
+		r.rumble = !r.rumble
+		err = f.Rumble(r.rumble)
+		info = r.info()
+	}) {
+		http.Error(w, "no such device", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, wiimote.ErrFeatureUnavailable) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+func main() {
+	flag.Parse()
+	if *version {
+		fmt.Println(wiimote.Version())
+		return
+	}
+	if *unit {
+		u, err := systemdutil.NewUnit("Wii remote dashboard", systemdutil.FlagArgs(flag.CommandLine, "unit", "listen")...)
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		u.ListenStream = *listen
+		fmt.Print(u)
+		return
+	}
+	if *debug {
+		wiimote.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	defer driver.Shutdown()
+	driver.CleanupOnSignal()
+
+	s := &server{remotes: make(map[string]*remote)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexHTML)
+	})
+	mux.HandleFunc("GET /api/devices", s.handleDevices)
+	mux.HandleFunc("GET /api/devices/{id}/events", s.handleEvents)
+	mux.HandleFunc("POST /api/devices/{id}/led/{n}", s.handleLED)
+	mux.HandleFunc("POST /api/devices/{id}/rumble", s.handleRumble)
+
+	if *simulate {
+		dev, err := sim.NewDevice(sim.DefaultConfig())
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		s.add(dev)
+	} else {
+		monitor, err := discover.NewWiimoteMonitor()
+		if err != nil {
+			log.Fatalln("error: ", err)
+		}
+		monitor.AssignPlayers(true)
+		go func() {
+			for {
+				info, err := monitor.Wait(-1)
+				if err != nil || info == nil {
+					log.Printf("error while polling: %v\n", err)
+					continue
+				}
+				dev, err := driver.NewDevice(info.Device, driver.BackendKernel)
+				if err != nil {
+					log.Printf("error creating device: %v\n", err)
+					continue
+				}
+				if info.Player != 0 {
+					dev.SetPlayerLED(info.Player)
+				}
+				s.add(dev)
+			}
+		}()
+	}
+
+	// the socket is passed by systemd if the dashboard is socket activated
+	ln, err := systemdutil.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalln("error: ", err)
+	}
+	if err := systemdutil.Ready(); err != nil {
+		log.Printf("unable to notify systemd: %v\n", err)
+	}
+	fmt.Printf("serving dashboard on http://%s/\n", ln.Addr())
+	log.Fatalln(http.Serve(ln, mux))
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// websocketGUID is appended to the key of the client, see RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// websocket is the server side of a websocket which only sends text messages. Messages of the
+// client are discarded, a closed connection is noticed on the next write.
+type websocket struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (*websocket, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return nil, errors.New("not a websocket request")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("missing websocket key")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("connection cannot be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws := &websocket{conn: conn, rw: rw}
+	go ws.discard()
+	return ws, nil
+}
+
+// discard reads and drops client frames until the connection is closed.
+func (ws *websocket) discard() {
+	var buf [512]byte
+	for {
+		if _, err := ws.rw.Read(buf[:]); err != nil {
+			ws.conn.Close()
+			return
+		}
+	}
+}
+
+// WriteText sends msg as a single unmasked text frame.
+func (ws *websocket) WriteText(msg []byte) error {
+	var hdr [10]byte
+	hdr[0] = 0x81 // final fragment, text
+	n := 2
+	switch {
+	case len(msg) < 126:
+		hdr[1] = byte(len(msg))
+	case len(msg) <= 0xffff:
+		hdr[1] = 126
+		binary.BigEndian.PutUint16(hdr[2:], uint16(len(msg)))
+		n = 4
+	default:
+		hdr[1] = 127
+		binary.BigEndian.PutUint64(hdr[2:], uint64(len(msg)))
+		n = 10
+	}
+	if _, err := ws.rw.Write(hdr[:n]); err != nil {
+		return err
+	}
+	if _, err := ws.rw.Write(msg); err != nil {
+		return err
+	}
+	return ws.rw.Flush()
+}
+
+func (ws *websocket) Close() error {
+	// close frame without status
+	ws.rw.Write([]byte{0x88, 0})
+	ws.rw.Flush()
+	return ws.conn.Close()
+}
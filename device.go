@@ -1,6 +1,9 @@
 package wiimote
 
-import "iter"
+import (
+	"context"
+	"iter"
+)
 
 type DeviceInfo interface {
 	// Parent returns the parent Device, or nil if the receiver has no parent Device
@@ -34,6 +37,9 @@ type DeviceInfo interface {
 	// The retrieved value is cached in the device.
 	// Repeated calls will return the same value and not open the attribute again.
 	SysattrValue(sysattr string) string
+
+	// PropertyValue retrieves the value of a device property (e.g. HID_UNIQ), and returns an empty string if there is no such property.
+	PropertyValue(key string) string
 }
 
 type DeviceEnumerator interface {
@@ -52,6 +58,13 @@ type DeviceEnumerator interface {
 	// AddMatchSysname adds a filter for the name of the device to include in the list.
 	AddMatchSysname(sysname string) (err error)
 
+	// AddMatchProperty adds a filter for a property of the device to include in the list. The value
+	// may be a shell pattern.
+	AddMatchProperty(property, value string) (err error)
+
+	// Match adds a predicate the devices must match, it is evaluated in Go after scanning.
+	Match(m DeviceMatcher)
+
 	// AddMatchParent adds a filter for a parent Device to include in the list.
 	AddMatchParent(parent DeviceInfo) error
 
@@ -75,6 +88,10 @@ type DeviceMonitor interface {
 
 	ReceiveDevice() DeviceInfo
 
+	// Devices returns a channel receiving devices until ctx is done, the channel is closed afterwards.
+	// The monitor must be switched to listening mode using EnableReceiving first.
+	Devices(ctx context.Context) <-chan DeviceInfo
+
 	// SetReceiveBufferSize sets the size of the kernel socket buffer.
 	// This call needs the appropriate privileges to succeed.
 	SetReceiveBufferSize(size int) (err error)
@@ -0,0 +1,55 @@
+package wiimote
+
+// DevType is the type of a device as reported by the kernel, see Device.Type.
+type DevType uint8
+
+const (
+	// DevTypeUnknown is a device type unknown to this package, Device.DevType returns its name
+	DevTypeUnknown DevType = iota
+	// DevTypeGen10 is a Wii Remote (RVL-CNT-01)
+	DevTypeGen10
+	// DevTypeGen20 is a Wii Remote Plus (RVL-CNT-01-TR) with a built-in Motion Plus
+	DevTypeGen20
+	// DevTypeBalanceBoard is a Wii Balance Board
+	DevTypeBalanceBoard
+	// DevTypeProController is a Wii U Pro Controller
+	DevTypeProController
+)
+
+// devtypeNames are the names of the device types used by the kernel.
+var devtypeNames = [...]string{"unknown", "gen10", "gen20", "balanceboard", "procontroller"}
+
+// ParseDevType returns the device type of the kernel name (e.g. "gen10"), DevTypeUnknown if the
+// name is unknown.
+func ParseDevType(name string) DevType {
+	for i, n := range devtypeNames[1:] {
+		if n == name {
+			return DevType(i + 1)
+		}
+	}
+	return DevTypeUnknown
+}
+
+// String returns the kernel name of the device type, see Device.DevType.
+func (t DevType) String() string {
+	if int(t) < len(devtypeNames) {
+		return devtypeNames[t]
+	}
+	return devtypeNames[DevTypeUnknown]
+}
+
+// Generation returns the generation of a Wii Remote (1 or 2), 0 for other devices.
+func (t DevType) Generation() int {
+	switch t {
+	case DevTypeGen10:
+		return 1
+	case DevTypeGen20:
+		return 2
+	}
+	return 0
+}
+
+// IsRemote reports whether the device type is a Wii Remote of any generation.
+func (t DevType) IsRemote() bool {
+	return t.Generation() != 0
+}
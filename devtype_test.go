@@ -0,0 +1,17 @@
+package wiimote
+
+import "testing"
+
+func TestDevType(t *testing.T) {
+	for typ := DevTypeGen10; typ <= DevTypeProController; typ++ {
+		if got := ParseDevType(typ.String()); got != typ {
+			t.Errorf("%v: expected round trip, got %v", typ, got)
+		}
+	}
+	if got := ParseDevType("gen30"); got != DevTypeUnknown {
+		t.Errorf("expected unknown, got %v", got)
+	}
+	if DevTypeGen20.Generation() != 2 || DevTypeBalanceBoard.IsRemote() {
+		t.Errorf("unexpected generation")
+	}
+}
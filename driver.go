@@ -3,8 +3,10 @@ package wiimote
 //go:generate morestringer -lookup Lookup{} -output stringer.go Led Key:cconst FeatureKind
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"iter"
 	"time"
 )
 
@@ -24,6 +26,67 @@ const (
 	Led4
 )
 
+// LedAt returns the LED at index, 0 to 3 counted left-to-right. ok is false if index is out
+// of range.
+func LedAt(index int) (led Led, ok bool) {
+	if index < 0 || index > 3 {
+		return 0, false
+	}
+	return Led1 << index, true
+}
+
+// PlayerLED returns the LED pattern for player n, as used by the Wii. Only players 1 to 4
+// have a pattern, ok is false otherwise.
+func PlayerLED(n int) (leds Led, ok bool) {
+	if n < 1 || n > 4 {
+		return 0, false
+	}
+	return Led1 << (n - 1), true
+}
+
+// ErrorPolicy describes how a device handles errors while polling for events.
+type ErrorPolicy uint8
+
+const (
+	// ErrorReturn returns the error from Poll and Wait, this is the default
+	ErrorReturn ErrorPolicy = iota
+	// ErrorRetry retries polling with an increasing delay up to one second
+	ErrorRetry
+	// ErrorEvent reports the error as EventError
+	ErrorEvent
+	// ErrorClose closes all features and reports an EventGone
+	ErrorClose
+)
+
+// OpenPolicy describes which features are opened automatically as soon as they become
+// available, e.g. when an extension is plugged in. See Device.SetOpenPolicy.
+type OpenPolicy struct {
+	// Kinds of features to open
+	Kinds FeatureKind
+	// Writable opens the features with write-access
+	Writable bool
+}
+
+var (
+	// PolicyOnDemand opens features only with OpenFeatures, this is the default
+	PolicyOnDemand = OpenPolicy{}
+	// PolicyAllAvailable opens every available feature
+	PolicyAllAvailable = OpenPolicy{Kinds: ^FeatureKind(0)}
+)
+
+// PolicyList opens the available features of kinds.
+func PolicyList(kinds FeatureKind) OpenPolicy {
+	return OpenPolicy{Kinds: kinds}
+}
+
+// ReadStats counts the reads of a device, see Device.ReadStats.
+type ReadStats struct {
+	// Reads is the number of read system calls
+	Reads uint64
+	// Events is the number of raw input events or reports read
+	Events uint64
+}
+
 type Device interface {
 	fmt.Stringer
 	Poller[Event]
@@ -33,9 +96,9 @@ type Device interface {
 	// point at the same device (symlinks may be resolved).
 	Syspath() string
 
-	// OpenFeatures all the requested features. If FeatureWritable is also set,
-	// the features are opened with write-access. Note that features that are
-	// already opened are ignored and not touched.
+	// OpenFeatures all the requested features. If wr is set, the features are
+	// opened with write-access, see Open to request the access per feature. Note
+	// that features that are already opened are ignored and not touched.
 	// If any feature fails to open, this function still tries to open the other
 	// requested features and then returns the error afterwards. Hence, if this
 	// function fails, you should use Opened() to get a bitmask of opened
@@ -45,8 +108,17 @@ type Device interface {
 	// kernel removes the feature or on error conditions. You always get an
 	// EventWatch event which you should react on. This is returned
 	// regardless whether Watch() was enabled or not.
+	//
+	// Errors of single features are reported as FeatureError.
 	OpenFeatures(ifaces FeatureKind, wr bool) error
 
+	// AutoReopen enables or disables reopening of features. If enabled, features requested
+	// with OpenFeatures which were closed because the kernel removed them (e.g. when an
+	// extension is replugged) are reopened as soon as they are available again. An
+	// EventFeatureOpened is emitted for each reopened feature. Features closed explicitly
+	// are not reopened.
+	AutoReopen(enable bool)
+
 	// Feature receives an feature and returns nil this feature is not opened
 	Feature(ifaces FeatureKind) Feature
 
@@ -56,23 +128,91 @@ type Device interface {
 	// See the WatchEvent event for more information.
 	Available(iface FeatureKind) bool
 
+	// Capabilities describes what the device supports, derived from its device type and the
+	// available features, so applications do not have to match DevType.
+	Capabilities() Capabilities
+
+	// SetErrorPolicy sets how errors while polling are handled, see ErrorPolicy.
+	SetErrorPolicy(policy ErrorPolicy)
+
+	// SetOpenPolicy sets which features are opened automatically, see OpenPolicy. Available
+	// features matching the policy are opened immediately, features appearing later are
+	// opened as soon as they are available and reported as EventFeatureOpened.
+	SetOpenPolicy(policy OpenPolicy)
+
+	// Pause stops reporting the events of opened features, e.g. while nobody consumes them. The
+	// features of close are closed to stop the reports of the device (e.g. 100Hz accelerometer
+	// data), they are reopened by Resume. Hotplug events and EventGone are reported while paused,
+	// features opened while paused report events.
+	Pause(close FeatureKind) error
+
+	// Resume reports the events of opened features again and reopens the features closed by Pause.
+	Resume() error
+
+	// SetMaxRate limits the samples of the feature of kind (e.g. EventAccel or EventIR) to hz
+	// per second. Samples arriving faster are coalesced, only the newest sample is reported
+	// once the interval passed, so the last sample is never lost. State is updated with every
+	// sample. Key events are never dropped. A hz of zero or less removes the limit.
+	SetMaxRate(kind FeatureKind, hz float64)
+
+	// RawEvents returns a channel receiving the unmodified input events of the feature of kind,
+	// e.g. to handle event codes unknown to this package. The events are sent by the goroutine
+	// polling the device and dropped if the channel is full. If cooked is false, no other events
+	// are reported for the feature. The channel is closed by StopRawEvents or another call to
+	// RawEvents for kind. Drivers without input events return ErrUnsupported.
+	RawEvents(kind FeatureKind, cooked bool) (<-chan RawEvent, error)
+
+	// StopRawEvents closes the channel of RawEvents for kind.
+	StopRawEvents(kind FeatureKind)
+
+	// ReadStats returns the number of reads and raw events since the device was created.
+	ReadStats() ReadStats
+
+	// State returns the latest values of all opened features, updated on every event returned
+	// by Poll. It is safe to call State while another goroutine polls the device.
+	State() State
+
 	// SetIRFull sets
 	IRFull() bool
 
 	// SetIRFull sets
 	SetIRFull(fullreport bool)
 
-	// LED reads the LED state for the given LED.
+	// LED reads the state of the LEDs, LEDs the device does not provide are reported as off.
+	// ErrNoLED is returned if the device has no LEDs. If an LED cannot be read, the state of
+	// the other LEDs is returned together with a *LEDError for every failed LED.
 	//
 	// LEDs are a static feature that does not have to be opened first.
 	LED() (result Led, _ error)
 
-	// SetLED writes the LED state for the given LED.
+	// SetLED writes the state of the LEDs, LEDs the device does not provide are skipped.
+	// ErrNoLED is returned if the device has no LEDs. The other LEDs are still written if an
+	// LED fails, a *LEDError is returned for every failed LED.
 	//
 	// LEDs are a static feature that does not have to be opened first.
 	SetLED(leds Led) error
 
+	// LEDs returns the LEDs the device provides, 0 if it has no LEDs (e.g. a Balance Board).
+	LEDs() Led
+
+	// LEDState reads the state of the LED at index, 0 to 3 counted left-to-right. Errors are
+	// returned as *LEDError, wrapping ErrNoLED if the device does not provide the LED.
+	LEDState(index int) (bool, error)
+
+	// SetLEDState turns the LED at index on or off, see LEDState.
+	SetLEDState(index int, on bool) error
+
+	// SetPlayerLED shows the player number n (1 to 4) on the LEDs, see PlayerLED.
+	//
+	// LEDs are a static feature that does not have to be opened first.
+	SetPlayerLED(n int) error
+
+	// Player returns the player number set with SetPlayerLED, 0 if no player number was set.
+	Player() int
+
 	// Battery reads the current battery capacity. The capacity is represented as percentage, thus the return value is an integer between 0 and 100.
+	// ErrNoBattery is returned if the device does not report a battery and an error matching
+	// ErrPermission if the battery cannot be read.
 	//
 	// Batteries are a static feature that does not have to be opened first.
 	Battery() (uint, error)
@@ -83,14 +223,92 @@ type Device interface {
 	// This is a static feature that does not have to be opened first.
 	DevType() (string, error)
 
+	// Type returns the device type of DevType, DevTypeUnknown if the device type cannot be
+	// determined or is unknown to this package.
+	//
+	// This is a static feature that does not have to be opened first.
+	Type() (DevType, error)
+
 	// Extension returns the extension type. If no extension is connected or the
 	// extension cannot be determined, it returns a string "none" and the corresponding error.
 	//
 	// This is a static feature that does not have to be opened first.
 	Extension() (string, error)
+
+	// UniqueID returns the unique identifier of the device, which is the Bluetooth address
+	// of the device (e.g. "00:1f:32:aa:bb:cc"). It can be used to recognize a physical
+	// device across reconnects.
+	//
+	// This is a static feature that does not have to be opened first.
+	UniqueID() (string, error)
+
+	// Disconnect closes the Bluetooth connection of the device to save its battery, the
+	// device is removed afterwards and reports EventGone. ErrUnsupported is returned if the
+	// driver cannot disconnect the device.
+	Disconnect() error
+
+	// OnCleanup registers fn to be called on Cleanup. Functions are called in reverse
+	// order of registration.
+	OnCleanup(fn func() error)
+
+	// Cleanup turns off rumble, restores the LEDs to the player number and calls all
+	// functions registered with OnCleanup. Cleanup should be called before the device
+	// is released, see driver.Shutdown to clean up all devices on exit.
+	Cleanup() error
+}
+
+// FDSet is implemented by devices reading from multiple file descriptors, e.g. created with
+// driver.WithExternalLoop. Applications with their own event loop (e.g. glib or a game engine)
+// watch the descriptors for readability and call Poll until it returns no event. Devices
+// reading from a single descriptor only provide FD.
+type FDSet interface {
+	// FDs returns the file descriptors to watch, the set changes when features are opened or
+	// closed and on Pause and Resume.
+	FDs() iter.Seq[int]
+
+	// OnFDsChanged sets fn to be called after the set of FDs changed, nil removes it. fn is
+	// called by the goroutine using the device.
+	OnFDsChanged(fn func())
+}
+
+// PollerOptions configures the delays of a poller, see Poller.SetOptions. The delays only apply
+// to drivers without a file descriptor, which have to be polled repeatedly. Drivers with a file
+// descriptor are waited for using poll(2).
+type PollerOptions struct {
+	// RetryDelay is the delay before polling again, 0 polls without delay
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the delay which is doubled on every retry without event, if it is
+	// not above RetryDelay the delay is constant
+	MaxRetryDelay time.Duration
+	// Jitter randomizes every delay by up to the given fraction (0..1)
+	Jitter float64
+}
+
+// DefaultPollerOptions polls every 10ms.
+var DefaultPollerOptions = PollerOptions{RetryDelay: 10 * time.Millisecond}
+
+// PollerOption modifies PollerOptions.
+type PollerOption func(*PollerOptions)
+
+// WithRetryDelay sets the delay before polling again. A delay of 0 busy-polls for minimum
+// latency.
+func WithRetryDelay(d time.Duration) PollerOption {
+	return func(o *PollerOptions) { o.RetryDelay = d }
+}
+
+// WithBackoff doubles the delay on every retry without event up to max, and randomizes each
+// delay by up to jitter (0..1) to spread the polls of multiple devices.
+func WithBackoff(max time.Duration, jitter float64) PollerOption {
+	return func(o *PollerOptions) {
+		o.MaxRetryDelay = max
+		o.Jitter = jitter
+	}
 }
 
 type Poller[T any] interface {
+	// SetOptions applies opts to the options of the poller, see PollerOptions.
+	SetOptions(opts ...PollerOption)
+
 	// Poll attempts to retrieve an event or data.
 	//
 	// Return values:
@@ -102,19 +320,32 @@ type Poller[T any] interface {
 	Poll() (T, bool, error)
 
 	// Wait waits for an event up to the specified timeout. A negative timeout is considered forever.
-	// It handles ErrRetry internally and returns the first valid event or error.
+	// It handles ErrRetry internally and returns the first valid event or error, or
+	// os.ErrDeadlineExceeded if no event arrived in time.
 	Wait(timeout time.Duration) (T, error)
 
+	// WaitContext waits for an event until ctx is done, in which case the error of ctx is returned.
+	// It handles ErrRetry internally and returns the first valid event or error.
+	WaitContext(ctx context.Context) (T, error)
+
 	// Wait waits for an event up to the specified timeout. A negative timeout is considered forever.
 	WaitReadable(timeout time.Duration) error
 
-	// Handle continuously polls and calls `yield` with new events.
-	// It blocks forever and should be used in a new goroutine.
+	// Handle continuously polls and calls `yield` with new events. It returns nil after
+	// yielding an EventGone or if the device was removed, and the error of the driver
+	// otherwise. It should be used in a new goroutine.
 	Handle(yield func(T)) error
 
-	// Stream continuously polls and writes events into ch. It is a wrapper for Handle.
-	// It blocks forever and should be used in a new goroutine.
+	// HandleContext is like Handle but returns the error of ctx as soon as ctx is done.
+	HandleContext(ctx context.Context, yield func(T)) error
+
+	// Stream continuously polls and writes events into ch. It is a wrapper for Handle and
+	// returns like Handle, ch is not closed.
 	//
 	//	p.Handle(func(ev T) { ch <- ev })
-	Stream(ch chan<- T)
+	Stream(ch chan<- T) error
+
+	// StreamContext is like Stream but returns the error of ctx as soon as ctx is done, also
+	// while waiting for ch.
+	StreamContext(ctx context.Context, ch chan<- T) error
 }
@@ -6,3 +6,17 @@ const (
 	BackendHID Backend = iota
 	BackendKernel
 )
+
+// Option configures a device created by NewDevice.
+type Option func(*options)
+
+type options struct {
+	externalLoop bool
+}
+
+// WithExternalLoop creates the device for an event loop of the application, which watches the
+// file descriptors of wiimote.FDSet instead of a descriptor owned by the device. Only the kernel
+// backend reads from multiple descriptors, the other backends ignore it.
+func WithExternalLoop() Option {
+	return func(o *options) { o.externalLoop = true }
+}
@@ -13,11 +13,13 @@ import (
 )
 
 type commonEvent struct {
+	dev       *device
 	iface     wiimote.Feature
 	timestamp time.Time
 }
 
 func (e commonEvent) Feature() wiimote.Feature { return e.iface }
+func (e commonEvent) Device() wiimote.Device   { return e.dev }
 func (e commonEvent) Timestamp() time.Time     { return e.timestamp }
 
 type feature struct {
@@ -41,6 +43,7 @@ type memResponse struct {
 
 type device struct {
 	wiimote.Poller[wiimote.Event]
+	common.Cleanups
 
 	transport Transport
 
@@ -56,16 +59,27 @@ type device struct {
 	// wether an extension is currently connected
 	hasExtension bool
 
+	// handles errors while polling
+	errs common.ErrorHandler
+
 	// led state
 	led    wiimote.Led
+	player int
 	rumble bool
 	irfull bool
+	// features not reported and features closed by Pause
+	muted, paused wiimote.FeatureKind
+	isPaused      bool
 
 	memory chan memResponse
 
 	ackErr map[uint8]error
 	ackMu  sync.Mutex
 
+	stats wiimote.ReadStats
+	state common.StateBuffer
+	limit common.RateLimit
+
 	interleaved [19]byte
 
 	// queued events (because a single report can generate multiple key events)
@@ -73,7 +87,8 @@ type device struct {
 }
 
 type memory struct {
-	dev *device
+	dev   *device
+	space memSpace
 }
 
 func (m memory) Close() error {
@@ -81,11 +96,11 @@ func (m memory) Close() error {
 }
 
 func (m memory) WriteAt(p []byte, off int64) (n int, err error) {
-	return m.dev.writeMemory(memEEPROM, uint32(off), p)
+	return m.dev.writeMemory(m.space, uint32(off), p)
 }
 
 func (m memory) ReadAt(p []byte, off int64) (n int, err error) {
-	return m.dev.readMemory(memEEPROM, uint32(off), p)
+	return m.dev.readMemory(m.space, uint32(off), p)
 }
 
 type Transport interface {
@@ -102,6 +117,8 @@ func NewDevice(transport Transport) wiimote.Device {
 		ackErr:     make(map[uint8]error),
 	}
 	d.Poller = common.NewPoller(d)
+	d.OnCleanup(common.RestoreDevice(d))
+	wiimote.Logger().Debug("device opened", "driver", "commonhid")
 	return d
 }
 
@@ -117,7 +134,11 @@ func (d *device) Rumble(state bool) error {
 }
 
 func (d *device) Memory() (wiimote.Memory, error) {
-	return memory{dev: d}, nil
+	return memory{dev: d, space: memEEPROM}, nil
+}
+
+func (d *device) Registers() (wiimote.Memory, error) {
+	return memory{dev: d, space: memREG}, nil
 }
 
 // Device interface
@@ -135,9 +156,77 @@ func (d *device) OpenFeatures(ifaces wiimote.FeatureKind, wr bool) error {
 	_ = wr // all features are writeable
 
 	d.openIfs |= ifaces
+	d.muted &^= ifaces
+	wiimote.Logger().Debug("features opened", "kind", ifaces)
+	return d.updateReportMode()
+}
+
+// Pause drops the reports of opened features, the features of close are closed until Resume
+// which reduces the report mode. The core feature stays open to receive status reports,
+// without other features the remote only reports when a button changes.
+func (d *device) RawEvents(kind wiimote.FeatureKind, cooked bool) (<-chan wiimote.RawEvent, error) {
+	return nil, wiimote.ErrUnsupported
+}
+
+func (d *device) StopRawEvents(kind wiimote.FeatureKind) {}
+
+func (d *device) SetMaxRate(kind wiimote.FeatureKind, hz float64) {
+	d.limit.SetMaxRate(kind, hz)
+}
+
+func (d *device) Pause(close wiimote.FeatureKind) error {
+	if d.isPaused {
+		return nil
+	}
+	d.paused = d.openIfs & close &^ wiimote.FeatureCore
+	d.openIfs &^= d.paused
+	d.muted = d.openIfs
+	d.isPaused = true
+	wiimote.Logger().Debug("device paused", "driver", "commonhid", "closed", d.paused)
+	return d.updateReportMode()
+}
+
+// Resume reports events again and reopens the features closed by Pause.
+func (d *device) Resume() error {
+	if !d.isPaused {
+		return nil
+	}
+	d.openIfs |= d.paused
+	d.paused, d.muted = 0, 0
+	d.isPaused = false
+	wiimote.Logger().Debug("device resumed", "driver", "commonhid")
 	return d.updateReportMode()
 }
 
+func (d *device) ReadStats() wiimote.ReadStats {
+	return d.stats
+}
+
+func (d *device) Capabilities() wiimote.Capabilities {
+	return common.Capabilities(d)
+}
+
+func (d *device) SetErrorPolicy(policy wiimote.ErrorPolicy) {
+	d.errs.Policy = policy
+}
+
+func (d *device) AutoReopen(enable bool) {
+	_ = enable // features are never removed
+}
+
+func (d *device) SetOpenPolicy(policy wiimote.OpenPolicy) {
+	// features are never removed, so only the available features are opened
+	var kinds wiimote.FeatureKind
+	for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+		if policy.Kinds&kind != 0 && d.Available(kind) && d.Feature(kind) == nil {
+			kinds |= kind
+		}
+	}
+	if kinds != 0 {
+		d.OpenFeatures(kinds, policy.Writable)
+	}
+}
+
 func (d *device) FD() int {
 	return d.transport.FD()
 }
@@ -166,7 +255,39 @@ func (d *device) LED() (wiimote.Led, error) {
 }
 
 func (d *device) SetLED(leds wiimote.Led) error {
-	return d.output(true, 0x11, byte(leds)<<4)
+	if err := d.output(true, 0x11, byte(leds)<<4); err != nil {
+		return err
+	}
+	d.led = leds
+	return nil
+}
+
+func (d *device) LEDs() wiimote.Led {
+	return wiimote.Led1 | wiimote.Led2 | wiimote.Led3 | wiimote.Led4
+}
+
+func (d *device) LEDState(index int) (bool, error) {
+	return common.LEDState(d, index)
+}
+
+func (d *device) SetLEDState(index int, on bool) error {
+	return common.SetLEDState(d, index, on)
+}
+
+func (d *device) SetPlayerLED(n int) error {
+	leds, ok := wiimote.PlayerLED(n)
+	if !ok {
+		return os.ErrInvalid
+	}
+	if err := d.SetLED(leds); err != nil {
+		return err
+	}
+	d.player = n
+	return nil
+}
+
+func (d *device) Player() int {
+	return d.player
 }
 
 func (d *device) Battery() (uint, error) {
@@ -177,28 +298,62 @@ func (d *device) DevType() (string, error) {
 	return "unknown", os.ErrInvalid
 }
 
+func (d *device) Type() (wiimote.DevType, error) {
+	devtype, err := d.DevType()
+	return wiimote.ParseDevType(devtype), err
+}
+
 func (d *device) Extension() (string, error) {
 	return "none", os.ErrInvalid
 }
 
+func (d *device) UniqueID() (string, error) {
+	return "", os.ErrInvalid
+}
+
+func (d *device) Disconnect() error {
+	return wiimote.ErrUnsupported
+}
+
 func (d *device) Poll() (wiimote.Event, bool, error) {
+	// events of paused features are dropped, coalesced samples are kept back and the state is
+	// updated with every sample
+	return d.limit.Poll(d.poll, d.state.Update, func(ev wiimote.Event) bool { return isMuted(ev, d.muted) })
+}
+
+func isMuted(ev wiimote.Event, muted wiimote.FeatureKind) bool {
+	f := ev.Feature()
+	return f != nil && f.Kind()&muted != 0
+}
+
+// State returns the latest values of all opened features.
+func (d *device) State() wiimote.State {
+	return d.state.State()
+}
+
+func (d *device) poll() (wiimote.Event, bool, error) {
 	select {
 	case ev := <-d.moreEvents:
 		return ev, len(d.moreEvents) > 0, nil
 	default:
 	}
 
-	d.readEvent()
+	if err := d.readEvent(); err != nil {
+		return d.errs.Handle(err, commonEvent{dev: d, timestamp: time.Now()}, func() {
+			d.openIfs = 0
+		})
+	}
 
 	select {
 	case ev := <-d.moreEvents:
+		d.errs.Reset()
 		return ev, len(d.moreEvents) > 0, nil
 	default:
 		return nil, false, common.ErrWouldBlock
 	}
 }
 
-func (d *device) readEvent() {
+func (d *device) readEvent() error {
 	d.WaitReadable(-1)
 
 	var buf [64]byte
@@ -206,26 +361,25 @@ func (d *device) readEvent() {
 	if err != nil {
 		// Non-blocking transport support (os.File O_NONBLOCK typically returns EAGAIN)
 		if errors.Is(err, syscall.EAGAIN) {
-			return
+			return nil
 		}
 
 		// Device gone
 		if errors.Is(err, io.EOF) {
 			d.moreEvents <- &wiimote.EventGone{
-				Event: commonEvent{iface: nil, timestamp: time.Now()},
+				Event: commonEvent{dev: d, iface: nil, timestamp: time.Now()},
 			}
-			return
+			return nil
 		}
 
-		// Any other error: treat like "watch"/state changed; caller should reopen etc.
-		d.moreEvents <- &wiimote.EventWatch{
-			Event: commonEvent{iface: nil, timestamp: time.Now()},
-		}
-		return
+		// Any other error is handled by the error policy
+		return err
 	}
+	d.stats.Reads++
 	if n <= 0 {
-		return
+		return nil
 	}
+	d.stats.Events++
 
 	report := buf[:n]
 	ts := time.Now()
@@ -237,8 +391,15 @@ func (d *device) readEvent() {
 		if report[3]&0x01 != 0 {
 			d.battery = 0
 		}
-		// throw event ?
-		d.hasExtension = report[3]&0x02 != 0
+		if ext := report[3]&0x02 != 0; ext != d.hasExtension {
+			d.hasExtension = ext
+			// extensions are not identified yet
+			if ext {
+				d.moreEvents <- &wiimote.EventExtensionConnected{Event: commonEvent{dev: d, timestamp: ts}, Type: "unknown"}
+			} else {
+				d.moreEvents <- &wiimote.EventExtensionDisconnected{Event: commonEvent{dev: d, timestamp: ts}, Type: "unknown"}
+			}
+		}
 		d.led = wiimote.Led(report[3] >> 4)
 	}
 	if rid == 0x21 {
@@ -277,12 +438,12 @@ func (d *device) readEvent() {
 	}
 	if rid == 0x31 || rid == 0x33 || rid == 0x35 || rid == 0x37 {
 		var accel wiimote.Vec3
-		accel.X = (int32(report[3])<<2 | int32(report[1]>>5)&0x03) - 0x80
-		accel.Y = (int32(report[4])<<2 | int32(report[1]>>4)&0x03) - 0x80
-		accel.Z = (int32(report[5])<<2 | int32(report[1]>>5)&0x02) - 0x80
+		accel.X = (int32(report[3])<<2 | int32(report[1]>>5)&0x03) - 0x200
+		accel.Y = (int32(report[4])<<2 | int32(report[1]>>4)&0x03) - 0x200
+		accel.Z = (int32(report[5])<<2 | int32(report[1]>>5)&0x02) - 0x200
 
 		d.moreEvents <- &wiimote.EventAccel{
-			Event: commonEvent{iface: d, timestamp: ts},
+			Event: commonEvent{dev: d, iface: d, timestamp: ts},
 			Accel: accel,
 		}
 	}
@@ -311,7 +472,7 @@ func (d *device) readEvent() {
 			(int32(report[1]>>5)&0x03)<<4
 
 		d.moreEvents <- &wiimote.EventAccel{
-			Event: commonEvent{iface: d, timestamp: ts},
+			Event: commonEvent{dev: d, iface: d, timestamp: ts},
 			Accel: accel,
 		}
 
@@ -333,12 +494,13 @@ func (d *device) readEvent() {
 		setSlot(&slots[3], report[9:])
 
 		d.moreEvents <- &wiimote.EventIR{
-			Event: commonEvent{iface: d, timestamp: ts},
+			Event: commonEvent{dev: d, iface: d, timestamp: ts},
 			Slots: slots,
 		}
 	}
 
 	// TODO: extensions
+	return nil
 }
 
 func (d *device) emitButtons(ts time.Time, btn uint16) {
@@ -355,7 +517,7 @@ func (d *device) emitButtons(ts time.Time, btn uint16) {
 		}
 		pressed := btn&value != 0
 		d.moreEvents <- &wiimote.EventKey{
-			Event:   commonEvent{iface: d, timestamp: ts},
+			Event:   commonEvent{dev: d, iface: d, timestamp: ts},
 			Code:    k,
 			Pressed: pressed,
 		}
@@ -387,7 +549,7 @@ func (d *device) emitIRBasic(ts time.Time, report []byte) {
 	slots[3].Y = int32(report[9]) | (int32(report[7]>>2)&0x03)<<8
 
 	d.moreEvents <- &wiimote.EventIR{
-		Event: commonEvent{iface: d, timestamp: ts},
+		Event: commonEvent{dev: d, iface: d, timestamp: ts},
 		Slots: slots,
 	}
 }
@@ -406,7 +568,7 @@ func (d *device) emitIRExtended(ts time.Time, report []byte) {
 	setSlot(&slots[3], report[9:])
 
 	d.moreEvents <- &wiimote.EventIR{
-		Event: commonEvent{iface: d, timestamp: ts},
+		Event: commonEvent{dev: d, iface: d, timestamp: ts},
 		Slots: slots,
 	}
 }
@@ -449,7 +611,9 @@ func (d *device) output(ack bool, report ...byte) error {
 		}
 		d.ackMu.Unlock()
 
-		d.readEvent()
+		if err := d.readEvent(); err != nil {
+			return err
+		}
 	}
 }
 func (d *device) enable(ack bool, report byte, enable bool) error {
@@ -532,15 +696,17 @@ func (d *device) readMemory(space memSpace, addr uint32, dst []byte) (int, error
 		n := min(len(dst)-off, 16)
 
 		/* send read request: 0x17 */
-		ah, am, al := encodeAddr(space, addr+uint32(off))
+		flags, ah, am, al := encodeAddr(space, addr+uint32(off))
 		sizeHi, sizeLo := byte(n>>8), byte(n)
-		if err := d.output(false, 0x17, 0x00, ah, am, al, sizeHi, sizeLo); err != nil {
+		if err := d.output(false, 0x17, flags, ah, am, al, sizeHi, sizeLo); err != nil {
 			return off, err
 		}
 
 		var resp *memResponse
 		for resp == nil {
-			d.readEvent()
+			if err := d.readEvent(); err != nil {
+				return off, err
+			}
 			select {
 			case r := <-d.memory:
 				resp = &r
@@ -588,11 +754,11 @@ func (d *device) writeMemory(space memSpace, addr uint32, src []byte) (int, erro
 			n = 16
 		}
 
-		ah, am, al := encodeAddr(space, addr+uint32(off))
+		flags, ah, am, al := encodeAddr(space, addr+uint32(off))
 
 		/* output report 0x16: [16][flags][addr_hi][addr_mid][addr_lo][size][data...] */
 		rep := make([]byte, 0, 6+n)
-		rep = append(rep, 0x16, 0x00, ah, am, al, byte(n))
+		rep = append(rep, 0x16, flags, ah, am, al, byte(n))
 		rep = append(rep, src[off:off+n]...)
 
 		if err := d.output(false, rep...); err != nil {
@@ -610,20 +776,18 @@ func (d *device) writeMemory(space memSpace, addr uint32, src []byte) (int, erro
 }
 
 /* helper: encode address with space bit */
-func encodeAddr(space memSpace, addr uint32) (hi, mid, lo byte) {
+func encodeAddr(space memSpace, addr uint32) (flags, hi, mid, lo byte) {
 	/*
-	   Wiimote convention: highest bit of hi-byte selects space:
+	   Wiimote convention: bit 2 of the flags-byte selects the space:
 	   - EEPROM: 0
 	   - REG:    1
-	   And remaining 23 bits are address.
+	   The remaining 3 bytes are the 24-bit address.
 	*/
-	a := addr & 0x00ffffff
-	hi = byte((a >> 16) & 0xff)
 	if space == memREG {
-		hi |= 0x10 /* NOTE: some docs say bit4 selects space; others use bit7.
-		   If your reads fail, flip this to 0x80.
-		   (I’m choosing 0x10 because that’s common in Wiibrew snippets.) */
+		flags = 0x04
 	}
+	a := addr & 0x00ffffff
+	hi = byte((a >> 16) & 0xff)
 	mid = byte((a >> 8) & 0xff)
 	lo = byte(a & 0xff)
 	return
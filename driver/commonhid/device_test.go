@@ -0,0 +1,48 @@
+package commonhid
+
+import (
+	"os"
+	"testing"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+type pipeTransport struct {
+	*os.File
+}
+
+func (p pipeTransport) FD() int { return int(p.Fd()) }
+
+// The accelerometer reports 10 bit values centered at 0x200, like hid-wiimote.
+func TestAccelCalibration(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	dev := NewDevice(pipeTransport{r})
+	defer dev.Cleanup()
+
+	for _, tc := range []struct {
+		report []byte
+		expect wiimote.Vec3
+	}{
+		{[]byte{0x31, 0x00, 0x00, 0x80, 0x80, 0x80}, wiimote.Vec3{}},
+		{[]byte{0x31, 0x60, 0x00, 0x99, 0x80, 0x66}, wiimote.Vec3{X: 103, Y: 2, Z: -102}},
+	} {
+		if _, err := w.Write(tc.report); err != nil {
+			t.Fatal(err)
+		}
+		ev, err := dev.Wait(-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accel, ok := ev.(*wiimote.EventAccel)
+		if !ok {
+			t.Fatalf("expected accelerometer event, got %T", ev)
+		}
+		if accel.Accel != tc.expect {
+			t.Errorf("%x: expected %v, got %v", tc.report, tc.expect, accel.Accel)
+		}
+	}
+}
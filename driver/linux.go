@@ -18,10 +18,18 @@ func NewMonitor() wiimote.DeviceMonitor {
 	return udev.NewMonitorFromNetlink(udev.MonitorUdev)
 }
 
-func NewDevice(info wiimote.DeviceInfo, backend Backend) (wiimote.Device, error) {
+func NewDevice(info wiimote.DeviceInfo, backend Backend, opts ...Option) (wiimote.Device, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 	switch backend {
 	case BackendKernel:
-		return linuxkernel.NewDevice(info, NewMonitor, NewEnumerate)
+		var kopts []linuxkernel.Option
+		if o.externalLoop {
+			kopts = append(kopts, linuxkernel.ExternalLoop())
+		}
+		return linuxkernel.NewDevice(info, NewMonitor, NewEnumerate, kopts...)
 	default:
 		transport, err := linuxhidraw.NewTransportFromInfo(info)
 		if err != nil {
@@ -10,15 +10,23 @@ import (
 	"github.com/friedelschoen/go-wiimote/internal/common"
 )
 
-func NewTransportFromInfo(info wiimote.DeviceInfo) (commonhid.Transport, error) {
+// Path returns the hidraw device node (e.g. /dev/hidraw3) of the hid device info.
+func Path(info wiimote.DeviceInfo) (string, error) {
 	entries, err := os.ReadDir(filepath.Join(info.Syspath(), "hidraw"))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	if len(entries) == 0 {
-		return nil, os.ErrNotExist
+		return "", os.ErrNotExist
+	}
+	return filepath.Join("/dev", entries[0].Name()), nil
+}
+
+func NewTransportFromInfo(info wiimote.DeviceInfo) (commonhid.Transport, error) {
+	hidrawpath, err := Path(info)
+	if err != nil {
+		return nil, err
 	}
-	hidrawpath := filepath.Join("/dev", entries[0].Name())
 
 	//syscall.O_RDWR|
 	fd, err := syscall.Open(hidrawpath, syscall.O_RDWR|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
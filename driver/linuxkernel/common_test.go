@@ -1,8 +1,13 @@
 package linuxkernel
 
 import (
+	"syscall"
 	"testing"
 	"time"
+	"unsafe"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
 )
 
 func testCTimeRoundtrip(t *testing.T, orig time.Time) {
@@ -17,3 +22,32 @@ func TestCTime(t *testing.T) {
 	testCTimeRoundtrip(t, time.Time{})
 	testCTimeRoundtrip(t, time.Now())
 }
+
+func TestEventBatch(t *testing.T) {
+	var fds [2]int
+	if err := syscall.Pipe2(fds[:], syscall.O_NONBLOCK); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	var b eventBatch
+	size := int(unsafe.Sizeof(b.events[0]))
+	if _, err := syscall.Write(fds[1], make([]byte, 3*size)); err != nil {
+		t.Fatal(err)
+	}
+
+	var stats wiimote.ReadStats
+	for i := range 3 {
+		ev, err := b.next(common.UnbufferedFile(fds[0]), &stats)
+		if ev == nil || err != nil {
+			t.Fatalf("%d: expected event, got %v", i, err)
+		}
+	}
+	if ev, err := b.next(common.UnbufferedFile(fds[0]), &stats); ev != nil || err != nil {
+		t.Errorf("expected no more events, got %v %v", ev, err)
+	}
+	if stats.Reads != 1 || stats.Events != 3 {
+		t.Errorf("expected one read of three events, got %+v", stats)
+	}
+}
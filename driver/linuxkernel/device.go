@@ -2,6 +2,7 @@ package linuxkernel
 
 import (
 	"errors"
+	"iter"
 	"os"
 	"path"
 	"runtime"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/friedelschoen/go-wiimote"
 	"github.com/friedelschoen/go-wiimote/internal/common"
+	"github.com/friedelschoen/go-wiimote/pkg/bluez"
 )
 
 const debugfs = "/sys/kernel/debug"
@@ -21,12 +23,17 @@ const debugfs = "/sys/kernel/debug"
 // object.
 type device struct {
 	wiimote.Poller[wiimote.Event]
+	common.Cleanups
 
 	newMonitor func() wiimote.DeviceMonitor
 	newEnum    func() wiimote.DeviceEnumerator
 
-	//  epoll file descriptor
+	//  epoll file descriptor, -1 if the device is integrated into an external event loop
 	efd int
+	// watched file descriptors -- fd -> source, only used without epoll, see ExternalLoop
+	fds map[int]int32
+	// called when fds changed, see OnFDsChanged
+	onFDs func()
 	//  main udev device
 	dev wiimote.DeviceInfo
 	//  udev monitor
@@ -34,18 +41,64 @@ type device struct {
 
 	// open features -- kind -> feature
 	openIfs map[wiimote.FeatureKind]feature
+	// requested features -- kind -> writable
+	requested map[wiimote.FeatureKind]bool
+	// features opened automatically when available
+	policy wiimote.OpenPolicy
+	// whether requested features are reopened when available
+	autoReopen bool
+	// handles errors while polling
+	errs common.ErrorHandler
 	// available features -- kind -> name
 	availIfs map[wiimote.FeatureKind]string
 	// device type attribute
 	devtypeAttr string
 	// extension attribute
 	extensionAttr string
+	// last read extension, "none" if no extension is connected
+	extension string
 	// battery capacity attribute
 	batteryAttr string
 	// led brightness attributes
 	ledAttrs [4]string
+	// player number set by SetPlayerLED
+	player int
+	// whether events of features are not reported, see Pause
+	paused bool
+	// features closed by Pause -- kind -> writable
+	pausedIfs map[wiimote.FeatureKind]bool
+	// features removed from epoll by Pause
+	unwatched wiimote.FeatureKind
 	// buffers internal events
 	moreEvents chan wiimote.Event
+	// counts reads of all features
+	stats wiimote.ReadStats
+	state common.StateBuffer
+	limit common.RateLimit
+	// channels of RawEvents
+	raw map[wiimote.FeatureKind]*rawTap
+}
+
+// rawTap receives the input events of a feature, see RawEvents.
+type rawTap struct {
+	ch     chan wiimote.RawEvent
+	cooked bool
+}
+
+// rawBuffer is the size of the channels returned by RawEvents.
+const rawBuffer = 256
+
+// Option configures a device created by NewDevice.
+type Option func(*device)
+
+// ExternalLoop creates the device without an epoll descriptor, FD returns -1. The descriptors
+// of the monitor and the opened features are watched by the application instead, see
+// wiimote.FDSet. Poll reads from all of them, Wait polls repeatedly.
+func ExternalLoop() Option {
+	return func(d *device) {
+		d.efd = -1
+		d.fds = make(map[int]int32)
+	}
 }
 
 // NewDevice creates a new device object. No features on the device are opened by
@@ -56,12 +109,15 @@ type device struct {
 // the hid device, which is normally /sys/bus/hid/devices/[dev].
 //
 // The object and underlying structure is freed automatically by default.
-func NewDevice(dev wiimote.DeviceInfo, newMonitor func() wiimote.DeviceMonitor, newEnum func() wiimote.DeviceEnumerator) (*device, error) {
+func NewDevice(dev wiimote.DeviceInfo, newMonitor func() wiimote.DeviceMonitor, newEnum func() wiimote.DeviceEnumerator, opts ...Option) (*device, error) {
 	var d device
 	d.Poller = common.NewPoller(&d)
 	d.dev = dev
 	d.newMonitor = newMonitor
 	d.newEnum = newEnum
+	for _, opt := range opts {
+		opt(&d)
+	}
 
 	drv := d.dev.Driver()
 	subs := d.dev.Subsystem()
@@ -75,48 +131,61 @@ func NewDevice(dev wiimote.DeviceInfo, newMonitor func() wiimote.DeviceMonitor,
 	d.moreEvents = make(chan wiimote.Event, 1024)
 	d.availIfs = make(map[wiimote.FeatureKind]string)
 	d.openIfs = make(map[wiimote.FeatureKind]feature)
+	d.requested = make(map[wiimote.FeatureKind]bool)
 
-	var err error
-	d.efd, err = syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
-	if err != nil {
-		return nil, err
+	if d.fds == nil {
+		var err error
+		d.efd, err = syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if err := d.readNodes(); err != nil {
-		syscall.Close(d.efd)
+		d.closeEpoll()
 		return nil, err
 	}
+	d.extension, _ = d.Extension()
 
 	d.umon = d.newMonitor()
 	if err := d.umon.FilterAddMatchSubsystem("input"); err != nil {
-		syscall.Close(d.efd)
+		d.closeEpoll()
 		return nil, err
 	}
 	if err := d.umon.FilterAddMatchSubsystem("hid"); err != nil {
-		syscall.Close(d.efd)
+		d.closeEpoll()
 		return nil, err
 	}
 	if err := d.umon.EnableReceiving(); err != nil {
-		syscall.Close(d.efd)
+		d.closeEpoll()
 		return nil, err
 	}
 
 	fd := d.umon.FD()
 	syscall.SetNonblock(fd, true)
 
-	var ep syscall.EpollEvent
-	ep.Events = syscall.EPOLLIN
-	ep.Fd = int32(fd)
-
-	if err := syscall.EpollCtl(d.efd, syscall.EPOLL_CTL_ADD, fd, &ep); err != nil {
-		syscall.Close(d.efd)
+	if err := d.watch(fd, sourceMonitor); err != nil {
+		d.closeEpoll()
 		return nil, err
 	}
 
-	runtime.AddCleanup(&d, func(fd int) { syscall.Close(fd) }, d.efd)
+	if d.efd >= 0 {
+		runtime.AddCleanup(&d, func(fd int) { syscall.Close(fd) }, d.efd)
+	}
+	d.OnCleanup(common.RestoreDevice(&d))
+
+	wiimote.Logger().Debug("device opened", "driver", "linuxkernel", "syspath", syspath)
 
 	return &d, nil
 }
 
+// Matchers for the child devices of a wiimote.
+var (
+	matchInput   = wiimote.MatchSubsystem("input").And(wiimote.MatchSysname("input*"))
+	matchEvent   = wiimote.MatchSubsystem("input").And(wiimote.MatchSysname("event*"))
+	matchLED     = wiimote.MatchSubsystem("leds").And(wiimote.MatchSysname("*[0-3]"))
+	matchBattery = wiimote.MatchSubsystem("power_supply")
+)
+
 // Scan the device \dev for child input devices and update our device-node
 // cache with the new information. This is called during device setup to
 // find all /dev/input/eventX nodes for all currently available features.
@@ -151,46 +220,46 @@ func (dev *device) readNodes() error {
 	if err != nil {
 		return err
 	}
+	prevAvail := dev.availIfs
+	dev.availIfs = make(map[wiimote.FeatureKind]string)
 	for d := range matches {
-		name := d.Sysname()
-		switch d.Subsystem() {
-		case "input":
-			if strings.HasPrefix(name, "input") {
-				name := d.SysattrValue("name")
-				if name == "" {
-					continue
-				}
-				prevIf = name
-			} else if strings.HasPrefix(name, "event") {
-				if prevIf == "" {
-					continue
-				}
-				node := d.Devnode()
-				if node == "" {
-					continue
-				}
-				kind, ok := featureKindFromName(prevIf)
-				if ok {
-					dev.availIfs[kind] = node
-					dev.moreEvents <- &wiimote.EventFeature{
-						Event: commonEvent{
-							timestamp: time.Now(),
-						},
-						Kind: kind,
-					}
-				}
+		switch {
+		case matchInput(d):
+			name := d.SysattrValue("name")
+			if name == "" {
+				continue
 			}
-		case "leds":
-			num := d.Syspath()[len(d.Syspath())-1]
-			if num < '0' || num > '3' {
+			prevIf = name
+		case matchEvent(d):
+			if prevIf == "" {
 				continue
 			}
-
-			if dev.ledAttrs[num-'0'] != "" {
+			node := d.Devnode()
+			if node == "" {
+				continue
+			}
+			kind, ok := featureKindFromName(prevIf)
+			if !ok {
 				continue
 			}
-			dev.ledAttrs[num-'0'] = path.Join(d.Syspath(), "brightness")
-		case "power_supply":
+			dev.availIfs[kind] = node
+			if _, ok := prevAvail[kind]; !ok {
+				wiimote.Logger().Debug("feature available", "kind", kind, "node", node)
+				dev.moreEvents <- &wiimote.EventFeature{
+					Event: commonEvent{
+						timestamp: time.Now(),
+						dev:       dev,
+					},
+					Kind: kind,
+				}
+			}
+		case matchLED(d):
+			num := d.Syspath()[len(d.Syspath())-1] - '0'
+			if dev.ledAttrs[num] != "" {
+				continue
+			}
+			dev.ledAttrs[num] = path.Join(d.Syspath(), "brightness")
+		case matchBattery(d):
 			if dev.batteryAttr != "" {
 				continue
 			}
@@ -198,21 +267,179 @@ func (dev *device) readNodes() error {
 		}
 	}
 
+	for kind := range prevAvail {
+		if _, ok := dev.availIfs[kind]; !ok {
+			wiimote.Logger().Debug("feature removed", "kind", kind)
+			dev.moreEvents <- &wiimote.EventFeature{
+				Event: commonEvent{
+					timestamp: time.Now(),
+					dev:       dev,
+				},
+				Kind:    kind,
+				Removed: true,
+			}
+		}
+	}
+
 	// close no longer available ifaces
 	for _, iff := range dev.openIfs {
 		if _, ok := dev.availIfs[iff.Kind()]; !ok {
-			iff.Close()
+			dev.closeLost(iff)
 		}
 	}
 
+	if dev.autoReopen {
+		dev.reopen()
+	}
+	dev.applyPolicy()
+
 	return nil
 }
 
+// closeLost closes a feature which was removed by the kernel. Contrary to Close, the feature
+// stays requested and is reopened if AutoReopen is enabled.
+func (dev *device) closeLost(iff feature) {
+	wr, requested := dev.requested[iff.Kind()]
+	wiimote.Logger().Debug("feature lost", "kind", iff.Kind(), "reopen", requested && dev.autoReopen)
+	iff.Close()
+	if requested {
+		dev.requested[iff.Kind()] = wr
+	}
+}
+
+// reopen opens all requested features which are available but not opened and emits an
+// EventFeatureOpened for each.
+func (dev *device) reopen() {
+	for kind, wr := range dev.requested {
+		dev.openAvailable(kind, wr)
+	}
+}
+
+// applyPolicy opens all available features matching the open policy which are not opened.
+func (dev *device) applyPolicy() {
+	for kind := range dev.availIfs {
+		if dev.policy.Kinds&kind == 0 {
+			continue
+		}
+		if dev.openAvailable(kind, dev.policy.Writable) {
+			dev.requested[kind] = dev.policy.Writable
+		}
+	}
+}
+
+// openAvailable opens kind if it is available but not opened and emits an EventFeatureOpened.
+func (dev *device) openAvailable(kind wiimote.FeatureKind, wr bool) bool {
+	if _, ok := dev.openIfs[kind]; ok {
+		return false
+	}
+	node, ok := dev.availIfs[kind]
+	if !ok {
+		return false
+	}
+	iface := featureFromName(kind)
+	if err := iface.open(dev, kind, node, wr); err != nil {
+		return false
+	}
+	dev.openIfs[kind] = iface
+	wiimote.Logger().Debug("feature opened automatically", "kind", kind)
+	dev.moreEvents <- &wiimote.EventFeatureOpened{
+		Event: commonEvent{
+			iface:     iface,
+			timestamp: time.Now(),
+		},
+		Kind: kind,
+	}
+	return true
+}
+
+// AutoReopen enables or disables reopening of features. If enabled, features requested with
+// OpenFeatures which were closed because the kernel removed them (e.g. when an extension is
+// replugged) are reopened as soon as they are available again. An EventFeatureOpened is
+// emitted for each reopened feature. Features closed explicitly are not reopened.
+func (dev *device) AutoReopen(enable bool) {
+	dev.autoReopen = enable
+	if enable {
+		dev.reopen()
+	}
+}
+
+// SetOpenPolicy sets which features are opened automatically, see wiimote.OpenPolicy.
+func (dev *device) SetOpenPolicy(policy wiimote.OpenPolicy) {
+	dev.policy = policy
+	dev.applyPolicy()
+}
+
+// sourceMonitor tags epoll events of the udev monitor, events of features are
+// tagged with their kind.
+const sourceMonitor int32 = 0
+
+// watch adds fd to the epoll descriptor, the epoll data holds fd and the source tag
+// to tell the monitor and features apart when dispatching.
+func (dev *device) watch(fd int, source int32) error {
+	if dev.fds != nil {
+		dev.fds[fd] = source
+		dev.fdsChanged()
+		return nil
+	}
+	ep := syscall.EpollEvent{
+		Events: syscall.EPOLLIN,
+		Fd:     int32(fd),
+		Pad:    source,
+	}
+	return syscall.EpollCtl(dev.efd, syscall.EPOLL_CTL_ADD, fd, &ep)
+}
+
+// closeEpoll closes the epoll descriptor of a device which failed to open.
+func (dev *device) closeEpoll() {
+	if dev.efd >= 0 {
+		syscall.Close(dev.efd)
+	}
+}
+
+// unwatch removes fd from the epoll descriptor, see watch.
+func (dev *device) unwatch(fd int) error {
+	if dev.fds != nil {
+		if _, ok := dev.fds[fd]; ok {
+			delete(dev.fds, fd)
+			dev.fdsChanged()
+		}
+		return nil
+	}
+	return syscall.EpollCtl(dev.efd, syscall.EPOLL_CTL_DEL, fd, nil)
+}
+
+func (dev *device) fdsChanged() {
+	if dev.onFDs != nil {
+		dev.onFDs()
+	}
+}
+
+// FDs returns the file descriptors of the monitor and the opened features, see wiimote.FDSet.
+// With epoll, the epoll descriptor is the only descriptor.
+func (dev *device) FDs() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if dev.fds == nil {
+			yield(dev.efd)
+			return
+		}
+		for fd := range dev.fds {
+			if !yield(fd) {
+				return
+			}
+		}
+	}
+}
+
+// OnFDsChanged sets fn to be called when FDs changed, see wiimote.FDSet.
+func (dev *device) OnFDsChanged(fn func()) {
+	dev.onFDs = fn
+}
+
 // FD returns the file-descriptor to notify readiness. If multiple file-descriptors
 // are used internally, they are multi-plexed through an epoll descriptor.
 // Therefore, this always returns the same single file-descriptor. You need to
 // watch this for readable-events (POLLIN/EPOLLIN) and call
-// Poll() whenever it is readable.
+// Poll() whenever it is readable. It returns -1 for devices created with ExternalLoop.
 func (dev *device) FD() int {
 	return dev.efd
 }
@@ -242,13 +469,14 @@ func (dev *device) OpenFeatures(ifaces wiimote.FeatureKind, wr bool) error {
 		if ifaces&kind == 0 {
 			continue
 		}
+		dev.requested[kind] = wr
 		node, ok := dev.availIfs[kind]
 		if !ok {
 			continue
 		}
 		iface := featureFromName(kind)
 		if err := iface.open(dev, kind, node, wr); err != nil {
-			errs = append(errs, err)
+			errs = append(errs, &wiimote.FeatureError{Kind: kind, Err: common.Permission(err)})
 			continue
 		}
 		dev.openIfs[kind] = iface
@@ -289,25 +517,60 @@ func (dev *device) Available(iface wiimote.FeatureKind) bool {
 // It returns the event or nil if an error occured, the continue-flag whether a new event can be polled right away and
 // optionally and error, if the error is ErrRetry, consider polling again for new events.
 func (dev *device) Poll() (wiimote.Event, bool, error) {
+	// coalesced samples are kept back, the state is updated with every sample
+	return dev.limit.Poll(dev.poll, dev.state.Update, nil)
+}
+
+// State returns the latest values of all opened features.
+func (dev *device) State() wiimote.State {
+	return dev.state.State()
+}
+
+func (dev *device) poll() (wiimote.Event, bool, error) {
 	select {
 	case e := <-dev.moreEvents:
 		return e, true, nil
 	default:
 	}
 
-	var ep [32]syscall.EpollEvent
-
-	//  write outgoing events here
-	n, err := syscall.EpollWait(dev.efd, ep[:], 0)
-	if err != nil {
-		return nil, false, err
+	// events of a previous batch are not reported by epoll
+	for _, iff := range dev.openIfs {
+		if dev.paused || !iff.batch().buffered() {
+			continue
+		}
+		ev, err := dispatchEvent(dev, iff)
+		if err != nil && !errors.Is(err, common.ErrWouldBlock) {
+			return dev.handleError(err)
+		}
+		if ev != nil {
+			dev.errs.Reset()
+			return ev, true, nil
+		}
 	}
-	for _, pollev := range ep[:n] {
-		ev, err := dev.dispatchEvent(pollev.Fd, pollev.Events)
+
+	var ep [32]syscall.EpollEvent
+	var ready []syscall.EpollEvent
+	if dev.fds != nil {
+		// readiness is unknown without epoll, all descriptors are non-blocking
+		ready = ep[:0]
+		for fd, source := range dev.fds {
+			ready = append(ready, syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(fd), Pad: source})
+		}
+	} else {
+		//  write outgoing events here
+		n, err := syscall.EpollWait(dev.efd, ep[:], 0)
 		if err != nil {
-			return nil, false, err
+			return dev.handleError(err)
+		}
+		ready = ep[:n]
+	}
+	for _, pollev := range ready {
+		ev, err := dev.dispatchEvent(pollev)
+		if err != nil && !errors.Is(err, common.ErrWouldBlock) {
+			return dev.handleError(err)
 		}
 		if ev != nil {
+			dev.errs.Reset()
 			return ev, true, nil
 		}
 	}
@@ -315,47 +578,225 @@ func (dev *device) Poll() (wiimote.Event, bool, error) {
 	return nil, false, common.ErrWouldBlock
 }
 
-// LED reads the LED state for the given LED.
+func (dev *device) RawEvents(kind wiimote.FeatureKind, cooked bool) (<-chan wiimote.RawEvent, error) {
+	dev.StopRawEvents(kind)
+	if dev.raw == nil {
+		dev.raw = make(map[wiimote.FeatureKind]*rawTap)
+	}
+	tap := &rawTap{ch: make(chan wiimote.RawEvent, rawBuffer), cooked: cooked}
+	dev.raw[kind] = tap
+	return tap.ch, nil
+}
+
+func (dev *device) StopRawEvents(kind wiimote.FeatureKind) {
+	if tap, ok := dev.raw[kind]; ok {
+		close(tap.ch)
+		delete(dev.raw, kind)
+	}
+}
+
+func (dev *device) SetMaxRate(kind wiimote.FeatureKind, hz float64) {
+	dev.limit.SetMaxRate(kind, hz)
+}
+
+// Pause stops reporting the events of opened features, the features of close are closed
+// until Resume. The file-descriptors of the other features are removed from epoll, the kernel
+// keeps buffering their latest events.
+func (dev *device) Pause(close wiimote.FeatureKind) error {
+	if dev.paused {
+		return nil
+	}
+	var errs []error
+	dev.pausedIfs = make(map[wiimote.FeatureKind]bool)
+	for kind, iff := range dev.openIfs {
+		if close&kind != 0 {
+			wr := dev.requested[kind]
+			if err := iff.Close(); err != nil {
+				errs = append(errs, &wiimote.FeatureError{Kind: kind, Err: err})
+				continue
+			}
+			dev.pausedIfs[kind] = wr
+			continue
+		}
+		if err := dev.unwatch(int(iff.fd())); err != nil {
+			errs = append(errs, &wiimote.FeatureError{Kind: kind, Err: err})
+			continue
+		}
+		dev.unwatched |= kind
+	}
+	dev.paused = true
+	wiimote.Logger().Debug("device paused", "syspath", dev.Syspath(), "closed", close)
+	return errors.Join(errs...)
+}
+
+// Resume reports the events of opened features again and reopens the features closed by Pause.
+func (dev *device) Resume() error {
+	if !dev.paused {
+		return nil
+	}
+	var errs []error
+	// features opened while paused are watched already
+	for kind, iff := range dev.openIfs {
+		if dev.unwatched&kind == 0 {
+			continue
+		}
+		if err := dev.watch(int(iff.fd()), int32(kind)); err != nil {
+			errs = append(errs, &wiimote.FeatureError{Kind: kind, Err: err})
+		}
+	}
+	dev.paused = false
+	dev.unwatched = 0
+	for kind, wr := range dev.pausedIfs {
+		if err := dev.OpenFeatures(kind, wr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	dev.pausedIfs = nil
+	wiimote.Logger().Debug("device resumed", "syspath", dev.Syspath())
+	return errors.Join(errs...)
+}
+
+func (dev *device) handleError(err error) (wiimote.Event, bool, error) {
+	base := commonEvent{timestamp: time.Now(), dev: dev}
+	return dev.errs.Handle(err, base, func() {
+		for _, iff := range dev.openIfs {
+			iff.Close()
+		}
+	})
+}
+
+// ReadStats returns the number of reads and raw events of all features since the device was created.
+func (dev *device) ReadStats() wiimote.ReadStats {
+	return dev.stats
+}
+
+// SetErrorPolicy sets how errors while polling are handled, see wiimote.ErrorPolicy.
+func (dev *device) Capabilities() wiimote.Capabilities {
+	return common.Capabilities(dev)
+}
+
+func (dev *device) SetErrorPolicy(policy wiimote.ErrorPolicy) {
+	dev.errs.Policy = policy
+}
+
+// LED reads the state of the LEDs, LEDs the device does not provide are reported as off.
 //
 // LEDs are a static feature that does not have to be opened first.
 func (dev *device) LED() (result wiimote.Led, _ error) {
-	for i := range 4 {
-		cont, err := os.ReadFile(dev.ledAttrs[i])
-		if err != nil {
-			return 0, err
+	if dev.LEDs() == 0 {
+		return 0, wiimote.ErrNoLED
+	}
+	var errs []error
+	for i, attr := range dev.ledAttrs {
+		if attr == "" {
+			continue
 		}
-		if strings.TrimSpace(string(cont)) == "1" {
+		on, err := dev.LEDState(i)
+		if err != nil {
+			errs = append(errs, err)
+		} else if on {
 			result |= 1 << i
 		}
 	}
-	return result, nil
+	return result, errors.Join(errs...)
 }
 
-// SetLED writes the LED state for the given LED.
+// SetLED writes the state of the LEDs, LEDs the device does not provide are skipped.
 //
 // LEDs are a static feature that does not have to be opened first.
 func (dev *device) SetLED(leds wiimote.Led) error {
-	for i := range 4 {
-		state := leds&(1<<i) != 0
-
-		cont := "0\n"
-		if state {
-			cont = "1\n"
+	if dev.LEDs() == 0 {
+		return wiimote.ErrNoLED
+	}
+	var errs []error
+	for i, attr := range dev.ledAttrs {
+		if attr != "" {
+			errs = append(errs, dev.SetLEDState(i, leds&(1<<i) != 0))
 		}
-		if err := os.WriteFile(dev.ledAttrs[i], []byte(cont), 0); err != nil {
-			return err
+	}
+	return errors.Join(errs...)
+}
+
+// LEDs returns the LEDs which have a node, the Balance Board has none.
+func (dev *device) LEDs() (leds wiimote.Led) {
+	for i, attr := range dev.ledAttrs {
+		if attr != "" {
+			leds |= 1 << i
 		}
 	}
+	return leds
+}
+
+// LEDState reads the brightness of the LED at index.
+func (dev *device) LEDState(index int) (bool, error) {
+	attr, err := dev.ledAttr(index)
+	if err != nil {
+		return false, err
+	}
+	cont, err := os.ReadFile(attr)
+	if err != nil {
+		return false, &wiimote.LEDError{Index: index, Err: common.Permission(err)}
+	}
+	return strings.TrimSpace(string(cont)) == "1", nil
+}
+
+// SetLEDState writes the brightness of the LED at index.
+func (dev *device) SetLEDState(index int, on bool) error {
+	attr, err := dev.ledAttr(index)
+	if err != nil {
+		return err
+	}
+	cont := "0\n"
+	if on {
+		cont = "1\n"
+	}
+	if err := os.WriteFile(attr, []byte(cont), 0); err != nil {
+		return &wiimote.LEDError{Index: index, Err: common.Permission(err)}
+	}
+	return nil
+}
+
+// ledAttr returns the brightness attribute of the LED at index.
+func (dev *device) ledAttr(index int) (string, error) {
+	if _, ok := wiimote.LedAt(index); !ok {
+		return "", &wiimote.LEDError{Index: index, Err: os.ErrInvalid}
+	}
+	if dev.ledAttrs[index] == "" {
+		return "", &wiimote.LEDError{Index: index, Err: wiimote.ErrNoLED}
+	}
+	return dev.ledAttrs[index], nil
+}
+
+// SetPlayerLED shows the player number n (1 to 4) on the LEDs, see PlayerLED.
+//
+// LEDs are a static feature that does not have to be opened first.
+func (dev *device) SetPlayerLED(n int) error {
+	leds, ok := wiimote.PlayerLED(n)
+	if !ok {
+		return os.ErrInvalid
+	}
+	if err := dev.SetLED(leds); err != nil {
+		return err
+	}
+	dev.player = n
 	return nil
 }
 
+// Player returns the player number set with SetPlayerLED, 0 if no player number was set.
+func (dev *device) Player() int {
+	return dev.player
+}
+
 // Battery reads the current battery capacity. The capacity is represented as percentage, thus the return value is an integer between 0 and 100.
 //
 // Batteries are a static feature that does not have to be opened first.
 func (dev *device) Battery() (uint, error) {
+	if dev.batteryAttr == "" {
+		return 0, wiimote.ErrNoBattery
+	}
 	cont, err := os.ReadFile(dev.batteryAttr)
 	if err != nil {
-		return 0, err
+		return 0, common.Permission(err)
 	}
 
 	cap, err := strconv.Atoi(strings.TrimSpace(string(cont)))
@@ -368,7 +809,12 @@ func (dev *device) Battery() (uint, error) {
 // This is a static feature that does not have to be opened first.
 func (dev *device) DevType() (string, error) {
 	cont, err := os.ReadFile(dev.devtypeAttr)
-	return strings.TrimSpace(string(cont)), err
+	return strings.TrimSpace(string(cont)), common.Permission(err)
+}
+
+func (dev *device) Type() (wiimote.DevType, error) {
+	devtype, err := dev.DevType()
+	return wiimote.ParseDevType(devtype), err
 }
 
 // Extension returns the extension type. If no extension is connected or the
@@ -377,7 +823,52 @@ func (dev *device) DevType() (string, error) {
 // This is a static feature that does not have to be opened first.
 func (dev *device) Extension() (string, error) {
 	cont, err := os.ReadFile(dev.extensionAttr)
-	return strings.TrimSpace(string(cont)), err
+	return strings.TrimSpace(string(cont)), common.Permission(err)
+}
+
+// checkExtension reads the extension and queues EventExtensionDisconnected and
+// EventExtensionConnected if it changed.
+func (dev *device) checkExtension() {
+	ext, err := dev.Extension()
+	if err != nil || ext == dev.extension {
+		return
+	}
+	ts := time.Now()
+	if dev.extension != "none" && dev.extension != "" {
+		dev.moreEvents <- &wiimote.EventExtensionDisconnected{
+			Event: commonEvent{timestamp: ts, dev: dev},
+			Type:  dev.extension,
+		}
+	}
+	if ext != "none" && ext != "" {
+		dev.moreEvents <- &wiimote.EventExtensionConnected{
+			Event: commonEvent{timestamp: ts, dev: dev},
+			Type:  ext,
+		}
+	}
+	dev.extension = ext
+}
+
+// UniqueID returns the unique identifier of the device, which is the Bluetooth address
+// of the device (e.g. "00:1f:32:aa:bb:cc"). It can be used to recognize a physical
+// device across reconnects.
+//
+// This is a static feature that does not have to be opened first.
+func (dev *device) UniqueID() (string, error) {
+	uniq := dev.dev.PropertyValue("HID_UNIQ")
+	if uniq == "" {
+		return "", os.ErrNotExist
+	}
+	return strings.ToLower(uniq), nil
+}
+
+// Disconnect closes the Bluetooth connection of the device using BlueZ, see bluez.Disconnect.
+func (dev *device) Disconnect() error {
+	uniq, err := dev.UniqueID()
+	if err != nil {
+		return err
+	}
+	return bluez.Disconnect(uniq)
 }
 
 func (dev *device) String() string {
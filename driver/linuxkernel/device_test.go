@@ -0,0 +1,313 @@
+package linuxkernel
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+)
+
+type fakeDevice struct {
+	subsystem, driver, syspath, action string
+}
+
+func (d fakeDevice) Parent() wiimote.DeviceInfo         { return nil }
+func (d fakeDevice) Subsystem() string                  { return d.subsystem }
+func (d fakeDevice) Sysname() string                    { return "" }
+func (d fakeDevice) Syspath() string                    { return d.syspath }
+func (d fakeDevice) Devnode() string                    { return "" }
+func (d fakeDevice) Driver() string                     { return d.driver }
+func (d fakeDevice) Action() string                     { return d.action }
+func (d fakeDevice) SysattrValue(sysattr string) string { return "" }
+func (d fakeDevice) PropertyValue(key string) string    { return "" }
+
+// fakeMonitor reports queued devices, its fd is readable while devices are queued.
+type fakeMonitor struct {
+	fds   [2]int
+	queue []wiimote.DeviceInfo
+}
+
+func newFakeMonitor(t *testing.T) *fakeMonitor {
+	var mon fakeMonitor
+	if err := syscall.Pipe2(mon.fds[:], syscall.O_NONBLOCK|syscall.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		syscall.Close(mon.fds[0])
+		syscall.Close(mon.fds[1])
+	})
+	return &mon
+}
+
+func (mon *fakeMonitor) push(dev wiimote.DeviceInfo) {
+	mon.queue = append(mon.queue, dev)
+	syscall.Write(mon.fds[1], []byte{0})
+}
+
+func (mon *fakeMonitor) FD() int                { return mon.fds[0] }
+func (mon *fakeMonitor) EnableReceiving() error { return nil }
+func (mon *fakeMonitor) ReceiveDevice() wiimote.DeviceInfo {
+	if len(mon.queue) == 0 {
+		return nil
+	}
+	var buf [1]byte
+	syscall.Read(mon.fds[0], buf[:])
+	dev := mon.queue[0]
+	mon.queue = mon.queue[1:]
+	return dev
+}
+func (mon *fakeMonitor) Devices(ctx context.Context) <-chan wiimote.DeviceInfo { return nil }
+func (mon *fakeMonitor) SetReceiveBufferSize(size int) error                   { return nil }
+func (mon *fakeMonitor) FilterAddMatchSubsystem(subsystem string) error        { return nil }
+func (mon *fakeMonitor) FilterUpdate() error                                   { return nil }
+func (mon *fakeMonitor) FilterRemove() error                                   { return nil }
+
+// fakeEnumerator enumerates no devices.
+type fakeEnumerator struct{}
+
+func (fakeEnumerator) AddMatchSubsystem(subsystem string) error       { return nil }
+func (fakeEnumerator) AddNomatchSubsystem(subsystem string) error     { return nil }
+func (fakeEnumerator) AddMatchSysattr(sysattr, value string) error    { return nil }
+func (fakeEnumerator) AddNomatchSysattr(sysattr, value string) error  { return nil }
+func (fakeEnumerator) AddMatchSysname(sysname string) error           { return nil }
+func (fakeEnumerator) AddMatchProperty(property, value string) error  { return nil }
+func (fakeEnumerator) Match(m wiimote.DeviceMatcher)                  {}
+func (fakeEnumerator) AddMatchParent(parent wiimote.DeviceInfo) error { return nil }
+func (fakeEnumerator) AddSyspath(syspath string) error                { return nil }
+func (fakeEnumerator) Devices() (iter.Seq[wiimote.DeviceInfo], error) {
+	return func(func(wiimote.DeviceInfo) bool) {}, nil
+}
+func (fakeEnumerator) Subsystems() (iter.Seq[string], error) {
+	return func(func(string) bool) {}, nil
+}
+
+func TestWatchMonitor(t *testing.T) {
+	mon := newFakeMonitor(t)
+	hid := fakeDevice{subsystem: "hid", driver: "wiimote", syspath: t.TempDir()}
+	dev, err := NewDevice(hid,
+		func() wiimote.DeviceMonitor { return mon },
+		func() wiimote.DeviceEnumerator { return fakeEnumerator{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ev, _, _ := dev.Poll(); ev != nil {
+		t.Fatalf("expected no event, got %T", ev)
+	}
+
+	// the nunchuk is unplugged
+	dev.availIfs[wiimote.FeatureNunchuck] = "/dev/input/event0"
+	dev.extension = "nunchuk"
+	if err := os.WriteFile(filepath.Join(hid.syspath, "extension"), []byte("none\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	change := hid
+	change.action = "change"
+	mon.push(change)
+	ev, _, err := dev.Poll()
+	watch, ok := ev.(*wiimote.EventWatch)
+	if !ok || err != nil {
+		t.Fatalf("expected watch event, got %T %v", ev, err)
+	}
+	if watch.Removed != wiimote.FeatureNunchuck || watch.Added != 0 ||
+		watch.PrevExtension != "nunchuk" || watch.Extension != "none" {
+		t.Errorf("unexpected change %+v", watch)
+	}
+	// drop the queued feature and extension events
+	for len(dev.moreEvents) > 0 {
+		<-dev.moreEvents
+	}
+
+	remove := hid
+	remove.action = "remove"
+	mon.push(remove)
+	ev, _, err = dev.Poll()
+	if _, ok := ev.(*wiimote.EventGone); !ok || err != nil {
+		t.Fatalf("expected gone event, got %T %v", ev, err)
+	}
+}
+
+func TestDispatchStaleFeature(t *testing.T) {
+	mon := newFakeMonitor(t)
+	hid := fakeDevice{subsystem: "hid", driver: "wiimote", syspath: t.TempDir()}
+	dev, err := NewDevice(hid,
+		func() wiimote.DeviceMonitor { return mon },
+		func() wiimote.DeviceEnumerator { return fakeEnumerator{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a closed feature must not be mistaken for the monitor
+	ep := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(mon.FD()), Pad: int32(wiimote.FeatureCore)}
+	mon.push(fakeDevice{subsystem: "hid", syspath: hid.syspath, action: "change"})
+	if ev, err := dev.dispatchEvent(ep); ev != nil || err != nil {
+		t.Fatalf("expected no event, got %T %v", ev, err)
+	}
+}
+
+func TestRawEvents(t *testing.T) {
+	mon := newFakeMonitor(t)
+	hid := fakeDevice{subsystem: "hid", driver: "wiimote", syspath: t.TempDir()}
+	dev, err := NewDevice(hid,
+		func() wiimote.DeviceMonitor { return mon },
+		func() wiimote.DeviceEnumerator { return fakeEnumerator{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fds [2]int
+	if err := syscall.Pipe2(fds[:], syscall.O_NONBLOCK); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+	iff := &featureAccel{commonFeature: commonFeature{dev: dev, opened: true, file: common.UnbufferedFile(fds[0]), kind: wiimote.FeatureAccel}}
+
+	// ABS_RX = 42 and SYN_REPORT
+	var report eventBatch
+	report.events[0]._type, report.events[0].code, report.events[0].value = 3, 3, 42
+	writeEvents := func() {
+		size := 2 * unsafe.Sizeof(report.events[0])
+		if _, err := syscall.Write(fds[1], unsafe.Slice((*byte)(unsafe.Pointer(&report.events[0])), size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ch, err := dev.RawEvents(wiimote.FeatureAccel, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeEvents()
+	ev, err := dispatchEvent(dev, iff)
+	if accel, ok := ev.(*wiimote.EventAccel); !ok || err != nil || accel.Accel.X != 42 {
+		t.Fatalf("expected accelerometer event, got %#v %v", ev, err)
+	}
+	if raw := <-ch; raw.Feature != wiimote.FeatureAccel || raw.Type != 3 || raw.Code != 3 || raw.Value != 42 {
+		t.Errorf("unexpected raw event: %+v", raw)
+	}
+	if raw := <-ch; raw.Type != 0 {
+		t.Errorf("expected SYN_REPORT, got %+v", raw)
+	}
+
+	// only raw events are reported
+	ch, err = dev.RawEvents(wiimote.FeatureAccel, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeEvents()
+	if ev, err := dispatchEvent(dev, iff); ev != nil || !errors.Is(err, common.ErrWouldBlock) {
+		t.Fatalf("expected no cooked event, got %T %v", ev, err)
+	}
+	if len(ch) != 2 {
+		t.Errorf("expected two raw events, got %d", len(ch))
+	}
+
+	dev.StopRawEvents(wiimote.FeatureAccel)
+	if _, ok := <-ch; !ok {
+		t.Errorf("expected buffered events before the channel is closed")
+	}
+}
+
+func TestExternalLoop(t *testing.T) {
+	mon := newFakeMonitor(t)
+	hid := fakeDevice{subsystem: "hid", driver: "wiimote", syspath: t.TempDir()}
+	dev, err := NewDevice(hid,
+		func() wiimote.DeviceMonitor { return mon },
+		func() wiimote.DeviceEnumerator { return fakeEnumerator{} },
+		ExternalLoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.FD() != -1 {
+		t.Fatalf("expected no epoll descriptor, got %d", dev.FD())
+	}
+	if fds := slices.Collect(dev.FDs()); len(fds) != 1 || fds[0] != mon.FD() {
+		t.Fatalf("expected the monitor descriptor, got %v", fds)
+	}
+
+	var fds [2]int
+	if err := syscall.Pipe2(fds[:], syscall.O_NONBLOCK); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fds[1])
+	changed := 0
+	dev.OnFDsChanged(func() { changed++ })
+	iff := &featureAccel{commonFeature: commonFeature{dev: dev, opened: true, file: common.UnbufferedFile(fds[0]), kind: wiimote.FeatureAccel}}
+	dev.watch(fds[0], int32(wiimote.FeatureAccel))
+	dev.openIfs[wiimote.FeatureAccel] = iff
+	if !slices.Contains(slices.Collect(dev.FDs()), fds[0]) || changed != 1 {
+		t.Fatalf("expected the feature to be watched, changed %d times", changed)
+	}
+
+	if ev, _, err := dev.Poll(); ev != nil || !errors.Is(err, common.ErrWouldBlock) {
+		t.Fatalf("expected no event, got %T %v", ev, err)
+	}
+	mon.push(fakeDevice{subsystem: "hid", syspath: hid.syspath, action: "change"})
+	if ev, _, err := dev.Poll(); err != nil {
+		t.Fatalf("expected event, got %v", err)
+	} else if _, ok := ev.(*wiimote.EventWatch); !ok {
+		t.Fatalf("expected watch event, got %T", ev)
+	}
+
+	iff.Close()
+	if slices.Contains(slices.Collect(dev.FDs()), fds[0]) || changed != 2 {
+		t.Fatalf("expected the closed feature not to be watched, changed %d times", changed)
+	}
+}
+
+func TestPartialLEDs(t *testing.T) {
+	mon := newFakeMonitor(t)
+	hid := fakeDevice{subsystem: "hid", driver: "wiimote", syspath: t.TempDir()}
+	dev, err := NewDevice(hid,
+		func() wiimote.DeviceMonitor { return mon },
+		func() wiimote.DeviceEnumerator { return fakeEnumerator{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.SetLED(wiimote.Led1); !errors.Is(err, wiimote.ErrNoLED) {
+		t.Fatalf("expected %v without LEDs, got %v", wiimote.ErrNoLED, err)
+	}
+
+	for _, i := range []int{0, 2} {
+		dev.ledAttrs[i] = filepath.Join(hid.syspath, "brightness"+strconv.Itoa(i))
+		if err := os.WriteFile(dev.ledAttrs[i], []byte("0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if leds := dev.LEDs(); leds != wiimote.Led1|wiimote.Led3 {
+		t.Errorf("expected LEDs %v, got %v", wiimote.Led1|wiimote.Led3, leds)
+	}
+	if err := dev.SetLED(wiimote.Led1 | wiimote.Led2 | wiimote.Led3); err != nil {
+		t.Fatal(err)
+	}
+	if leds, err := dev.LED(); err != nil || leds != wiimote.Led1|wiimote.Led3 {
+		t.Errorf("expected %v, got %v %v", wiimote.Led1|wiimote.Led3, leds, err)
+	}
+
+	var lederr *wiimote.LEDError
+	if _, err := dev.LEDState(1); !errors.Is(err, wiimote.ErrNoLED) || !errors.As(err, &lederr) || lederr.Index != 1 {
+		t.Errorf("expected missing LED 2, got %v", err)
+	}
+	if err := dev.SetLEDState(0, false); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Remove(dev.ledAttrs[2])
+	leds, err := dev.LED()
+	if !errors.As(err, &lederr) || lederr.Index != 2 {
+		t.Errorf("expected error of LED 3, got %v", err)
+	}
+	if leds != 0 {
+		t.Errorf("expected state of the other LEDs, got %v", leds)
+	}
+}
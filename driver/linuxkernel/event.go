@@ -1,6 +1,7 @@
 package linuxkernel
 
 import (
+	"syscall"
 	"time"
 
 	"github.com/friedelschoen/go-wiimote"
@@ -9,12 +10,24 @@ import (
 type commonEvent struct {
 	iface     feature
 	timestamp time.Time
+	// device of events without feature
+	dev *device
 }
 
 func (evt commonEvent) Feature() wiimote.Feature {
 	return evt.iface
 }
 
+func (evt commonEvent) Device() wiimote.Device {
+	if evt.iface != nil {
+		return evt.iface.Device()
+	}
+	if evt.dev != nil {
+		return evt.dev
+	}
+	return nil
+}
+
 func (evt commonEvent) Timestamp() time.Time {
 	return evt.timestamp
 }
@@ -63,35 +76,62 @@ func (dev *device) readUmon(pollEv uint32) (wiimote.Event, error) {
 		return &wiimote.EventGone{
 			Event: commonEvent{
 				timestamp: time.Now(),
-				iface:     nil,
+				dev:       dev,
 			},
 		}, nil
 	}
 
 	// notify caller via generic hotplug event
 	if hotplug {
-		dev.readNodes()
-		return &wiimote.EventWatch{
-			Event: commonEvent{
-				timestamp: time.Now(),
-				iface:     nil,
-			},
-		}, nil
+		return dev.watchEvent(), nil
 	}
 
 	return nil, nil
 }
 
-func (dev *device) dispatchEvent(evFd int32, pollEv uint32) (wiimote.Event, error) {
-	if dev.umon != nil && dev.umon.FD() == int(evFd) {
-		return dev.readUmon(pollEv)
+// watchEvent reads the nodes and the extension again and returns an EventWatch describing
+// what changed.
+func (dev *device) watchEvent() *wiimote.EventWatch {
+	avail, opened := dev.kinds()
+	ev := &wiimote.EventWatch{
+		Event: commonEvent{
+			timestamp: time.Now(),
+			dev:       dev,
+		},
+		PrevExtension: dev.extension,
 	}
-	for _, iff := range dev.openIfs {
-		if int32(iff.fd()) != evFd {
-			continue
-		}
-		return dispatchEvent(iff)
+	dev.readNodes()
+	dev.checkExtension()
+	nowAvail, nowOpened := dev.kinds()
+	ev.Added = nowAvail &^ avail
+	ev.Removed = avail &^ nowAvail
+	ev.Closed = opened &^ nowOpened
+	ev.Extension = dev.extension
+	return ev
+}
+
+// kinds returns the available and opened features.
+func (dev *device) kinds() (avail, opened wiimote.FeatureKind) {
+	for kind := range dev.availIfs {
+		avail |= kind
 	}
+	for kind := range dev.openIfs {
+		opened |= kind
+	}
+	return avail, opened
+}
 
-	return nil, nil
+func (dev *device) dispatchEvent(ep syscall.EpollEvent) (wiimote.Event, error) {
+	if ep.Pad == sourceMonitor {
+		if dev.umon == nil {
+			return nil, nil
+		}
+		return dev.readUmon(ep.Events)
+	}
+	iff, ok := dev.openIfs[wiimote.FeatureKind(ep.Pad)]
+	// the feature may be closed or reopened on another fd since the event was queued
+	if !ok || int32(iff.fd()) != ep.Fd {
+		return nil, nil
+	}
+	return dispatchEvent(dev, iff)
 }
@@ -3,8 +3,8 @@ package linuxkernel
 // #include "input-defs.h"
 import "C"
 import (
+	"errors"
 	"io"
-	"os"
 	"path/filepath"
 	"syscall"
 	"time"
@@ -18,6 +18,7 @@ type feature interface {
 	wiimote.Feature
 
 	fd() common.UnbufferedFile
+	batch() *eventBatch
 	open(dev *device, kind wiimote.FeatureKind, node string, wr bool) error
 	acceptEvent(ts time.Time, event, code uint16, value int32) (wiimote.Event, error)
 }
@@ -31,6 +32,8 @@ type commonFeature struct {
 	file common.UnbufferedFile
 	// current kind
 	kind wiimote.FeatureKind
+	// events read but not yet dispatched
+	pending eventBatch
 }
 
 func (iface *commonFeature) Kind() wiimote.FeatureKind {
@@ -44,6 +47,10 @@ func (iface *commonFeature) fd() common.UnbufferedFile {
 	return iface.file
 }
 
+func (iface *commonFeature) batch() *eventBatch {
+	return &iface.pending
+}
+
 // Opened returns a bitmask of opened features. Features may be closed due to
 // error-conditions at any time. However, features are never opened
 // automatically.
@@ -60,6 +67,7 @@ func (iff *commonFeature) open(dev *device, kind wiimote.FeatureKind, node strin
 	}
 
 	iff.dev = dev
+	iff.kind = kind
 
 	flags := syscall.O_NONBLOCK | syscall.O_CLOEXEC
 	if wr {
@@ -71,16 +79,14 @@ func (iff *commonFeature) open(dev *device, kind wiimote.FeatureKind, node strin
 	}
 	file := common.UnbufferedFile(fd)
 
-	var ep syscall.EpollEvent
-	ep.Events = syscall.EPOLLIN
-	ep.Fd = int32(fd)
-	if err := syscall.EpollCtl(iff.dev.efd, syscall.EPOLL_CTL_ADD, int(fd), &ep); err != nil {
+	if err := dev.watch(fd, int32(kind)); err != nil {
 		file.Close()
 		return err
 	}
 
 	iff.opened = true
 	iff.file = file
+	wiimote.Logger().Debug("feature opened", "kind", kind, "node", node, "writable", wr)
 	return nil
 }
 
@@ -88,16 +94,21 @@ func (iff *commonFeature) Close() error {
 	if !iff.opened {
 		return nil
 	}
-	if err := syscall.EpollCtl(iff.dev.efd, syscall.EPOLL_CTL_DEL, int(iff.file), nil); err != nil {
+	// features of a paused device are not watched
+	if err := iff.dev.unwatch(int(iff.file)); err != nil && !errors.Is(err, syscall.ENOENT) {
 		return err
 	}
+	iff.dev.unwatched &^= iff.kind
 	if err := iff.file.Close(); err != nil {
 		return err
 	}
 	iff.opened = false
 	iff.file = 0
+	iff.pending.reset()
+	wiimote.Logger().Debug("feature closed", "kind", iff.kind)
 
 	delete(iff.dev.openIfs, iff.kind)
+	delete(iff.dev.requested, iff.kind)
 	return iff.dev.readNodes()
 }
 
@@ -146,8 +157,11 @@ func (iff *rumbleFeature) Close() error {
 //
 // This requires the core-feature to be opened in writable mode.
 func (dev *rumbleFeature) Rumble(state bool) error {
-	if !dev.opened || !dev.rumbleValid {
-		return os.ErrInvalid
+	if !dev.opened {
+		return wiimote.ErrNotOpened
+	}
+	if !dev.rumbleValid {
+		return wiimote.ErrFeatureUnavailable
 	}
 
 	var ev C.struct_input_event
@@ -182,36 +196,13 @@ func (iface *featureCore) acceptEvent(ts time.Time, event, code uint16, value in
 		return nil, nil
 	}
 
-	var key wiimote.Key
-	switch code {
-	case C.KEY_LEFT:
-		key = wiimote.KeyLeft
-	case C.KEY_RIGHT:
-		key = wiimote.KeyRight
-	case C.KEY_UP:
-		key = wiimote.KeyUp
-	case C.KEY_DOWN:
-		key = wiimote.KeyDown
-	case C.KEY_NEXT:
-		key = wiimote.KeyPlus
-	case C.KEY_PREVIOUS:
-		key = wiimote.KeyMinus
-	case C.BTN_1:
-		key = wiimote.KeyOne
-	case C.BTN_2:
-		key = wiimote.KeyTwo
-	case C.BTN_A:
-		key = wiimote.KeyA
-	case C.BTN_B:
-		key = wiimote.KeyB
-	case C.BTN_MODE:
-		key = wiimote.KeyHome
-	default:
+	key, ok := KeyFromCode(wiimote.FeatureCore, code)
+	if !ok {
 		return nil, nil
 	}
 
 	var ev wiimote.EventKey
-	ev.Event = commonEvent{iface, ts}
+	ev.Event = commonEvent{iface: iface, timestamp: ts}
 	ev.Code = key
 	ev.Pressed = value != 0
 	return &ev, nil
@@ -238,7 +229,7 @@ type featureAccel struct {
 func (iface *featureAccel) acceptEvent(ts time.Time, event, code uint16, value int32) (wiimote.Event, error) {
 	if event == C.EV_SYN {
 		var ev wiimote.EventAccel
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Accel = iface.accel
 		return &ev, nil
 	}
@@ -278,7 +269,7 @@ func (iface *featureIR) open(dev *device, kind wiimote.FeatureKind, node string,
 func (iface *featureIR) acceptEvent(ts time.Time, event, code uint16, value int32) (wiimote.Event, error) {
 	if event == C.EV_SYN {
 		var ev wiimote.EventIR
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Slots = iface.slots
 		return &ev, nil
 	}
@@ -374,7 +365,7 @@ func (iface *featureMotionPlus) acceptEvent(ts time.Time, event, code uint16, va
 		}
 
 		var ev wiimote.EventMotionPlus
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Speed = iface.speed
 		return &ev, nil
 	}
@@ -408,18 +399,13 @@ func (iface *featureNunchuck) acceptEvent(ts time.Time, event, code uint16, valu
 		if value < 0 || value > 1 {
 			return nil, nil
 		}
-		var key wiimote.Key
-		switch code {
-		case C.BTN_C:
-			key = wiimote.KeyC
-		case C.BTN_Z:
-			key = wiimote.KeyZ
-		default:
+		key, ok := KeyFromCode(wiimote.FeatureNunchuck, code)
+		if !ok {
 			return nil, nil
 		}
 
 		var ev wiimote.EventNunchukKey
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Code = key
 		ev.Pressed = value != 0
 		return &ev, nil
@@ -438,7 +424,7 @@ func (iface *featureNunchuck) acceptEvent(ts time.Time, event, code uint16, valu
 		}
 	case C.EV_SYN:
 		var ev wiimote.EventNunchukMove
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Stick = iface.stick
 		ev.Accel = iface.accel
 		return &ev, nil
@@ -463,44 +449,13 @@ func (iface *featureClassicController) acceptEvent(ts time.Time, event, code uin
 			return nil, nil
 		}
 
-		var key wiimote.Key
-		switch code {
-		case C.BTN_A:
-			key = wiimote.KeyA
-		case C.BTN_B:
-			key = wiimote.KeyB
-		case C.BTN_X:
-			key = wiimote.KeyX
-		case C.BTN_Y:
-			key = wiimote.KeyY
-		case C.KEY_NEXT:
-			key = wiimote.KeyPlus
-		case C.KEY_PREVIOUS:
-			key = wiimote.KeyMinus
-		case C.BTN_MODE:
-			key = wiimote.KeyHome
-		case C.KEY_LEFT:
-			key = wiimote.KeyLeft
-		case C.KEY_RIGHT:
-			key = wiimote.KeyRight
-		case C.KEY_UP:
-			key = wiimote.KeyUp
-		case C.KEY_DOWN:
-			key = wiimote.KeyDown
-		case C.BTN_TL:
-			key = wiimote.KeyTL
-		case C.BTN_TR:
-			key = wiimote.KeyTR
-		case C.BTN_TL2:
-			key = wiimote.KeyZL
-		case C.BTN_TR2:
-			key = wiimote.KeyZR
-		default:
+		key, ok := KeyFromCode(wiimote.FeatureClassicController, code)
+		if !ok {
 			return nil, nil
 		}
 
 		var ev wiimote.EventClassicControllerKey
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Code = key
 		ev.Pressed = value != 0
 		return &ev, nil
@@ -521,7 +476,7 @@ func (iface *featureClassicController) acceptEvent(ts time.Time, event, code uin
 		}
 	case C.EV_SYN:
 		var ev wiimote.EventClassicControllerMove
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.StickLeft = iface.stickLeft
 		ev.StickRight = iface.stickRight
 		ev.ShoulderLeft = iface.shoulderLeft
@@ -541,7 +496,7 @@ type featureBalanceBoard struct {
 func (iface *featureBalanceBoard) acceptEvent(ts time.Time, event, code uint16, value int32) (wiimote.Event, error) {
 	if event == C.EV_SYN {
 		var ev wiimote.EventBalanceBoard
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Weights = iface.weights
 		return &ev, nil
 	}
@@ -577,48 +532,13 @@ func (iface *featureProController) acceptEvent(ts time.Time, event, code uint16,
 			return nil, nil
 		}
 
-		var key wiimote.Key
-		switch code {
-		case C.BTN_EAST:
-			key = wiimote.KeyA
-		case C.BTN_SOUTH:
-			key = wiimote.KeyB
-		case C.BTN_NORTH:
-			key = wiimote.KeyX
-		case C.BTN_WEST:
-			key = wiimote.KeyY
-		case C.BTN_START:
-			key = wiimote.KeyPlus
-		case C.BTN_SELECT:
-			key = wiimote.KeyMinus
-		case C.BTN_MODE:
-			key = wiimote.KeyHome
-		case C.BTN_DPAD_LEFT:
-			key = wiimote.KeyLeft
-		case C.BTN_DPAD_RIGHT:
-			key = wiimote.KeyRight
-		case C.BTN_DPAD_UP:
-			key = wiimote.KeyUp
-		case C.BTN_DPAD_DOWN:
-			key = wiimote.KeyDown
-		case C.BTN_TL:
-			key = wiimote.KeyTL
-		case C.BTN_TR:
-			key = wiimote.KeyTR
-		case C.BTN_TL2:
-			key = wiimote.KeyZL
-		case C.BTN_TR2:
-			key = wiimote.KeyZR
-		case C.BTN_THUMBL:
-			key = wiimote.KeyThumbL
-		case C.BTN_THUMBR:
-			key = wiimote.KeyThumbR
-		default:
+		key, ok := KeyFromCode(wiimote.FeatureProController, code)
+		if !ok {
 			return nil, nil
 		}
 
 		var ev wiimote.EventProControllerKey
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Code = key
 		ev.Pressed = value != 0
 		return &ev, nil
@@ -635,7 +555,7 @@ func (iface *featureProController) acceptEvent(ts time.Time, event, code uint16,
 		}
 	case C.EV_SYN:
 		var ev wiimote.EventProControllerMove
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Sticks = iface.sticks
 		return &ev, nil
 	}
@@ -663,18 +583,13 @@ func (iface *featureDrums) acceptEvent(ts time.Time, event, code uint16, value i
 			return nil, nil
 		}
 
-		var key wiimote.Key
-		switch code {
-		case C.BTN_START:
-			key = wiimote.KeyPlus
-		case C.BTN_SELECT:
-			key = wiimote.KeyMinus
-		default:
+		key, ok := KeyFromCode(wiimote.FeatureDrums, code)
+		if !ok {
 			return nil, nil
 		}
 
 		var ev wiimote.EventDrumsKey
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Code = key
 		ev.Pressed = value != 0
 		return &ev, nil
@@ -701,7 +616,7 @@ func (iface *featureDrums) acceptEvent(ts time.Time, event, code uint16, value i
 		}
 	case C.EV_SYN:
 		var ev wiimote.EventDrumsMove
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Pad = iface.pad
 		ev.CymbalLeft = iface.cymbalLeft
 		ev.CymbalRight = iface.cymbalRight
@@ -731,32 +646,13 @@ func (iface *featureGuitar) acceptEvent(ts time.Time, event, code uint16, value
 			return nil, nil
 		}
 
-		var key wiimote.Key
-		switch code {
-		case C.BTN_FRET_FAR_UP:
-			key = wiimote.KeyFretFarUp
-		case C.BTN_FRET_UP:
-			key = wiimote.KeyFretUp
-		case C.BTN_FRET_MID:
-			key = wiimote.KeyFretMid
-		case C.BTN_FRET_LOW:
-			key = wiimote.KeyFretLow
-		case C.BTN_FRET_FAR_LOW:
-			key = wiimote.KeyFretFarLow
-		case C.BTN_STRUM_BAR_UP:
-			key = wiimote.KeyStrumBarUp
-		case C.BTN_STRUM_BAR_DOWN:
-			key = wiimote.KeyStrumBarDown
-		case C.BTN_START:
-			key = wiimote.KeyPlus
-		case C.BTN_MODE:
-			key = wiimote.KeyHome
-		default:
+		key, ok := KeyFromCode(wiimote.FeatureGuitar, code)
+		if !ok {
 			return nil, nil
 		}
 
 		var ev wiimote.EventGuitarKey
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Code = key
 		ev.Pressed = value != 0
 		return &ev, nil
@@ -773,7 +669,7 @@ func (iface *featureGuitar) acceptEvent(ts time.Time, event, code uint16, value
 		}
 	case C.EV_SYN:
 		var ev wiimote.EventGuitarMove
-		ev.Event = commonEvent{iface, ts}
+		ev.Event = commonEvent{iface: iface, timestamp: ts}
 		ev.Stick = iface.stick
 		ev.WhammyBar = iface.whammyBar
 		ev.FretBar = iface.fretBar
@@ -836,30 +732,61 @@ func featureFromName(kind wiimote.FeatureKind) feature {
 	return nil
 }
 
-func readEvent(fd common.UnbufferedFile) (*C.struct_input_event, error) {
-	var ev C.struct_input_event
-	buf := unsafe.Slice((*byte)(unsafe.Pointer(&ev)), unsafe.Sizeof(ev))
+// batchSize is the number of events read at once, a page of events.
+const batchSize = 4096 / int(unsafe.Sizeof(C.struct_input_event{}))
 
-	n, err := fd.Read(buf)
-	if err == syscall.EAGAIN {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	if n != int(unsafe.Sizeof(ev)) {
-		return nil, io.ErrShortBuffer
+// eventBatch buffers the events returned by a single read.
+type eventBatch struct {
+	events [batchSize]C.struct_input_event
+	// events[off:n] are not yet dispatched
+	off, n int
+}
+
+func (b *eventBatch) buffered() bool {
+	return b.off < b.n
+}
+
+func (b *eventBatch) reset() {
+	b.off, b.n = 0, 0
+}
+
+// next returns the next buffered event or reads a new batch from fd, it returns nil if no event
+// is available.
+func (b *eventBatch) next(fd common.UnbufferedFile, stats *wiimote.ReadStats) (*C.struct_input_event, error) {
+	if !b.buffered() {
+		buf := unsafe.Slice((*byte)(unsafe.Pointer(&b.events[0])), unsafe.Sizeof(b.events))
+		n, err := fd.Read(buf)
+		if err == syscall.EAGAIN {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		stats.Reads++
+		if n%int(unsafe.Sizeof(b.events[0])) != 0 {
+			return nil, io.ErrShortBuffer
+		}
+		b.off, b.n = 0, n/int(unsafe.Sizeof(b.events[0]))
+		stats.Events += uint64(b.n)
+		if b.n == 0 {
+			return nil, nil
+		}
 	}
-	return &ev, nil
+	ev := &b.events[b.off]
+	b.off++
+	return ev, nil
 }
 
-func dispatchEvent(iff feature) (wiimote.Event, error) {
+func dispatchEvent(dev *device, iff feature) (wiimote.Event, error) {
 	for {
-		input, err := readEvent(iff.fd())
+		input, err := iff.batch().next(iff.fd(), &dev.stats)
 		if err != nil {
-			iff.Close()
+			dev.closeLost(iff)
 			return &wiimote.EventWatch{
-				Event: commonEvent{iff, time.Now()},
+				Event:         commonEvent{iface: iff, timestamp: time.Now()},
+				Closed:        iff.Kind(),
+				PrevExtension: dev.extension,
+				Extension:     dev.extension,
 			}, nil
 		}
 		if input == nil {
@@ -870,6 +797,16 @@ func dispatchEvent(iff feature) (wiimote.Event, error) {
 		code := uint16(input.code)
 		value := int32(input.value)
 
+		if tap, ok := dev.raw[iff.Kind()]; ok {
+			select {
+			case tap.ch <- wiimote.RawEvent{Feature: iff.Kind(), Type: eventType, Code: code, Value: value, Time: ts}:
+			default:
+			}
+			if !tap.cooked {
+				continue
+			}
+		}
+
 		event, err := iff.acceptEvent(ts, eventType, code, value)
 		if event != nil || err != nil {
 			return event, err
@@ -0,0 +1,106 @@
+package linuxkernel
+
+// #include "input-defs.h"
+import "C"
+import (
+	"github.com/friedelschoen/go-wiimote"
+)
+
+type keyCode struct {
+	code uint16
+	key  wiimote.Key
+}
+
+// keyCodes holds the evdev key-codes which are reported by the kernel driver per feature.
+var keyCodes = map[wiimote.FeatureKind][]keyCode{
+	wiimote.FeatureCore: {
+		{C.KEY_LEFT, wiimote.KeyLeft},
+		{C.KEY_RIGHT, wiimote.KeyRight},
+		{C.KEY_UP, wiimote.KeyUp},
+		{C.KEY_DOWN, wiimote.KeyDown},
+		{C.KEY_NEXT, wiimote.KeyPlus},
+		{C.KEY_PREVIOUS, wiimote.KeyMinus},
+		{C.BTN_1, wiimote.KeyOne},
+		{C.BTN_2, wiimote.KeyTwo},
+		{C.BTN_A, wiimote.KeyA},
+		{C.BTN_B, wiimote.KeyB},
+		{C.BTN_MODE, wiimote.KeyHome},
+	},
+	wiimote.FeatureNunchuck: {
+		{C.BTN_C, wiimote.KeyC},
+		{C.BTN_Z, wiimote.KeyZ},
+	},
+	wiimote.FeatureClassicController: {
+		{C.BTN_A, wiimote.KeyA},
+		{C.BTN_B, wiimote.KeyB},
+		{C.BTN_X, wiimote.KeyX},
+		{C.BTN_Y, wiimote.KeyY},
+		{C.KEY_NEXT, wiimote.KeyPlus},
+		{C.KEY_PREVIOUS, wiimote.KeyMinus},
+		{C.BTN_MODE, wiimote.KeyHome},
+		{C.KEY_LEFT, wiimote.KeyLeft},
+		{C.KEY_RIGHT, wiimote.KeyRight},
+		{C.KEY_UP, wiimote.KeyUp},
+		{C.KEY_DOWN, wiimote.KeyDown},
+		{C.BTN_TL, wiimote.KeyTL},
+		{C.BTN_TR, wiimote.KeyTR},
+		{C.BTN_TL2, wiimote.KeyZL},
+		{C.BTN_TR2, wiimote.KeyZR},
+	},
+	wiimote.FeatureProController: {
+		{C.BTN_EAST, wiimote.KeyA},
+		{C.BTN_SOUTH, wiimote.KeyB},
+		{C.BTN_NORTH, wiimote.KeyX},
+		{C.BTN_WEST, wiimote.KeyY},
+		{C.BTN_START, wiimote.KeyPlus},
+		{C.BTN_SELECT, wiimote.KeyMinus},
+		{C.BTN_MODE, wiimote.KeyHome},
+		{C.BTN_DPAD_LEFT, wiimote.KeyLeft},
+		{C.BTN_DPAD_RIGHT, wiimote.KeyRight},
+		{C.BTN_DPAD_UP, wiimote.KeyUp},
+		{C.BTN_DPAD_DOWN, wiimote.KeyDown},
+		{C.BTN_TL, wiimote.KeyTL},
+		{C.BTN_TR, wiimote.KeyTR},
+		{C.BTN_TL2, wiimote.KeyZL},
+		{C.BTN_TR2, wiimote.KeyZR},
+		{C.BTN_THUMBL, wiimote.KeyThumbL},
+		{C.BTN_THUMBR, wiimote.KeyThumbR},
+	},
+	wiimote.FeatureDrums: {
+		{C.BTN_START, wiimote.KeyPlus},
+		{C.BTN_SELECT, wiimote.KeyMinus},
+	},
+	wiimote.FeatureGuitar: {
+		{C.BTN_FRET_FAR_UP, wiimote.KeyFretFarUp},
+		{C.BTN_FRET_UP, wiimote.KeyFretUp},
+		{C.BTN_FRET_MID, wiimote.KeyFretMid},
+		{C.BTN_FRET_LOW, wiimote.KeyFretLow},
+		{C.BTN_FRET_FAR_LOW, wiimote.KeyFretFarLow},
+		{C.BTN_STRUM_BAR_UP, wiimote.KeyStrumBarUp},
+		{C.BTN_STRUM_BAR_DOWN, wiimote.KeyStrumBarDown},
+		{C.BTN_START, wiimote.KeyPlus},
+		{C.BTN_MODE, wiimote.KeyHome},
+	},
+}
+
+// KeyFromCode returns the key which is reported by the feature kind for the evdev key-code code.
+// It returns false if kind does not report code.
+func KeyFromCode(kind wiimote.FeatureKind, code uint16) (wiimote.Key, bool) {
+	for _, kc := range keyCodes[kind] {
+		if kc.code == code {
+			return kc.key, true
+		}
+	}
+	return 0, false
+}
+
+// CodeFromKey returns the evdev key-code which the feature kind uses to report key.
+// It returns false if kind does not report key.
+func CodeFromKey(kind wiimote.FeatureKind, key wiimote.Key) (uint16, bool) {
+	for _, kc := range keyCodes[kind] {
+		if kc.key == key {
+			return kc.code, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,43 @@
+package linuxkernel
+
+import (
+	"testing"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+func TestKeyCodeRoundtrip(t *testing.T) {
+	for kind, codes := range keyCodes {
+		for _, kc := range codes {
+			key, ok := KeyFromCode(kind, kc.code)
+			if !ok || key != kc.key {
+				t.Errorf("%v: KeyFromCode(%#x) = %v, %v; expected %v", kind, kc.code, key, ok, kc.key)
+			}
+			code, ok := CodeFromKey(kind, kc.key)
+			if !ok || code != kc.code {
+				t.Errorf("%v: CodeFromKey(%v) = %#x, %v; expected %#x", kind, kc.key, code, ok, kc.code)
+			}
+		}
+	}
+}
+
+func TestKeyCodeUnknown(t *testing.T) {
+	if _, ok := CodeFromKey(wiimote.FeatureNunchuck, wiimote.KeyA); ok {
+		t.Errorf("nunchuk should not report KeyA")
+	}
+	if _, ok := KeyFromCode(wiimote.FeatureAccel, 0); ok {
+		t.Errorf("accelerometer should not report keys")
+	}
+}
+
+func TestKeyFeatures(t *testing.T) {
+	// the features of wiimote.KeyFeatures match the codes of the driver
+	for _, info := range wiimote.AllWiiKeys() {
+		for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+			_, ok := CodeFromKey(kind, info.Key)
+			if ok != (info.Features&kind != 0) {
+				t.Errorf("%v: reported by %v is %v, expected %v", info.Name, kind, ok, info.Features&kind != 0)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package linuxkernel
+
+import (
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-uinput"
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// The uinput tests create a virtual "Nintendo Wii Remote" input device and run the open and
+// dispatch path of the driver against it. They need write access to /dev/uinput and only run
+// if WIIMOTE_UINPUT_TEST is set.
+
+// inputDevice is a child device of a remote, as enumerated by readNodes.
+type inputDevice struct {
+	fakeDevice
+	sysname, devnode, name string
+}
+
+func (d inputDevice) Sysname() string { return d.sysname }
+func (d inputDevice) Devnode() string { return d.devnode }
+func (d inputDevice) SysattrValue(sysattr string) string {
+	if sysattr == "name" {
+		return d.name
+	}
+	return ""
+}
+
+// listEnumerator enumerates devs.
+type listEnumerator struct {
+	fakeEnumerator
+	devs []wiimote.DeviceInfo
+}
+
+func (e listEnumerator) Devices() (iter.Seq[wiimote.DeviceInfo], error) {
+	return slices.Values(e.devs), nil
+}
+
+// newUinputRemote creates a virtual input device named like the core feature and returns it
+// with a device of the driver using it as core feature.
+func newUinputRemote(t *testing.T) (*uinput.Keyboard, *device) {
+	if os.Getenv("WIIMOTE_UINPUT_TEST") == "" {
+		t.Skip("set WIIMOTE_UINPUT_TEST to run tests using /dev/uinput")
+	}
+	kbd, err := uinput.CreateKeyboard("Nintendo Wii Remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { kbd.Close() })
+
+	syspath, err := kbd.Syspath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the event node is created asynchronously by the kernel and udev
+	var event string
+	for range 100 {
+		matches, _ := filepath.Glob(filepath.Join(syspath, "event*"))
+		if len(matches) > 0 {
+			event = filepath.Base(matches[0])
+			if _, err := os.Stat("/dev/input/" + event); err == nil {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if event == "" {
+		t.Fatalf("no event node of %s", syspath)
+	}
+
+	children := []wiimote.DeviceInfo{
+		inputDevice{fakeDevice: fakeDevice{subsystem: "input", syspath: syspath}, sysname: filepath.Base(syspath), name: "Nintendo Wii Remote"},
+		inputDevice{fakeDevice: fakeDevice{subsystem: "input", syspath: filepath.Join(syspath, event)}, sysname: event, devnode: "/dev/input/" + event},
+	}
+	hid := fakeDevice{subsystem: "hid", driver: "wiimote", syspath: t.TempDir()}
+	mon := newFakeMonitor(t)
+	dev, err := NewDevice(hid,
+		func() wiimote.DeviceMonitor { return mon },
+		func() wiimote.DeviceEnumerator { return listEnumerator{devs: children} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kbd, dev
+}
+
+// nextKey waits for the next key event of dev, other events are skipped.
+func nextKey(t *testing.T, dev *device) *wiimote.EventKey {
+	for {
+		ev, err := dev.Wait(time.Second)
+		if err != nil {
+			t.Fatalf("unable to wait for key event: %v", err)
+		}
+		if ev, ok := ev.(*wiimote.EventKey); ok {
+			return ev
+		}
+	}
+}
+
+func TestUinputCoreKeys(t *testing.T) {
+	kbd, dev := newUinputRemote(t)
+	if !dev.Available(wiimote.FeatureCore) {
+		t.Fatalf("expected core feature to be available")
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []wiimote.Key{wiimote.KeyA, wiimote.KeyB, wiimote.KeyHome, wiimote.KeyPlus, wiimote.KeyLeft} {
+		code, ok := CodeFromKey(wiimote.FeatureCore, key)
+		if !ok {
+			t.Fatalf("no code for %v", key)
+		}
+		for _, pressed := range []bool{true, false} {
+			if err := kbd.Key(uinput.Key(code), pressed); err != nil {
+				t.Fatal(err)
+			}
+			ev := nextKey(t, dev)
+			if ev.Code != key || ev.Pressed != pressed {
+				t.Errorf("injected %v pressed=%v, got %v pressed=%v", key, pressed, ev.Code, ev.Pressed)
+			}
+			if f := ev.Feature(); f == nil || f.Kind() != wiimote.FeatureCore {
+				t.Errorf("expected event of the core feature, got %v", f)
+			}
+		}
+	}
+}
+
+func TestUinputUnmappedKey(t *testing.T) {
+	kbd, dev := newUinputRemote(t)
+	if err := dev.OpenFeatures(wiimote.FeatureCore, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// keys not emitted by a remote are dropped
+	codeA, _ := CodeFromKey(wiimote.FeatureCore, wiimote.KeyA)
+	if err := kbd.Key(uinput.KeyEnter, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbd.Key(uinput.Key(codeA), true); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextKey(t, dev); ev.Code != wiimote.KeyA {
+		t.Errorf("expected only A, got %v", ev.Code)
+	}
+}
+
+func TestUinputClose(t *testing.T) {
+	kbd, dev := newUinputRemote(t)
+	if err := dev.OpenFeatures(wiimote.FeatureCore, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Feature(wiimote.FeatureCore).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if dev.Feature(wiimote.FeatureCore) != nil {
+		t.Fatalf("expected core feature to be closed")
+	}
+
+	codeA, _ := CodeFromKey(wiimote.FeatureCore, wiimote.KeyA)
+	kbd.Key(uinput.Key(codeA), true)
+	for {
+		ev, err := dev.Wait(100 * time.Millisecond)
+		if err != nil {
+			break
+		}
+		if _, ok := ev.(*wiimote.EventKey); ok {
+			t.Fatalf("expected no key events of a closed feature")
+		}
+	}
+}
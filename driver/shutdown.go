@@ -0,0 +1,37 @@
+package driver
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/friedelschoen/go-wiimote/internal/common"
+)
+
+// CleanupAll calls Cleanup on all devices which were not cleaned up yet.
+func CleanupAll() error {
+	return common.CleanupAll()
+}
+
+// Shutdown cleans up all devices. It is meant to be deferred in main, if main panics
+// the devices are cleaned up before the panic continues.
+//
+//	defer driver.Shutdown()
+func Shutdown() {
+	if r := recover(); r != nil {
+		common.CleanupAll()
+		panic(r)
+	}
+	common.CleanupAll()
+}
+
+// CleanupOnSignal cleans up all devices and exits the process when SIGINT or SIGTERM is received.
+func CleanupOnSignal() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ch
+		common.CleanupAll()
+		os.Exit(1)
+	}()
+}
@@ -0,0 +1,112 @@
+package sim
+
+import (
+	"math"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Wave describes a sinusoidal signal. The value at time t is
+// Offset + Amplitude * sin(2π t / Period), a zero Period results in a constant Offset.
+type Wave struct {
+	Offset    wiimote.Vec3
+	Amplitude wiimote.Vec3
+	Period    time.Duration
+}
+
+// At returns the value of the wave at t.
+func (w Wave) At(t time.Duration) wiimote.Vec3 {
+	if w.Period <= 0 {
+		return w.Offset
+	}
+	s := math.Sin(2 * math.Pi * float64(t) / float64(w.Period))
+	return wiimote.Vec3{
+		X: w.Offset.X + int32(math.Round(float64(w.Amplitude.X)*s)),
+		Y: w.Offset.Y + int32(math.Round(float64(w.Amplitude.Y)*s)),
+		Z: w.Offset.Z + int32(math.Round(float64(w.Amplitude.Z)*s)),
+	}
+}
+
+// KeyPress describes a scripted key press.
+type KeyPress struct {
+	Key wiimote.Key
+	// At is the time after creation of the device when the key is pressed
+	At time.Duration
+	// Duration is the time the key is held
+	Duration time.Duration
+}
+
+// Path describes the movement of an IR dot. The dot moves linearly from point to point
+// and returns to the first point after Period. A path with a single point is static.
+type Path struct {
+	Points []wiimote.Vec2
+	Period time.Duration
+}
+
+// At returns the position of the dot at t, ok is false if the path is empty.
+func (p Path) At(t time.Duration) (pos wiimote.Vec2, ok bool) {
+	switch {
+	case len(p.Points) == 0:
+		return wiimote.Vec2{}, false
+	case len(p.Points) == 1 || p.Period <= 0:
+		return p.Points[0], true
+	}
+
+	// position on the closed path in segments
+	at := float64(t%p.Period) / float64(p.Period) * float64(len(p.Points))
+	seg := int(at)
+	frac := at - float64(seg)
+	from := p.Points[seg%len(p.Points)]
+	to := p.Points[(seg+1)%len(p.Points)]
+	return wiimote.Vec2{
+		X: from.X + int32(math.Round(float64(to.X-from.X)*frac)),
+		Y: from.Y + int32(math.Round(float64(to.Y-from.Y)*frac)),
+	}, true
+}
+
+// Config describes the synthetic data produced by a simulated device.
+type Config struct {
+	// Rate is the number of reports per second
+	Rate int
+	// Accel is the accelerometer signal
+	Accel Wave
+	// Keys are the scripted key presses
+	Keys []KeyPress
+	// Repeat restarts the key script after this duration, if 0 the script is run once
+	Repeat time.Duration
+	// Dots are the paths of up to four IR dots
+	Dots []Path
+	// Scenario replaces Accel and Dots if set
+	Scenario *Scenario
+
+	DevType   string
+	Extension string
+	UniqueID  string
+	Battery   uint
+}
+
+// DefaultConfig returns a configuration of a remote at rest which slowly tilts, presses A every
+// two seconds and points at a sensor bar moving in a diamond shape.
+func DefaultConfig() Config {
+	return Config{
+		Rate: 100,
+		Accel: Wave{
+			Offset:    wiimote.Vec3{X: 0, Y: 0, Z: 100},
+			Amplitude: wiimote.Vec3{X: 20, Y: 20, Z: 0},
+			Period:    4 * time.Second,
+		},
+		Keys: []KeyPress{
+			{Key: wiimote.KeyA, At: time.Second, Duration: 200 * time.Millisecond},
+		},
+		Repeat: 2 * time.Second,
+		Dots: []Path{
+			{Points: []wiimote.Vec2{{X: 312, Y: 384}, {X: 412, Y: 284}, {X: 512, Y: 384}, {X: 412, Y: 484}}, Period: 8 * time.Second},
+			{Points: []wiimote.Vec2{{X: 512, Y: 384}, {X: 612, Y: 284}, {X: 712, Y: 384}, {X: 612, Y: 484}}, Period: 8 * time.Second},
+		},
+		DevType:   "gen10",
+		Extension: "none",
+		UniqueID:  "00:00:00:00:00:00",
+		Battery:   100,
+	}
+}
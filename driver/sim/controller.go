@@ -0,0 +1,56 @@
+package sim
+
+import "github.com/friedelschoen/go-wiimote"
+
+// Controller drives a simulated device programmatically, e.g. from a test or a GUI under
+// development. Changes are reported with the next sample of the device, see Config.Rate.
+// Keys pressed by a Controller are reported in addition to the scripted keys.
+//
+// A Controller is safe to use while another goroutine polls the device.
+type Controller struct {
+	d *device
+}
+
+// PressKey presses key until it is released with ReleaseKey.
+func (c *Controller) PressKey(key wiimote.Key) {
+	c.d.ctl.Lock()
+	c.d.ctl.keys[key] = true
+	c.d.ctl.Unlock()
+}
+
+// ReleaseKey releases a key pressed with PressKey.
+func (c *Controller) ReleaseKey(key wiimote.Key) {
+	c.d.ctl.Lock()
+	delete(c.d.ctl.keys, key)
+	c.d.ctl.Unlock()
+}
+
+// SetAccel reports accel as accelerometer data instead of the configured signal.
+func (c *Controller) SetAccel(accel wiimote.Vec3) {
+	c.d.ctl.Lock()
+	c.d.ctl.accel = &accel
+	c.d.ctl.Unlock()
+}
+
+// SetDots reports up to four visible IR dots instead of the configured paths, the other slots
+// are invisible. Calling SetDots without dots hides all dots.
+func (c *Controller) SetDots(dots ...wiimote.Vec2) {
+	var slots [4]wiimote.IRSlot
+	for i := range slots {
+		slots[i].Vec2 = invalidDot
+		if i < len(dots) {
+			slots[i].Vec2 = dots[i]
+		}
+	}
+	c.d.ctl.Lock()
+	c.d.ctl.slots = &slots
+	c.d.ctl.Unlock()
+}
+
+// Reset releases all keys pressed by the Controller and restores the configured data.
+func (c *Controller) Reset() {
+	c.d.ctl.Lock()
+	clear(c.d.ctl.keys)
+	c.d.ctl.accel, c.d.ctl.slots = nil, nil
+	c.d.ctl.Unlock()
+}
@@ -0,0 +1,447 @@
+// Package sim implements a simulated device which produces synthetic data. It can be
+// used to develop and demonstrate applications without a Bluetooth adapter or remote. The data
+// is described by a Config or a Scenario file, NewSimulatedDevice additionally returns a
+// Controller to press keys and move the remote programmatically.
+package sim
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+	"golang.org/x/sys/unix"
+)
+
+type commonEvent struct {
+	dev       *device
+	iface     wiimote.Feature
+	timestamp time.Time
+}
+
+func (e commonEvent) Feature() wiimote.Feature { return e.iface }
+func (e commonEvent) Device() wiimote.Device   { return e.dev }
+func (e commonEvent) Timestamp() time.Time     { return e.timestamp }
+
+type feature struct {
+	kind wiimote.FeatureKind
+	dev  *device
+}
+
+func (f feature) Kind() wiimote.FeatureKind { return f.kind }
+func (f feature) Device() wiimote.Device    { return f.dev }
+func (f feature) Opened() bool              { return f.dev.openIfs&f.kind != 0 }
+func (f feature) Close() error {
+	f.dev.openIfs &^= f.kind
+	return nil
+}
+
+type coreFeature struct {
+	feature
+}
+
+func (f coreFeature) Rumble(state bool) error {
+	f.dev.rumble = state
+	return nil
+}
+
+type device struct {
+	wiimote.Poller[wiimote.Event]
+	common.Cleanups
+
+	cfg   Config
+	start time.Time
+	// timer file descriptor, expires Rate times per second
+	tfd int
+
+	errs    common.ErrorHandler
+	openIfs wiimote.FeatureKind
+	led     wiimote.Led
+	player  int
+	rumble  bool
+	irfull  bool
+	// features not reported and features closed by Pause
+	muted, paused wiimote.FeatureKind
+	isPaused      bool
+
+	// reported pressed state of keys
+	keys map[wiimote.Key]bool
+	// state set by a Controller
+	ctl struct {
+		sync.Mutex
+		keys  map[wiimote.Key]bool
+		accel *wiimote.Vec3
+		slots *[4]wiimote.IRSlot
+	}
+
+	moreEvents chan wiimote.Event
+	stats      wiimote.ReadStats
+	state      common.StateBuffer
+	limit      common.RateLimit
+}
+
+// NewDevice creates a simulated device which produces the data described by cfg. The core
+// features (core, accelerometer and IR) are available. The device starts producing data as
+// soon as it is created.
+func NewDevice(cfg Config) (wiimote.Device, error) {
+	d, err := newDevice(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewSimulatedDevice is like NewDevice and returns a Controller to drive the device in addition
+// to the data described by cfg.
+func NewSimulatedDevice(cfg Config) (wiimote.Device, *Controller, error) {
+	d, err := newDevice(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, &Controller{d}, nil
+}
+
+func newDevice(cfg Config) (*device, error) {
+	if cfg.Rate <= 0 {
+		return nil, os.ErrInvalid
+	}
+
+	d := &device{
+		cfg:        cfg,
+		start:      time.Now(),
+		keys:       make(map[wiimote.Key]bool),
+		moreEvents: make(chan wiimote.Event, 64),
+	}
+	d.ctl.keys = make(map[wiimote.Key]bool)
+	d.Poller = common.NewPoller(d)
+
+	var err error
+	d.tfd, err = unix.TimerfdCreate(unix.CLOCK_MONOTONIC, unix.TFD_NONBLOCK|unix.TFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.arm(true); err != nil {
+		unix.Close(d.tfd)
+		return nil, err
+	}
+
+	runtime.AddCleanup(d, func(fd int) { unix.Close(fd) }, d.tfd)
+	d.OnCleanup(common.RestoreDevice(d))
+	return d, nil
+}
+
+func (d *device) Capabilities() wiimote.Capabilities {
+	return common.Capabilities(d)
+}
+
+func (d *device) SetErrorPolicy(policy wiimote.ErrorPolicy) {
+	d.errs.Policy = policy
+}
+
+func (d *device) AutoReopen(enable bool) {
+	_ = enable // features are never removed
+}
+
+func (d *device) SetOpenPolicy(policy wiimote.OpenPolicy) {
+	// features are never removed, so only the available features are opened
+	var kinds wiimote.FeatureKind
+	for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+		if policy.Kinds&kind != 0 && d.Available(kind) && d.Feature(kind) == nil {
+			kinds |= kind
+		}
+	}
+	if kinds != 0 {
+		d.OpenFeatures(kinds, policy.Writable)
+	}
+}
+
+// arm starts or stops the timer producing the data.
+func (d *device) arm(enable bool) error {
+	var spec unix.ItimerSpec
+	if enable {
+		interval := unix.NsecToTimespec(int64(time.Second) / int64(d.cfg.Rate))
+		spec = unix.ItimerSpec{Interval: interval, Value: interval}
+	}
+	return unix.TimerfdSettime(d.tfd, 0, &spec, nil)
+}
+
+func (d *device) FD() int { return d.tfd }
+
+func (d *device) Poll() (wiimote.Event, bool, error) {
+	// events of paused features are dropped, coalesced samples are kept back and the state is
+	// updated with every sample
+	return d.limit.Poll(d.poll, d.state.Update, func(ev wiimote.Event) bool { return isMuted(ev, d.muted) })
+}
+
+func isMuted(ev wiimote.Event, muted wiimote.FeatureKind) bool {
+	f := ev.Feature()
+	return f != nil && f.Kind()&muted != 0
+}
+
+// State returns the latest values of all opened features.
+func (d *device) State() wiimote.State {
+	return d.state.State()
+}
+
+func (d *device) poll() (wiimote.Event, bool, error) {
+	select {
+	case ev := <-d.moreEvents:
+		return ev, len(d.moreEvents) > 0, nil
+	default:
+	}
+
+	var buf [8]byte
+	if _, err := unix.Read(d.tfd, buf[:]); err != nil {
+		if errors.Is(err, unix.EAGAIN) {
+			return nil, false, common.ErrWouldBlock
+		}
+		return d.errs.Handle(err, commonEvent{dev: d, timestamp: time.Now()}, func() {
+			d.openIfs = 0
+		})
+	}
+	d.stats.Reads++
+	pending := len(d.moreEvents)
+	d.step(time.Now())
+	d.stats.Events += uint64(len(d.moreEvents) - pending)
+
+	select {
+	case ev := <-d.moreEvents:
+		return ev, len(d.moreEvents) > 0, nil
+	default:
+		return nil, false, common.ErrWouldBlock
+	}
+}
+
+// step generates the events of all opened features at now.
+func (d *device) step(now time.Time) {
+	t := now.Sub(d.start)
+
+	var smp Sample
+	if d.cfg.Scenario != nil {
+		smp = d.cfg.Scenario.At(t)
+	} else {
+		smp.Accel = d.cfg.Accel.At(t)
+		for i := range smp.Slots {
+			smp.Slots[i].Vec2 = invalidDot
+			if i >= len(d.cfg.Dots) {
+				continue
+			}
+			if pos, ok := d.cfg.Dots[i].At(t); ok {
+				smp.Slots[i].Vec2 = pos
+			}
+		}
+	}
+	d.ctl.Lock()
+	if d.ctl.accel != nil {
+		smp.Accel = *d.ctl.accel
+	}
+	if d.ctl.slots != nil {
+		smp.Slots = *d.ctl.slots
+	}
+	d.ctl.Unlock()
+
+	if d.openIfs&wiimote.FeatureCore != 0 {
+		d.emitKeys(now, t)
+	}
+	if d.openIfs&wiimote.FeatureAccel != 0 {
+		d.moreEvents <- &wiimote.EventAccel{
+			Event: commonEvent{dev: d, iface: feature{wiimote.FeatureAccel, d}, timestamp: now},
+			Accel: smp.Accel,
+		}
+	}
+	if d.openIfs&wiimote.FeatureIR != 0 {
+		d.moreEvents <- &wiimote.EventIR{
+			Event: commonEvent{dev: d, iface: feature{wiimote.FeatureIR, d}, timestamp: now},
+			Slots: smp.Slots,
+		}
+	}
+}
+
+func (d *device) emitKeys(now time.Time, t time.Duration) {
+	pressed := make(map[wiimote.Key]bool)
+	scripted(pressed, d.cfg.Keys, t, d.cfg.Repeat)
+	if d.cfg.Scenario != nil {
+		scripted(pressed, d.cfg.Scenario.Keys, t, d.cfg.Scenario.Loop)
+	}
+	d.ctl.Lock()
+	maps.Copy(pressed, d.ctl.keys)
+	d.ctl.Unlock()
+
+	// keys are reported in a stable order
+	keys := slices.Collect(maps.Keys(pressed))
+	for key := range d.keys {
+		if !pressed[key] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if d.keys[key] == pressed[key] {
+			continue
+		}
+		if pressed[key] {
+			d.keys[key] = true
+		} else {
+			delete(d.keys, key)
+		}
+		d.moreEvents <- &wiimote.EventKey{
+			Event:   commonEvent{dev: d, iface: coreFeature{feature{wiimote.FeatureCore, d}}, timestamp: now},
+			Code:    key,
+			Pressed: pressed[key],
+		}
+	}
+}
+
+// scripted marks the keys of script pressed at t, the script restarts after repeat if set.
+func scripted(pressed map[wiimote.Key]bool, script []KeyPress, t, repeat time.Duration) {
+	if repeat > 0 {
+		t %= repeat
+	}
+	for _, kp := range script {
+		if t >= kp.At && t < kp.At+kp.Duration {
+			pressed[kp.Key] = true
+		}
+	}
+}
+
+func (d *device) String() string {
+	return "wiimote-device (simulated)"
+}
+
+func (d *device) Syspath() string { return "" }
+
+func (d *device) OpenFeatures(ifaces wiimote.FeatureKind, wr bool) error {
+	_ = wr // all features are writeable
+
+	d.openIfs |= ifaces & wiimote.FeatureSetCore
+	if d.isPaused && d.muted&ifaces != 0 {
+		d.muted &^= ifaces
+		return d.arm(true)
+	}
+	d.muted &^= ifaces
+	return nil
+}
+
+func (d *device) RawEvents(kind wiimote.FeatureKind, cooked bool) (<-chan wiimote.RawEvent, error) {
+	return nil, wiimote.ErrUnsupported
+}
+
+func (d *device) StopRawEvents(kind wiimote.FeatureKind) {}
+
+func (d *device) SetMaxRate(kind wiimote.FeatureKind, hz float64) {
+	d.limit.SetMaxRate(kind, hz)
+}
+
+func (d *device) Pause(close wiimote.FeatureKind) error {
+	if d.isPaused {
+		return nil
+	}
+	d.paused = d.openIfs & close
+	d.openIfs &^= d.paused
+	d.muted = d.openIfs
+	d.isPaused = true
+	return d.arm(false)
+}
+
+func (d *device) Resume() error {
+	if !d.isPaused {
+		return nil
+	}
+	d.openIfs |= d.paused
+	d.paused, d.muted = 0, 0
+	d.isPaused = false
+	return d.arm(true)
+}
+
+func (d *device) Feature(kind wiimote.FeatureKind) wiimote.Feature {
+	if d.openIfs&kind == 0 {
+		return nil
+	}
+	if kind == wiimote.FeatureCore {
+		return coreFeature{feature{kind, d}}
+	}
+	return feature{kind, d}
+}
+
+func (d *device) Available(iface wiimote.FeatureKind) bool {
+	return iface&wiimote.FeatureSetCore != 0
+}
+
+func (d *device) IRFull() bool { return d.irfull }
+
+func (d *device) SetIRFull(fullreport bool) { d.irfull = fullreport }
+
+func (d *device) ReadStats() wiimote.ReadStats { return d.stats }
+
+func (d *device) LED() (wiimote.Led, error) {
+	return d.led, nil
+}
+
+func (d *device) SetLED(leds wiimote.Led) error {
+	d.led = leds
+	return nil
+}
+
+func (d *device) LEDs() wiimote.Led {
+	return wiimote.Led1 | wiimote.Led2 | wiimote.Led3 | wiimote.Led4
+}
+
+func (d *device) LEDState(index int) (bool, error) {
+	return common.LEDState(d, index)
+}
+
+func (d *device) SetLEDState(index int, on bool) error {
+	return common.SetLEDState(d, index, on)
+}
+
+func (d *device) SetPlayerLED(n int) error {
+	leds, ok := wiimote.PlayerLED(n)
+	if !ok {
+		return os.ErrInvalid
+	}
+	d.led = leds
+	d.player = n
+	return nil
+}
+
+func (d *device) Player() int {
+	return d.player
+}
+
+func (d *device) Battery() (uint, error) {
+	return d.cfg.Battery, nil
+}
+
+func (d *device) DevType() (string, error) {
+	return d.cfg.DevType, nil
+}
+
+func (d *device) Type() (wiimote.DevType, error) {
+	devtype, err := d.DevType()
+	return wiimote.ParseDevType(devtype), err
+}
+
+func (d *device) Extension() (string, error) {
+	return d.cfg.Extension, nil
+}
+
+func (d *device) UniqueID() (string, error) {
+	if d.cfg.UniqueID == "" {
+		return "", os.ErrNotExist
+	}
+	return d.cfg.UniqueID, nil
+}
+
+// Disconnect closes all features and reports EventGone, which is delivered on the next tick
+// of the timer.
+func (d *device) Disconnect() error {
+	d.openIfs, d.muted, d.paused = 0, 0, 0
+	d.isPaused = false
+	d.moreEvents <- &wiimote.EventGone{Event: commonEvent{dev: d, timestamp: time.Now()}}
+	return d.arm(true)
+}
@@ -0,0 +1,203 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+func TestPathAt(t *testing.T) {
+	p := Path{
+		Points: []wiimote.Vec2{{X: 0, Y: 0}, {X: 100, Y: 0}},
+		Period: 2 * time.Second,
+	}
+	tests := []struct {
+		at     time.Duration
+		expect wiimote.Vec2
+	}{
+		{0, wiimote.Vec2{X: 0, Y: 0}},
+		{500 * time.Millisecond, wiimote.Vec2{X: 50, Y: 0}},
+		{time.Second, wiimote.Vec2{X: 100, Y: 0}},
+		{1500 * time.Millisecond, wiimote.Vec2{X: 50, Y: 0}},
+		{2 * time.Second, wiimote.Vec2{X: 0, Y: 0}},
+	}
+	for _, tc := range tests {
+		got, ok := p.At(tc.at)
+		if !ok || got != tc.expect {
+			t.Errorf("at %v: expected %v, got %v (ok=%v)", tc.at, tc.expect, got, ok)
+		}
+	}
+
+	if _, ok := (Path{}).At(0); ok {
+		t.Errorf("expected empty path to be invalid")
+	}
+}
+
+func TestDeviceEvents(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Keys = []KeyPress{{Key: wiimote.KeyB, At: 0, Duration: time.Hour}}
+
+	dev, err := NewDevice(cfg)
+	if err != nil {
+		t.Fatalf("unable to create device: %v", err)
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore|wiimote.FeatureAccel|wiimote.FeatureIR, true); err != nil {
+		t.Fatalf("unable to open features: %v", err)
+	}
+
+	var gotKey, gotAccel, gotIR bool
+	for range 16 {
+		ev, err := dev.Wait(time.Second)
+		if err != nil {
+			t.Fatalf("unable to wait for event: %v", err)
+		}
+		switch ev := ev.(type) {
+		case *wiimote.EventKey:
+			gotKey = ev.Code == wiimote.KeyB && ev.Pressed
+		case *wiimote.EventAccel:
+			gotAccel = true
+		case *wiimote.EventIR:
+			gotIR = ev.Slots[0].Valid() && ev.Slots[1].Valid() && !ev.Slots[2].Valid()
+		}
+	}
+	if !gotKey || !gotAccel || !gotIR {
+		t.Errorf("expected key, accel and ir events, got key=%v accel=%v ir=%v", gotKey, gotAccel, gotIR)
+	}
+	if state := dev.State(); !state.Keys.Has(wiimote.KeyB) || !state.IR[0].Valid() {
+		t.Errorf("expected B held and IR in state, got %+v", state)
+	}
+}
+
+func TestOpenPolicy(t *testing.T) {
+	dev, err := NewDevice(DefaultConfig())
+	if err != nil {
+		t.Fatalf("unable to create device: %v", err)
+	}
+	dev.SetOpenPolicy(wiimote.PolicyList(wiimote.FeatureAccel | wiimote.FeatureNunchuck))
+	if dev.Feature(wiimote.FeatureAccel) == nil {
+		t.Errorf("expected accelerometer to be opened")
+	}
+	if dev.Feature(wiimote.FeatureCore) != nil || dev.Feature(wiimote.FeatureNunchuck) != nil {
+		t.Errorf("expected only available features of the policy to be opened")
+	}
+}
+
+func TestDevicePause(t *testing.T) {
+	dev, err := NewDevice(DefaultConfig())
+	if err != nil {
+		t.Fatalf("unable to create device: %v", err)
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore|wiimote.FeatureAccel|wiimote.FeatureIR, true); err != nil {
+		t.Fatalf("unable to open features: %v", err)
+	}
+
+	if err := dev.Pause(wiimote.FeatureIR); err != nil {
+		t.Fatal(err)
+	}
+	if dev.Feature(wiimote.FeatureIR) != nil {
+		t.Errorf("expected IR to be closed while paused")
+	}
+	if ev, err := dev.Wait(100 * time.Millisecond); err == nil {
+		t.Fatalf("expected no events while paused, got %T", ev)
+	}
+
+	if err := dev.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if dev.Feature(wiimote.FeatureIR) == nil {
+		t.Errorf("expected IR to be reopened")
+	}
+	var gotIR bool
+	for range 16 {
+		ev, err := dev.Wait(time.Second)
+		if err != nil {
+			t.Fatalf("unable to wait for event: %v", err)
+		}
+		if _, ok := ev.(*wiimote.EventIR); ok {
+			gotIR = true
+		}
+	}
+	if !gotIR {
+		t.Errorf("expected IR events after resume")
+	}
+}
+
+func TestDeviceDisconnect(t *testing.T) {
+	dev, err := NewDevice(DefaultConfig())
+	if err != nil {
+		t.Fatalf("unable to create device: %v", err)
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore|wiimote.FeatureAccel, true); err != nil {
+		t.Fatalf("unable to open features: %v", err)
+	}
+	if err := dev.Pause(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dev.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	ev, err := dev.Wait(time.Second)
+	if _, ok := ev.(*wiimote.EventGone); err != nil || !ok {
+		t.Fatalf("expected EventGone, got %T %v", ev, err)
+	}
+	if ev.Device() != dev {
+		t.Errorf("expected EventGone of the device, got %v", ev.Device())
+	}
+	if dev.Feature(wiimote.FeatureAccel) != nil {
+		t.Errorf("expected features to be closed")
+	}
+}
+
+func TestController(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Keys = nil
+	dev, ctl, err := NewSimulatedDevice(cfg)
+	if err != nil {
+		t.Fatalf("unable to create device: %v", err)
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore|wiimote.FeatureAccel|wiimote.FeatureIR, true); err != nil {
+		t.Fatalf("unable to open features: %v", err)
+	}
+
+	accel := wiimote.Vec3{X: 1, Y: 2, Z: 3}
+	dot := wiimote.Vec2{X: 100, Y: 200}
+	ctl.PressKey(wiimote.KeyB)
+	ctl.SetAccel(accel)
+	ctl.SetDots(dot)
+
+	var gotKey, gotAccel, gotIR bool
+	for range 6 {
+		ev, err := dev.Wait(time.Second)
+		if err != nil {
+			t.Fatalf("unable to wait for event: %v", err)
+		}
+		switch ev := ev.(type) {
+		case *wiimote.EventKey:
+			gotKey = ev.Code == wiimote.KeyB && ev.Pressed
+		case *wiimote.EventAccel:
+			gotAccel = ev.Accel == accel
+		case *wiimote.EventIR:
+			gotIR = ev.Slots[0].Vec2 == dot && !ev.Slots[1].Valid()
+		}
+	}
+	if !gotKey || !gotAccel || !gotIR {
+		t.Fatalf("expected controlled key, accel and IR, got %v %v %v", gotKey, gotAccel, gotIR)
+	}
+
+	ctl.Reset()
+	for range 6 {
+		ev, err := dev.Wait(time.Second)
+		if err != nil {
+			t.Fatalf("unable to wait for event: %v", err)
+		}
+		if ev, ok := ev.(*wiimote.EventKey); ok {
+			if ev.Code != wiimote.KeyB || ev.Pressed {
+				t.Errorf("expected release of B, got %v %v", ev.Code, ev.Pressed)
+			}
+			return
+		}
+	}
+	t.Errorf("expected release of B after reset")
+}
@@ -0,0 +1,301 @@
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// invalidDot is the position of an IR dot which is not visible, see wiimote.IRSlot.Valid.
+var invalidDot = wiimote.Vec2{X: 1023, Y: 1023}
+
+// DotsKeyframe sets the positions of the IR dots at a given time. Invisible dots are
+// at (1023, 1023).
+type DotsKeyframe struct {
+	At   time.Duration
+	Dots [4]wiimote.Vec2
+}
+
+// RollKeyframe sets the roll of the remote in radians at a given time.
+type RollKeyframe struct {
+	At   time.Duration
+	Roll float64
+}
+
+// Dropout hides all IR dots for Duration.
+type Dropout struct {
+	At       time.Duration
+	Duration time.Duration
+}
+
+// Scenario describes the movement of IR dots, the roll of the remote and key presses over time. Values
+// between keyframes are interpolated linearly, a dot which is invisible in either keyframe
+// is not interpolated.
+//
+// Scenarios are written in a line-based format, '#' starts a comment:
+//
+//	at 0s dots 312,384 712,384    # two visible dots
+//	at 1s dots 412,284 -          # the second dot is invisible
+//	at 1s roll 0                  # roll in degrees
+//	at 2s roll 30
+//	at 2.5s dropout 250ms         # hide all dots
+//	at 3s key A 200ms             # press A for 200 milliseconds
+//	loop 4s                       # restart after 4 seconds
+type Scenario struct {
+	Dots     []DotsKeyframe
+	Rolls    []RollKeyframe
+	Dropouts []Dropout
+	Keys     []KeyPress
+	// Loop restarts the scenario after this duration, if 0 the last keyframes are held
+	Loop time.Duration
+}
+
+// Sample is the state of a scenario at a given time.
+type Sample struct {
+	At    time.Duration
+	Slots [4]wiimote.IRSlot
+	Accel wiimote.Vec3
+}
+
+// ParseScenario reads a scenario from r.
+func ParseScenario(r io.Reader) (*Scenario, error) {
+	var s Scenario
+	scan := bufio.NewScanner(r)
+	for line := 1; scan.Scan(); line++ {
+		text, _, _ := strings.Cut(scan.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if err := s.parseLine(fields); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(s.Dots, func(a, b DotsKeyframe) int { return int(a.At - b.At) })
+	slices.SortStableFunc(s.Rolls, func(a, b RollKeyframe) int { return int(a.At - b.At) })
+	return &s, nil
+}
+
+func (s *Scenario) parseLine(fields []string) error {
+	switch fields[0] {
+	case "loop":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: loop <duration>")
+		}
+		d, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return err
+		}
+		s.Loop = d
+		return nil
+	case "at":
+	default:
+		return fmt.Errorf("unknown command: %s", fields[0])
+	}
+
+	if len(fields) < 3 {
+		return fmt.Errorf("usage: at <duration> <dots|roll|dropout|key> ...")
+	}
+	at, err := time.ParseDuration(fields[1])
+	if err != nil {
+		return err
+	}
+	args := fields[3:]
+	switch fields[2] {
+	case "dots":
+		if len(args) > 4 {
+			return fmt.Errorf("at most 4 dots are supported")
+		}
+		kf := DotsKeyframe{At: at, Dots: [4]wiimote.Vec2{invalidDot, invalidDot, invalidDot, invalidDot}}
+		for i, arg := range args {
+			if arg == "-" {
+				continue
+			}
+			xstr, ystr, ok := strings.Cut(arg, ",")
+			if !ok {
+				return fmt.Errorf("invalid dot: %s", arg)
+			}
+			x, err := strconv.ParseInt(xstr, 10, 32)
+			if err != nil {
+				return err
+			}
+			y, err := strconv.ParseInt(ystr, 10, 32)
+			if err != nil {
+				return err
+			}
+			kf.Dots[i] = wiimote.Vec2{X: int32(x), Y: int32(y)}
+		}
+		s.Dots = append(s.Dots, kf)
+	case "roll":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: at <duration> roll <degrees>")
+		}
+		deg, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return err
+		}
+		s.Rolls = append(s.Rolls, RollKeyframe{At: at, Roll: deg * math.Pi / 180})
+	case "dropout":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: at <duration> dropout <duration>")
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return err
+		}
+		s.Dropouts = append(s.Dropouts, Dropout{At: at, Duration: d})
+	case "key":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: at <duration> key <key> <duration>")
+		}
+		key, ok := wiimote.ParseKey(args[0])
+		if !ok {
+			return fmt.Errorf("unknown key: %s", args[0])
+		}
+		d, err := time.ParseDuration(args[1])
+		if err != nil {
+			return err
+		}
+		s.Keys = append(s.Keys, KeyPress{Key: key, At: at, Duration: d})
+	default:
+		return fmt.Errorf("unknown keyframe: %s", fields[2])
+	}
+	return nil
+}
+
+// Length returns the duration of the scenario, which is Loop if set or the time of the last keyframe,
+// dropout or key press otherwise.
+func (s *Scenario) Length() time.Duration {
+	if s.Loop > 0 {
+		return s.Loop
+	}
+	var length time.Duration
+	for _, kf := range s.Dots {
+		length = max(length, kf.At)
+	}
+	for _, kf := range s.Rolls {
+		length = max(length, kf.At)
+	}
+	for _, d := range s.Dropouts {
+		length = max(length, d.At+d.Duration)
+	}
+	for _, kp := range s.Keys {
+		length = max(length, kp.At+kp.Duration)
+	}
+	return length
+}
+
+// keyframes returns the index of the last keyframe before or at t and the fraction to the next
+// keyframe. next is -1 if there is no next keyframe.
+func keyframes(n int, at func(int) time.Duration, t time.Duration) (prev, next int, frac float64) {
+	prev, next = 0, -1
+	for i := range n {
+		if at(i) > t {
+			next = i
+			break
+		}
+		prev = i
+	}
+	if next <= prev {
+		return prev, -1, 0
+	}
+	return prev, next, float64(t-at(prev)) / float64(at(next)-at(prev))
+}
+
+func lerp(a, b int32, frac float64) int32 {
+	return a + int32(math.Round(float64(b-a)*frac))
+}
+
+// Roll returns the roll in radians at t.
+func (s *Scenario) Roll(t time.Duration) float64 {
+	if s.Loop > 0 {
+		t %= s.Loop
+	}
+	if len(s.Rolls) == 0 {
+		return 0
+	}
+	prev, next, frac := keyframes(len(s.Rolls), func(i int) time.Duration { return s.Rolls[i].At }, t)
+	if next == -1 {
+		return s.Rolls[prev].Roll
+	}
+	return s.Rolls[prev].Roll + (s.Rolls[next].Roll-s.Rolls[prev].Roll)*frac
+}
+
+// At returns the IR slots and acceleration at t. The dots are rotated around the center of the
+// camera by the roll and the acceleration is the gravity of a remote with this roll.
+func (s *Scenario) At(t time.Duration) Sample {
+	smp := Sample{At: t}
+	if s.Loop > 0 {
+		t %= s.Loop
+	}
+
+	roll := s.Roll(t)
+	sin, cos := math.Sin(roll), math.Cos(roll)
+	smp.Accel = wiimote.Vec3{
+		X: int32(math.Round(100 * sin)),
+		Z: int32(math.Round(100 * cos)),
+	}
+
+	for i := range smp.Slots {
+		smp.Slots[i].Vec2 = invalidDot
+	}
+	for _, d := range s.Dropouts {
+		if t >= d.At && t < d.At+d.Duration {
+			return smp
+		}
+	}
+	if len(s.Dots) == 0 {
+		return smp
+	}
+
+	prev, next, frac := keyframes(len(s.Dots), func(i int) time.Duration { return s.Dots[i].At }, t)
+	for i := range smp.Slots {
+		dot := s.Dots[prev].Dots[i]
+		if dot == invalidDot {
+			continue
+		}
+		if next != -1 && s.Dots[next].Dots[i] != invalidDot {
+			to := s.Dots[next].Dots[i]
+			dot = wiimote.Vec2{X: lerp(dot.X, to.X, frac), Y: lerp(dot.Y, to.Y, frac)}
+		}
+		if roll != 0 {
+			dx, dy := float64(dot.X-512), float64(dot.Y-384)
+			dot = wiimote.Vec2{
+				X: 512 + int32(math.Round(cos*dx-sin*dy)),
+				Y: 384 + int32(math.Round(sin*dx+cos*dy)),
+			}
+		}
+		smp.Slots[i].Vec2 = dot
+	}
+	return smp
+}
+
+// Samples returns the samples of the scenario at rate samples per second from the start
+// up to its length. Samples are computed independently of the wall-clock, which makes them
+// suitable for reproducible tests.
+func (s *Scenario) Samples(rate int) iter.Seq[Sample] {
+	return func(yield func(Sample) bool) {
+		if rate <= 0 {
+			return
+		}
+		step := time.Second / time.Duration(rate)
+		length := s.Length()
+		for t := time.Duration(0); t <= length; t += step {
+			if !yield(s.At(t)) {
+				return
+			}
+		}
+	}
+}
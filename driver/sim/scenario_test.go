@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/pkg/irpointer"
+)
+
+const testScenario = `
+# sensor bar moving to the right
+at 0s dots 312,384 712,384
+at 1s dots 412,384 812,384
+at 1s roll 0
+at 2s roll 90
+at 3s dropout 500ms
+at 3s key a 100ms
+at 4s dots 412,384 -
+`
+
+func TestParseScenario(t *testing.T) {
+	s, err := ParseScenario(strings.NewReader(testScenario))
+	if err != nil {
+		t.Fatalf("unable to parse scenario: %v", err)
+	}
+	if len(s.Dots) != 3 || len(s.Rolls) != 2 || len(s.Dropouts) != 1 || len(s.Keys) != 1 {
+		t.Fatalf("unexpected keyframes: %+v", s)
+	}
+	if s.Keys[0] != (KeyPress{Key: wiimote.KeyA, At: 3 * time.Second, Duration: 100 * time.Millisecond}) {
+		t.Errorf("unexpected key press %+v", s.Keys[0])
+	}
+	if s.Length() != 4*time.Second {
+		t.Errorf("expected length of 4s, got %v", s.Length())
+	}
+
+	for _, bad := range []string{"at 1s", "at x dots 1,2", "at 1s dots 1;2", "jump 1s", "at 1s roll a", "at 1s key foo 1s"} {
+		if _, err := ParseScenario(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestScenarioAt(t *testing.T) {
+	s, err := ParseScenario(strings.NewReader(testScenario))
+	if err != nil {
+		t.Fatalf("unable to parse scenario: %v", err)
+	}
+
+	smp := s.At(500 * time.Millisecond)
+	if smp.Slots[0].Vec2 != (wiimote.Vec2{X: 362, Y: 384}) || smp.Slots[1].Vec2 != (wiimote.Vec2{X: 762, Y: 384}) {
+		t.Errorf("expected interpolated dots, got %v %v", smp.Slots[0].Vec2, smp.Slots[1].Vec2)
+	}
+	if smp.Slots[2].Valid() {
+		t.Errorf("expected third slot to be invalid")
+	}
+
+	smp = s.At(2 * time.Second)
+	if smp.Accel != (wiimote.Vec3{X: 100, Z: 0}) {
+		t.Errorf("expected accel of a rolled remote, got %v", smp.Accel)
+	}
+	if smp.Slots[0].Vec2 != (wiimote.Vec2{X: 512, Y: 284}) {
+		t.Errorf("expected rotated dot, got %v", smp.Slots[0].Vec2)
+	}
+
+	smp = s.At(3200 * time.Millisecond)
+	for i, slot := range smp.Slots {
+		if slot.Valid() {
+			t.Errorf("expected slot %d to be invalid during dropout", i)
+		}
+	}
+
+	smp = s.At(5 * time.Second)
+	if !smp.Slots[0].Valid() || smp.Slots[1].Valid() {
+		t.Errorf("expected only the first dot to be visible, got %v", smp.Slots)
+	}
+}
+
+func TestScenarioPointer(t *testing.T) {
+	s, err := ParseScenario(strings.NewReader(`
+at 0s dots 312,384 712,384
+at 1s dots 412,384 812,384
+`))
+	if err != nil {
+		t.Fatalf("unable to parse scenario: %v", err)
+	}
+
+	pointer := irpointer.NewIRPointer()
+	var first, last irpointer.Frame
+	for smp := range s.Samples(100) {
+		frame := pointer.Step(smp.Slots, smp.Accel)
+		if !frame.Valid {
+			t.Fatalf("expected valid frame at %v", smp.At)
+		}
+		if smp.At == 0 {
+			first = frame
+		}
+		last = frame
+	}
+	// moving the dots to the right moves the pointer to the left
+	if !(last.Position.X < first.Position.X) || math.Abs(last.Position.Y-first.Position.Y) > 1e-6 {
+		t.Errorf("unexpected pointer movement from %v to %v", first.Position, last.Position)
+	}
+}
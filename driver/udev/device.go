@@ -10,19 +10,17 @@ import (
 
 // Device wraps a libudev device object
 type Device struct {
-	udevContext
+	*Context
 	ptr *C.struct_udev_device
 }
 
 func deviceUnref(d *Device) {
+	d.lock()
+	defer d.unlock()
 	if d.ptr != nil {
 		C.udev_device_unref(d.ptr)
 		d.ptr = nil
 	}
-	if d.udevPtr != nil {
-		C.udev_unref(d.udevPtr)
-		d.udevPtr = nil
-	}
 }
 
 // Parent returns the parent Device, or nil if the receiver has no parent Device
@@ -34,7 +32,7 @@ func (d *Device) Parent() wiimote.DeviceInfo {
 		return nil
 	}
 	ptr = C.udev_device_ref(ptr)
-	pd := newDevice()
+	pd := d.newDevice()
 	pd.ptr = ptr
 	return pd
 }
@@ -97,3 +95,12 @@ func (d *Device) SysattrValue(sysattr string) string {
 	defer freeCharPtr(s)
 	return C.GoString(C.udev_device_get_sysattr_value(d.ptr, s))
 }
+
+// PropertyValue retrieves the value of a device property (e.g. HID_UNIQ), and returns an empty string if there is no such property.
+func (d *Device) PropertyValue(key string) string {
+	d.lock()
+	defer d.unlock()
+	k := C.CString(key)
+	defer freeCharPtr(k)
+	return C.GoString(C.udev_device_get_property_value(d.ptr, k))
+}
@@ -20,11 +20,8 @@ func ExampleDevice() {
 	// Output:
 	// Sysname:zero
 	// Syspath:/sys/devices/virtual/mem/zero
-	// Devpath:/devices/virtual/mem/zero
 	// Devnode:/dev/zero
 	// Subsystem:mem
-	// Devtype:
-	// Sysnum:
 	// Driver:
 }
 
@@ -15,14 +15,17 @@ import (
 
 // Enumerate is an opaque struct wrapping a udev enumerate object.
 type Enumerate struct {
-	udevContext
+	*Context
 	ptr *C.struct_udev_enumerate
+	// matchers evaluated in Go on the scanned devices
+	matchers []wiimote.DeviceMatcher
 }
 
 // Unref the Enumerate object
 func enumerateUnref(e *Enumerate) {
+	e.lock()
+	defer e.unlock()
 	C.udev_enumerate_unref(e.ptr)
-	C.udev_unref(e.udevPtr)
 }
 
 // AddMatchSubsystem adds a filter for a subsystem of the device to include in the list.
@@ -87,6 +90,39 @@ func (e *Enumerate) AddMatchSysname(sysname string) (err error) {
 	return
 }
 
+// AddMatchProperty adds a filter for a property of the device to include in the list. The value
+// may be a shell pattern.
+func (e *Enumerate) AddMatchProperty(property, value string) (err error) {
+	e.lock()
+	defer e.unlock()
+	p, v := C.CString(property), C.CString(value)
+	defer freeCharPtr(p)
+	defer freeCharPtr(v)
+	if C.udev_enumerate_add_match_property(e.ptr, p, v) != 0 {
+		err = errors.New("udev: udev_enumerate_add_match_property failed")
+	}
+	return
+}
+
+// AddMatchTag adds a filter for a tag of the device to include in the list.
+func (e *Enumerate) AddMatchTag(tag string) (err error) {
+	e.lock()
+	defer e.unlock()
+	t := C.CString(tag)
+	defer freeCharPtr(t)
+	if C.udev_enumerate_add_match_tag(e.ptr, t) != 0 {
+		err = errors.New("udev: udev_enumerate_add_match_tag failed")
+	}
+	return
+}
+
+// Match adds a predicate the devices must match, it is evaluated in Go after scanning.
+func (e *Enumerate) Match(m wiimote.DeviceMatcher) {
+	e.lock()
+	defer e.unlock()
+	e.matchers = append(e.matchers, m)
+}
+
 // AddMatchParent adds a filter for a parent Device to include in the list.
 func (e *Enumerate) AddMatchParent(parent wiimote.DeviceInfo) error {
 	e.lock()
@@ -123,12 +159,16 @@ func (e *Enumerate) Devices() (it iter.Seq[wiimote.DeviceInfo], err error) {
 		return
 	}
 
-	names := enumerateName(&e.udevContext, func() *C.struct_udev_list_entry {
+	names := enumerateName(e.Context, func() *C.struct_udev_list_entry {
 		e.lock()
 		defer e.unlock()
 		return C.udev_enumerate_get_list_entry(e.ptr)
 	})
-	return sequences.Map(names, func(path string) wiimote.DeviceInfo { return NewDeviceFromSyspath(path) }), nil
+	devs := sequences.Map(names, func(path string) wiimote.DeviceInfo { return e.NewDeviceFromSyspath(path) })
+	for _, m := range e.matchers {
+		devs = sequences.Filter(devs, m)
+	}
+	return devs, nil
 }
 
 // Subsystems returns an Iterator over the subsystem syspaths matching the filter, sorted in dependency order.
@@ -140,7 +180,7 @@ func (e *Enumerate) Subsystems() (it iter.Seq[string], err error) {
 		return
 	}
 
-	return enumerateName(&e.udevContext, func() *C.struct_udev_list_entry {
+	return enumerateName(e.Context, func() *C.struct_udev_list_entry {
 		e.lock()
 		defer e.unlock()
 		return C.udev_enumerate_get_list_entry(e.ptr)
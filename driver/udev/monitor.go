@@ -4,21 +4,25 @@ package udev
 // #include <libudev.h>
 import "C"
 import (
+	"context"
 	"errors"
+	"syscall"
 
 	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
 )
 
 // Monitor is an opaque object handling an event source
 type Monitor struct {
-	udevContext
+	*Context
 	ptr *C.struct_udev_monitor
 }
 
 // Unref the monitor
 func monitorUnref(m *Monitor) {
+	m.lock()
+	defer m.unlock()
 	C.udev_monitor_unref(m.ptr)
-	C.udev_unref(m.udevPtr)
 }
 
 // FD receives a file descriptor which can be checked for rediness
@@ -45,11 +49,57 @@ func (m *Monitor) ReceiveDevice() wiimote.DeviceInfo {
 	if ptr == nil {
 		return nil
 	}
-	d := newDevice()
+	d := m.newDevice()
 	d.ptr = ptr
 	return d
 }
 
+// monitorSource polls a monitor for devices without blocking.
+type monitorSource struct {
+	m *Monitor
+}
+
+func (s monitorSource) FD() int {
+	fd := s.m.FD()
+	if fd >= 0 {
+		syscall.SetNonblock(fd, true)
+	}
+	return fd
+}
+
+func (s monitorSource) Poll() (wiimote.DeviceInfo, bool, error) {
+	dev := s.m.ReceiveDevice()
+	if dev == nil {
+		return nil, false, common.ErrWouldBlock
+	}
+	return dev, true, nil
+}
+
+// Devices returns a channel receiving devices until ctx is done, the channel is closed afterwards.
+// The monitor must be switched to listening mode using EnableReceiving first.
+func (m *Monitor) Devices(ctx context.Context) <-chan wiimote.DeviceInfo {
+	ch := make(chan wiimote.DeviceInfo)
+	p := common.NewPoller(monitorSource{m})
+	go func() {
+		defer close(ch)
+		for {
+			dev, err := p.WaitContext(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					wiimote.Logger().Debug("unable to receive device", "err", err)
+				}
+				return
+			}
+			select {
+			case ch <- dev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
 // SetReceiveBufferSize sets the size of the kernel socket buffer.
 // This call needs the appropriate privileges to succeed.
 func (m *Monitor) SetReceiveBufferSize(size int) (err error) {
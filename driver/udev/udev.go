@@ -9,105 +9,131 @@ import (
 	"sync"
 )
 
-// udevContext is an opaque struct wraping a udev library context
-type udevContext struct {
+// Context wraps a libudev context. Devices, monitors and enumerators created from a context share
+// it, libudev is not thread-safe so all their calls are serialized by the lock of the context.
+// The context is released after all objects created from it are released.
+type Context struct {
 	// A pointer to the C struct udev context
-	udevPtr *C.struct_udev
+	udev *C.struct_udev
 	// Mutex for thread sync as libudev is not thread safe when called with the same struct udev
 	m sync.Mutex
 }
 
-// Lock locks a udev context
-func (u *udevContext) lock() {
-	u.m.Lock()
+var defaultContext = sync.OnceValue(NewContext)
+
+// Default returns the context used by the package-level constructors.
+func Default() *Context {
+	return defaultContext()
 }
 
-// Unlock unlocks a udev context
-func (u *udevContext) unlock() {
-	u.m.Unlock()
-}
-
-// newDevice is a private helper function and returns a pointer to a new device.
-// The device is also added t the devices map in the udev context.
-// The agrument ptr is a pointer to the underlying C udev_device structure.
-// The function returns nil if the pointer passed is NULL.
-func newDevice() (d *Device) {
-	// Create a new device object
-	d = &Device{}
-	d.udevPtr = C.udev_new()
-	runtime.SetFinalizer(d, deviceUnref)
-	// Return the device object
-	return
+// NewContext creates a new libudev context.
+func NewContext() *Context {
+	ctx := &Context{udev: C.udev_new()}
+	runtime.AddCleanup(ctx, func(ptr *C.struct_udev) { C.udev_unref(ptr) }, ctx.udev)
+	return ctx
 }
 
-// newMonitor is a private helper function and returns a pointer to a new monitor.
-// The monitor is also added t the monitors map in the udev context.
-// The agrument ptr is a pointer to the underlying C udev_monitor structure.
-// The function returns nil if the pointer passed is NULL.
-func newMonitor() (m *Monitor) {
-	// Create a new device object
-	m = &Monitor{}
-	m.udevPtr = C.udev_new()
-	runtime.SetFinalizer(m, monitorUnref)
-	// Return the device object
-	return
+// Lock locks a udev context
+func (ctx *Context) lock() {
+	ctx.m.Lock()
 }
 
-func newEnumerate() (e *Enumerate) {
-	e = &Enumerate{}
-	e.udevPtr = C.udev_new()
-	runtime.SetFinalizer(e, enumerateUnref)
-	// Return the device object
+// Unlock unlocks a udev context
+func (ctx *Context) unlock() {
+	ctx.m.Unlock()
+}
+
+// newDevice is a private helper function and returns a pointer to a new device bound to ctx.
+func (ctx *Context) newDevice() (d *Device) {
+	d = &Device{Context: ctx}
+	runtime.SetFinalizer(d, deviceUnref)
 	return
 }
 
 // NewDeviceFromSyspath returns a pointer to a new device identified by its syspath, and nil on error
 // The device is identified by the syspath argument
-func NewDeviceFromSyspath(syspath string) *Device {
-	d := newDevice()
+func (ctx *Context) NewDeviceFromSyspath(syspath string) *Device {
+	d := ctx.newDevice()
 	// Lock the udev context
-	d.lock()
-	defer d.unlock()
+	ctx.lock()
+	defer ctx.unlock()
 	// Convert Go strings to C strings for passing
 	s := C.CString(syspath)
 	defer freeCharPtr(s)
 	// Return a new device
-	d.ptr = C.udev_device_new_from_syspath(d.udevPtr, s)
+	d.ptr = C.udev_device_new_from_syspath(ctx.udev, s)
 	return d
 }
 
 // NewDeviceFromSubsystemSysname returns a pointer to a new device identified by its subystem and sysname, and nil on error
-func NewDeviceFromSubsystemSysname(subsystem, sysname string) *Device {
-	d := newDevice()
-	d.lock()
-	defer d.unlock()
+func (ctx *Context) NewDeviceFromSubsystemSysname(subsystem, sysname string) *Device {
+	d := ctx.newDevice()
+	ctx.lock()
+	defer ctx.unlock()
 	ss, sn := C.CString(subsystem), C.CString(sysname)
 	defer freeCharPtr(ss)
 	defer freeCharPtr(sn)
-	d.ptr = C.udev_device_new_from_subsystem_sysname(d.udevPtr, ss, sn)
+	d.ptr = C.udev_device_new_from_subsystem_sysname(ctx.udev, ss, sn)
 	return d
 }
 
 // NewDeviceFromDeviceID returns a pointer to a new device identified by its device id, and nil on error
-func NewDeviceFromDeviceID(id string) *Device {
-	d := newDevice()
-	d.lock()
-	defer d.unlock()
+func (ctx *Context) NewDeviceFromDeviceID(id string) *Device {
+	d := ctx.newDevice()
+	ctx.lock()
+	defer ctx.unlock()
 	i := C.CString(id)
 	defer freeCharPtr(i)
-	d.ptr = C.udev_device_new_from_device_id(d.udevPtr, i)
+	d.ptr = C.udev_device_new_from_device_id(ctx.udev, i)
 	return d
 }
 
 // NewEnumerate returns a pointer to a new enumerate, and nil on error
-func NewEnumerate() *Enumerate {
-	e := newEnumerate()
-	e.lock()
-	defer e.unlock()
-	e.ptr = C.udev_enumerate_new(e.udevPtr)
+func (ctx *Context) NewEnumerate() *Enumerate {
+	e := &Enumerate{Context: ctx}
+	runtime.SetFinalizer(e, enumerateUnref)
+	ctx.lock()
+	defer ctx.unlock()
+	e.ptr = C.udev_enumerate_new(ctx.udev)
 	return e
 }
 
+// NewMonitorFromNetlink returns a pointer to a new monitor listening to a NetLink socket, and nil on error
+// The name argument is either "kernel" or "udev".
+// When passing "kernel" the events are received before they are processed by udev.
+// When passing "udev" the events are received after udev has processed the events and created device nodes.
+// In most cases you will want to use "udev".
+func (ctx *Context) NewMonitorFromNetlink(t MonitorType) *Monitor {
+	m := &Monitor{Context: ctx}
+	runtime.SetFinalizer(m, monitorUnref)
+	ctx.lock()
+	defer ctx.unlock()
+	n := C.CString(t.Name())
+	defer freeCharPtr(n)
+	m.ptr = C.udev_monitor_new_from_netlink(ctx.udev, n)
+	return m
+}
+
+// NewDeviceFromSyspath returns a pointer to a new device identified by its syspath using the default context.
+func NewDeviceFromSyspath(syspath string) *Device {
+	return Default().NewDeviceFromSyspath(syspath)
+}
+
+// NewDeviceFromSubsystemSysname returns a pointer to a new device identified by its subystem and sysname using the default context.
+func NewDeviceFromSubsystemSysname(subsystem, sysname string) *Device {
+	return Default().NewDeviceFromSubsystemSysname(subsystem, sysname)
+}
+
+// NewDeviceFromDeviceID returns a pointer to a new device identified by its device id using the default context.
+func NewDeviceFromDeviceID(id string) *Device {
+	return Default().NewDeviceFromDeviceID(id)
+}
+
+// NewEnumerate returns a pointer to a new enumerate using the default context.
+func NewEnumerate() *Enumerate {
+	return Default().NewEnumerate()
+}
+
 // MonitorType describes how a monitor or enumerator should look for devices.
 type MonitorType uint
 
@@ -127,17 +153,7 @@ func (t MonitorType) Name() string {
 	}
 }
 
-// NewMonitorFromNetlink returns a pointer to a new monitor listening to a NetLink socket, and nil on error
-// The name argument is either "kernel" or "udev".
-// When passing "kernel" the events are received before they are processed by udev.
-// When passing "udev" the events are received after udev has processed the events and created device nodes.
-// In most cases you will want to use "udev".
+// NewMonitorFromNetlink returns a pointer to a new monitor listening to a NetLink socket using the default context.
 func NewMonitorFromNetlink(t MonitorType) *Monitor {
-	m := newMonitor()
-	m.lock()
-	defer m.unlock()
-	n := C.CString(t.Name())
-	defer freeCharPtr(n)
-	m.ptr = C.udev_monitor_new_from_netlink(m.udevPtr, n)
-	return m
+	return Default().NewMonitorFromNetlink(t)
 }
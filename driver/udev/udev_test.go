@@ -1,6 +1,7 @@
 package udev
 
 import (
+	"context"
 	"fmt"
 	"testing"
 )
@@ -41,3 +42,23 @@ func ExampleNewEnumerate() {
 func TestNewEnumerate(t *testing.T) {
 	_ = NewEnumerate()
 }
+
+func TestMonitorDevices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := NewMonitorFromNetlink(MonitorUdev).Devices(ctx)
+	cancel()
+	for range ch {
+	}
+}
+
+func TestSharedContext(t *testing.T) {
+	ctx := NewContext()
+	d := ctx.NewDeviceFromSubsystemSysname("mem", "zero")
+	e := ctx.NewEnumerate()
+	if d.Context != ctx || e.Context != ctx {
+		t.Errorf("expected objects to share the context")
+	}
+	if NewEnumerate().Context != Default() {
+		t.Errorf("expected package-level constructors to use the default context")
+	}
+}
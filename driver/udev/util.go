@@ -17,7 +17,7 @@ func freeCharPtr(s *C.char) {
 }
 
 // enumeratorIterator creates an iterator over an udev_list_entry, init() should return the initial entry.
-func enumeratorIterator(ctx *udevContext, init func() *C.struct_udev_list_entry) iter.Seq[*C.struct_udev_list_entry] {
+func enumeratorIterator(ctx *Context, init func() *C.struct_udev_list_entry) iter.Seq[*C.struct_udev_list_entry] {
 	return sequences.Unfold(init, func(l *C.struct_udev_list_entry) (*C.struct_udev_list_entry, bool) {
 		ctx.lock()
 		defer ctx.unlock()
@@ -27,7 +27,7 @@ func enumeratorIterator(ctx *udevContext, init func() *C.struct_udev_list_entry)
 }
 
 // enumeratorIterator creates an iterator over names of udev_list_entry, init() should return the initial entry.
-func enumerateName(ctx *udevContext, init func() *C.struct_udev_list_entry) iter.Seq[string] {
+func enumerateName(ctx *Context, init func() *C.struct_udev_list_entry) iter.Seq[string] {
 	return sequences.Map(enumeratorIterator(ctx, init), func(l *C.struct_udev_list_entry) string {
 		ctx.lock()
 		defer ctx.unlock()
@@ -36,7 +36,7 @@ func enumerateName(ctx *udevContext, init func() *C.struct_udev_list_entry) iter
 }
 
 // enumeratorIterator creates an iterator over names and values of udev_list_entry, init() should return the initial entry.
-func enumerateNameValue(ctx *udevContext, init func() *C.struct_udev_list_entry) iter.Seq2[string, string] {
+func enumerateNameValue(ctx *Context, init func() *C.struct_udev_list_entry) iter.Seq2[string, string] {
 	return sequences.Map12(enumeratorIterator(ctx, init), func(l *C.struct_udev_list_entry) (string, string) {
 		ctx.lock()
 		defer ctx.unlock()
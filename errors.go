@@ -0,0 +1,51 @@
+package wiimote
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Errors returned by devices and features, they may be wrapped and should be tested using errors.Is.
+var (
+	// ErrFeatureUnavailable is returned if a feature is not present on the device.
+	ErrFeatureUnavailable = errors.New("feature not available")
+	// ErrNotOpened is returned if a feature is used which is not opened.
+	ErrNotOpened = errors.New("feature not opened")
+	// ErrPermission is returned if a node of the device cannot be accessed. Errors matching
+	// ErrPermission also match os.ErrPermission.
+	ErrPermission = errors.New("permission denied")
+	// ErrNoBattery is returned if the device does not report a battery.
+	ErrNoBattery = errors.New("device has no battery")
+	// ErrNoLED is returned if the device does not provide LEDs.
+	ErrNoLED = errors.New("device has no LEDs")
+	// ErrUnsupported is returned if the driver of a device does not support an operation.
+	ErrUnsupported = errors.New("not supported by the driver")
+)
+
+// FeatureError records an error of a feature, e.g. while opening it.
+type FeatureError struct {
+	Kind FeatureKind
+	Err  error
+}
+
+func (e *FeatureError) Error() string {
+	return e.Kind.String() + ": " + e.Err.Error()
+}
+
+func (e *FeatureError) Unwrap() error {
+	return e.Err
+}
+
+// LEDError records an error of a single LED, Index is counted from 0 left-to-right.
+type LEDError struct {
+	Index int
+	Err   error
+}
+
+func (e *LEDError) Error() string {
+	return "LED " + strconv.Itoa(e.Index+1) + ": " + e.Err.Error()
+}
+
+func (e *LEDError) Unwrap() error {
+	return e.Err
+}
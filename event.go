@@ -2,6 +2,8 @@ package wiimote
 
 import (
 	"time"
+
+	"github.com/friedelschoen/go-wiimote/pkg/geom"
 )
 
 // Key Event Identifiers
@@ -99,11 +101,9 @@ const (
 	KeyFretFarLow
 )
 
-// Vec2 represents a 2D point or vector to X and Y, may be interpreted different depending on the event .
-type Vec2 struct {
-	X int32 `json:"x"`
-	Y int32 `json:"y"`
-}
+// Vec2 represents a 2D point or vector to X and Y, may be interpreted different depending on the event.
+// See geom.Vec2 for its arithmetic.
+type Vec2 = geom.Vec2
 
 // Vec3 represents a 3D point or vector to X, Y and Z, may be interpreted different depending on the event.
 type Vec3 struct {
@@ -114,7 +114,8 @@ type Vec3 struct {
 
 // Rect represents a 2D floating point rectangle, streched over an Min and Max point.
 type Rect struct {
-	Min, Max Vec2
+	Min Vec2 `json:"min"`
+	Max Vec2 `json:"max"`
 }
 
 func (r Rect) Contains(p Vec2) bool {
@@ -137,6 +138,9 @@ func (r Rect) Empty() bool {
 // consider using a type-switch to retrieve the specific event type and data
 type Event interface {
 	Feature() Feature
+	// Device returns the device which emitted the event, nil if unknown (e.g. for decoded
+	// events). Unlike Feature, it is also set for events of the whole device like EventGone.
+	Device() Device
 	Timestamp() time.Time
 }
 
@@ -172,9 +176,9 @@ type EventIR struct {
 // IRSlot describes Infra-Red Tracking on a WiiMote
 type IRSlot struct {
 	Vec2
-	Size      uint8
-	Bounds    Rect
-	Intensity uint8
+	Size      uint8 `json:"size"`
+	Bounds    Rect  `json:"bounds"`
+	Intensity uint8 `json:"intensity"`
 }
 
 // Valid returns wether this slot holds a valid source. If not it has no track and is considered disabled.
@@ -182,6 +186,51 @@ func (slot IRSlot) Valid() bool {
 	return slot.X != 1023 || slot.Y != 1023
 }
 
+// InvalidIRSlot is a slot without source.
+var InvalidIRSlot = IRSlot{Vec2: Vec2{X: 1023, Y: 1023}}
+
+// Confidence returns how likely the slot tracks a real source in the range 0..1. The intensity
+// is only reported in full IR mode and the size in extended IR mode, if neither is reported a
+// valid slot has a confidence of 1. Ghost dots tend to be small and faint.
+func (slot IRSlot) Confidence() float64 {
+	switch {
+	case !slot.Valid():
+		return 0
+	case slot.Intensity > 0:
+		return float64(slot.Intensity) / 0xff
+	case slot.Size > 0:
+		return float64(slot.Size) / 0x0f
+	}
+	return 1
+}
+
+// IRMask is a set of IR slots, bit n is slot n.
+type IRMask uint8
+
+// IRSlots returns the mask of slots.
+func IRSlots(slots ...int) IRMask {
+	var m IRMask
+	for _, n := range slots {
+		m |= 1 << n
+	}
+	return m
+}
+
+// Has returns whether slot n is in m.
+func (m IRMask) Has(n int) bool {
+	return m&(1<<n) != 0
+}
+
+// Ignore returns slots with the slots in m invalidated.
+func (m IRMask) Ignore(slots [4]IRSlot) [4]IRSlot {
+	for n := range slots {
+		if m.Has(n) {
+			slots[n] = InvalidIRSlot
+		}
+	}
+	return slots
+}
+
 // EventBalanceBoard provides balance-board weight data. Four sensors report weight-data
 // for each of the four edges of the board.
 type EventBalanceBoard struct {
@@ -193,6 +242,7 @@ type EventBalanceBoard struct {
 // acceleration, of the motion-plus extension.
 type EventMotionPlus struct {
 	Event
+	// Speed is the raw rotation speed, see AngularVelocity for degree per second
 	Speed Vec3 `json:"speed"`
 }
 
@@ -216,9 +266,8 @@ type EventProControllerMove struct {
 // EventWatch is sent whenever an extension was hotplugged (plugged or
 // unplugged), a device-detection finished or some other static data
 // changed which cannot be monitored separately.
-// An application should check what changed by examining the device is
-// testing whether all required features are still available.
-// Non-hotplug aware devices may discard this event.
+// The fields describe what changed, so the device does not have to be
+// examined again. Non-hotplug aware devices may discard this event.
 //
 // This is only returned if you explicitly watched for hotplug events.
 // See Device.Watch().
@@ -228,6 +277,32 @@ type EventProControllerMove struct {
 // returned regardless whether you watch for hotplug events or not.
 type EventWatch struct {
 	Event
+	// Added and Removed are the features which became available or
+	// unavailable
+	Added   FeatureKind `json:"added"`
+	Removed FeatureKind `json:"removed"`
+	// Closed are the opened features which were closed, either because
+	// they were removed or the kernel closed the file-descriptor
+	Closed FeatureKind `json:"closed"`
+	// PrevExtension and Extension are the extension before and after the
+	// change, see Device.Extension. They are equal if the extension did
+	// not change.
+	PrevExtension string `json:"prev_extension"`
+	Extension     string `json:"extension"`
+}
+
+// EventExtensionConnected is provided after EventWatch when an extension was plugged in.
+// Type is the extension as reported by Device.Extension (e.g. "nunchuk").
+type EventExtensionConnected struct {
+	Event
+	Type string `json:"extension"`
+}
+
+// EventExtensionDisconnected is provided after EventWatch when an extension was unplugged.
+// Type is the extension which was connected before.
+type EventExtensionDisconnected struct {
+	Event
+	Type string `json:"extension"`
 }
 
 // EventClassicControllerKey provides Classic Controller key events.
@@ -323,6 +398,20 @@ type EventFeature struct {
 	Removed bool
 }
 
+// EventFeatureOpened is provided when a feature was reopened automatically.
+// See Device.AutoReopen.
+type EventFeatureOpened struct {
+	Event
+	Kind FeatureKind
+}
+
+// EventError is provided when polling failed and the device uses ErrorEvent as
+// error policy. See Device.SetErrorPolicy.
+type EventError struct {
+	Event
+	Err error
+}
+
 // EventGone provides Removal Event.
 // This event is sent whenever the device was removed. No payload is provided.
 // Non-hotplug aware applications may discard this event.
@@ -330,3 +419,13 @@ type EventFeature struct {
 type EventGone struct {
 	Event
 }
+
+// RawEvent is an unmodified input event of the kernel, see Device.RawEvents. Type, Code and
+// Value are defined in linux/input-event-codes.h.
+type RawEvent struct {
+	Feature FeatureKind
+	Type    uint16
+	Code    uint16
+	Value   int32
+	Time    time.Time
+}
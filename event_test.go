@@ -3,7 +3,7 @@ package wiimote
 import "testing"
 
 func TestIRSlotValid(t *testing.T) {
-	slot := IRSlot{Vec2{
+	slot := IRSlot{Vec2: Vec2{
 		X: 0,
 		Y: 0,
 	}}
@@ -14,7 +14,7 @@ func TestIRSlotValid(t *testing.T) {
 }
 
 func TestIRSlotInvalid(t *testing.T) {
-	slot := IRSlot{Vec2{
+	slot := IRSlot{Vec2: Vec2{
 		X: 1023,
 		Y: 1023,
 	}}
@@ -26,7 +26,7 @@ func TestIRSlotInvalid(t *testing.T) {
 
 func TestIRSlotMixedvalid(t *testing.T) {
 	// only if both fields are 1023, the slot is invalid!
-	slot := IRSlot{Vec2{
+	slot := IRSlot{Vec2: Vec2{
 		X: 1023,
 		Y: 1024,
 	}}
@@ -35,3 +35,31 @@ func TestIRSlotMixedvalid(t *testing.T) {
 		t.Errorf("IRSlot{%v, %v} should be valid but is not", slot.X, slot.Y)
 	}
 }
+
+func TestIRSlotConfidence(t *testing.T) {
+	cases := []struct {
+		slot IRSlot
+		want float64
+	}{
+		{InvalidIRSlot, 0},
+		{IRSlot{Vec2: Vec2{X: 10, Y: 10}}, 1},
+		{IRSlot{Vec2: Vec2{X: 10, Y: 10}, Size: 0x0f}, 1},
+		{IRSlot{Vec2: Vec2{X: 10, Y: 10}, Size: 3, Intensity: 0x33}, 0.2},
+	}
+	for _, c := range cases {
+		if got := c.slot.Confidence(); got != c.want {
+			t.Errorf("%+v.Confidence() = %v, want %v", c.slot, got, c.want)
+		}
+	}
+}
+
+func TestIRMaskIgnore(t *testing.T) {
+	var slots [4]IRSlot
+	mask := IRSlots(1, 3)
+	slots = mask.Ignore(slots)
+	for n, slot := range slots {
+		if slot.Valid() == mask.Has(n) {
+			t.Errorf("slot %d: valid = %v with mask %04b", n, slot.Valid(), mask)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package wiimote
 
 import (
+	"errors"
 	"io"
 )
 
@@ -34,6 +35,14 @@ type MemoryFeature interface {
 	Memory() (Memory, error)
 }
 
+// RegisterFeature provides access to the control registers of the device, which configure the
+// IR camera, the speaker and extensions. It is only provided by the hidraw backend.
+type RegisterFeature interface {
+	Feature
+
+	Registers() (Memory, error)
+}
+
 type MotionPlusFeature interface {
 	Feature
 
@@ -79,3 +88,37 @@ const (
 
 	FeatureSetCore = FeatureCore | FeatureAccel | FeatureIR | FeatureSpeaker
 )
+
+// OpenRequest describes features to open with their access, see Open. A FeatureKind opens its
+// features read-only, FeatureKind.Writable with write-access.
+type OpenRequest interface {
+	request() (kinds FeatureKind, writable bool)
+}
+
+func (k FeatureKind) request() (FeatureKind, bool) { return k, false }
+
+type writableKinds FeatureKind
+
+func (k writableKinds) request() (FeatureKind, bool) { return FeatureKind(k), true }
+
+// Writable requests the features of k with write-access, e.g. the core feature for rumble.
+func (k FeatureKind) Writable() OpenRequest {
+	return writableKinds(k)
+}
+
+// Open opens the features of every request with its own access, so only the features which are
+// written to need write-access:
+//
+//	wiimote.Open(dev, wiimote.FeatureCore.Writable(), wiimote.FeatureAccel|wiimote.FeatureIR)
+//
+// It opens all requests and returns the joined errors, see Device.OpenFeatures.
+func Open(dev Device, reqs ...OpenRequest) error {
+	var errs []error
+	for _, req := range reqs {
+		kinds, writable := req.request()
+		if err := dev.OpenFeatures(kinds, writable); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,34 @@
+package wiimote
+
+import (
+	"errors"
+	"testing"
+)
+
+// openRecorder records the calls of OpenFeatures, other methods are not implemented.
+type openRecorder struct {
+	Device
+	opened map[FeatureKind]bool
+}
+
+func (d *openRecorder) OpenFeatures(kinds FeatureKind, wr bool) error {
+	if kinds&FeatureGuitar != 0 {
+		return &FeatureError{Kind: FeatureGuitar, Err: ErrUnsupported}
+	}
+	d.opened[kinds] = wr
+	return nil
+}
+
+func TestOpen(t *testing.T) {
+	dev := &openRecorder{opened: make(map[FeatureKind]bool)}
+	err := Open(dev, FeatureCore.Writable(), FeatureAccel|FeatureIR, FeatureGuitar)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected error of the guitar, got %v", err)
+	}
+	if wr, ok := dev.opened[FeatureCore]; !ok || !wr {
+		t.Errorf("expected core to be opened writable")
+	}
+	if wr, ok := dev.opened[FeatureAccel|FeatureIR]; !ok || wr {
+		t.Errorf("expected sensors to be opened read-only")
+	}
+}
@@ -4,5 +4,6 @@ go 1.24.0
 
 require (
 	github.com/friedelschoen/go-uinput v0.1.0
+	github.com/godbus/dbus/v5 v5.2.2
 	golang.org/x/sys v0.38.0
 )
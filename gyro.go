@@ -0,0 +1,63 @@
+package wiimote
+
+import "math"
+
+// MPMode is the measurement range of a Motion Plus axis. The Motion Plus switches every axis
+// to fast mode independently when it rotates faster than the slow range.
+type MPMode uint8
+
+const (
+	// MPSlow covers up to MPSlowRange degree per second with a higher resolution
+	MPSlow MPMode = iota
+	// MPFast covers up to MPFastRange degree per second
+	MPFast
+)
+
+const (
+	// MPUnitsPerDegree is the number of units of EventMotionPlus per degree per second. The
+	// sensor reports 8192/595 units per degree per second in slow mode, hid-wiimote multiplies
+	// slow mode readings by 9 and fast mode readings by 9*2000/440, so this factor applies to
+	// both modes.
+	MPUnitsPerDegree = 9 * 8192.0 / 595.0
+
+	// MPSlowRange is the largest rotation speed in degree per second measured in slow mode
+	MPSlowRange = 440.0
+	// MPFastRange is the largest rotation speed in degree per second measured in fast mode
+	MPFastRange = 2000.0
+)
+
+func (m MPMode) String() string {
+	if m == MPFast {
+		return "fast"
+	}
+	return "slow"
+}
+
+// MPDegrees converts the raw rotation speed v of the Motion Plus to degree per second.
+func MPDegrees(v Vec3) FVec3 {
+	return FVec3{
+		X: float64(v.X) / MPUnitsPerDegree,
+		Y: float64(v.Y) / MPUnitsPerDegree,
+		Z: float64(v.Z) / MPUnitsPerDegree,
+	}
+}
+
+func mpMode(deg float64) MPMode {
+	if math.Abs(deg) > MPSlowRange {
+		return MPFast
+	}
+	return MPSlow
+}
+
+// AngularVelocity returns the rotation speed in degree per second.
+func (ev *EventMotionPlus) AngularVelocity() FVec3 {
+	return MPDegrees(ev.Speed)
+}
+
+// Mode returns the measurement range of the X, Y and Z axis. The kernel does not report the
+// mode of a sample, it is derived from the speed: a speed outside the slow range can only be
+// measured in fast mode.
+func (ev *EventMotionPlus) Mode() [3]MPMode {
+	deg := ev.AngularVelocity()
+	return [3]MPMode{mpMode(deg.X), mpMode(deg.Y), mpMode(deg.Z)}
+}
@@ -0,0 +1,40 @@
+package wiimote
+
+import (
+	"math"
+	"testing"
+)
+
+// kernelMPValue scales the raw reading of the Motion Plus (without the offset of 8192) like
+// wiimod_mp_in_data of hid-wiimote.
+func kernelMPValue(raw int32, slow bool) int32 {
+	if !slow {
+		return raw * 2000 * 9 / 440
+	}
+	return raw * 9
+}
+
+func TestMotionPlusDegrees(t *testing.T) {
+	// 8192/595 raw units per degree per second in slow mode, 2000/440 times less in fast mode
+	ev := EventMotionPlus{Speed: Vec3{
+		X: kernelMPValue(8192, true),
+		Y: kernelMPValue(-8192*440/2000, false),
+		Z: kernelMPValue(0, true),
+	}}
+	deg := ev.AngularVelocity()
+	if math.Abs(deg.X-595) > 1e-9 || math.Abs(deg.Y+595) > 0.5 || deg.Z != 0 {
+		t.Errorf("expected (595, -595, 0), got %v", deg)
+	}
+	if mode := ev.Mode(); mode != [3]MPMode{MPFast, MPFast, MPSlow} {
+		t.Errorf("expected fast, fast, slow, got %v", mode)
+	}
+
+	// 100 degree per second in slow mode
+	ev.Speed = Vec3{X: kernelMPValue(1377, true)}
+	if deg := ev.AngularVelocity(); math.Abs(deg.X-100) > 0.1 {
+		t.Errorf("expected 100 degree per second, got %v", deg.X)
+	}
+	if mode := ev.Mode(); mode[0] != MPSlow {
+		t.Errorf("expected slow mode, got %v", mode[0])
+	}
+}
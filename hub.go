@@ -0,0 +1,162 @@
+package wiimote
+
+import (
+	"context"
+	"sync"
+)
+
+// Hub distributes the events of a device to typed subscriptions, so applications interested
+// in a single feature do not have to filter the event stream themselves. The device is polled
+// by a single loop, see Run.
+//
+//	hub := wiimote.NewHub(dev)
+//	ir, err := hub.IR()
+//	go hub.Run(ctx)
+//	for ev := range ir.C {
+//		...
+//	}
+type Hub struct {
+	dev Device
+
+	mu     sync.Mutex
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+type subscription struct {
+	deliver func(Event)
+	close   func()
+}
+
+// NewHub creates a hub for dev.
+func NewHub(dev Device) *Hub {
+	return &Hub{dev: dev, subs: make(map[*subscription]struct{})}
+}
+
+// Device returns the device of the hub.
+func (h *Hub) Device() Device {
+	return h.dev
+}
+
+// Run polls the device and delivers the events to all subscriptions until ctx is done or the
+// device is gone. It returns like Poller.HandleContext. All subscriptions are closed afterwards.
+func (h *Hub) Run(ctx context.Context) error {
+	err := h.dev.HandleContext(ctx, h.dispatch)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		sub.close()
+		delete(h.subs, sub)
+	}
+	return err
+}
+
+func (h *Hub) dispatch(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		sub.deliver(ev)
+	}
+}
+
+// Subscription delivers the events of type T of a hub.
+type Subscription[T Event] struct {
+	// C receives the events, it is closed when the subscription is closed or the hub stops
+	C <-chan T
+
+	hub *Hub
+	sub *subscription
+	// events dropped because C was full
+	dropped uint64
+}
+
+// Dropped returns the number of events dropped because C was full.
+func (s *Subscription[T]) Dropped() uint64 {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.dropped
+}
+
+// Close stops the subscription and closes C. The feature is not closed.
+func (s *Subscription[T]) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s.sub]; ok {
+		s.sub.close()
+		delete(s.hub.subs, s.sub)
+	}
+}
+
+// Subscribe opens the feature of kind, if it is not opened yet, and returns a subscription
+// receiving all events of type T. Events are dropped if the buffer of size buffer is full,
+// the polling loop is never blocked by a subscription.
+//
+// Devices are not thread-safe, subscriptions opening a feature should be made before Run.
+func Subscribe[T Event](h *Hub, kind FeatureKind, buffer int) (*Subscription[T], error) {
+	if kind != 0 && h.dev.Feature(kind) == nil {
+		if err := h.dev.OpenFeatures(kind, false); err != nil {
+			return nil, err
+		}
+	}
+
+	ch := make(chan T, buffer)
+	s := &Subscription[T]{C: ch, hub: h}
+	s.sub = &subscription{
+		deliver: func(ev Event) {
+			tev, ok := ev.(T)
+			if !ok {
+				return
+			}
+			select {
+			case ch <- tev:
+			default:
+				s.dropped++
+			}
+		},
+		close: func() { close(ch) },
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return s, nil
+	}
+	h.subs[s.sub] = struct{}{}
+	return s, nil
+}
+
+// subscriptionBuffer is the buffer size of the typed subscriptions of Hub.
+const subscriptionBuffer = 64
+
+// Keys subscribes to the keys of the core feature.
+func (h *Hub) Keys() (*Subscription[*EventKey], error) {
+	return Subscribe[*EventKey](h, FeatureCore, subscriptionBuffer)
+}
+
+// Accel subscribes to the accelerometer.
+func (h *Hub) Accel() (*Subscription[*EventAccel], error) {
+	return Subscribe[*EventAccel](h, FeatureAccel, subscriptionBuffer)
+}
+
+// IR subscribes to the IR camera.
+func (h *Hub) IR() (*Subscription[*EventIR], error) {
+	return Subscribe[*EventIR](h, FeatureIR, subscriptionBuffer)
+}
+
+// MotionPlus subscribes to the Motion Plus.
+func (h *Hub) MotionPlus() (*Subscription[*EventMotionPlus], error) {
+	return Subscribe[*EventMotionPlus](h, FeatureMotionPlus, subscriptionBuffer)
+}
+
+// Nunchuk subscribes to the movement of a Nunchuk, its keys are reported as EventNunchukKey.
+func (h *Hub) Nunchuk() (*Subscription[*EventNunchukMove], error) {
+	return Subscribe[*EventNunchukMove](h, FeatureNunchuck, subscriptionBuffer)
+}
+
+// BalanceBoard subscribes to the weight sensors of a balance board.
+func (h *Hub) BalanceBoard() (*Subscription[*EventBalanceBoard], error) {
+	return Subscribe[*EventBalanceBoard](h, FeatureBalanceBoard, subscriptionBuffer)
+}
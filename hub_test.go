@@ -0,0 +1,53 @@
+package wiimote_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
+)
+
+func TestHubSubscribe(t *testing.T) {
+	cfg := sim.DefaultConfig()
+	cfg.Keys = []sim.KeyPress{{Key: wiimote.KeyA, At: 0, Duration: time.Hour}}
+	dev, err := sim.NewDevice(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hub := wiimote.NewHub(dev)
+	ir, err := hub.IR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := hub.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.Feature(wiimote.FeatureIR) == nil || dev.Feature(wiimote.FeatureCore) == nil {
+		t.Fatalf("expected subscribed features to be opened")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- hub.Run(ctx) }()
+
+	if ev, ok := <-ir.C; !ok || ev.Feature().Kind() != wiimote.FeatureIR {
+		t.Fatalf("expected IR event, got %v", ev)
+	}
+	if ev, ok := <-keys.C; !ok || ev.Code != wiimote.KeyA {
+		t.Fatalf("expected key A, got %v", ev)
+	}
+
+	keys.Close()
+	cancel()
+	<-done
+	for range ir.C {
+	}
+	if _, ok := <-keys.C; ok {
+		t.Errorf("expected closed subscription")
+	}
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"weak"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// registry holds weak pointers, so devices which are no longer referenced are released and
+// their file descriptors are closed.
+var (
+	registryMu sync.Mutex
+	registry   = make(map[weak.Pointer[Cleanups]]struct{})
+)
+
+// Cleanups is a registry of functions which restore the state of a device. It is embedded
+// into devices to implement OnCleanup and Cleanup.
+type Cleanups struct {
+	mu         sync.Mutex
+	funcs      []func() error
+	registered bool
+}
+
+// OnCleanup registers fn to be called on Cleanup. Functions are called in reverse order of
+// registration.
+func (c *Cleanups) OnCleanup(fn func() error) {
+	c.mu.Lock()
+	c.funcs = append(c.funcs, fn)
+	register := !c.registered
+	c.registered = true
+	c.mu.Unlock()
+
+	if register {
+		wp := weak.Make(c)
+		registryMu.Lock()
+		registry[wp] = struct{}{}
+		registryMu.Unlock()
+		runtime.AddCleanup(c, unregister, wp)
+	}
+}
+
+func unregister(wp weak.Pointer[Cleanups]) {
+	registryMu.Lock()
+	delete(registry, wp)
+	registryMu.Unlock()
+}
+
+// Cleanup calls all registered functions and removes them. It returns the joined errors
+// of all functions.
+func (c *Cleanups) Cleanup() error {
+	c.mu.Lock()
+	funcs := c.funcs
+	c.funcs = nil
+	unreg := c.registered
+	c.registered = false
+	c.mu.Unlock()
+	if unreg {
+		unregister(weak.Make(c))
+	}
+
+	var errs []error
+	for i := len(funcs) - 1; i >= 0; i-- {
+		if err := funcs[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CleanupAll calls Cleanup on every registry which has registered functions.
+func CleanupAll() error {
+	registryMu.Lock()
+	all := make([]*Cleanups, 0, len(registry))
+	for wp := range registry {
+		if c := wp.Value(); c != nil {
+			all = append(all, c)
+		}
+	}
+	registryMu.Unlock()
+
+	var errs []error
+	for _, c := range all {
+		if err := c.Cleanup(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RestoreDevice returns a cleanup function which turns off rumble and restores the LEDs of dev
+// to the player number set with SetPlayerLED. If no player number was set, the LEDs are
+// restored to the current state, which is the player number assigned on connect.
+func RestoreDevice(dev wiimote.Device) func() error {
+	leds, lederr := dev.LED()
+	return func() error {
+		var errs []error
+		if rumble, ok := dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature); ok {
+			// rumble is unavailable if the feature is not writable
+			if err := rumble.Rumble(false); err != nil && !errors.Is(err, os.ErrInvalid) {
+				errs = append(errs, err)
+			}
+		}
+		if player := dev.Player(); player != 0 {
+			errs = append(errs, dev.SetPlayerLED(player))
+		} else if lederr == nil {
+			errs = append(errs, dev.SetLED(leds))
+		}
+		return errors.Join(errs...)
+	}
+}
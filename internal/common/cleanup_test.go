@@ -0,0 +1,82 @@
+package common
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+	"weak"
+)
+
+func TestCleanupOrder(t *testing.T) {
+	var c Cleanups
+	var order []int
+	c.OnCleanup(func() error { order = append(order, 1); return nil })
+	c.OnCleanup(func() error { order = append(order, 2); return nil })
+
+	if err := c.Cleanup(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(order) != 2 || order[0] != 2 || order[1] != 1 {
+		t.Errorf("expected reverse order [2 1], got %v", order)
+	}
+
+	// functions are only called once
+	if err := c.Cleanup(); err != nil || len(order) != 2 {
+		t.Errorf("expected no further calls, got %v (err %v)", order, err)
+	}
+}
+
+func TestCleanupAll(t *testing.T) {
+	errFail := errors.New("fail")
+
+	var a, b Cleanups
+	var called int
+	a.OnCleanup(func() error { called++; return nil })
+	b.OnCleanup(func() error { called++; return errFail })
+
+	if err := CleanupAll(); !errors.Is(err, errFail) {
+		t.Errorf("expected %v, got %v", errFail, err)
+	}
+	if called != 2 {
+		t.Errorf("expected 2 calls, got %d", called)
+	}
+	if err := CleanupAll(); err != nil || called != 2 {
+		t.Errorf("expected registry to be empty, got %d calls (err %v)", called, err)
+	}
+}
+
+func TestCleanupReleased(t *testing.T) {
+	type device struct {
+		Cleanups
+		fd int
+	}
+	released := make(chan int, 1)
+	dev := &device{fd: 42}
+	dev.OnCleanup(func() error { return nil })
+	runtime.AddCleanup(dev, func(fd int) { released <- fd }, dev.fd)
+	wp := weak.Make(&dev.Cleanups)
+	dev = nil
+
+	deadline := time.After(5 * time.Second)
+	closed := false
+	for {
+		runtime.GC()
+		select {
+		case fd := <-released:
+			if fd != 42 {
+				t.Errorf("expected cleanup of fd 42, got %d", fd)
+			}
+			closed = true
+		case <-deadline:
+			t.Fatalf("expected unreferenced device to be released and unregistered")
+		case <-time.After(10 * time.Millisecond):
+		}
+		registryMu.Lock()
+		_, registered := registry[wp]
+		registryMu.Unlock()
+		if closed && !registered {
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+const (
+	minBackoff = 10 * time.Millisecond
+	maxBackoff = time.Second
+)
+
+// RetryAfter is returned by Poll if the driver should not be polled again before Delay passed.
+// It matches ErrWouldBlock, the poller holds back the next poll by Delay.
+type RetryAfter struct {
+	Delay time.Duration
+}
+
+func (e RetryAfter) Error() string {
+	return fmt.Sprintf("retry after %v", e.Delay)
+}
+
+func (e RetryAfter) Is(target error) bool {
+	return target == ErrWouldBlock
+}
+
+// ErrorHandler applies a wiimote.ErrorPolicy to errors of a device.
+type ErrorHandler struct {
+	Policy  wiimote.ErrorPolicy
+	backoff time.Duration
+}
+
+// Reset resets the retry delay, it is called after a successful poll.
+func (h *ErrorHandler) Reset() {
+	h.backoff = 0
+}
+
+// Handle applies the policy to err and returns the result of Poll. base is the embedded event of
+// an EventError or EventGone, close is called to close the device if the policy is ErrorClose.
+func (h *ErrorHandler) Handle(err error, base wiimote.Event, close func()) (wiimote.Event, bool, error) {
+	switch h.Policy {
+	case wiimote.ErrorRetry:
+		h.backoff = min(max(2*h.backoff, minBackoff), maxBackoff)
+		return nil, false, RetryAfter{Delay: h.backoff}
+	case wiimote.ErrorEvent:
+		return &wiimote.EventError{Event: base, Err: err}, false, nil
+	case wiimote.ErrorClose:
+		close()
+		return &wiimote.EventGone{Event: base}, false, nil
+	}
+	return nil, false, err
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+func TestErrorHandler(t *testing.T) {
+	errFail := errors.New("fail")
+
+	var h ErrorHandler
+	if _, _, err := h.Handle(errFail, nil, nil); err != errFail {
+		t.Errorf("ErrorReturn: expected %v, got %v", errFail, err)
+	}
+
+	h.Policy = wiimote.ErrorEvent
+	ev, _, err := h.Handle(errFail, nil, nil)
+	if evErr, ok := ev.(*wiimote.EventError); err != nil || !ok || evErr.Err != errFail {
+		t.Errorf("ErrorEvent: expected EventError, got (%v,%v)", ev, err)
+	}
+
+	closed := false
+	h.Policy = wiimote.ErrorClose
+	ev, _, err = h.Handle(errFail, nil, func() { closed = true })
+	if _, ok := ev.(*wiimote.EventGone); err != nil || !ok || !closed {
+		t.Errorf("ErrorClose: expected EventGone and close, got (%v,%v) closed=%v", ev, err, closed)
+	}
+
+	h.Policy = wiimote.ErrorRetry
+	var retry RetryAfter
+	for _, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond} {
+		_, _, err := h.Handle(errFail, nil, nil)
+		if !errors.Is(err, ErrWouldBlock) || !errors.As(err, &retry) {
+			t.Fatalf("ErrorRetry: expected %v, got %v", ErrWouldBlock, err)
+		}
+		if retry.Delay != want {
+			t.Errorf("ErrorRetry: expected delay %v, got %v", want, retry.Delay)
+		}
+	}
+	h.Reset()
+	if h.backoff != 0 {
+		t.Errorf("expected backoff to be reset")
+	}
+}
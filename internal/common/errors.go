@@ -0,0 +1,29 @@
+package common
+
+import (
+	"errors"
+	"os"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// permissionError marks an os.ErrPermission as wiimote.ErrPermission.
+type permissionError struct {
+	err error
+}
+
+func (e permissionError) Error() string { return e.err.Error() }
+func (e permissionError) Unwrap() error { return e.err }
+
+func (e permissionError) Is(target error) bool {
+	return target == wiimote.ErrPermission
+}
+
+// Permission returns err so it matches wiimote.ErrPermission if it is a permission error, other
+// errors are returned unchanged.
+func Permission(err error) error {
+	if err == nil || !errors.Is(err, os.ErrPermission) || errors.Is(err, wiimote.ErrPermission) {
+		return err
+	}
+	return permissionError{err}
+}
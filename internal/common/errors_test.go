@@ -0,0 +1,25 @@
+package common
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+func TestPermission(t *testing.T) {
+	err := Permission(&os.PathError{Op: "open", Path: "/dev/input/event0", Err: os.ErrPermission})
+	if !errors.Is(err, wiimote.ErrPermission) || !errors.Is(err, os.ErrPermission) {
+		t.Errorf("expected %v to match both permission errors", err)
+	}
+	if Permission(io.EOF) != io.EOF || Permission(nil) != nil {
+		t.Errorf("expected other errors to be unchanged")
+	}
+
+	ferr := error(&wiimote.FeatureError{Kind: wiimote.FeatureIR, Err: err})
+	if !errors.Is(ferr, wiimote.ErrPermission) {
+		t.Errorf("expected %v to unwrap to a permission error", ferr)
+	}
+}
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"os"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// LEDState reads the LED at index of dev from the state of all LEDs, for drivers which only
+// access the LEDs at once.
+func LEDState(dev wiimote.Device, index int) (bool, error) {
+	led, err := ledAt(dev, index)
+	if err != nil {
+		return false, err
+	}
+	leds, err := dev.LED()
+	if err != nil {
+		return false, &wiimote.LEDError{Index: index, Err: err}
+	}
+	return leds&led != 0, nil
+}
+
+// SetLEDState turns the LED at index of dev on or off by writing the state of all LEDs, for
+// drivers which only access the LEDs at once.
+func SetLEDState(dev wiimote.Device, index int, on bool) error {
+	led, err := ledAt(dev, index)
+	if err != nil {
+		return err
+	}
+	leds, err := dev.LED()
+	if err != nil {
+		return &wiimote.LEDError{Index: index, Err: err}
+	}
+	if on {
+		leds |= led
+	} else {
+		leds &^= led
+	}
+	if err := dev.SetLED(leds); err != nil {
+		return &wiimote.LEDError{Index: index, Err: err}
+	}
+	return nil
+}
+
+func ledAt(dev wiimote.Device, index int) (wiimote.Led, error) {
+	led, ok := wiimote.LedAt(index)
+	if !ok {
+		return 0, &wiimote.LEDError{Index: index, Err: os.ErrInvalid}
+	}
+	if dev.LEDs()&led == 0 {
+		return 0, &wiimote.LEDError{Index: index, Err: wiimote.ErrNoLED}
+	}
+	return led, nil
+}
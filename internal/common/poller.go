@@ -1,9 +1,13 @@
 package common
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"math/rand/v2"
+	"os"
+	"runtime"
 	"time"
 
 	"github.com/friedelschoen/go-wiimote"
@@ -14,6 +18,21 @@ import (
 // The poller should wait for readability and retry.
 var ErrWouldBlock = errors.New("would block; wait readable and retry")
 
+// WakeAfter is returned by Poll if the driver has an event due after Delay, even if its file
+// descriptor does not become readable. It matches ErrWouldBlock, the poller waits for
+// readability at most Delay.
+type WakeAfter struct {
+	Delay time.Duration
+}
+
+func (e WakeAfter) Error() string {
+	return fmt.Sprintf("wake after %v", e.Delay)
+}
+
+func (e WakeAfter) Is(target error) bool {
+	return target == ErrWouldBlock
+}
+
 // pollerDriver defines a source that can be polled for events or data.
 type pollerDriver[T any] interface {
 	// FD returns a non-blocking file descriptor. When it becomes readable,
@@ -35,27 +54,127 @@ type poller[T any] struct {
 	drv  pollerDriver[T]
 	fd   int
 	wait bool
+	// eventfd to interrupt a poll when a context is done, -1 if not yet created
+	efd int
+
+	opts wiimote.PollerOptions
+	// current delay between retries, 0 after an event
+	delay time.Duration
+	// delay before the next poll requested by the driver with RetryAfter
+	backoff time.Duration
+	// longest wait for readability requested by the driver with WakeAfter, -1 if none
+	wake time.Duration
 }
 
 // NewPoller creates a new poller for the given driver.
 // The poller initially assumes Poll() should be called without waiting.
 func NewPoller[T any](drv pollerDriver[T]) wiimote.Poller[T] {
-	return &poller[T]{drv: drv, fd: -1}
+	return &poller[T]{drv: drv, fd: -1, efd: -1, opts: wiimote.DefaultPollerOptions, wake: -1}
+}
+
+func (p *poller[T]) SetOptions(opts ...wiimote.PollerOption) {
+	for _, opt := range opts {
+		opt(&p.opts)
+	}
+	p.delay = 0
+}
+
+// retryDelay returns the delay before the next poll of a driver without file descriptor.
+func (p *poller[T]) retryDelay() time.Duration {
+	if p.delay == 0 || p.opts.MaxRetryDelay <= p.opts.RetryDelay {
+		p.delay = p.opts.RetryDelay
+	} else {
+		p.delay = min(2*p.delay, p.opts.MaxRetryDelay)
+	}
+	d := p.delay
+	if p.opts.Jitter > 0 {
+		d += time.Duration(float64(d) * p.opts.Jitter * (2*rand.Float64() - 1))
+	}
+	return d
 }
 
 func (p *poller[T]) Poll() (T, bool, error) {
-	return p.drv.Poll()
+	return p.poll()
+}
+
+// poll polls the driver and logs every retrieved event.
+func (p *poller[T]) poll() (T, bool, error) {
+	ev, more, err := p.drv.Poll()
+	var retry RetryAfter
+	if errors.As(err, &retry) {
+		p.backoff = retry.Delay
+	}
+	var wake WakeAfter
+	if errors.As(err, &wake) {
+		p.wake = wake.Delay
+	} else {
+		p.wake = -1
+	}
+	if err == nil {
+		p.delay = 0
+		if l := wiimote.Logger(); l.Enabled(context.Background(), wiimote.LevelTrace) {
+			l.Log(context.Background(), wiimote.LevelTrace, "event dispatched", "type", fmt.Sprintf("%T", ev), "more", more)
+		}
+	}
+	return ev, more, err
 }
 
 // WaitReadable waits until the driver FD is readable or a timeout passes.
 // timeout < 0 means "wait forever".
 func (p *poller[T]) WaitReadable(timeout time.Duration) error {
+	return p.waitReadable(context.Background(), timeout)
+}
+
+// interrupt returns the eventfd which is written to when ctx is done.
+func (p *poller[T]) interrupt() (int, error) {
+	if p.efd >= 0 {
+		return p.efd, nil
+	}
+	efd, err := unix.Eventfd(0, unix.EFD_CLOEXEC|unix.EFD_NONBLOCK)
+	if err != nil {
+		return -1, err
+	}
+	p.efd = efd
+	runtime.AddCleanup(p, func(fd int) { unix.Close(fd) }, efd)
+	return efd, nil
+}
+
+func (p *poller[T]) waitReadable(ctx context.Context, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.backoff > 0 {
+		// the driver asked to hold back polling, the fd may be readable already
+		d := p.backoff
+		if timeout >= 0 {
+			d = min(d, timeout)
+		}
+		p.backoff -= d
+		select {
+		case <-time.After(d):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 	if p.fd < 0 {
 		p.fd = p.drv.FD()
 	}
 	if p.fd < 0 {
 		// Driver does not provide an FD; caller must rely on retry.
-		return nil
+		d := p.retryDelay()
+		if p.wake >= 0 {
+			d = min(d, p.wake)
+		}
+		if d <= 0 {
+			return nil
+		}
+		select {
+		case <-time.After(d):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
 	fds := []unix.PollFd{{
@@ -63,10 +182,36 @@ func (p *poller[T]) WaitReadable(timeout time.Duration) error {
 		Events: unix.POLLIN,
 	}}
 
+	if ctx.Done() != nil {
+		efd, err := p.interrupt()
+		if err != nil {
+			return err
+		}
+		// clear a wakeup of a previous context
+		var buf [8]byte
+		unix.Read(efd, buf[:])
+
+		fds = append(fds, unix.PollFd{
+			Fd:     int32(efd),
+			Events: unix.POLLIN,
+		})
+		stop := context.AfterFunc(ctx, func() {
+			one := [8]byte{1}
+			unix.Write(efd, one[:])
+		})
+		defer stop()
+	}
+
 	ms := -1
 	if timeout >= 0 {
 		ms = int(timeout.Milliseconds())
 	}
+	if p.wake >= 0 {
+		// the driver has an event due, round up to not wake too early
+		if wake := int((p.wake + time.Millisecond - 1) / time.Millisecond); ms < 0 || wake < ms {
+			ms = wake
+		}
+	}
 
 	for {
 		n, err := unix.Poll(fds, ms)
@@ -83,6 +228,10 @@ func (p *poller[T]) WaitReadable(timeout time.Duration) error {
 			return nil
 		}
 
+		if len(fds) > 1 && fds[1].Revents != 0 {
+			return ctx.Err()
+		}
+
 		re := fds[0].Revents
 		if re&(unix.POLLERR|unix.POLLHUP|unix.POLLNVAL) != 0 {
 			return fmt.Errorf("poll revents=%#x", re)
@@ -93,15 +242,20 @@ func (p *poller[T]) WaitReadable(timeout time.Duration) error {
 }
 
 func (p *poller[T]) Wait(timeout time.Duration) (T, error) {
+	deadline := time.Now().Add(timeout)
 	for {
 		if p.wait {
-			if err := p.WaitReadable(timeout); err != nil {
+			wait := timeout
+			if timeout >= 0 {
+				wait = max(time.Until(deadline), 0)
+			}
+			if err := p.WaitReadable(wait); err != nil {
 				var zero T
 				return zero, err
 			}
 		}
 
-		ev, more, err := p.drv.Poll()
+		ev, more, err := p.poll()
 		switch {
 		case err == nil:
 			p.wait = !more
@@ -110,6 +264,10 @@ func (p *poller[T]) Wait(timeout time.Duration) (T, error) {
 		case errors.Is(err, ErrWouldBlock):
 			// nothing available now; next iteration should wait for readability.
 			p.wait = true
+			if timeout >= 0 && p.fd >= 0 && !time.Now().Before(deadline) {
+				var zero T
+				return zero, os.ErrDeadlineExceeded
+			}
 			continue
 
 		default:
@@ -120,36 +278,87 @@ func (p *poller[T]) Wait(timeout time.Duration) (T, error) {
 	}
 }
 
-func (p *poller[T]) drain(yield func(T)) {
+func (p *poller[T]) WaitContext(ctx context.Context) (T, error) {
 	for {
-		ev, more, err := p.drv.Poll()
+		if p.wait {
+			if err := p.waitReadable(ctx, -1); err != nil {
+				var zero T
+				return zero, err
+			}
+		}
+
+		ev, more, err := p.poll()
+		switch {
+		case err == nil:
+			p.wait = !more
+			return ev, nil
+
+		case errors.Is(err, ErrWouldBlock):
+			p.wait = true
+			continue
+
+		default:
+			var zero T
+			return zero, err
+		}
+	}
+}
+
+// drain yields all immediately available events. It returns io.EOF after an EventGone and the
+// error of the driver on failure.
+func (p *poller[T]) drain(ctx context.Context, yield func(T)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ev, more, err := p.poll()
 		switch {
 		case err == nil:
 			yield(ev)
+			if _, gone := any(ev).(*wiimote.EventGone); gone {
+				return io.EOF
+			}
 			if !more {
-				return
+				return nil
 			}
-			continue
 
 		case errors.Is(err, ErrWouldBlock):
-			return
+			return nil
 
 		default:
-			log.Printf("error while polling for event: %v", err)
-			return
+			return err
 		}
 	}
 }
 
 func (p *poller[T]) Handle(yield func(T)) error {
+	return p.HandleContext(context.Background(), yield)
+}
+
+func (p *poller[T]) HandleContext(ctx context.Context, yield func(T)) error {
 	for {
-		p.drain(yield)
-		if err := p.WaitReadable(-1); err != nil {
+		if err := p.drain(ctx, yield); err != nil {
+			// the device is gone, a removed device fails with ENODEV
+			if errors.Is(err, io.EOF) || errors.Is(err, unix.ENODEV) {
+				return nil
+			}
+			return err
+		}
+		if err := p.waitReadable(ctx, -1); err != nil {
 			return err
 		}
 	}
 }
 
-func (p *poller[T]) Stream(ch chan<- T) {
-	p.Handle(func(ev T) { ch <- ev })
+func (p *poller[T]) Stream(ch chan<- T) error {
+	return p.StreamContext(context.Background(), ch)
+}
+
+func (p *poller[T]) StreamContext(ctx context.Context, ch chan<- T) error {
+	return p.HandleContext(ctx, func(ev T) {
+		select {
+		case ch <- ev:
+		case <-ctx.Done():
+		}
+	})
 }
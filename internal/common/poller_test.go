@@ -1,10 +1,14 @@
 package common
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"golang.org/x/sys/unix"
 )
 
 type pollStep[T any] struct {
@@ -101,3 +105,186 @@ func TestPollerWait_ContKeepsDontWaitTrue(t *testing.T) {
 		t.Fatalf("expected FD() still not called, got %d", d.fdCalls)
 	}
 }
+
+func TestPollerWaitContext_Cancel(t *testing.T) {
+	var pipe [2]int
+	if err := unix.Pipe2(pipe[:], unix.O_CLOEXEC|unix.O_NONBLOCK); err != nil {
+		t.Fatalf("unable to create pipe: %v", err)
+	}
+	defer unix.Close(pipe[0])
+	defer unix.Close(pipe[1])
+
+	d := &fakeDriver[int]{
+		fd: pipe[0],
+		steps: []pollStep[int]{
+			{ev: 0, cont: false, err: ErrWouldBlock},
+		},
+	}
+	p := NewPoller(d)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := p.WaitContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// a readable fd is reported after a previous context was cancelled
+	d.steps = append(d.steps, pollStep[int]{ev: 7})
+	unix.Write(pipe[1], []byte{0})
+	ev, err := p.WaitContext(context.Background())
+	if err != nil || ev != 7 {
+		t.Fatalf("expected (7,nil), got (%v,%v)", ev, err)
+	}
+}
+
+func TestPollerHandle_StopsOnGone(t *testing.T) {
+	d := &fakeDriver[wiimote.Event]{
+		fd: -1,
+		steps: []pollStep[wiimote.Event]{
+			{ev: &wiimote.EventKey{}, cont: true},
+			{ev: &wiimote.EventGone{}},
+			{ev: &wiimote.EventKey{}},
+		},
+	}
+	p := NewPoller(d)
+
+	var n int
+	if err := p.Handle(func(wiimote.Event) { n++ }); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 events, got %d", n)
+	}
+}
+
+func TestPollerHandle_ReturnsError(t *testing.T) {
+	errFail := errors.New("fail")
+	d := &fakeDriver[int]{
+		fd:    -1,
+		steps: []pollStep[int]{{ev: 1, cont: true}, {err: errFail}},
+	}
+	if err := NewPoller(d).Handle(func(int) {}); !errors.Is(err, errFail) {
+		t.Fatalf("expected %v, got %v", errFail, err)
+	}
+
+	d = &fakeDriver[int]{fd: -1, steps: []pollStep[int]{{err: unix.ENODEV}}}
+	if err := NewPoller(d).Handle(func(int) {}); err != nil {
+		t.Fatalf("expected nil err for removed device, got %v", err)
+	}
+}
+
+func TestPollerStreamContext_Cancel(t *testing.T) {
+	d := &fakeDriver[int]{fd: -1, steps: []pollStep[int]{{ev: 1, cont: true}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// nobody reads ch, the stream must stop anyway
+	ch := make(chan int)
+	if err := NewPoller(d).StreamContext(ctx, ch); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestPollerOptions_Backoff(t *testing.T) {
+	p := &poller[int]{opts: wiimote.DefaultPollerOptions}
+	p.SetOptions(wiimote.WithRetryDelay(time.Millisecond), wiimote.WithBackoff(5*time.Millisecond, 0))
+	var got []time.Duration
+	for range 5 {
+		got = append(got, p.retryDelay())
+	}
+	want := []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond, 5 * time.Millisecond}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected delays %v, got %v", want, got)
+		}
+	}
+
+	p.SetOptions(wiimote.WithBackoff(0, 0.5))
+	for range 10 {
+		if d := p.retryDelay(); d < time.Millisecond/2 || d > 3*time.Millisecond/2 {
+			t.Fatalf("expected jittered delay around 1ms, got %v", d)
+		}
+	}
+}
+
+func TestPollerOptions_BusyPoll(t *testing.T) {
+	d := &fakeDriver[int]{fd: -1}
+	for range 50 {
+		d.steps = append(d.steps, pollStep[int]{err: ErrWouldBlock})
+	}
+	d.steps = append(d.steps, pollStep[int]{ev: 3})
+	p := NewPoller(d)
+	p.SetOptions(wiimote.WithRetryDelay(0))
+
+	start := time.Now()
+	if ev, err := p.Wait(-1); err != nil || ev != 3 {
+		t.Fatalf("expected (3,nil), got (%v,%v)", ev, err)
+	}
+	if time.Since(start) > 10*time.Millisecond {
+		t.Fatalf("expected busy-polling to be fast, took %v", time.Since(start))
+	}
+}
+
+func TestPollerWaitContext_CancelRetry(t *testing.T) {
+	var pipe [2]int
+	if err := unix.Pipe2(pipe[:], unix.O_CLOEXEC|unix.O_NONBLOCK); err != nil {
+		t.Fatalf("unable to create pipe: %v", err)
+	}
+	defer unix.Close(pipe[0])
+	defer unix.Close(pipe[1])
+	// the fd stays readable, only the retry delay holds back polling
+	unix.Write(pipe[1], []byte{0})
+
+	d := &fakeDriver[int]{
+		fd: pipe[0],
+		steps: []pollStep[int]{
+			{err: RetryAfter{Delay: time.Hour}},
+			{ev: 7},
+		},
+	}
+	p := NewPoller(d)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := p.WaitContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("expected the retry delay to be cancelled, took %v", time.Since(start))
+	}
+	if d.pollCalls != 1 {
+		t.Fatalf("expected no poll during the retry delay, got %d calls", d.pollCalls)
+	}
+}
+
+func TestPollerWait_WakeAfter(t *testing.T) {
+	var pipe [2]int
+	if err := unix.Pipe2(pipe[:], unix.O_CLOEXEC|unix.O_NONBLOCK); err != nil {
+		t.Fatalf("unable to create pipe: %v", err)
+	}
+	defer unix.Close(pipe[0])
+	defer unix.Close(pipe[1])
+
+	// the fd never becomes readable, the event is due after the wake
+	d := &fakeDriver[int]{
+		fd: pipe[0],
+		steps: []pollStep[int]{
+			{ev: 0, cont: false, err: WakeAfter{Delay: 20 * time.Millisecond}},
+			{ev: 42, cont: false, err: nil},
+		},
+	}
+	p := NewPoller(d)
+
+	start := time.Now()
+	ev, err := p.Wait(time.Second)
+	if err != nil || ev != 42 {
+		t.Fatalf("expected (42,nil), got (%v,%v)", ev, err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("expected to be woken after 20ms, took %v", elapsed)
+	}
+}
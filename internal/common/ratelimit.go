@@ -0,0 +1,145 @@
+package common
+
+import (
+	"errors"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// RateLimit coalesces the samples of features limited by wiimote.Device.SetMaxRate, only the
+// newest sample is reported once per interval. A sample arriving within the interval is kept
+// back and reported when the interval passed, unless a newer sample replaces it.
+type RateLimit struct {
+	interval map[wiimote.FeatureKind]time.Duration
+	// time of the next sample reported per feature
+	next map[wiimote.FeatureKind]time.Time
+	// newest sample kept back per feature
+	pending map[wiimote.FeatureKind]wiimote.Event
+}
+
+// SetMaxRate limits the samples of kind to hz per second, hz <= 0 removes the limit.
+func (l *RateLimit) SetMaxRate(kind wiimote.FeatureKind, hz float64) {
+	if hz <= 0 {
+		delete(l.interval, kind)
+		delete(l.next, kind)
+		delete(l.pending, kind)
+		return
+	}
+	if l.interval == nil {
+		l.interval = make(map[wiimote.FeatureKind]time.Duration)
+		l.next = make(map[wiimote.FeatureKind]time.Time)
+		l.pending = make(map[wiimote.FeatureKind]wiimote.Event)
+	}
+	l.interval[kind] = time.Duration(float64(time.Second) / hz)
+	delete(l.next, kind)
+	delete(l.pending, kind)
+}
+
+// Drop reports whether ev is a sample arriving before the interval of its feature passed. A
+// dropped sample is kept back, see Due.
+func (l *RateLimit) Drop(ev wiimote.Event) bool {
+	if len(l.interval) == 0 || !isSample(ev) {
+		return false
+	}
+	f := ev.Feature()
+	if f == nil {
+		return false
+	}
+	interval, ok := l.interval[f.Kind()]
+	if !ok {
+		return false
+	}
+	t := ev.Timestamp()
+	next, ok := l.next[f.Kind()]
+	if ok && t.Before(next) {
+		l.pending[f.Kind()] = ev
+		return true
+	}
+	delete(l.pending, f.Kind())
+	// keep the rate if samples arrive late, but do not catch up after a gap
+	if !ok || t.Sub(next) >= interval {
+		next = t
+	}
+	l.next[f.Kind()] = next.Add(interval)
+	return false
+}
+
+// Due returns a kept back sample whose interval passed at now.
+func (l *RateLimit) Due(now time.Time) (wiimote.Event, bool) {
+	for kind, ev := range l.pending {
+		next := l.next[kind]
+		if now.Before(next) {
+			continue
+		}
+		delete(l.pending, kind)
+		l.next[kind] = next.Add(l.interval[kind])
+		return ev, true
+	}
+	return nil, false
+}
+
+// Wait returns the time until the next kept back sample is due, false if there is none.
+func (l *RateLimit) Wait(now time.Time) (time.Duration, bool) {
+	var wait time.Duration
+	found := false
+	for kind := range l.pending {
+		if d := l.next[kind].Sub(now); !found || d < wait {
+			wait, found = d, true
+		}
+	}
+	return max(wait, 0), found
+}
+
+// Poll returns the next event of poll to report. Every event which is not skipped is passed to
+// update, also samples which are kept back, so the state of the device stays current. Kept back
+// samples are reported when their interval passed; until then, the poller is woken when the next
+// one is due, see WakeAfter. skip may be nil.
+func (l *RateLimit) Poll(poll func() (wiimote.Event, bool, error), update func(wiimote.Event), skip func(wiimote.Event) bool) (wiimote.Event, bool, error) {
+	now := time.Now()
+	if ev, ok := l.Due(now); ok {
+		return ev, true, nil
+	}
+	for {
+		ev, more, err := poll()
+		if ev == nil {
+			return nil, more, l.wake(err, now)
+		}
+		if skip != nil && skip(ev) {
+			if !more {
+				return nil, false, l.wake(ErrWouldBlock, now)
+			}
+			continue
+		}
+		update(ev)
+		if !l.Drop(ev) {
+			return ev, more, err
+		}
+		if !more {
+			return nil, false, l.wake(ErrWouldBlock, now)
+		}
+	}
+}
+
+// wake replaces ErrWouldBlock by WakeAfter if a sample is kept back.
+func (l *RateLimit) wake(err error, now time.Time) error {
+	var retry RetryAfter
+	if !errors.Is(err, ErrWouldBlock) || errors.As(err, &retry) {
+		return err
+	}
+	if d, ok := l.Wait(now); ok {
+		return WakeAfter{Delay: d}
+	}
+	return err
+}
+
+// isSample reports whether ev reports a measurement which is superseded by the next one.
+func isSample(ev wiimote.Event) bool {
+	switch ev.(type) {
+	case *wiimote.EventAccel, *wiimote.EventIR, *wiimote.EventMotionPlus, *wiimote.EventBalanceBoard,
+		*wiimote.EventNunchukMove, *wiimote.EventClassicControllerMove, *wiimote.EventProControllerMove,
+		*wiimote.EventDrumsMove, *wiimote.EventGuitarMove:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+type fakeFeature struct {
+	kind wiimote.FeatureKind
+}
+
+func (f fakeFeature) Kind() wiimote.FeatureKind { return f.kind }
+func (f fakeFeature) Device() wiimote.Device    { return nil }
+func (f fakeFeature) Opened() bool              { return true }
+func (f fakeFeature) Close() error              { return nil }
+
+type fakeEvent struct {
+	kind wiimote.FeatureKind
+	t    time.Time
+}
+
+func (e fakeEvent) Feature() wiimote.Feature { return fakeFeature{e.kind} }
+func (e fakeEvent) Device() wiimote.Device   { return nil }
+func (e fakeEvent) Timestamp() time.Time     { return e.t }
+
+func TestRateLimit(t *testing.T) {
+	var l RateLimit
+	l.SetMaxRate(wiimote.FeatureAccel, 25)
+
+	start := time.Unix(0, 0)
+	var reported int
+	// one second of 100Hz samples
+	for i := range 100 {
+		ts := start.Add(time.Duration(i) * 10 * time.Millisecond)
+		if !l.Drop(&wiimote.EventAccel{Event: fakeEvent{wiimote.FeatureAccel, ts}}) {
+			reported++
+		}
+		// other features and keys are not limited
+		if l.Drop(&wiimote.EventIR{Event: fakeEvent{wiimote.FeatureIR, ts}}) {
+			t.Fatalf("expected IR not to be limited")
+		}
+		if l.Drop(&wiimote.EventKey{Event: fakeEvent{wiimote.FeatureAccel, ts}}) {
+			t.Fatalf("expected keys not to be limited")
+		}
+	}
+	if reported != 25 {
+		t.Errorf("expected 25 samples, got %d", reported)
+	}
+
+	l.SetMaxRate(wiimote.FeatureAccel, 0)
+	if l.Drop(&wiimote.EventAccel{Event: fakeEvent{wiimote.FeatureAccel, start}}) {
+		t.Errorf("expected the limit to be removed")
+	}
+}
+
+func TestRateLimitKeepsLastSample(t *testing.T) {
+	var l RateLimit
+	l.SetMaxRate(wiimote.FeatureAccel, 25)
+
+	now := time.Now()
+	var queue []wiimote.Event
+	for i := range 3 {
+		ts := now.Add(time.Duration(i) * 10 * time.Millisecond)
+		queue = append(queue, &wiimote.EventAccel{Event: fakeEvent{wiimote.FeatureAccel, ts}, Accel: wiimote.Vec3{X: int32(i)}})
+	}
+	poll := func() (wiimote.Event, bool, error) {
+		if len(queue) == 0 {
+			return nil, false, ErrWouldBlock
+		}
+		ev := queue[0]
+		queue = queue[1:]
+		return ev, len(queue) > 0, nil
+	}
+	var updates int
+	update := func(wiimote.Event) { updates++ }
+
+	ev, _, err := l.Poll(poll, update, nil)
+	if err != nil || ev.(*wiimote.EventAccel).Accel.X != 0 {
+		t.Fatalf("expected first sample, got %v %v", ev, err)
+	}
+	// the last two samples arrive within the interval, the newest is kept back
+	_, _, err = l.Poll(poll, update, nil)
+	var wake WakeAfter
+	if !errors.As(err, &wake) || !errors.Is(err, ErrWouldBlock) || wake.Delay > 40*time.Millisecond {
+		t.Fatalf("expected a wake within the interval, got %v", err)
+	}
+	if updates != 3 {
+		t.Errorf("expected the state to be updated with every sample, got %d updates", updates)
+	}
+
+	time.Sleep(wake.Delay)
+	ev, _, err = l.Poll(poll, update, nil)
+	if err != nil || ev.(*wiimote.EventAccel).Accel.X != 2 {
+		t.Fatalf("expected the last sample after the interval, got %v %v", ev, err)
+	}
+	if _, _, err := l.Poll(poll, update, nil); err != ErrWouldBlock {
+		t.Errorf("expected %v without kept back samples, got %v", ErrWouldBlock, err)
+	}
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// StateBuffer holds the state of a device. It is updated by the goroutine polling the device
+// and can be read from any goroutine.
+type StateBuffer struct {
+	m     sync.Mutex
+	state wiimote.State
+}
+
+// Update applies ev to the state.
+func (b *StateBuffer) Update(ev wiimote.Event) {
+	if ev == nil {
+		return
+	}
+	b.m.Lock()
+	b.state.Update(ev)
+	b.m.Unlock()
+}
+
+// State returns a copy of the state.
+func (b *StateBuffer) State() wiimote.State {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.state
+}
+
+// Capabilities returns the capabilities of dev derived from its device type and available
+// features, see wiimote.CapabilitiesOf.
+func Capabilities(dev wiimote.Device) wiimote.Capabilities {
+	var available wiimote.FeatureKind
+	for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+		if dev.Available(kind) {
+			available |= kind
+		}
+	}
+	devtype, _ := dev.DevType()
+	return wiimote.CapabilitiesOf(devtype, available)
+}
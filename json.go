@@ -0,0 +1,283 @@
+package wiimote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var eventType = reflect.TypeFor[Event]()
+
+// eventTypeName returns the type tag of an event type, e.g. "classic_controller_key" for
+// EventClassicControllerKey.
+func eventTypeName(t reflect.Type) string {
+	name := strings.TrimPrefix(t.Name(), "Event")
+	var w strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		// start a new word at an upper-case letter, unless it continues an acronym
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			w.WriteByte('_')
+		}
+		w.WriteRune(unicode.ToLower(r))
+	}
+	return w.String()
+}
+
+// innerEvent returns the Event embedded in v, nil if it is not set.
+func innerEvent(v reflect.Value) Event {
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.Anonymous {
+			continue
+		}
+		if f.Type == eventType {
+			ev, _ := v.Field(i).Interface().(Event)
+			return ev
+		}
+		if f.Type.Kind() == reflect.Struct {
+			if ev := innerEvent(v.Field(i)); ev != nil {
+				return ev
+			}
+		}
+	}
+	return nil
+}
+
+type jsonObject struct {
+	buf   bytes.Buffer
+	first bool
+}
+
+func (o *jsonObject) field(name string, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if !o.first {
+		o.buf.WriteByte(',')
+	}
+	o.first = false
+	key, _ := json.Marshal(name)
+	o.buf.Write(key)
+	o.buf.WriteByte(':')
+	o.buf.Write(b)
+	return nil
+}
+
+// fields writes the exported fields of v, flattening embedded structs and skipping the
+// embedded Event.
+func (o *jsonObject) fields(v reflect.Value) error {
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() || f.Type == eventType {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := o.fields(v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		value := v.Field(i).Interface()
+		switch x := value.(type) {
+		case Key:
+			value = KeyName(x)
+		case FeatureKind:
+			value = x.String()
+		case error:
+			value = x.Error()
+		}
+		if err := o.field(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalEvent encodes ev as JSON object with its type tag (e.g. "key" for EventKey), timestamp,
+// source and payload. All events of this package implement json.Marshaler using MarshalEvent,
+// other packages may do so for their own events.
+func MarshalEvent(ev Event) ([]byte, error) {
+	v := reflect.ValueOf(ev)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return json.Marshal(ev)
+	}
+
+	o := jsonObject{first: true}
+	o.buf.WriteByte('{')
+	o.field("type", eventTypeName(v.Type()))
+	if inner := innerEvent(v); inner != nil {
+		o.field("timestamp", inner.Timestamp().Format(time.RFC3339Nano))
+		src := EventSource(ev)
+		if src.Feature != 0 {
+			o.field("feature", src.Feature.String())
+		}
+		if src.UniqueID != "" {
+			o.field("unique_id", src.UniqueID)
+		}
+		if src.Player != 0 {
+			o.field("player", src.Player)
+		}
+	}
+	if err := o.fields(v); err != nil {
+		return nil, err
+	}
+	o.buf.WriteByte('}')
+	return o.buf.Bytes(), nil
+}
+
+func (ev EventKey) MarshalJSON() ([]byte, error)                   { return MarshalEvent(&ev) }
+func (ev EventAccel) MarshalJSON() ([]byte, error)                 { return MarshalEvent(&ev) }
+func (ev EventIR) MarshalJSON() ([]byte, error)                    { return MarshalEvent(&ev) }
+func (ev EventBalanceBoard) MarshalJSON() ([]byte, error)          { return MarshalEvent(&ev) }
+func (ev EventMotionPlus) MarshalJSON() ([]byte, error)            { return MarshalEvent(&ev) }
+func (ev EventProControllerKey) MarshalJSON() ([]byte, error)      { return MarshalEvent(&ev) }
+func (ev EventProControllerMove) MarshalJSON() ([]byte, error)     { return MarshalEvent(&ev) }
+func (ev EventWatch) MarshalJSON() ([]byte, error)                 { return MarshalEvent(&ev) }
+func (ev EventExtensionConnected) MarshalJSON() ([]byte, error)    { return MarshalEvent(&ev) }
+func (ev EventExtensionDisconnected) MarshalJSON() ([]byte, error) { return MarshalEvent(&ev) }
+func (ev EventClassicControllerKey) MarshalJSON() ([]byte, error)  { return MarshalEvent(&ev) }
+func (ev EventClassicControllerMove) MarshalJSON() ([]byte, error) { return MarshalEvent(&ev) }
+func (ev EventNunchukKey) MarshalJSON() ([]byte, error)            { return MarshalEvent(&ev) }
+func (ev EventNunchukMove) MarshalJSON() ([]byte, error)           { return MarshalEvent(&ev) }
+func (ev EventDrumsKey) MarshalJSON() ([]byte, error)              { return MarshalEvent(&ev) }
+func (ev EventDrumsMove) MarshalJSON() ([]byte, error)             { return MarshalEvent(&ev) }
+func (ev EventGuitarKey) MarshalJSON() ([]byte, error)             { return MarshalEvent(&ev) }
+func (ev EventGuitarMove) MarshalJSON() ([]byte, error)            { return MarshalEvent(&ev) }
+func (ev EventFeature) MarshalJSON() ([]byte, error)               { return MarshalEvent(&ev) }
+func (ev EventFeatureOpened) MarshalJSON() ([]byte, error)         { return MarshalEvent(&ev) }
+func (ev EventError) MarshalJSON() ([]byte, error)                 { return MarshalEvent(&ev) }
+func (ev EventGone) MarshalJSON() ([]byte, error)                  { return MarshalEvent(&ev) }
+
+// eventTypes maps the type tags to the events of this package.
+var eventTypes = func() map[string]reflect.Type {
+	types := make(map[string]reflect.Type)
+	for _, ev := range []Event{
+		&EventKey{}, &EventAccel{}, &EventIR{}, &EventBalanceBoard{}, &EventMotionPlus{},
+		&EventProControllerKey{}, &EventProControllerMove{}, &EventWatch{},
+		&EventExtensionConnected{}, &EventExtensionDisconnected{},
+		&EventClassicControllerKey{}, &EventClassicControllerMove{},
+		&EventNunchukKey{}, &EventNunchukMove{}, &EventDrumsKey{}, &EventDrumsMove{},
+		&EventGuitarKey{}, &EventGuitarMove{}, &EventFeature{}, &EventFeatureOpened{},
+		&EventError{}, &EventGone{},
+	} {
+		t := reflect.TypeOf(ev).Elem()
+		types[eventTypeName(t)] = t
+	}
+	return types
+}()
+
+type decodedEvent struct {
+	timestamp time.Time
+}
+
+func (decodedEvent) Feature() Feature       { return nil }
+func (decodedEvent) Device() Device         { return nil }
+func (e decodedEvent) Timestamp() time.Time { return e.timestamp }
+
+// setFields is the inverse of jsonObject.fields.
+func setFields(v reflect.Value, obj map[string]json.RawMessage, inner Event) error {
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Type == eventType {
+			v.Field(i).Set(reflect.ValueOf(&inner).Elem())
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := setFields(v.Field(i), obj, inner); err != nil {
+				return err
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		raw, ok := obj[name]
+		if !ok {
+			continue
+		}
+		field := v.Field(i).Addr().Interface()
+		switch field := field.(type) {
+		case *Key, *FeatureKind, *error:
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return err
+			}
+			switch field := field.(type) {
+			case *Key:
+				*field, ok = LookupKey(s)
+			case *FeatureKind:
+				*field, ok = LookupFeatureKind(s)
+			case *error:
+				*field, ok = errors.New(s), true
+			}
+			if !ok {
+				return fmt.Errorf("invalid %s: %q", name, s)
+			}
+		default:
+			if err := json.Unmarshal(raw, field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalEvent decodes an event encoded by MarshalEvent. The embedded Event of the decoded
+// event is created by inner from the timestamp and feature kind of the encoded event, if inner
+// is nil its Feature returns nil.
+func UnmarshalEvent(data []byte, inner func(ts time.Time, kind FeatureKind) Event) (Event, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	var head struct {
+		Type      string    `json:"type"`
+		Timestamp time.Time `json:"timestamp"`
+		Feature   string    `json:"feature"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+	t, ok := eventTypes[head.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", head.Type)
+	}
+	kind, _ := LookupFeatureKind(head.Feature)
+	var ev Event = decodedEvent{head.Timestamp}
+	if inner != nil {
+		ev = inner(head.Timestamp, kind)
+	}
+	v := reflect.New(t)
+	if err := setFields(v.Elem(), obj, ev); err != nil {
+		return nil, err
+	}
+	return v.Interface().(Event), nil
+}
@@ -0,0 +1,71 @@
+package wiimote
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMarshalEvent(t *testing.T) {
+	tests := []struct {
+		ev     Event
+		expect string
+	}{
+		{&EventKey{Event: testEvent{}, Code: KeyA, Pressed: true},
+			`{"type":"key","timestamp":"0001-01-01T00:00:00Z","code":"KEY_A","pressed":true}`},
+		{&EventClassicControllerKey{EventKey{Event: testEvent{}, Code: KeyX}},
+			`{"type":"classic_controller_key","timestamp":"0001-01-01T00:00:00Z","code":"KEY_X","pressed":false}`},
+		{&EventAccel{Accel: Vec3{X: 1, Y: 2, Z: 3}},
+			`{"type":"accel","accel":{"x":1,"y":2,"z":3}}`},
+		{&EventFeature{Kind: FeatureNunchuck, Removed: true},
+			`{"type":"feature","Kind":"FeatureNunchuck","Removed":true}`},
+		{&EventError{Err: errors.New("boom")},
+			`{"type":"error","Err":"boom"}`},
+	}
+	for _, tc := range tests {
+		b, err := json.Marshal(tc.ev)
+		if err != nil {
+			t.Fatalf("unable to marshal %T: %v", tc.ev, err)
+		}
+		if string(b) != tc.expect {
+			t.Errorf("%T: expected %s, got %s", tc.ev, tc.expect, b)
+		}
+	}
+}
+
+func TestEventTypeName(t *testing.T) {
+	for ev, expect := range map[Event]string{
+		&EventIR{}:                 "ir",
+		&EventMotionPlus{}:         "motion_plus",
+		&EventProControllerMove{}:  "pro_controller_move",
+		&EventExtensionConnected{}: "extension_connected",
+	} {
+		b, _ := MarshalEvent(ev)
+		var v struct{ Type string }
+		json.Unmarshal(b, &v)
+		if v.Type != expect {
+			t.Errorf("%T: expected %q, got %q", ev, expect, v.Type)
+		}
+	}
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	for _, ev := range []Event{
+		&EventNunchukKey{EventKey{Event: testEvent{}, Code: KeyZ, Pressed: true}},
+		&EventIR{Event: testEvent{}, Slots: [4]IRSlot{{Vec2: Vec2{X: 1, Y: 2}, Size: 3}}},
+		&EventFeature{Event: testEvent{}, Kind: FeatureGuitar},
+	} {
+		b, err := MarshalEvent(ev)
+		if err != nil {
+			t.Fatalf("unable to marshal %T: %v", ev, err)
+		}
+		got, err := UnmarshalEvent(b, nil)
+		if err != nil {
+			t.Fatalf("unable to unmarshal %s: %v", b, err)
+		}
+		b2, _ := MarshalEvent(got)
+		if string(b) != string(b2) {
+			t.Errorf("expected %s, got %s", b, b2)
+		}
+	}
+}
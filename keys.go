@@ -0,0 +1,89 @@
+package wiimote
+
+import "strings"
+
+// KeyName returns the name of k as accepted by LookupKey and ParseKey, e.g. "KEY_HOME".
+func KeyName(k Key) string {
+	return k.String()
+}
+
+// ParseKey is like LookupKey but is case-insensitive and accepts names without the "KEY_"
+// prefix, e.g. "home" or "KEY_HOME".
+func ParseKey(name string) (Key, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if key, ok := LookupKey(name); ok {
+		return key, true
+	}
+	return LookupKey("KEY_" + name)
+}
+
+// KeyInfo describes a key for listings, e.g. in a mapping editor.
+type KeyInfo struct {
+	Key Key
+	// Name is the name of the key, see KeyName
+	Name string
+	// Features are the features which emit the key, e.g. KeyA is emitted by the core, classic
+	// controller and pro controller features
+	Features FeatureKind
+}
+
+// keyFeatures holds the features emitting each key, as reported by the kernel driver.
+var keyFeatures = [...]FeatureKind{
+	KeyLeft:         FeatureCore | FeatureClassicController | FeatureProController,
+	KeyRight:        FeatureCore | FeatureClassicController | FeatureProController,
+	KeyUp:           FeatureCore | FeatureClassicController | FeatureProController,
+	KeyDown:         FeatureCore | FeatureClassicController | FeatureProController,
+	KeyA:            FeatureCore | FeatureClassicController | FeatureProController,
+	KeyB:            FeatureCore | FeatureClassicController | FeatureProController,
+	KeyPlus:         FeatureCore | FeatureClassicController | FeatureProController | FeatureDrums | FeatureGuitar,
+	KeyMinus:        FeatureCore | FeatureClassicController | FeatureProController | FeatureDrums,
+	KeyHome:         FeatureCore | FeatureClassicController | FeatureProController | FeatureGuitar,
+	KeyOne:          FeatureCore,
+	KeyTwo:          FeatureCore,
+	KeyX:            FeatureClassicController | FeatureProController,
+	KeyY:            FeatureClassicController | FeatureProController,
+	KeyTL:           FeatureClassicController | FeatureProController,
+	KeyTR:           FeatureClassicController | FeatureProController,
+	KeyZL:           FeatureClassicController | FeatureProController,
+	KeyZR:           FeatureClassicController | FeatureProController,
+	KeyThumbL:       FeatureProController,
+	KeyThumbR:       FeatureProController,
+	KeyC:            FeatureNunchuck,
+	KeyZ:            FeatureNunchuck,
+	KeyStrumBarUp:   FeatureGuitar,
+	KeyStrumBarDown: FeatureGuitar,
+	KeyFretFarUp:    FeatureGuitar,
+	KeyFretUp:       FeatureGuitar,
+	KeyFretMid:      FeatureGuitar,
+	KeyFretLow:      FeatureGuitar,
+	KeyFretFarLow:   FeatureGuitar,
+}
+
+// AllWiiKeys returns all keys in order of their value.
+func AllWiiKeys() []KeyInfo {
+	keys := make([]KeyInfo, len(keyFeatures))
+	for i, features := range keyFeatures {
+		keys[i] = KeyInfo{Key: Key(i), Name: KeyName(Key(i)), Features: features}
+	}
+	return keys
+}
+
+// KeyFeatures returns the features which emit key, 0 if key is unknown.
+func KeyFeatures(key Key) FeatureKind {
+	if int(key) >= len(keyFeatures) {
+		return 0
+	}
+	return keyFeatures[key]
+}
+
+// KeysOf returns the keys emitted by any of the features in kinds, in order of their value. It
+// is used to group keys by feature, e.g. KeysOf(FeatureNunchuck) returns KeyC and KeyZ.
+func KeysOf(kinds FeatureKind) []Key {
+	var keys []Key
+	for i, features := range keyFeatures {
+		if features&kinds != 0 {
+			keys = append(keys, Key(i))
+		}
+	}
+	return keys
+}
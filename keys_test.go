@@ -0,0 +1,46 @@
+package wiimote
+
+import "testing"
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  Key
+		ok   bool
+	}{
+		{"KEY_HOME", KeyHome, true},
+		{"home", KeyHome, true},
+		{" t_l ", KeyTL, true},
+		{"key_strum_bar_up", KeyStrumBarUp, true},
+		{"KEY_NOPE", 0, false},
+	}
+	for _, tc := range tests {
+		key, ok := ParseKey(tc.name)
+		if ok != tc.ok || key != tc.key {
+			t.Errorf("%q: expected %v %v, got %v %v", tc.name, tc.key, tc.ok, key, ok)
+		}
+	}
+	for key := KeyLeft; key <= KeyFretFarLow; key++ {
+		if got, ok := ParseKey(KeyName(key)); !ok || got != key {
+			t.Errorf("%v: name %q does not round-trip", key, KeyName(key))
+		}
+	}
+}
+
+func TestAllWiiKeys(t *testing.T) {
+	keys := AllWiiKeys()
+	if len(keys) != int(KeyFretFarLow)+1 {
+		t.Fatalf("expected %d keys, got %d", KeyFretFarLow+1, len(keys))
+	}
+	for i, info := range keys {
+		if info.Key != Key(i) || info.Name != KeyName(info.Key) || info.Features == 0 {
+			t.Errorf("unexpected key %+v", info)
+		}
+	}
+	if got := KeysOf(FeatureNunchuck); len(got) != 2 || got[0] != KeyC || got[1] != KeyZ {
+		t.Errorf("expected nunchuk keys C and Z, got %v", got)
+	}
+	if KeyFeatures(KeyOne) != FeatureCore || KeyFeatures(KeyFretFarLow+1) != 0 {
+		t.Errorf("unexpected features of KeyOne or unknown key")
+	}
+}
@@ -0,0 +1,27 @@
+package wiimote
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// LevelTrace is the log level of very verbose messages, like every dispatched event.
+const LevelTrace = slog.LevelDebug - 4
+
+var logger atomic.Pointer[slog.Logger]
+
+// SetLogger sets the logger used by this module and its drivers. The logger receives
+// messages about opened and closed devices, hotplugged features and pointer decisions at
+// slog.LevelDebug, and every dispatched event at LevelTrace. If l is nil, slog.Default is
+// used, which is also the initial state.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// Logger returns the logger set using SetLogger.
+func Logger() *slog.Logger {
+	if l := logger.Load(); l != nil {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,85 @@
+package wiimote
+
+import "path"
+
+// DeviceMatcher is a predicate on devices evaluated in Go. Matchers are combined using And, Or
+// and Not, see DeviceEnumerator.Match.
+type DeviceMatcher func(dev DeviceInfo) bool
+
+// glob matches value against the shell pattern, an invalid pattern matches nothing.
+func glob(pattern, value string) bool {
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// MatchSubsystem matches devices of subsystem.
+func MatchSubsystem(subsystem string) DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		return dev.Subsystem() == subsystem
+	}
+}
+
+// MatchDriver matches devices bound to driver.
+func MatchDriver(driver string) DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		return dev.Driver() == driver
+	}
+}
+
+// MatchSysname matches devices whose name matches the shell pattern (e.g. "event*").
+func MatchSysname(pattern string) DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		return glob(pattern, dev.Sysname())
+	}
+}
+
+// MatchSysattr matches devices whose sys attribute matches the shell pattern.
+func MatchSysattr(sysattr, pattern string) DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		return glob(pattern, dev.SysattrValue(sysattr))
+	}
+}
+
+// MatchProperty matches devices whose property matches the shell pattern (e.g. HID_NAME, "Nintendo*").
+func MatchProperty(property, pattern string) DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		return glob(pattern, dev.PropertyValue(property))
+	}
+}
+
+// And matches devices matching m and all others.
+func (m DeviceMatcher) And(others ...DeviceMatcher) DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		if !m(dev) {
+			return false
+		}
+		for _, o := range others {
+			if !o(dev) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches devices matching m or any of others.
+func (m DeviceMatcher) Or(others ...DeviceMatcher) DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		if m(dev) {
+			return true
+		}
+		for _, o := range others {
+			if o(dev) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches devices not matching m.
+func (m DeviceMatcher) Not() DeviceMatcher {
+	return func(dev DeviceInfo) bool {
+		return !m(dev)
+	}
+}
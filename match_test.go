@@ -0,0 +1,43 @@
+package wiimote
+
+import "testing"
+
+// fakeDevice is a DeviceInfo with fixed attributes.
+type fakeDevice struct {
+	subsystem, sysname, driver string
+	attrs                      map[string]string
+}
+
+func (d fakeDevice) Parent() DeviceInfo                   { return nil }
+func (d fakeDevice) Subsystem() string                    { return d.subsystem }
+func (d fakeDevice) Sysname() string                      { return d.sysname }
+func (d fakeDevice) Syspath() string                      { return "/sys/devices/" + d.sysname }
+func (d fakeDevice) Devnode() string                      { return "" }
+func (d fakeDevice) Driver() string                       { return d.driver }
+func (d fakeDevice) Action() string                       { return "" }
+func (d fakeDevice) SysattrValue(sysattr string) string   { return d.attrs[sysattr] }
+func (d fakeDevice) PropertyValue(property string) string { return d.attrs[property] }
+
+func TestDeviceMatcher(t *testing.T) {
+	event := fakeDevice{subsystem: "input", sysname: "event3"}
+	remote := fakeDevice{subsystem: "hid", sysname: "0005:057E:0306.0001", driver: "wiimote",
+		attrs: map[string]string{"HID_NAME": "Nintendo RVL-CNT-01", "devtype": "gen10"}}
+
+	tests := []struct {
+		m    DeviceMatcher
+		dev  DeviceInfo
+		want bool
+	}{
+		{MatchSubsystem("input").And(MatchSysname("event*")), event, true},
+		{MatchSubsystem("input").And(MatchSysname("input*")), event, false},
+		{MatchDriver("wiimote").And(MatchProperty("HID_NAME", "Nintendo*")), remote, true},
+		{MatchSysattr("devtype", "gen20").Or(MatchSysattr("devtype", "gen1?")), remote, true},
+		{MatchSysattr("devtype", "gen20").Not(), remote, true},
+		{MatchSysname("["), event, false},
+	}
+	for i, test := range tests {
+		if got := test.m(test.dev); got != test.want {
+			t.Errorf("%d: expected %v, got %v", i, test.want, got)
+		}
+	}
+}
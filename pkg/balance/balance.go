@@ -0,0 +1,226 @@
+// Package balance analyzes the weights of a Wii Balance Board and emits discrete events: a
+// user stepping on or off, jumps and changes of the lean direction.
+package balance
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// UnitsPerKg is the number of units of EventBalanceBoard per kilogram.
+const UnitsPerKg = 100
+
+// Indices of the sensors in EventBalanceBoard.Weights.
+const (
+	TopRight = iota
+	BottomRight
+	TopLeft
+	BottomLeft
+)
+
+// Total returns the total weight on the board in kilograms.
+func Total(weights [4]int32) float64 {
+	return float64(weights[0]+weights[1]+weights[2]+weights[3]) / UnitsPerKg
+}
+
+// Center returns the center of balance in the range -1..1, X is positive to the right and Y
+// towards the top sensors. The center is 0 if the board is empty.
+func Center(weights [4]int32) wiimote.FVec2 {
+	total := float64(weights[0] + weights[1] + weights[2] + weights[3])
+	if total <= 0 {
+		return wiimote.FVec2{}
+	}
+	right := float64(weights[TopRight] + weights[BottomRight])
+	left := float64(weights[TopLeft] + weights[BottomLeft])
+	top := float64(weights[TopRight] + weights[TopLeft])
+	bottom := float64(weights[BottomRight] + weights[BottomLeft])
+	return wiimote.FVec2{X: (right - left) / total, Y: (top - bottom) / total}
+}
+
+// Lean is the direction the user leans to.
+type Lean uint8
+
+const (
+	LeanCenter Lean = iota
+	LeanLeft
+	LeanRight
+	LeanForward
+	LeanBack
+)
+
+var leanNames = [...]string{"center", "left", "right", "forward", "back"}
+
+func (l Lean) String() string {
+	if int(l) < len(leanNames) {
+		return leanNames[l]
+	}
+	return "Lean(" + strconv.Itoa(int(l)) + ")"
+}
+
+// EventStepOn is emitted when a user steps on the board.
+type EventStepOn struct {
+	wiimote.Event
+	// Weight in kilograms
+	Weight float64
+}
+
+// EventStepOff is emitted when the user left the board.
+type EventStepOff struct {
+	wiimote.Event
+}
+
+// EventJump is emitted when the user lands after a jump.
+type EventJump struct {
+	wiimote.Event
+	// Airtime is the time the board was empty
+	Airtime time.Duration
+	// Landing is the weight in kilograms when landing
+	Landing float64
+}
+
+// EventLean is emitted when the lean direction changes.
+type EventLean struct {
+	wiimote.Event
+	Lean Lean
+	// Center of balance, see Center
+	Center wiimote.FVec2
+}
+
+// Analyzer detects events from the weights of a balance board. It is not thread-safe.
+type Analyzer struct {
+	// StepOnWeight is the weight in kilograms from which the board is considered occupied
+	StepOnWeight float64
+	// StepOffTime is the time the board must be empty to report a step-off. A shorter empty
+	// board is a jump.
+	StepOffTime time.Duration
+	// LandingFactor is the factor of the standing weight the weight must spike to after a jump
+	LandingFactor float64
+	// LandingWindow is the time after touching the board again in which the spike must occur
+	LandingWindow time.Duration
+	// LeanThreshold is the offset of the center of balance (0..1) which is reported as lean
+	LeanThreshold float64
+	// LeanHysteresis is subtracted from LeanThreshold to return to the center
+	LeanHysteresis float64
+	// WeightSmoothing is the weight of a new sample for the standing weight (0..1)
+	WeightSmoothing float64
+
+	on         bool
+	standing   float64
+	emptySince time.Time
+	airtime    time.Duration
+	landUntil  time.Time
+	lean       Lean
+}
+
+// NewAnalyzer returns an analyzer with thresholds which work for adults.
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{
+		StepOnWeight:    10,
+		StepOffTime:     time.Second,
+		LandingFactor:   1.2,
+		LandingWindow:   250 * time.Millisecond,
+		LeanThreshold:   0.3,
+		LeanHysteresis:  0.1,
+		WeightSmoothing: 0.02,
+	}
+}
+
+// Reset forgets the user on the board.
+func (a *Analyzer) Reset() {
+	a.on = false
+	a.emptySince = time.Time{}
+	a.landUntil = time.Time{}
+	a.lean = LeanCenter
+}
+
+// Standing returns the smoothed weight of the user in kilograms, 0 if the board is empty.
+func (a *Analyzer) Standing() float64 {
+	if !a.on {
+		return 0
+	}
+	return a.standing
+}
+
+// Update processes a balance board event and returns the detected events, other events are
+// ignored. The detected events embed ev.
+func (a *Analyzer) Update(ev wiimote.Event) []wiimote.Event {
+	bb, ok := ev.(*wiimote.EventBalanceBoard)
+	if !ok {
+		return nil
+	}
+	t := ev.Timestamp()
+	total := Total(bb.Weights)
+
+	if total < a.StepOnWeight {
+		if !a.on {
+			return nil
+		}
+		if a.emptySince.IsZero() {
+			a.emptySince = t
+		}
+		if t.Sub(a.emptySince) < a.StepOffTime {
+			return nil
+		}
+		a.Reset()
+		return []wiimote.Event{&EventStepOff{Event: ev}}
+	}
+
+	if !a.on {
+		a.on = true
+		a.standing = total
+		a.lean = LeanCenter
+		return []wiimote.Event{&EventStepOn{Event: ev, Weight: total}}
+	}
+
+	var out []wiimote.Event
+	if !a.emptySince.IsZero() {
+		// touching the board again after a short empty board
+		a.airtime = t.Sub(a.emptySince)
+		a.emptySince = time.Time{}
+		a.landUntil = t.Add(a.LandingWindow)
+	}
+	if !a.landUntil.IsZero() {
+		if t.After(a.landUntil) {
+			a.landUntil = time.Time{}
+		} else if total >= a.standing*a.LandingFactor {
+			a.landUntil = time.Time{}
+			out = append(out, &EventJump{Event: ev, Airtime: a.airtime, Landing: total})
+		}
+		// the weight is not reliable while landing
+		return out
+	}
+
+	s := a.WeightSmoothing
+	a.standing = s*total + (1-s)*a.standing
+
+	center := Center(bb.Weights)
+	if lean := a.leanOf(center); lean != a.lean {
+		a.lean = lean
+		out = append(out, &EventLean{Event: ev, Lean: lean, Center: center})
+	}
+	return out
+}
+
+// leanOf returns the lean direction of center, keeping the current direction within the
+// hysteresis.
+func (a *Analyzer) leanOf(center wiimote.FVec2) Lean {
+	threshold := a.LeanThreshold
+	if a.lean != LeanCenter {
+		threshold -= a.LeanHysteresis
+	}
+	if max(math.Abs(center.X), math.Abs(center.Y)) < threshold {
+		return LeanCenter
+	}
+	switch {
+	case math.Abs(center.X) >= math.Abs(center.Y) && center.X < 0:
+		return LeanLeft
+	case math.Abs(center.X) >= math.Abs(center.Y):
+		return LeanRight
+	case center.Y > 0:
+		return LeanForward
+	}
+	return LeanBack
+}
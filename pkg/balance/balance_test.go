@@ -0,0 +1,107 @@
+package balance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+type testEvent struct {
+	ts time.Time
+}
+
+func (e testEvent) Feature() wiimote.Feature { return nil }
+func (e testEvent) Device() wiimote.Device   { return nil }
+func (e testEvent) Timestamp() time.Time     { return e.ts }
+
+// sample returns a balance board event with kg distributed by the center of balance.
+func sample(at time.Duration, kg float64, center wiimote.FVec2) *wiimote.EventBalanceBoard {
+	quarter := kg * UnitsPerKg / 4
+	w := func(x, y float64) int32 { return int32(quarter * (1 + x) * (1 + y)) }
+	return &wiimote.EventBalanceBoard{
+		Event: testEvent{time.Unix(0, 0).Add(at)},
+		Weights: [4]int32{
+			TopRight:    w(center.X, center.Y),
+			BottomRight: w(center.X, -center.Y),
+			TopLeft:     w(-center.X, center.Y),
+			BottomLeft:  w(-center.X, -center.Y),
+		},
+	}
+}
+
+func run(a *Analyzer, samples ...*wiimote.EventBalanceBoard) []wiimote.Event {
+	var out []wiimote.Event
+	for _, s := range samples {
+		out = append(out, a.Update(s)...)
+	}
+	return out
+}
+
+func TestCenter(t *testing.T) {
+	c := Center(sample(0, 80, wiimote.FVec2{X: 0.5, Y: -0.25}).Weights)
+	if c.X < 0.49 || c.X > 0.51 || c.Y < -0.26 || c.Y > -0.24 {
+		t.Fatalf("expected center (0.5 -0.25), got %v", c)
+	}
+	if got := Total(sample(0, 80, wiimote.FVec2{}).Weights); got != 80 {
+		t.Fatalf("expected 80kg, got %v", got)
+	}
+}
+
+func TestStepOnJumpStepOff(t *testing.T) {
+	a := NewAnalyzer()
+	ms := time.Millisecond
+	out := run(a,
+		sample(0, 0, wiimote.FVec2{}),
+		sample(10*ms, 80, wiimote.FVec2{}),
+		sample(20*ms, 80, wiimote.FVec2{}),
+		// jump
+		sample(30*ms, 2, wiimote.FVec2{}),
+		sample(330*ms, 2, wiimote.FVec2{}),
+		sample(340*ms, 60, wiimote.FVec2{}),
+		sample(350*ms, 120, wiimote.FVec2{}),
+		sample(360*ms, 80, wiimote.FVec2{}),
+		// step off
+		sample(400*ms, 0, wiimote.FVec2{}),
+		sample(2*time.Second, 0, wiimote.FVec2{}),
+	)
+	if len(out) != 3 {
+		t.Fatalf("expected step-on, jump and step-off, got %#v", out)
+	}
+	if on, ok := out[0].(*EventStepOn); !ok || on.Weight != 80 {
+		t.Errorf("expected step-on with 80kg, got %#v", out[0])
+	}
+	if jump, ok := out[1].(*EventJump); !ok || jump.Airtime != 310*ms || jump.Landing != 120 {
+		t.Errorf("expected jump with 310ms airtime, got %#v", out[1])
+	}
+	if _, ok := out[2].(*EventStepOff); !ok {
+		t.Errorf("expected step-off, got %#v", out[2])
+	}
+}
+
+func TestLean(t *testing.T) {
+	a := NewAnalyzer()
+	out := run(a,
+		sample(0, 80, wiimote.FVec2{}),
+		sample(10*time.Millisecond, 80, wiimote.FVec2{X: -0.5}),
+		// within the hysteresis
+		sample(20*time.Millisecond, 80, wiimote.FVec2{X: -0.25}),
+		sample(30*time.Millisecond, 80, wiimote.FVec2{Y: 0.4}),
+		sample(40*time.Millisecond, 80, wiimote.FVec2{}),
+	)
+	var leans []Lean
+	for _, ev := range out {
+		if lean, ok := ev.(*EventLean); ok {
+			leans = append(leans, lean.Lean)
+		}
+	}
+	want := []Lean{LeanLeft, LeanForward, LeanCenter}
+	if len(leans) != len(want) {
+		t.Fatalf("expected %v, got %v", want, leans)
+	}
+	for i := range want {
+		if leans[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, leans)
+		}
+	}
+}
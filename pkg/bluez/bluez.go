@@ -0,0 +1,52 @@
+// Package bluez scans, pairs and connects remotes using the BlueZ daemon on the system bus,
+// see Client.
+package bluez
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName     = "org.bluez"
+	deviceIface = "org.bluez.Device1"
+)
+
+// ErrNotFound is returned if BlueZ does not know a device with the address.
+var ErrNotFound = errors.New("bluetooth device not found")
+
+// DevicePath returns the object path of the device with the Bluetooth address addr
+// (e.g. "00:1f:32:aa:bb:cc").
+func DevicePath(conn *dbus.Conn, addr string) (dbus.ObjectPath, error) {
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	call := conn.Object(busName, "/").Call("org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0)
+	if err := call.Store(&objects); err != nil {
+		return "", err
+	}
+	for path, ifaces := range objects {
+		props, ok := ifaces[deviceIface]
+		if !ok {
+			continue
+		}
+		if a, ok := props["Address"].Value().(string); ok && strings.EqualFold(a, addr) {
+			return path, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Disconnect closes the connection to the device with the Bluetooth address addr. The
+// device stays paired and can reconnect.
+func Disconnect(addr string) error {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return err
+	}
+	path, err := DevicePath(conn, addr)
+	if err != nil {
+		return err
+	}
+	return conn.Object(busName, path).Call(deviceIface+".Disconnect", 0).Err
+}
@@ -0,0 +1,51 @@
+package bluez
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestIsWiimote(t *testing.T) {
+	tests := []struct {
+		dev    Device
+		expect bool
+	}{
+		{Device{Name: "Nintendo RVL-CNT-01"}, true},
+		{Device{Name: "Nintendo RVL-WBC-01"}, true},
+		{Device{Modalias: "usb:v057Ep0330d8000"}, true},
+		{Device{Class: 0x002504}, true},
+		{Device{Name: "Keyboard", Class: 0x002504}, false},
+		{Device{Name: "Headphones", Class: 0x240404}, false},
+	}
+	for _, tc := range tests {
+		if got := IsWiimote(tc.dev); got != tc.expect {
+			t.Errorf("%+v: expected %v, got %v", tc.dev, tc.expect, got)
+		}
+	}
+}
+
+func TestNewDevice(t *testing.T) {
+	dev := newDevice("/org/bluez/hci0/dev_00_1F_32_AA_BB_CC", map[string]dbus.Variant{
+		"Address": dbus.MakeVariant("00:1F:32:AA:BB:CC"),
+		"Name":    dbus.MakeVariant("Nintendo RVL-CNT-01-TR"),
+		"Paired":  dbus.MakeVariant(true),
+	})
+	if dev.Address != "00:1F:32:AA:BB:CC" || !dev.Paired || dev.Trusted || !IsWiimote(dev) {
+		t.Errorf("unexpected device %+v", dev)
+	}
+}
+
+func TestPIN(t *testing.T) {
+	pin, err := PIN("00:1f:32:aa:bb:cc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []byte{0xcc, 0xbb, 0xaa, 0x32, 0x1f, 0x00}; !bytes.Equal(pin, expect) {
+		t.Errorf("expected %x, got %x", expect, pin)
+	}
+	if _, err := PIN("invalid"); err == nil {
+		t.Errorf("expected error for invalid address")
+	}
+}
@@ -0,0 +1,226 @@
+package bluez
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	adapterIface       = "org.bluez.Adapter1"
+	objectManagerIface = "org.freedesktop.DBus.ObjectManager"
+	propertiesIface    = "org.freedesktop.DBus.Properties"
+)
+
+// ErrNoAdapter is returned if no Bluetooth adapter is present.
+var ErrNoAdapter = errors.New("no bluetooth adapter")
+
+// Client scans, pairs and connects remotes using a Bluetooth adapter.
+//
+// A remote is paired permanently by pressing the red sync button while scanning, it then
+// reconnects by itself when a button is pressed. Pressing 1+2 pairs the remote for a single
+// connection only.
+type Client struct {
+	conn    *dbus.Conn
+	adapter dbus.ObjectPath
+}
+
+// NewClient returns a client using the first Bluetooth adapter on the system bus.
+func NewClient() (*Client, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn}
+	objects, err := c.objects()
+	if err != nil {
+		return nil, err
+	}
+	for path, ifaces := range objects {
+		if _, ok := ifaces[adapterIface]; ok && (c.adapter == "" || path < c.adapter) {
+			c.adapter = path
+		}
+	}
+	if c.adapter == "" {
+		return nil, ErrNoAdapter
+	}
+	return c, nil
+}
+
+// Adapter returns the object path of the used adapter (e.g. "/org/bluez/hci0").
+func (c *Client) Adapter() dbus.ObjectPath {
+	return c.adapter
+}
+
+func (c *Client) objects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	err := c.conn.Object(busName, "/").Call(objectManagerIface+".GetManagedObjects", 0).Store(&objects)
+	return objects, err
+}
+
+// owns returns whether path is a device of the adapter.
+func (c *Client) owns(path dbus.ObjectPath) bool {
+	return strings.HasPrefix(string(path), string(c.adapter)+"/")
+}
+
+// Devices returns the devices known to the adapter, including devices which are not connected.
+func (c *Client) Devices() ([]Device, error) {
+	objects, err := c.objects()
+	if err != nil {
+		return nil, err
+	}
+	var devs []Device
+	for path, ifaces := range objects {
+		if props, ok := ifaces[deviceIface]; ok && c.owns(path) {
+			devs = append(devs, newDevice(path, props))
+		}
+	}
+	return devs, nil
+}
+
+// Wiimotes returns the remotes known to the adapter, see IsWiimote.
+func (c *Client) Wiimotes() ([]Device, error) {
+	devs, err := c.Devices()
+	var remotes []Device
+	for _, dev := range devs {
+		if IsWiimote(dev) {
+			remotes = append(remotes, dev)
+		}
+	}
+	return remotes, err
+}
+
+// device reads the properties of the device at path.
+func (c *Client) device(path dbus.ObjectPath) (Device, error) {
+	var props map[string]dbus.Variant
+	err := c.conn.Object(busName, path).Call(propertiesIface+".GetAll", 0, deviceIface).Store(&props)
+	return newDevice(path, props), err
+}
+
+// Scan discovers remotes until ctx is done, found is called once for every remote seen. Remotes
+// are only discoverable while the sync button or 1+2 is pressed. The error of ctx is returned
+// once it is done.
+func (c *Client) Scan(ctx context.Context, found func(Device)) error {
+	added := []dbus.MatchOption{
+		dbus.WithMatchInterface(objectManagerIface),
+		dbus.WithMatchMember("InterfacesAdded"),
+	}
+	changed := []dbus.MatchOption{
+		dbus.WithMatchInterface(propertiesIface),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchPathNamespace(c.adapter),
+	}
+	if err := c.conn.AddMatchSignal(added...); err != nil {
+		return err
+	}
+	defer c.conn.RemoveMatchSignal(added...)
+	if err := c.conn.AddMatchSignal(changed...); err != nil {
+		return err
+	}
+	defer c.conn.RemoveMatchSignal(changed...)
+
+	signals := make(chan *dbus.Signal, 16)
+	c.conn.Signal(signals)
+	defer c.conn.RemoveSignal(signals)
+
+	adapter := c.conn.Object(busName, c.adapter)
+	if err := adapter.CallWithContext(ctx, adapterIface+".StartDiscovery", 0).Err; err != nil {
+		return err
+	}
+	defer adapter.Call(adapterIface+".StopDiscovery", 0)
+
+	seen := make(map[string]bool)
+	for {
+		var sig *dbus.Signal
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig = <-signals:
+		}
+
+		var dev Device
+		switch {
+		case sig.Name == objectManagerIface+".InterfacesAdded" && len(sig.Body) >= 2:
+			path, _ := sig.Body[0].(dbus.ObjectPath)
+			ifaces, _ := sig.Body[1].(map[string]map[string]dbus.Variant)
+			props, ok := ifaces[deviceIface]
+			if !ok || !c.owns(path) {
+				continue
+			}
+			dev = newDevice(path, props)
+		case sig.Name == propertiesIface+".PropertiesChanged" && len(sig.Body) >= 1:
+			if iface, _ := sig.Body[0].(string); iface != deviceIface || !c.owns(sig.Path) {
+				continue
+			}
+			var err error
+			if dev, err = c.device(sig.Path); err != nil {
+				// the device was removed in the meantime
+				continue
+			}
+		default:
+			continue
+		}
+		if IsWiimote(dev) && !seen[dev.Address] {
+			seen[dev.Address] = true
+			found(dev)
+		}
+	}
+}
+
+// Pair pairs dev if it is not paired yet, trusts it and connects it. BlueZ supplies the PIN of
+// the remote, see PIN.
+func (c *Client) Pair(ctx context.Context, dev Device) error {
+	obj := c.conn.Object(busName, dev.Path)
+	if !dev.Paired {
+		if err := obj.CallWithContext(ctx, deviceIface+".Pair", 0).Err; err != nil {
+			return err
+		}
+	}
+	if err := c.Trust(dev); err != nil {
+		return err
+	}
+	return c.Connect(ctx, dev)
+}
+
+// Trust marks dev as trusted, which allows it to reconnect without confirmation.
+func (c *Client) Trust(dev Device) error {
+	if dev.Trusted {
+		return nil
+	}
+	obj := c.conn.Object(busName, dev.Path)
+	return obj.Call(propertiesIface+".Set", 0, deviceIface, "Trusted", dbus.MakeVariant(true)).Err
+}
+
+// Connect connects dev if it is not connected.
+func (c *Client) Connect(ctx context.Context, dev Device) error {
+	if dev.Connected {
+		return nil
+	}
+	return c.conn.Object(busName, dev.Path).CallWithContext(ctx, deviceIface+".Connect", 0).Err
+}
+
+// Reconnect connects all paired remotes which are not connected. Most remotes only accept a
+// connection after a button is pressed, the errors of all failed remotes are returned.
+func (c *Client) Reconnect(ctx context.Context) error {
+	remotes, err := c.Wiimotes()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, dev := range remotes {
+		if dev.Paired && !dev.Connected {
+			if err := c.Connect(ctx, dev); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", dev.Address, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Disconnect closes the connection to dev, it stays paired.
+func (c *Client) Disconnect(dev Device) error {
+	return c.conn.Object(busName, dev.Path).Call(deviceIface+".Disconnect", 0).Err
+}
@@ -0,0 +1,82 @@
+package bluez
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// names of the remotes as reported over Bluetooth
+var wiimoteNames = []string{
+	"Nintendo RVL-CNT-01",    // Wii Remote
+	"Nintendo RVL-CNT-01-TR", // Wii Remote Plus
+	"Nintendo RVL-CNT-01-UC", // Wii U Pro Controller
+	"Nintendo RVL-WBC-01",    // Wii Balance Board
+}
+
+// device classes of the remotes, for remotes which do not report their name yet
+var wiimoteClasses = []uint32{0x002504, 0x000508}
+
+// Device describes a Bluetooth device known to BlueZ, see org.bluez.Device1.
+type Device struct {
+	Path    dbus.ObjectPath
+	Address string
+	Name    string
+	// Class is the Bluetooth device class, 0 if unknown
+	Class uint32
+	// Modalias describes the vendor and product (e.g. "usb:v057Ep0306d0600")
+	Modalias string
+
+	Paired, Trusted, Connected bool
+}
+
+// newDevice returns the device at path described by the properties of org.bluez.Device1.
+func newDevice(path dbus.ObjectPath, props map[string]dbus.Variant) Device {
+	dev := Device{Path: path}
+	fields := map[string]any{
+		"Address":   &dev.Address,
+		"Name":      &dev.Name,
+		"Class":     &dev.Class,
+		"Modalias":  &dev.Modalias,
+		"Paired":    &dev.Paired,
+		"Trusted":   &dev.Trusted,
+		"Connected": &dev.Connected,
+	}
+	for name, field := range fields {
+		if v, ok := props[name]; ok {
+			v.Store(field)
+		}
+	}
+	return dev
+}
+
+// IsWiimote returns whether dev is a Nintendo remote, recognized by its name, product or
+// device class.
+func IsWiimote(dev Device) bool {
+	if slices.Contains(wiimoteNames, dev.Name) {
+		return true
+	}
+	modalias := strings.ToLower(dev.Modalias)
+	if strings.Contains(modalias, "v057ep0306") || strings.Contains(modalias, "v057ep0330") {
+		return true
+	}
+	return dev.Name == "" && slices.Contains(wiimoteClasses, dev.Class)
+}
+
+// PIN returns the PIN to pair a remote, which is the Bluetooth address addr in reverse byte
+// order. Pairing with the red sync button uses the address of the adapter, pairing with 1+2
+// uses the address of the remote.
+//
+// The PIN is binary and cannot be passed by a BlueZ agent, BlueZ supplies it itself using its
+// wiimote plugin. PIN is provided for other Bluetooth stacks.
+func PIN(addr string) ([]byte, error) {
+	hw, err := net.ParseMAC(addr)
+	if err != nil {
+		return nil, err
+	}
+	pin := slices.Clone(hw)
+	slices.Reverse(pin)
+	return pin, nil
+}
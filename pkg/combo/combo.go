@@ -0,0 +1,239 @@
+// Package combo emits synthetic events for long-presses and chords of core keys, so actions
+// like "hold HOME for 2 seconds" can be attached without timers.
+package combo
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+	"golang.org/x/sys/unix"
+)
+
+// Combo is a set of core keys held together for at least Hold. A combo of a single key with a
+// hold duration is a long-press, a combo of multiple keys without hold duration is a chord.
+type Combo struct {
+	// Name identifies the combo in EventCombo
+	Name string
+	// Keys which must be held
+	Keys wiimote.KeySet
+	// Hold is the duration all keys must be held, 0 to trigger immediately
+	Hold time.Duration
+}
+
+// LongPress returns a combo of key held for d.
+func LongPress(name string, key wiimote.Key, d time.Duration) Combo {
+	var keys wiimote.KeySet
+	keys.Set(key, true)
+	return Combo{Name: name, Keys: keys, Hold: d}
+}
+
+// Chord returns a combo of keys held together.
+func Chord(name string, keys ...wiimote.Key) Combo {
+	var set wiimote.KeySet
+	for _, k := range keys {
+		set.Set(k, true)
+	}
+	return Combo{Name: name, Keys: set}
+}
+
+// EventCombo is emitted when a combo is triggered and again with Released set as soon as one
+// of its keys is released.
+type EventCombo struct {
+	wiimote.Event
+	Combo
+	Released bool
+}
+
+type comboEvent struct {
+	feature   wiimote.Feature
+	timestamp time.Time
+}
+
+func (e comboEvent) Feature() wiimote.Feature { return e.feature }
+func (e comboEvent) Timestamp() time.Time     { return e.timestamp }
+
+func (e comboEvent) Device() wiimote.Device {
+	if e.feature == nil {
+		return nil
+	}
+	return e.feature.Device()
+}
+
+type comboState struct {
+	since time.Time
+	held  bool
+	fired bool
+}
+
+// Processor passes all events of a source and inserts EventCombo events. Only core key events
+// are considered. Combos with a hold duration are triggered by a timer, so no events of the
+// source are required while the keys are held.
+//
+// Processors are not thread-safe.
+type Processor struct {
+	wiimote.Poller[wiimote.Event]
+
+	src     wiimote.Poller[wiimote.Event]
+	combos  []Combo
+	state   []comboState
+	keys    wiimote.KeySet
+	feature wiimote.Feature
+	pending []wiimote.Event
+
+	efd int
+	tfd int
+}
+
+// New creates a processor of the events of src. If src provides a file descriptor (as all
+// devices do), the processor provides one which is readable when either src has events or a
+// combo expires.
+func New(src wiimote.Poller[wiimote.Event], combos ...Combo) (*Processor, error) {
+	p := &Processor{
+		src:    src,
+		combos: combos,
+		state:  make([]comboState, len(combos)),
+		efd:    -1,
+	}
+	p.Poller = common.NewPoller(p)
+
+	var err error
+	p.tfd, err = unix.TimerfdCreate(unix.CLOCK_MONOTONIC, unix.TFD_NONBLOCK|unix.TFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	fds := []int{p.tfd}
+	if s, ok := src.(interface{ FD() int }); ok {
+		p.efd, err = unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+		if err != nil {
+			unix.Close(p.tfd)
+			return nil, err
+		}
+		fds = append(fds, p.efd)
+		for _, fd := range []int{p.tfd, s.FD()} {
+			ev := unix.EpollEvent{Events: unix.EPOLLIN, Fd: int32(fd)}
+			if err := unix.EpollCtl(p.efd, unix.EPOLL_CTL_ADD, fd, &ev); err != nil {
+				for _, fd := range fds {
+					unix.Close(fd)
+				}
+				return nil, err
+			}
+		}
+	}
+	runtime.AddCleanup(p, func(fds []int) {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+	}, fds)
+	return p, nil
+}
+
+// FD returns a file descriptor which is readable when Poll should be called, -1 if the source
+// does not provide one.
+func (p *Processor) FD() int {
+	return p.efd
+}
+
+// Held returns the core keys currently held.
+func (p *Processor) Held() wiimote.KeySet {
+	return p.keys
+}
+
+// Poll returns the next event of the source or a pending EventCombo.
+func (p *Processor) Poll() (wiimote.Event, bool, error) {
+	if ev, ok := p.next(); ok {
+		return ev, true, nil
+	}
+
+	var buf [8]byte
+	unix.Read(p.tfd, buf[:])
+
+	ev, more, err := p.src.Poll()
+	if err != nil {
+		if !errors.Is(err, common.ErrWouldBlock) {
+			return nil, false, err
+		}
+		p.expire(time.Now())
+		p.arm()
+		if ev, ok := p.next(); ok {
+			return ev, len(p.pending) > 0, nil
+		}
+		return nil, false, err
+	}
+
+	if key, ok := ev.(*wiimote.EventKey); ok {
+		p.feature = key.Feature()
+		p.keys.Set(key.Code, key.Pressed)
+		p.update(key.Timestamp())
+		p.arm()
+	}
+	return ev, more || len(p.pending) > 0, nil
+}
+
+func (p *Processor) next() (wiimote.Event, bool) {
+	if len(p.pending) == 0 {
+		return nil, false
+	}
+	ev := p.pending[0]
+	p.pending = p.pending[1:]
+	return ev, true
+}
+
+func (p *Processor) emit(c Combo, ts time.Time, released bool) {
+	p.pending = append(p.pending, &EventCombo{
+		Event:    comboEvent{p.feature, ts},
+		Combo:    c,
+		Released: released,
+	})
+}
+
+// update starts or stops the combos after the held keys changed at ts.
+func (p *Processor) update(ts time.Time) {
+	for i, c := range p.combos {
+		st := &p.state[i]
+		held := c.Keys != 0 && p.keys&c.Keys == c.Keys
+		switch {
+		case held && !st.held:
+			*st = comboState{since: ts, held: true}
+		case !held && st.held:
+			if st.fired {
+				p.emit(c, ts, true)
+			}
+			*st = comboState{}
+		}
+	}
+	p.expire(ts)
+}
+
+// expire triggers all held combos whose hold duration passed at now.
+func (p *Processor) expire(now time.Time) {
+	for i, c := range p.combos {
+		st := &p.state[i]
+		if st.held && !st.fired && !now.Before(st.since.Add(c.Hold)) {
+			st.fired = true
+			p.emit(c, st.since.Add(c.Hold), false)
+		}
+	}
+}
+
+// arm sets the timer to the earliest pending combo.
+func (p *Processor) arm() {
+	var deadline time.Time
+	for i, c := range p.combos {
+		st := p.state[i]
+		if !st.held || st.fired {
+			continue
+		}
+		if at := st.since.Add(c.Hold); deadline.IsZero() || at.Before(deadline) {
+			deadline = at
+		}
+	}
+	var spec unix.ItimerSpec
+	if !deadline.IsZero() {
+		// an expired deadline must still arm the timer, a zero value disarms it
+		spec.Value = unix.NsecToTimespec(max(int64(time.Until(deadline)), 1))
+	}
+	unix.TimerfdSettime(p.tfd, 0, &spec, nil)
+}
@@ -0,0 +1,56 @@
+package combo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
+)
+
+func TestLongPressAndChord(t *testing.T) {
+	cfg := sim.DefaultConfig()
+	cfg.Keys = []sim.KeyPress{
+		{Key: wiimote.KeyA, At: 0, Duration: time.Hour},
+		{Key: wiimote.KeyB, At: 0, Duration: 200 * time.Millisecond},
+	}
+	dev, err := sim.NewDevice(cfg)
+	if err != nil {
+		t.Fatalf("unable to create device: %v", err)
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore, true); err != nil {
+		t.Fatalf("unable to open features: %v", err)
+	}
+
+	proc, err := New(dev,
+		LongPress("hold-a", wiimote.KeyA, 100*time.Millisecond),
+		Chord("a+b", wiimote.KeyA, wiimote.KeyB))
+	if err != nil {
+		t.Fatalf("unable to create processor: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var got []string
+	for len(got) < 3 {
+		ev, err := proc.WaitContext(ctx)
+		if err != nil {
+			t.Fatalf("expected combos, got %v before: %v", got, err)
+		}
+		if ev, ok := ev.(*EventCombo); ok {
+			name := ev.Name
+			if ev.Released {
+				name += " released"
+			}
+			got = append(got, name)
+		}
+	}
+	expect := []string{"a+b", "hold-a", "a+b released"}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("expected %v, got %v", expect, got)
+			break
+		}
+	}
+}
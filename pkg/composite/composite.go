@@ -0,0 +1,256 @@
+// Package composite merges the features of a device into a single logical controller: the
+// buttons of the remote and its extension form one set of buttons, and every stick, trigger
+// and motion sensor is reported normalized, regardless of the feature which produced it.
+// Mapping and gamepad layers can use a Controller instead of handling each extension.
+package composite
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Stick is an analog stick of the controller.
+type Stick uint8
+
+const (
+	// StickLeft is the Nunchuk stick, the left stick of the Classic or Pro Controller, or the
+	// stick of a guitar or drums
+	StickLeft Stick = iota
+	// StickRight is the right stick of the Classic or Pro Controller
+	StickRight
+)
+
+func (s Stick) String() string {
+	switch s {
+	case StickLeft:
+		return "left"
+	case StickRight:
+		return "right"
+	}
+	return "Stick(" + strconv.Itoa(int(s)) + ")"
+}
+
+// InputKind describes which value of an Input changed.
+type InputKind uint8
+
+const (
+	// InputButton is a pressed or released button
+	InputButton InputKind = iota
+	// InputStick is a moved stick
+	InputStick
+	// InputTrigger is a moved analog trigger
+	InputTrigger
+	// InputAccel is a new acceleration of the remote or the Nunchuk
+	InputAccel
+	// InputRotation is a new rotation speed of the Motion Plus
+	InputRotation
+)
+
+var inputNames = [...]string{"button", "stick", "trigger", "accel", "rotation"}
+
+func (k InputKind) String() string {
+	if int(k) < len(inputNames) {
+		return inputNames[k]
+	}
+	return "InputKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Input is a change of the controller.
+type Input struct {
+	Kind InputKind
+	Time time.Time
+	// Source is the feature which produced the input
+	Source wiimote.FeatureKind
+
+	// Button and Pressed are set for InputButton
+	Button  wiimote.Key
+	Pressed bool
+	// Stick and Position (-1..1) are set for InputStick
+	Stick    Stick
+	Position wiimote.FVec2
+	// Trigger (KeyTL or KeyTR) and Value (0..1) are set for InputTrigger
+	Trigger wiimote.Key
+	Value   float64
+	// Accel in g is set for InputAccel, Source tells the remote and the Nunchuk apart
+	Accel wiimote.FVec3
+	// Rotation in degree per second is set for InputRotation
+	Rotation wiimote.FVec3
+}
+
+// Controller is the logical controller of a device. It is not thread-safe.
+type Controller struct {
+	// Accel is the calibration of the accelerometer of the remote
+	Accel wiimote.AccelCalibration
+	// NunchukAccel is the calibration of the accelerometer of the Nunchuk
+	NunchukAccel wiimote.AccelCalibration
+	// Sticks are the calibrations by feature, features without calibration use the nominal
+	// range of the kernel
+	Sticks map[wiimote.FeatureKind][2]wiimote.StickCalibration
+
+	buttons  wiimote.KeySet
+	sticks   [2]wiimote.FVec2
+	triggers *wiimote.ClassicTriggers
+	accel    wiimote.FVec3
+	nunchuk  wiimote.FVec3
+	rotation wiimote.FVec3
+}
+
+// New returns a controller using the nominal calibrations.
+func New() *Controller {
+	return &Controller{
+		Accel:        wiimote.NominalAccel,
+		NunchukAccel: wiimote.NominalNunchukAccel,
+		triggers:     wiimote.NewClassicTriggers(),
+	}
+}
+
+// Reset releases all buttons and centers all sticks.
+func (c *Controller) Reset() {
+	c.buttons = 0
+	c.sticks = [2]wiimote.FVec2{}
+	c.triggers = wiimote.NewClassicTriggers()
+}
+
+// Buttons returns the held buttons.
+func (c *Controller) Buttons() wiimote.KeySet {
+	return c.buttons
+}
+
+// Stick returns the position of s in the range -1..1.
+func (c *Controller) Stick(s Stick) wiimote.FVec2 {
+	if int(s) >= len(c.sticks) {
+		return wiimote.FVec2{}
+	}
+	return c.sticks[s]
+}
+
+// Trigger returns the position of trigger (KeyTL or KeyTR) in the range 0..1.
+func (c *Controller) Trigger(trigger wiimote.Key) float64 {
+	return c.triggers.Trigger(trigger)
+}
+
+// AccelG returns the acceleration of the remote in g.
+func (c *Controller) AccelG() wiimote.FVec3 {
+	return c.accel
+}
+
+// NunchukG returns the acceleration of the Nunchuk in g.
+func (c *Controller) NunchukG() wiimote.FVec3 {
+	return c.nunchuk
+}
+
+// Rotation returns the rotation speed of the Motion Plus in degree per second.
+func (c *Controller) Rotation() wiimote.FVec3 {
+	return c.rotation
+}
+
+func (c *Controller) calibration(kind wiimote.FeatureKind, i int, def wiimote.StickCalibration) wiimote.StickCalibration {
+	if cal, ok := c.Sticks[kind]; ok {
+		return cal[i]
+	}
+	return def
+}
+
+// Update processes ev and returns the resulting inputs, events without input are ignored.
+func (c *Controller) Update(ev wiimote.Event) []Input {
+	if ev == nil {
+		return nil
+	}
+	base := Input{Time: ev.Timestamp()}
+	if f := ev.Feature(); f != nil {
+		base.Source = f.Kind()
+	}
+
+	var inputs []Input
+	button := func(key wiimote.Key, pressed bool) {
+		c.buttons.Set(key, pressed)
+		in := base
+		in.Kind, in.Button, in.Pressed = InputButton, key, pressed
+		inputs = append(inputs, in)
+	}
+	stick := func(s Stick, pos wiimote.FVec2) {
+		c.sticks[s] = pos
+		in := base
+		in.Kind, in.Stick, in.Position = InputStick, s, pos
+		inputs = append(inputs, in)
+	}
+	triggers := func() {
+		for _, key := range []wiimote.Key{wiimote.KeyTL, wiimote.KeyTR} {
+			in := base
+			in.Kind, in.Trigger, in.Value = InputTrigger, key, c.triggers.Trigger(key)
+			inputs = append(inputs, in)
+		}
+	}
+
+	switch ev := ev.(type) {
+	case *wiimote.EventKey:
+		button(ev.Code, ev.Pressed)
+	case *wiimote.EventNunchukKey:
+		button(ev.Code, ev.Pressed)
+	case *wiimote.EventProControllerKey:
+		button(ev.Code, ev.Pressed)
+	case *wiimote.EventDrumsKey:
+		button(ev.Code, ev.Pressed)
+	case *wiimote.EventGuitarKey:
+		button(ev.Code, ev.Pressed)
+	case *wiimote.EventClassicControllerKey:
+		button(ev.Code, ev.Pressed)
+		if ev.Code == wiimote.KeyTL || ev.Code == wiimote.KeyTR {
+			c.triggers.Update(ev)
+			triggers()
+		}
+
+	case *wiimote.EventNunchukMove:
+		stick(StickLeft, c.calibration(base.Source, 0, wiimote.NunchukStick).Normalize(ev.Stick))
+		c.nunchuk = c.NunchukAccel.G(ev.Accel)
+		in := base
+		in.Kind, in.Accel = InputAccel, c.nunchuk
+		inputs = append(inputs, in)
+	case *wiimote.EventClassicControllerMove:
+		stick(StickLeft, c.calibration(base.Source, 0, wiimote.ClassicControllerStick).Normalize(ev.StickLeft))
+		stick(StickRight, c.calibration(base.Source, 1, wiimote.ClassicControllerRightStick).Normalize(ev.StickRight))
+		c.triggers.Update(ev)
+		triggers()
+	case *wiimote.EventProControllerMove:
+		stick(StickLeft, c.calibration(base.Source, 0, wiimote.ProControllerStick).Normalize(ev.Sticks[0]))
+		stick(StickRight, c.calibration(base.Source, 1, wiimote.ProControllerStick).Normalize(ev.Sticks[1]))
+	case *wiimote.EventGuitarMove:
+		stick(StickLeft, c.calibration(base.Source, 0, wiimote.ClassicControllerStick).Normalize(ev.Stick))
+	case *wiimote.EventDrumsMove:
+		stick(StickLeft, c.calibration(base.Source, 0, wiimote.ClassicControllerStick).Normalize(ev.Pad))
+
+	case *wiimote.EventAccel:
+		c.accel = ev.GWith(c.Accel)
+		in := base
+		in.Kind, in.Accel = InputAccel, c.accel
+		inputs = append(inputs, in)
+	case *wiimote.EventMotionPlus:
+		c.rotation = ev.AngularVelocity()
+		in := base
+		in.Kind, in.Rotation = InputRotation, c.rotation
+		inputs = append(inputs, in)
+
+	case *wiimote.EventExtensionDisconnected:
+		// the buttons and sticks of the extension are gone
+		for key := wiimote.Key(0); key < 64; key++ {
+			if c.buttons.Has(key) && !coreKey(key) {
+				button(key, false)
+			}
+		}
+		stick(StickLeft, wiimote.FVec2{})
+		stick(StickRight, wiimote.FVec2{})
+	}
+	return inputs
+}
+
+// coreKey reports whether key is a button of the remote.
+func coreKey(key wiimote.Key) bool {
+	switch key {
+	case wiimote.KeyLeft, wiimote.KeyRight, wiimote.KeyUp, wiimote.KeyDown, wiimote.KeyA, wiimote.KeyB,
+		wiimote.KeyPlus, wiimote.KeyMinus, wiimote.KeyHome, wiimote.KeyOne, wiimote.KeyTwo:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package composite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+type testFeature struct {
+	kind wiimote.FeatureKind
+}
+
+func (f testFeature) Close() error              { return nil }
+func (f testFeature) Kind() wiimote.FeatureKind { return f.kind }
+func (f testFeature) Device() wiimote.Device    { return nil }
+func (f testFeature) Opened() bool              { return true }
+
+type testEvent struct {
+	kind wiimote.FeatureKind
+}
+
+func (e testEvent) Feature() wiimote.Feature { return testFeature{e.kind} }
+func (e testEvent) Device() wiimote.Device   { return nil }
+func (e testEvent) Timestamp() time.Time     { return time.Unix(0, 0) }
+
+func TestControllerMergesFeatures(t *testing.T) {
+	c := New()
+	core := testEvent{wiimote.FeatureCore}
+	nunchuk := testEvent{wiimote.FeatureNunchuck}
+
+	c.Update(&wiimote.EventKey{Event: core, Code: wiimote.KeyA, Pressed: true})
+	inputs := c.Update(&wiimote.EventNunchukKey{EventKey: wiimote.EventKey{Event: nunchuk, Code: wiimote.KeyZ, Pressed: true}})
+	if len(inputs) != 1 || inputs[0].Kind != InputButton || inputs[0].Source != wiimote.FeatureNunchuck {
+		t.Fatalf("unexpected inputs %+v", inputs)
+	}
+	if b := c.Buttons(); !b.Has(wiimote.KeyA) || !b.Has(wiimote.KeyZ) {
+		t.Fatalf("expected A and Z held, got %b", b)
+	}
+
+	inputs = c.Update(&wiimote.EventNunchukMove{Event: nunchuk, Stick: wiimote.Vec2{X: 100}, Accel: wiimote.Vec3{Z: 200}})
+	if len(inputs) != 2 || inputs[0].Kind != InputStick || inputs[1].Kind != InputAccel {
+		t.Fatalf("unexpected inputs %+v", inputs)
+	}
+	if pos := c.Stick(StickLeft); pos.X != 1 || pos.Y != 0 {
+		t.Errorf("expected stick at (1 0), got %v", pos)
+	}
+	if g := c.NunchukG(); g.Z != 1 {
+		t.Errorf("expected 1g on Z, got %v", g)
+	}
+
+	// unplugging the nunchuk releases its buttons but keeps the buttons of the remote
+	c.Update(&wiimote.EventExtensionDisconnected{Event: core, Type: "nunchuk"})
+	if b := c.Buttons(); !b.Has(wiimote.KeyA) || b.Has(wiimote.KeyZ) {
+		t.Fatalf("expected only A held, got %b", b)
+	}
+	if pos := c.Stick(StickLeft); pos != (wiimote.FVec2{}) {
+		t.Errorf("expected centered stick, got %v", pos)
+	}
+}
+
+func TestControllerClassic(t *testing.T) {
+	c := New()
+	classic := testEvent{wiimote.FeatureClassicController}
+	inputs := c.Update(&wiimote.EventClassicControllerMove{
+		Event:        classic,
+		StickLeft:    wiimote.Vec2{X: -30},
+		StickRight:   wiimote.Vec2{Y: 15},
+		ShoulderLeft: wiimote.ClassicTriggerMax / 2,
+	})
+	if len(inputs) != 4 {
+		t.Fatalf("expected two sticks and two triggers, got %+v", inputs)
+	}
+	if pos := c.Stick(StickLeft); pos.X != -1 {
+		t.Errorf("expected left stick at -1, got %v", pos)
+	}
+	if pos := c.Stick(StickRight); pos.Y != 1 {
+		t.Errorf("expected right stick at 1, got %v", pos)
+	}
+	if v := c.Trigger(wiimote.KeyTL); v < 0.4 || v > 0.6 {
+		t.Errorf("expected half pressed trigger, got %v", v)
+	}
+}
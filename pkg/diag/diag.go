@@ -0,0 +1,198 @@
+// Package diag diagnoses the environment of an application, e.g. a missing kernel driver or
+// missing permissions on the device nodes. Applications may run Check at startup to print
+// actionable hints instead of failing with a bare permission error.
+package diag
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Roots of the checked file systems, changed by tests.
+var (
+	sysfs = "/sys"
+	devfs = "/dev"
+)
+
+// Status is the outcome of a check.
+type Status uint8
+
+const (
+	// OK means the check passed
+	OK Status = iota
+	// Warning means the check failed but applications may work regardless
+	Warning
+	// Failed means the check failed and applications will not work
+	Failed
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Warning:
+		return "warning"
+	case Failed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// Result describes the outcome of a single check.
+type Result struct {
+	// Name of the check
+	Name   string
+	Status Status
+	// Detail describes the outcome
+	Detail string
+	// Fix suggests how to resolve a failed check, empty if the check passed
+	Fix string
+}
+
+func (r Result) String() string {
+	s := fmt.Sprintf("[%v] %s: %s", r.Status, r.Name, r.Detail)
+	if r.Fix != "" {
+		s += "\n    fix: " + r.Fix
+	}
+	return s
+}
+
+// Check runs all checks and returns their results, the first result reports the versions.
+func Check() []Result {
+	results := []Result{CheckVersion(), CheckHID(), CheckDriver(), CheckUinput()}
+	return append(results, CheckDevices()...)
+}
+
+// AnyFailed returns whether any result failed.
+func AnyFailed(results []Result) bool {
+	return slices.ContainsFunc(results, func(r Result) bool { return r.Status == Failed })
+}
+
+// CheckVersion reports the versions of this module, the Go toolchain, the kernel and the driver,
+// see wiimote.Version. It always passes, the driver itself is checked by CheckDriver.
+func CheckVersion() Result {
+	return Result{Name: "version", Detail: wiimote.Version().String()}
+}
+
+// CheckHID checks whether the hid subsystem is available.
+func CheckHID() Result {
+	r := Result{Name: "hid subsystem"}
+	if _, err := os.Stat(filepath.Join(sysfs, "bus", "hid")); err != nil {
+		r.Status = Failed
+		r.Detail = "hid bus not found"
+		r.Fix = "enable CONFIG_HID in the kernel or load the module: modprobe hid"
+		return r
+	}
+	r.Detail = "available"
+	return r
+}
+
+// CheckDriver checks whether the wiimote kernel driver (hid-wiimote) is loaded.
+func CheckDriver() Result {
+	r := Result{Name: "wiimote driver"}
+	if _, err := os.Stat(filepath.Join(sysfs, "bus", "hid", "drivers", "wiimote")); err != nil {
+		r.Status = Failed
+		r.Detail = "hid-wiimote is not loaded"
+		r.Fix = "load the driver: modprobe hid-wiimote"
+		return r
+	}
+	r.Detail = "loaded"
+	return r
+}
+
+// CheckUinput checks whether /dev/uinput is writable, which is required to create virtual
+// input devices.
+func CheckUinput() Result {
+	r := checkAccess("uinput", filepath.Join(devfs, "uinput"), syscall.O_RDWR)
+	if r.Status == Failed {
+		// virtual devices are only used by the mapping tools
+		r.Status = Warning
+		if r.Fix == "" {
+			r.Fix = "load the module: modprobe uinput"
+		}
+	}
+	return r
+}
+
+// CheckDevices checks whether the event nodes of all connected devices are accessible. A warning
+// is reported if no device is connected.
+func CheckDevices() []Result {
+	nodes, _ := filepath.Glob(filepath.Join(sysfs, "bus", "hid", "drivers", "wiimote", "*", "input", "input*", "event*"))
+	if len(nodes) == 0 {
+		return []Result{{
+			Name:   "devices",
+			Status: Warning,
+			Detail: "no device connected",
+			Fix:    "connect a device using bluetoothctl and press the sync button",
+		}}
+	}
+	var results []Result
+	for _, node := range nodes {
+		name := filepath.Base(node)
+		results = append(results, checkAccess(name, filepath.Join(devfs, "input", name), syscall.O_RDWR))
+	}
+	return results
+}
+
+// checkAccess checks whether path can be opened using mode.
+func checkAccess(name, path string, mode int) Result {
+	r := Result{Name: name}
+	f, err := os.OpenFile(path, mode, 0)
+	if err == nil {
+		f.Close()
+		r.Detail = path + " is accessible"
+		return r
+	}
+	r.Status = Failed
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		r.Detail = path + " does not exist"
+	case errors.Is(err, os.ErrPermission):
+		r.Detail = path + " is not accessible"
+		r.Fix = permissionFix(path)
+	default:
+		r.Detail = err.Error()
+	}
+	return r
+}
+
+// permissionFix suggests how to gain access to path based on its owning group.
+func permissionFix(path string) string {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return "install udev rules granting access (see wiicheck -rules " + RulesPath + ")"
+	}
+	gid := strconv.Itoa(int(st.Gid))
+	group := gid
+	if g, err := user.LookupGroupId(gid); err == nil {
+		group = g.Name
+	}
+	if st.Gid == 0 || st.Mode&0o060 != 0o060 {
+		return "install udev rules granting access to a group (see wiicheck -rules " + RulesPath + ")"
+	}
+	if inGroup(gid) {
+		return fmt.Sprintf("you are in group %s, log out and in again to apply the membership", group)
+	}
+	return fmt.Sprintf("add yourself to group %s: usermod -aG %s $USER", group, group)
+}
+
+// inGroup returns whether the current user is a member of the group gid.
+func inGroup(gid string) bool {
+	u, err := user.Current()
+	if err != nil {
+		return false
+	}
+	groups, err := u.GroupIds()
+	if err != nil {
+		return false
+	}
+	return slices.Contains(groups, gid)
+}
@@ -0,0 +1,62 @@
+package diag
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	sysfs = filepath.Join(dir, "sys")
+	devfs = filepath.Join(dir, "dev")
+	t.Cleanup(func() { sysfs, devfs = "/sys", "/dev" })
+
+	if r := CheckDriver(); r.Status != Failed || r.Fix == "" {
+		t.Errorf("expected missing driver to fail with fix, got %v", r)
+	}
+	if r := CheckUinput(); r.Status != Warning {
+		t.Errorf("expected missing uinput to warn, got %v", r)
+	}
+	if r := CheckDevices(); len(r) != 1 || r[0].Status != Warning {
+		t.Errorf("expected no devices to warn, got %v", r)
+	}
+
+	event := filepath.Join(sysfs, "bus", "hid", "drivers", "wiimote", "0005:057E:0306.0001", "input", "input7", "event3")
+	if err := os.MkdirAll(event, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(devfs, "input"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(devfs, "input", "event3"), nil, 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	results := Check()
+	if AnyFailed(results) {
+		t.Errorf("expected all checks to pass, got %v", results)
+	}
+	if first := results[0]; first.Name != "version" || !strings.HasPrefix(first.Detail, "go-wiimote ") {
+		t.Errorf("expected version to be reported first, got %v", first)
+	}
+	if last := results[len(results)-1]; last.Name != "event3" || last.Status != OK {
+		t.Errorf("expected event3 to be accessible, got %v", last)
+	}
+}
+
+func TestWriteRules(t *testing.T) {
+	var w strings.Builder
+	if err := WriteRules(&w, "wii"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`DRIVERS=="wiimote", GROUP="wii"`, `KERNEL=="uinput", GROUP="wii"`, "/bin/chgrp wii"} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("expected rules to contain %q:\n%s", want, w.String())
+		}
+	}
+	if err := WriteRules(&w, `wii" MODE="0666`); err == nil {
+		t.Errorf("expected invalid group to fail")
+	}
+}
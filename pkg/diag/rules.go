@@ -0,0 +1,36 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// RulesPath is the recommended location of the rules written by WriteRules.
+const RulesPath = "/etc/udev/rules.d/70-wiimote.rules"
+
+var rulesTemplate = template.Must(template.New("rules").Parse(`# udev rules granting group {{.}} access to Nintendo Wii devices and uinput.
+# Reload using: udevadm control --reload && udevadm trigger
+
+# event nodes of the wiimote kernel driver
+SUBSYSTEM=="input", KERNEL=="event*", DRIVERS=="wiimote", GROUP="{{.}}", MODE="0660"
+
+# hidraw nodes of Nintendo devices connected via Bluetooth
+SUBSYSTEM=="hidraw", KERNELS=="0005:057E:*", GROUP="{{.}}", MODE="0660"
+
+# LEDs of the wiimote kernel driver
+SUBSYSTEM=="leds", DRIVERS=="wiimote", RUN+="/bin/chgrp {{.}} /sys%p/brightness", RUN+="/bin/chmod g+w /sys%p/brightness"
+
+# virtual input devices
+SUBSYSTEM=="misc", KERNEL=="uinput", GROUP="{{.}}", MODE="0660", OPTIONS+="static_node=uinput"
+`))
+
+// WriteRules writes udev rules granting group access to the event nodes, hidraw nodes and LEDs
+// of connected devices and to /dev/uinput. The rules are usually installed at RulesPath.
+func WriteRules(w io.Writer, group string) error {
+	if group == "" || strings.ContainsAny(group, "\"\\ \t\n%$") {
+		return fmt.Errorf("invalid group name: %q", group)
+	}
+	return rulesTemplate.Execute(w, group)
+}
@@ -0,0 +1,51 @@
+package discover
+
+// physicalKey returns a key identifying the physical remote of info. Some kernels expose multiple
+// hid devices for a single remote, these share the unique identifier and the parent device.
+func physicalKey(info *DeviceInfo) string {
+	if info.Uniq != "" {
+		return "uniq:" + info.Uniq
+	}
+	if parent := info.Device.Parent(); parent != nil {
+		return "parent:" + parent.Syspath()
+	}
+	return "syspath:" + info.Syspath
+}
+
+// dedup tracks the reported physical remotes.
+type dedup struct {
+	// syspath -> physical key
+	keys map[string]string
+	// physical key -> number of syspaths
+	refs map[string]int
+}
+
+func newDedup() *dedup {
+	return &dedup{
+		keys: make(map[string]string),
+		refs: make(map[string]int),
+	}
+}
+
+// add registers the hid device at syspath and returns false if the physical remote was
+// already reported.
+func (d *dedup) add(syspath, key string) bool {
+	if _, ok := d.keys[syspath]; ok {
+		return false
+	}
+	d.keys[syspath] = key
+	d.refs[key]++
+	return d.refs[key] == 1
+}
+
+// remove unregisters the hid device at syspath.
+func (d *dedup) remove(syspath string) {
+	key, ok := d.keys[syspath]
+	if !ok {
+		return
+	}
+	delete(d.keys, syspath)
+	if d.refs[key]--; d.refs[key] <= 0 {
+		delete(d.refs, key)
+	}
+}
@@ -0,0 +1,32 @@
+package discover
+
+import "testing"
+
+func TestDedup(t *testing.T) {
+	d := newDedup()
+
+	if !d.add("/sys/hid/0001", "uniq:00:11") {
+		t.Fatalf("expected first device to be reported")
+	}
+	if d.add("/sys/hid/0002", "uniq:00:11") {
+		t.Errorf("expected second hid device of the same remote to be dropped")
+	}
+	if d.add("/sys/hid/0001", "uniq:00:11") {
+		t.Errorf("expected same hid device to be dropped")
+	}
+	if !d.add("/sys/hid/0003", "uniq:00:22") {
+		t.Errorf("expected other remote to be reported")
+	}
+
+	// the remote is still present via the second hid device
+	d.remove("/sys/hid/0001")
+	if d.add("/sys/hid/0004", "uniq:00:11") {
+		t.Errorf("expected remote to be dropped while a hid device is present")
+	}
+
+	d.remove("/sys/hid/0002")
+	d.remove("/sys/hid/0004")
+	if !d.add("/sys/hid/0005", "uniq:00:11") {
+		t.Errorf("expected reconnected remote to be reported")
+	}
+}
@@ -3,6 +3,10 @@ package discover
 import (
 	"iter"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -12,9 +16,101 @@ import (
 	"github.com/friedelschoen/go-wiimote/internal/sequences"
 )
 
-// IterDevices returns all currently available devices. It returns an error if the
-// initialization failed. Each iteration yields a device and error if the device-creation failed.
-func IterDevices() (iter.Seq[wiimote.DeviceInfo], error) {
+// DeviceInfo describes a wiimote-device as found by IterDevices or a WiimoteMonitor.
+// The attributes are read once when the device is discovered and are not updated afterwards.
+type DeviceInfo struct {
+	// Device is the underlying device which can be passed to driver.NewDevice.
+	Device wiimote.DeviceInfo
+
+	// Syspath is the sysfs path of the hid device.
+	Syspath string
+	// DevType is the device type (e.g. "gen10", "gen20", "balanceboard", "procontroller").
+	DevType string
+	// Extension is the connected extension (e.g. "none", "nunchuk", "motionp+classic").
+	Extension string
+	// Battery is the battery capacity in percent, 0 if unknown.
+	Battery uint
+	// Uniq is the unique identifier of the device, which is the Bluetooth address of the device.
+	Uniq string
+	// Player is the player number assigned by a WiimoteMonitor, 0 if unassigned.
+	// See WiimoteMonitor.AssignPlayers.
+	Player int
+}
+
+// Type returns the device type of DevType.
+func (info *DeviceInfo) Type() wiimote.DevType {
+	return wiimote.ParseDevType(info.DevType)
+}
+
+// Filter decides whether a discovered device should be reported.
+type Filter func(info *DeviceInfo) bool
+
+// OnlyDevTypes reports only devices of the given device types (e.g. "gen20" or "balanceboard"),
+// see DeviceInfo.DevType. The kernel reports "pending" until the device type is detected, a
+// WiimoteMonitor checks such devices again when the detection finished.
+func OnlyDevTypes(types ...string) Filter {
+	return func(info *DeviceInfo) bool {
+		return slices.Contains(types, info.DevType)
+	}
+}
+
+// OnlyBalanceBoards reports only Wii Balance Boards.
+func OnlyBalanceBoards() Filter {
+	return func(info *DeviceInfo) bool {
+		return info.Type() == wiimote.DevTypeBalanceBoard
+	}
+}
+
+// OnlyMotionPlus reports only remotes with a Motion Plus, either built-in (Wii Remote Plus)
+// or connected as extension.
+func OnlyMotionPlus() Filter {
+	return func(info *DeviceInfo) bool {
+		return info.Type() == wiimote.DevTypeGen20 || strings.HasPrefix(info.Extension, "motionp")
+	}
+}
+
+func isWiimote(dev wiimote.DeviceInfo) bool {
+	return dev != nil && dev.Driver() == "wiimote" && dev.Subsystem() == "hid"
+}
+
+func readBattery(syspath string) uint {
+	matches, _ := filepath.Glob(filepath.Join(syspath, "power_supply", "*", "capacity"))
+	for _, m := range matches {
+		cont, err := os.ReadFile(m)
+		if err != nil {
+			continue
+		}
+		cap, err := strconv.Atoi(strings.TrimSpace(string(cont)))
+		if err != nil {
+			continue
+		}
+		return uint(cap)
+	}
+	return 0
+}
+
+// newDeviceInfo reads the attributes of dev and returns nil if dev is filtered out.
+func newDeviceInfo(dev wiimote.DeviceInfo, filters []Filter) *DeviceInfo {
+	info := &DeviceInfo{
+		Device:    dev,
+		Syspath:   dev.Syspath(),
+		DevType:   strings.TrimSpace(dev.SysattrValue("devtype")),
+		Extension: strings.TrimSpace(dev.SysattrValue("extension")),
+		Uniq:      strings.ToLower(dev.PropertyValue("HID_UNIQ")),
+	}
+	info.Battery = readBattery(info.Syspath)
+	for _, f := range filters {
+		if !f(info) {
+			return nil
+		}
+	}
+	return info
+}
+
+// IterDevices returns all currently available devices which pass all filters. It returns an error if the
+// initialization failed. Every physical remote is reported once, even if the kernel exposes multiple
+// hid devices for it.
+func IterDevices(filters ...Filter) (iter.Seq[*DeviceInfo], error) {
 	enum := driver.NewEnumerate()
 	if err := enum.AddMatchSubsystem("hid"); err != nil {
 		return nil, err
@@ -25,22 +121,22 @@ func IterDevices() (iter.Seq[wiimote.DeviceInfo], error) {
 		return nil, err
 	}
 
-	deviter := sequences.Map(iter, func(dev wiimote.DeviceInfo) wiimote.DeviceInfo {
-		if dev == nil {
-			return nil
-		}
-		if dev.Action() != "" && dev.Action() != "add" {
-			return nil
-		}
-		if dev.Driver() != "wiimote" || dev.Subsystem() != "hid" {
-			return nil
-		}
-		return dev
-	})
-	deviter = sequences.Filter(deviter, func(d wiimote.DeviceInfo) bool {
-		return d != nil
+	// enumerated devices do not carry an action, so only the driver is checked
+	iter = sequences.Filter(iter, isWiimote)
+	deviter := sequences.Map(iter, func(dev wiimote.DeviceInfo) *DeviceInfo {
+		return newDeviceInfo(dev, filters)
 	})
-	return deviter, nil
+	return func(yield func(*DeviceInfo) bool) {
+		seen := newDedup()
+		for info := range deviter {
+			if info == nil || !seen.add(info.Syspath, physicalKey(info)) {
+				continue
+			}
+			if !yield(info) {
+				return
+			}
+		}
+	}, nil
 }
 
 // WiimoteMonitor describes a monitor for wiimote-devices. This includes currently available
@@ -48,27 +144,37 @@ func IterDevices() (iter.Seq[wiimote.DeviceInfo], error) {
 //
 // Monitors are not thread-safe.
 type WiimoteMonitor struct {
-	wiimote.Poller[wiimote.DeviceInfo]
+	wiimote.Poller[*DeviceInfo]
 
 	monitor wiimote.DeviceMonitor
-	enum    chan wiimote.DeviceInfo
+	enum    chan *DeviceInfo
+	filters []Filter
+	// player number -> syspath, nil if players are not assigned
+	players map[int]string
+	// reported physical remotes
+	seen *dedup
 }
 
-// NewWiimoteMonitor creates a new monitor.
+// NewWiimoteMonitor creates a new monitor which reports devices passing all filters.
 //
 // A monitor always provides all devices that are available on a system
 // and hot-plugged devices.
 //
+// Every physical remote is reported once, even if the kernel exposes multiple hid devices for it.
+// A remote is reported again after all its hid devices are removed.
+//
 // The object and underlying structure is freed automatically by default.
-func NewWiimoteMonitor() (*WiimoteMonitor, error) {
+func NewWiimoteMonitor(filters ...Filter) (*WiimoteMonitor, error) {
 	var mon WiimoteMonitor
 	mon.Poller = common.NewPoller(&mon)
+	mon.filters = filters
+	mon.seen = newDedup()
 
-	devs, err := IterDevices()
+	devs, err := IterDevices(filters...)
 	if err != nil {
 		return nil, err
 	}
-	mon.enum = make(chan wiimote.DeviceInfo)
+	mon.enum = make(chan *DeviceInfo)
 	go func() {
 		for dev := range devs {
 			mon.enum <- dev
@@ -89,6 +195,44 @@ func NewWiimoteMonitor() (*WiimoteMonitor, error) {
 	return &mon, nil
 }
 
+// NewMonitorWithFilter creates a monitor which reports only devices of the given device types,
+// see OnlyDevTypes. Devices of other types are skipped before they are reported.
+func NewMonitorWithFilter(types ...string) (*WiimoteMonitor, error) {
+	return NewWiimoteMonitor(OnlyDevTypes(types...))
+}
+
+// AssignPlayers enables or disables automatic assignment of player numbers. If enabled, every
+// reported device gets the lowest free player number (1 to 4) in DeviceInfo.Player, which can be
+// shown using Device.SetPlayerLED. The number is released when the device is removed.
+func (mon *WiimoteMonitor) AssignPlayers(enable bool) {
+	if !enable {
+		mon.players = nil
+	} else if mon.players == nil {
+		mon.players = make(map[int]string)
+	}
+}
+
+func (mon *WiimoteMonitor) assignPlayer(info *DeviceInfo) {
+	if mon.players == nil {
+		return
+	}
+	for n := 1; n <= 4; n++ {
+		if _, ok := mon.players[n]; !ok {
+			mon.players[n] = info.Syspath
+			info.Player = n
+			return
+		}
+	}
+}
+
+func (mon *WiimoteMonitor) releasePlayer(syspath string) {
+	for n, path := range mon.players {
+		if path == syspath {
+			delete(mon.players, n)
+		}
+	}
+}
+
 // FD returns the file-descriptor to notify readiness. The FD is non-blocking.
 // Only one file-descriptor exists, that is, this function always returns the
 // same descriptor.
@@ -98,10 +242,9 @@ func (mon *WiimoteMonitor) FD() int {
 	return fd
 }
 
-// Poll returns a single device-name on each call. A device-name is actually
+// Poll returns a single device on each call. The device's syspath is
 // an absolute sysfs path to the device's root-node. This is normally a path
-// to /sys/bus/hid/devices/[dev]/. You can use this path to create a new
-// struct wii_iface object.
+// to /sys/bus/hid/devices/[dev]/.
 //
 // After a monitor was created, this function returns all currently available
 // devices. After all devices have been returned. After that, this function polls the
@@ -109,19 +252,38 @@ func (mon *WiimoteMonitor) FD() int {
 // if the monitor was opened to watch the system for hotplug events.
 //
 // Use FD() to get notified when a new event is available.
-func (mon *WiimoteMonitor) Poll() (wiimote.DeviceInfo, bool, error) {
+func (mon *WiimoteMonitor) Poll() (*DeviceInfo, bool, error) {
 	// test if enumerator has devices, then wait for new devices
-	if iter, ok := <-mon.enum; ok {
-		return iter, true, nil
+	if info, ok := <-mon.enum; ok {
+		mon.seen.add(info.Syspath, physicalKey(info))
+		mon.assignPlayer(info)
+		return info, true, nil
 	}
 
 	dev := mon.monitor.ReceiveDevice()
 	if dev == nil {
 		return nil, false, common.ErrWouldBlock
 	}
-	if (dev.Action() != "" && dev.Action() != "add") || dev.Driver() != "wiimote" || dev.Subsystem() != "hid" {
+	act := dev.Action()
+	if act == "remove" || act == "unbind" {
+		mon.releasePlayer(dev.Syspath())
+		mon.seen.remove(dev.Syspath())
+		return nil, false, common.ErrWouldBlock
+	}
+	// The hid device is announced with "add" before the wiimote driver is bound,
+	// the driver (and its attributes) are only present with the following "bind".
+	// The device type is "pending" until the detection finished, which is announced with "change".
+	if act != "add" && act != "bind" && act != "change" {
+		return nil, false, common.ErrWouldBlock
+	}
+	if !isWiimote(dev) {
 		return nil, false, common.ErrWouldBlock
 	}
 	time.Sleep(50 * time.Millisecond)
-	return dev, false, nil
+	info := newDeviceInfo(dev, mon.filters)
+	if info == nil || !mon.seen.add(info.Syspath, physicalKey(info)) {
+		return nil, false, common.ErrWouldBlock
+	}
+	mon.assignPlayer(info)
+	return info, false, nil
 }
@@ -0,0 +1,92 @@
+package discover
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+)
+
+func TestOnlyDevTypes(t *testing.T) {
+	f := OnlyDevTypes("balanceboard", "gen20")
+	for devtype, expect := range map[string]bool{
+		"balanceboard":  true,
+		"gen20":         true,
+		"gen10":         false,
+		"procontroller": false,
+		"":              false,
+	} {
+		if got := f(&DeviceInfo{DevType: devtype}); got != expect {
+			t.Errorf("%q: expected %v, got %v", devtype, expect, got)
+		}
+	}
+}
+
+type testDevice struct {
+	wiimote.DeviceInfo
+	action  string
+	devtype string
+}
+
+func (d *testDevice) Action() string    { return d.action }
+func (d *testDevice) Driver() string    { return "wiimote" }
+func (d *testDevice) Subsystem() string { return "hid" }
+func (d *testDevice) Syspath() string   { return "/sys/hid/0001" }
+
+func (d *testDevice) SysattrValue(sysattr string) string {
+	if sysattr == "devtype" {
+		return d.devtype
+	}
+	return ""
+}
+
+func (d *testDevice) PropertyValue(key string) string {
+	if key == "HID_UNIQ" {
+		return "00:11"
+	}
+	return ""
+}
+
+type testMonitor struct {
+	wiimote.DeviceMonitor
+	devices []wiimote.DeviceInfo
+}
+
+func (m *testMonitor) ReceiveDevice() wiimote.DeviceInfo {
+	if len(m.devices) == 0 {
+		return nil
+	}
+	dev := m.devices[0]
+	m.devices = m.devices[1:]
+	return dev
+}
+
+func TestMonitorPendingDevType(t *testing.T) {
+	enum := make(chan *DeviceInfo)
+	close(enum)
+	mon := &WiimoteMonitor{
+		monitor: &testMonitor{devices: []wiimote.DeviceInfo{
+			&testDevice{action: "bind", devtype: "pending"},
+			&testDevice{action: "change", devtype: "gen20"},
+			&testDevice{action: "change", devtype: "gen20"},
+		}},
+		enum:    enum,
+		filters: []Filter{OnlyDevTypes("gen20")},
+		seen:    newDedup(),
+	}
+
+	if _, _, err := mon.Poll(); !errors.Is(err, common.ErrWouldBlock) {
+		t.Fatalf("expected pending device to be skipped, got %v", err)
+	}
+	info, _, err := mon.Poll()
+	if err != nil {
+		t.Fatalf("expected detected device to be reported, got %v", err)
+	}
+	if info.DevType != "gen20" {
+		t.Errorf("expected device type gen20, got %q", info.DevType)
+	}
+	if _, _, err := mon.Poll(); !errors.Is(err, common.ErrWouldBlock) {
+		t.Errorf("expected device to be reported once, got %v", err)
+	}
+}
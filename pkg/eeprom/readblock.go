@@ -127,3 +127,19 @@ func ReadAccelCalibration(r io.ReaderAt) (accel [2]wiimote.Vec3, speaker uint8,
 
 	return
 }
+
+// accelCenter is subtracted from the raw accelerometer values by the drivers.
+const accelCenter = 0x200
+
+// ReadAccelCalib reads the accelerometer calibration in the units of wiimote.EventAccel.
+func ReadAccelCalib(r io.ReaderAt) (wiimote.AccelCalibration, error) {
+	accel, _, _, err := ReadAccelCalibration(r)
+	if err != nil {
+		return wiimote.AccelCalibration{}, err
+	}
+	center := wiimote.Vec3{X: accelCenter, Y: accelCenter, Z: accelCenter}
+	return wiimote.AccelCalibration{
+		Zero: wiimote.Vec3{X: accel[0].X - center.X, Y: accel[0].Y - center.Y, Z: accel[0].Z - center.Z},
+		One:  wiimote.Vec3{X: accel[1].X - center.X, Y: accel[1].Y - center.Y, Z: accel[1].Z - center.Z},
+	}, nil
+}
@@ -0,0 +1,207 @@
+// Package fitness counts exercise repetitions on a Wii Balance Board. Squats and heel raises
+// are detected from the total weight and the oscillation of the center of balance, every
+// completed repetition is reported as RepEvent and a session is summarized by Summary.
+package fitness
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/pkg/balance"
+)
+
+// Exercise is the kind of a repetition.
+type Exercise uint8
+
+const (
+	// Squat moves the center of balance towards the heels and unloads the board when going down
+	Squat Exercise = iota
+	// HeelRaise moves the center of balance towards the toes
+	HeelRaise
+)
+
+var exerciseNames = [...]string{"squat", "heel raise"}
+
+func (e Exercise) String() string {
+	if int(e) < len(exerciseNames) {
+		return exerciseNames[e]
+	}
+	return "Exercise(" + strconv.Itoa(int(e)) + ")"
+}
+
+// RepEvent is emitted when a repetition is completed.
+type RepEvent struct {
+	wiimote.Event
+	Exercise Exercise
+	// Count is the number of repetitions of Exercise in the session, including this one
+	Count int
+	// Duration is the time from leaving to returning to the upright position
+	Duration time.Duration
+	// Depth is the peak offset of the center of balance (0..1) during the repetition
+	Depth float64
+}
+
+// ExerciseSummary summarizes the repetitions of an exercise.
+type ExerciseSummary struct {
+	Count int
+	// Duration is the total duration of all repetitions
+	Duration time.Duration
+	// Depth is the average depth of the repetitions, see RepEvent
+	Depth float64
+}
+
+// Average returns the average duration of a repetition.
+func (s ExerciseSummary) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Duration / time.Duration(s.Count)
+}
+
+// Summary summarizes a session.
+type Summary struct {
+	// Start is the time the user stepped on the board, End the time of the latest sample
+	Start, End time.Time
+	// Weight is the standing weight of the user in kilograms
+	Weight    float64
+	Exercises map[Exercise]ExerciseSummary
+}
+
+// Reps returns the number of repetitions of all exercises.
+func (s Summary) Reps() int {
+	n := 0
+	for _, e := range s.Exercises {
+		n += e.Count
+	}
+	return n
+}
+
+// Counter counts repetitions from the weights of a balance board. It is not thread-safe.
+type Counter struct {
+	// MinWeight is the weight in kilograms from which the board is considered occupied
+	MinWeight float64
+	// SquatShift is the offset of the center of balance towards the heels (0..1) which starts a squat
+	SquatShift float64
+	// SquatDip is the fraction of the standing weight the total must drop by to start a squat
+	SquatDip float64
+	// RaiseShift is the offset of the center of balance towards the toes (0..1) which starts a
+	// heel raise
+	RaiseShift float64
+	// Hysteresis is subtracted from the shifts to return to the upright position
+	Hysteresis float64
+	// MinRepTime and MaxRepTime are the bounds of the duration of a repetition, other
+	// movements are not counted
+	MinRepTime, MaxRepTime time.Duration
+	// WeightSmoothing is the weight of a new sample for the standing weight (0..1)
+	WeightSmoothing float64
+
+	on       bool
+	standing float64
+	inRep    bool
+	exercise Exercise
+	repStart time.Time
+	depth    float64
+	summary  Summary
+}
+
+// NewCounter returns a counter with thresholds which work for adults.
+func NewCounter() *Counter {
+	return &Counter{
+		MinWeight:       10,
+		SquatShift:      0.2,
+		SquatDip:        0.1,
+		RaiseShift:      0.3,
+		Hysteresis:      0.1,
+		MinRepTime:      300 * time.Millisecond,
+		MaxRepTime:      10 * time.Second,
+		WeightSmoothing: 0.02,
+	}
+}
+
+// Reset ends the session, the next sample with a user on the board starts a new session.
+func (c *Counter) Reset() {
+	c.on = false
+	c.inRep = false
+	c.summary = Summary{}
+}
+
+// Summary returns the summary of the current session.
+func (c *Counter) Summary() Summary {
+	s := c.summary
+	s.Weight = c.standing
+	s.Exercises = make(map[Exercise]ExerciseSummary, len(c.summary.Exercises))
+	for e, es := range c.summary.Exercises {
+		s.Exercises[e] = es
+	}
+	return s
+}
+
+// Update processes a balance board event and returns the completed repetition, other events
+// are ignored. The session continues when the user steps off, see Reset.
+func (c *Counter) Update(ev wiimote.Event) (*RepEvent, bool) {
+	bb, ok := ev.(*wiimote.EventBalanceBoard)
+	if !ok {
+		return nil, false
+	}
+	t := ev.Timestamp()
+	total := balance.Total(bb.Weights)
+	if total < c.MinWeight {
+		// stepping off aborts the repetition
+		c.inRep = false
+		return nil, false
+	}
+	if !c.on {
+		c.on = true
+		c.standing = total
+		c.summary = Summary{Start: t, Exercises: make(map[Exercise]ExerciseSummary)}
+	}
+	c.summary.End = t
+
+	center := balance.Center(bb.Weights)
+	if !c.inRep {
+		switch {
+		case center.Y <= -c.SquatShift || total <= c.standing*(1-c.SquatDip):
+			c.start(Squat, t)
+		case center.Y >= c.RaiseShift:
+			c.start(HeelRaise, t)
+		default:
+			s := c.WeightSmoothing
+			c.standing = s*total + (1-s)*c.standing
+			return nil, false
+		}
+	}
+	c.depth = max(c.depth, math.Abs(center.Y))
+
+	if !c.upright(center, total) {
+		return nil, false
+	}
+	c.inRep = false
+	duration := t.Sub(c.repStart)
+	if duration < c.MinRepTime || duration > c.MaxRepTime {
+		return nil, false
+	}
+
+	es := c.summary.Exercises[c.exercise]
+	es.Depth = (es.Depth*float64(es.Count) + c.depth) / float64(es.Count+1)
+	es.Count++
+	es.Duration += duration
+	c.summary.Exercises[c.exercise] = es
+	return &RepEvent{Event: ev, Exercise: c.exercise, Count: es.Count, Duration: duration, Depth: c.depth}, true
+}
+
+func (c *Counter) start(e Exercise, t time.Time) {
+	c.inRep = true
+	c.exercise = e
+	c.repStart = t
+	c.depth = 0
+}
+
+// upright reports whether the user returned from the position of the exercise.
+func (c *Counter) upright(center wiimote.FVec2, total float64) bool {
+	if c.exercise == HeelRaise {
+		return center.Y < c.RaiseShift-c.Hysteresis
+	}
+	return center.Y > -(c.SquatShift-c.Hysteresis) && total > c.standing*(1-c.SquatDip/2)
+}
@@ -0,0 +1,88 @@
+package fitness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/pkg/balance"
+)
+
+type testEvent struct {
+	ts time.Time
+}
+
+func (e testEvent) Feature() wiimote.Feature { return nil }
+func (e testEvent) Device() wiimote.Device   { return nil }
+func (e testEvent) Timestamp() time.Time     { return e.ts }
+
+// sample returns a balance board event with kg distributed by the offset y of the center of
+// balance towards the toes.
+func sample(at time.Duration, kg, y float64) *wiimote.EventBalanceBoard {
+	quarter := kg * balance.UnitsPerKg / 4
+	return &wiimote.EventBalanceBoard{
+		Event: testEvent{time.Unix(0, 0).Add(at)},
+		Weights: [4]int32{
+			balance.TopRight:    int32(quarter * (1 + y)),
+			balance.BottomRight: int32(quarter * (1 - y)),
+			balance.TopLeft:     int32(quarter * (1 + y)),
+			balance.BottomLeft:  int32(quarter * (1 - y)),
+		},
+	}
+}
+
+// reps feeds one sample per 100ms at the weights and offsets to c.
+func reps(c *Counter, start time.Duration, kg, y []float64) []*RepEvent {
+	var out []*RepEvent
+	for i := range kg {
+		if rep, ok := c.Update(sample(start+time.Duration(i)*100*time.Millisecond, kg[i], y[i])); ok {
+			out = append(out, rep)
+		}
+	}
+	return out
+}
+
+func TestSquatsAndHeelRaises(t *testing.T) {
+	c := NewCounter()
+	var got []*RepEvent
+	for i := range 3 {
+		// going down unloads the board and moves the center to the heels
+		got = append(got, reps(c, time.Duration(i)*2*time.Second,
+			[]float64{80, 80, 80, 65, 75, 80, 85, 80, 80},
+			[]float64{0, 0, 0, -0.1, -0.3, -0.3, -0.2, 0, 0})...)
+	}
+	got = append(got, reps(c, 10*time.Second,
+		[]float64{80, 80, 80, 80, 80, 80},
+		[]float64{0, 0.4, 0.5, 0.4, 0, 0})...)
+
+	if len(got) != 4 {
+		t.Fatalf("expected 4 repetitions, got %d", len(got))
+	}
+	for i, rep := range got[:3] {
+		if rep.Exercise != Squat || rep.Count != i+1 {
+			t.Errorf("expected squat %d, got %v %d", i+1, rep.Exercise, rep.Count)
+		}
+	}
+	if got[3].Exercise != HeelRaise || got[3].Count != 1 || got[3].Depth < 0.49 {
+		t.Errorf("expected heel raise with depth 0.5, got %+v", got[3])
+	}
+
+	s := c.Summary()
+	if s.Reps() != 4 || s.Exercises[Squat].Count != 3 || s.Exercises[Squat].Average() != 400*time.Millisecond {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s.Weight < 79 || s.Weight > 81 {
+		t.Errorf("expected weight of 80kg, got %v", s.Weight)
+	}
+}
+
+func TestSwayIsNoRep(t *testing.T) {
+	c := NewCounter()
+	// too short and too small movements
+	got := reps(c, 0,
+		[]float64{80, 80, 78, 80, 80, 80, 80},
+		[]float64{0, 0.1, -0.15, 0.4, 0.1, -0.05, 0})
+	if len(got) != 0 {
+		t.Fatalf("expected no repetitions, got %+v", got)
+	}
+}
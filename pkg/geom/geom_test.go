@@ -0,0 +1,42 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVecMath(t *testing.T) {
+	v, w := Vec2{X: 3, Y: 4}, Vec2{X: 1, Y: -2}
+	if got := v.Add(w); got != (Vec2{X: 4, Y: 2}) {
+		t.Errorf("Add = %v", got)
+	}
+	if got := v.Sub(w); got != (Vec2{X: 2, Y: 6}) {
+		t.Errorf("Sub = %v", got)
+	}
+	if got := v.Scale(0.5); got != (Vec2{X: 2, Y: 2}) {
+		t.Errorf("Scale = %v", got)
+	}
+	if got := v.Dot(w); got != -5 {
+		t.Errorf("Dot = %v", got)
+	}
+	if got := v.Len(); got != 5 {
+		t.Errorf("Len = %v", got)
+	}
+	if got := v.Normalize(); math.Abs(got.X-0.6) > 1e-9 || math.Abs(got.Y-0.8) > 1e-9 {
+		t.Errorf("Normalize = %v", got)
+	}
+}
+
+func TestRectTranslate(t *testing.T) {
+	src := FRect{Min: FVec2{X: -1, Y: -1}, Max: FVec2{X: 1, Y: 1}}
+	dst := FRect{Min: FVec2{X: 0, Y: 0}, Max: FVec2{X: 100, Y: 50}}
+	if got := src.Translate(FVec2{X: 0, Y: 0}, dst, false); got != (FVec2{X: 50, Y: 25}) {
+		t.Errorf("Translate(center) = %v", got)
+	}
+	if got := src.Translate(FVec2{X: 3, Y: -3}, dst, true); got != (FVec2{X: 100, Y: 0}) {
+		t.Errorf("Translate(clamped) = %v", got)
+	}
+	if !src.Contains(FVec2{}) || src.Contains(src.Max) {
+		t.Errorf("Contains is not inclusive on Min and exclusive on Max")
+	}
+}
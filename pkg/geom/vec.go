@@ -0,0 +1,91 @@
+// Package geom provides the integer and floating point vectors and rectangles shared by the
+// wiimote packages.
+package geom
+
+import "math"
+
+// Vec2 represents a 2D point or vector to X and Y, may be interpreted different depending on the event .
+type Vec2 struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+}
+
+// FVec2 represents a 2D floating point vector to X and Y.
+type FVec2 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ToF converts v to floating point.
+func (v Vec2) ToF() FVec2 {
+	return FVec2{X: float64(v.X), Y: float64(v.Y)}
+}
+
+// Add returns v+w.
+func (v Vec2) Add(w Vec2) Vec2 {
+	return Vec2{X: v.X + w.X, Y: v.Y + w.Y}
+}
+
+// Sub returns v-w.
+func (v Vec2) Sub(w Vec2) Vec2 {
+	return Vec2{X: v.X - w.X, Y: v.Y - w.Y}
+}
+
+// Scale returns v multiplied by f, rounded to the nearest integer.
+func (v Vec2) Scale(f float64) Vec2 {
+	return v.ToF().Scale(f).Round()
+}
+
+// Dot returns the dot product of v and w.
+func (v Vec2) Dot(w Vec2) int64 {
+	return int64(v.X)*int64(w.X) + int64(v.Y)*int64(w.Y)
+}
+
+// Len returns the length of v.
+func (v Vec2) Len() float64 {
+	return v.ToF().Len()
+}
+
+// Normalize returns v scaled to a length of 1, see FVec2.Normalize.
+func (v Vec2) Normalize() FVec2 {
+	return v.ToF().Normalize()
+}
+
+// Round returns v rounded to the nearest integers.
+func (v FVec2) Round() Vec2 {
+	return Vec2{X: int32(math.Round(v.X)), Y: int32(math.Round(v.Y))}
+}
+
+// Add returns v+w.
+func (v FVec2) Add(w FVec2) FVec2 {
+	return FVec2{X: v.X + w.X, Y: v.Y + w.Y}
+}
+
+// Sub returns v-w.
+func (v FVec2) Sub(w FVec2) FVec2 {
+	return FVec2{X: v.X - w.X, Y: v.Y - w.Y}
+}
+
+// Scale returns v multiplied by f.
+func (v FVec2) Scale(f float64) FVec2 {
+	return FVec2{X: v.X * f, Y: v.Y * f}
+}
+
+// Dot returns the dot product of v and w.
+func (v FVec2) Dot(w FVec2) float64 {
+	return v.X*w.X + v.Y*w.Y
+}
+
+// Len returns the length of v.
+func (v FVec2) Len() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Normalize returns v scaled to a length of 1. The zero vector is returned unchanged.
+func (v FVec2) Normalize() FVec2 {
+	l := v.Len()
+	if l == 0 {
+		return v
+	}
+	return v.Scale(1 / l)
+}
@@ -0,0 +1,242 @@
+// Package gesture recognizes high-level gestures like shakes, swings and twists from
+// accelerometer and Motion Plus events, and boxing-style gestures of both hands using a
+// Nunchuk. The recognizer uses simple heuristics and needs no
+// training, the thresholds can be tuned.
+package gesture
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Kind describes the kind of a gesture.
+type Kind uint8
+
+const (
+	// Shake is a repeated back-and-forth movement along any axis
+	Shake Kind = iota
+	SwingLeft
+	SwingRight
+	SwingUp
+	SwingDown
+	// TwistCW is a fast clockwise rotation around the length of the remote, requires Motion Plus
+	TwistCW
+	// TwistCCW is a fast counter-clockwise rotation around the length of the remote, requires Motion Plus
+	TwistCCW
+	// Thrust is a fast movement towards the screen
+	Thrust
+	// PunchLeft is a punch of the left hand, see TwoHand
+	PunchLeft
+	// PunchRight is a punch of the right hand, see TwoHand
+	PunchRight
+	// Block is raising both hands upright in front of the face, see TwoHand
+	Block
+	// Impact is the abrupt stop of a punch, see TwoHand
+	Impact
+)
+
+var kindNames = [...]string{"Shake", "SwingLeft", "SwingRight", "SwingUp", "SwingDown", "TwistCW", "TwistCCW", "Thrust",
+	"PunchLeft", "PunchRight", "Block", "Impact"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Gesture is a recognized gesture.
+type Gesture struct {
+	Kind Kind
+	// Time of the sample which completed the gesture
+	Time time.Time
+	// Strength is the peak acceleration in g for shakes, swings and thrusts, and the peak
+	// rotation speed in degree per second for twists
+	Strength float64
+	// Hand which performed the gesture, only set by TwoHand
+	Hand Hand
+}
+
+// Recognizer recognizes gestures from a stream of events.
+type Recognizer struct {
+	// Calibration of the accelerometer
+	Calibration wiimote.AccelCalibration
+	// GravitySmoothing is the weight of a new sample for the gravity estimate (0..1)
+	GravitySmoothing float64
+
+	// SwingThreshold is the linear acceleration in g which starts a swing or thrust
+	SwingThreshold float64
+	// ShakeThreshold is the linear acceleration in g counted as shake peak
+	ShakeThreshold float64
+	// ShakeCount is the number of direction changes within ShakeWindow to detect a shake
+	ShakeCount int
+	// ShakeWindow is the time in which the direction changes of a shake must occur
+	ShakeWindow time.Duration
+	// TwistThreshold is the rotation speed in degree per second which starts a twist
+	TwistThreshold float64
+	// Cooldown is the time after a gesture in which no other gesture of the same family
+	// (movement or twist) is reported
+	Cooldown time.Duration
+
+	gravity     wiimote.FVec3
+	alive       bool
+	moveUntil   time.Time
+	twistUntil  time.Time
+	shakeSign   [3]int
+	shakeFlips  []time.Time
+	shakeStrong float64
+}
+
+// NewRecognizer returns a recognizer with thresholds which work for casual movements.
+func NewRecognizer() *Recognizer {
+	return &Recognizer{
+		Calibration:      wiimote.NominalAccel,
+		GravitySmoothing: 0.05,
+		SwingThreshold:   1.5,
+		ShakeThreshold:   1.2,
+		ShakeCount:       4,
+		ShakeWindow:      800 * time.Millisecond,
+		TwistThreshold:   300,
+		Cooldown:         400 * time.Millisecond,
+	}
+}
+
+// Reset drops the gravity estimate and all pending gestures.
+func (r *Recognizer) Reset() {
+	r.alive = false
+	r.moveUntil = time.Time{}
+	r.twistUntil = time.Time{}
+	r.shakeSign = [3]int{}
+	r.shakeFlips = nil
+	r.shakeStrong = 0
+}
+
+// Update processes an accelerometer or Motion Plus event, other events are ignored. It returns
+// the recognized gesture and true, if any.
+func (r *Recognizer) Update(ev wiimote.Event) (Gesture, bool) {
+	switch ev := ev.(type) {
+	case *wiimote.EventAccel:
+		return r.UpdateAccel(ev.GWith(r.Calibration), ev.Timestamp())
+	case *wiimote.EventMotionPlus:
+		return r.UpdateMotionPlus(ev.Speed, ev.Timestamp())
+	}
+	return Gesture{}, false
+}
+
+// UpdateAccel is like Update but takes the acceleration in g.
+func (r *Recognizer) UpdateAccel(g wiimote.FVec3, t time.Time) (Gesture, bool) {
+	if !r.alive {
+		r.alive = true
+		r.gravity = g
+		return Gesture{}, false
+	}
+	a := r.GravitySmoothing
+	r.gravity = wiimote.FVec3{
+		X: a*g.X + (1-a)*r.gravity.X,
+		Y: a*g.Y + (1-a)*r.gravity.Y,
+		Z: a*g.Z + (1-a)*r.gravity.Z,
+	}
+	lin := [3]float64{g.X - r.gravity.X, g.Y - r.gravity.Y, g.Z - r.gravity.Z}
+
+	if gest, ok := r.updateShake(lin, t); ok {
+		return gest, true
+	}
+
+	if t.Before(r.moveUntil) {
+		return Gesture{}, false
+	}
+	axis, peak := dominant(lin)
+	if math.Abs(peak) < r.SwingThreshold {
+		return Gesture{}, false
+	}
+	// X points to the left, Y towards the screen and Z upwards
+	var kind Kind
+	switch {
+	case axis == 0 && peak > 0:
+		kind = SwingLeft
+	case axis == 0:
+		kind = SwingRight
+	case axis == 1 && peak > 0:
+		kind = Thrust
+	case axis == 1:
+		// pulling back is not a gesture
+		return Gesture{}, false
+	case peak > 0:
+		kind = SwingUp
+	default:
+		kind = SwingDown
+	}
+	r.moveUntil = t.Add(r.Cooldown)
+	return Gesture{Kind: kind, Time: t, Strength: math.Abs(peak)}, true
+}
+
+func (r *Recognizer) updateShake(lin [3]float64, t time.Time) (Gesture, bool) {
+	for i, v := range lin {
+		if math.Abs(v) < r.ShakeThreshold {
+			continue
+		}
+		sign := 1
+		if v < 0 {
+			sign = -1
+		}
+		if r.shakeSign[i] != 0 && r.shakeSign[i] != sign {
+			r.shakeFlips = append(r.shakeFlips, t)
+			r.shakeStrong = max(r.shakeStrong, math.Abs(v))
+		}
+		r.shakeSign[i] = sign
+	}
+
+	// drop direction changes outside of the window
+	n := 0
+	for _, flip := range r.shakeFlips {
+		if t.Sub(flip) <= r.ShakeWindow {
+			r.shakeFlips[n] = flip
+			n++
+		}
+	}
+	r.shakeFlips = r.shakeFlips[:n]
+	if n == 0 {
+		r.shakeStrong = 0
+	}
+
+	if n < r.ShakeCount {
+		return Gesture{}, false
+	}
+	gest := Gesture{Kind: Shake, Time: t, Strength: r.shakeStrong}
+	r.shakeFlips = r.shakeFlips[:0]
+	r.shakeSign = [3]int{}
+	r.shakeStrong = 0
+	r.moveUntil = t.Add(r.Cooldown)
+	return gest, true
+}
+
+// UpdateMotionPlus is like Update but takes the raw rotation speed of the Motion Plus.
+func (r *Recognizer) UpdateMotionPlus(speed wiimote.Vec3, t time.Time) (Gesture, bool) {
+	if t.Before(r.twistUntil) {
+		return Gesture{}, false
+	}
+	// Y is the rotation around the length of the remote
+	roll := wiimote.MPDegrees(speed).Y
+	if math.Abs(roll) < r.TwistThreshold {
+		return Gesture{}, false
+	}
+	kind := TwistCW
+	if roll < 0 {
+		kind = TwistCCW
+	}
+	r.twistUntil = t.Add(r.Cooldown)
+	return Gesture{Kind: kind, Time: t, Strength: math.Abs(roll)}, true
+}
+
+// dominant returns the axis and value of the largest component of v.
+func dominant(v [3]float64) (axis int, value float64) {
+	for i, x := range v {
+		if math.Abs(x) > math.Abs(value) {
+			axis, value = i, x
+		}
+	}
+	return
+}
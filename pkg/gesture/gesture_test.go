@@ -0,0 +1,148 @@
+package gesture
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+var rest = wiimote.FVec3{Z: 1}
+
+func feed(r *Recognizer, start time.Time, samples []wiimote.FVec3) []Gesture {
+	var out []Gesture
+	for i, s := range samples {
+		if g, ok := r.UpdateAccel(s, start.Add(time.Duration(i)*10*time.Millisecond)); ok {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+func repeat(v wiimote.FVec3, n int) []wiimote.FVec3 {
+	s := make([]wiimote.FVec3, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+func TestSwing(t *testing.T) {
+	tests := []struct {
+		peak wiimote.FVec3
+		kind Kind
+	}{
+		{wiimote.FVec3{X: 2, Z: 1}, SwingLeft},
+		{wiimote.FVec3{X: -2, Z: 1}, SwingRight},
+		{wiimote.FVec3{Z: 3}, SwingUp},
+		{wiimote.FVec3{Z: -1}, SwingDown},
+		{wiimote.FVec3{Y: 2, Z: 1}, Thrust},
+	}
+	for _, tc := range tests {
+		r := NewRecognizer()
+		samples := repeat(rest, 20)
+		samples = append(samples, tc.peak, tc.peak)
+		samples = append(samples, repeat(rest, 20)...)
+		got := feed(r, time.Unix(0, 0), samples)
+		if len(got) != 1 || got[0].Kind != tc.kind {
+			t.Errorf("%+v: expected single %v, got %v", tc.peak, tc.kind, got)
+		}
+	}
+}
+
+func TestShake(t *testing.T) {
+	r := NewRecognizer()
+	samples := repeat(rest, 20)
+	for range 3 {
+		samples = append(samples, repeat(wiimote.FVec3{X: 2.5, Z: 1}, 5)...)
+		samples = append(samples, repeat(wiimote.FVec3{X: -2.5, Z: 1}, 5)...)
+	}
+	got := feed(r, time.Unix(0, 0), samples)
+
+	shakes := 0
+	for _, g := range got {
+		if g.Kind == Shake {
+			shakes++
+		}
+	}
+	if shakes != 1 {
+		t.Fatalf("expected one shake, got %v", got)
+	}
+}
+
+func TestRestIsNoGesture(t *testing.T) {
+	r := NewRecognizer()
+	if got := feed(r, time.Unix(0, 0), repeat(rest, 200)); len(got) != 0 {
+		t.Fatalf("expected no gestures, got %v", got)
+	}
+}
+
+func TestTwist(t *testing.T) {
+	r := NewRecognizer()
+	start := time.Unix(0, 0)
+	deg := 500.0
+	fast := int32(deg * wiimote.MPUnitsPerDegree)
+
+	if _, ok := r.UpdateMotionPlus(wiimote.Vec3{Y: 100}, start); ok {
+		t.Fatalf("expected no twist at low speed")
+	}
+	g, ok := r.UpdateMotionPlus(wiimote.Vec3{Y: -fast}, start.Add(10*time.Millisecond))
+	if !ok || g.Kind != TwistCCW {
+		t.Fatalf("expected TwistCCW, got %v %v", g, ok)
+	}
+	// cooldown
+	if _, ok := r.UpdateMotionPlus(wiimote.Vec3{Y: fast}, start.Add(20*time.Millisecond)); ok {
+		t.Fatalf("expected no twist during cooldown")
+	}
+	g, ok = r.UpdateMotionPlus(wiimote.Vec3{Y: fast}, start.Add(time.Second))
+	if !ok || g.Kind != TwistCW {
+		t.Fatalf("expected TwistCW, got %v %v", g, ok)
+	}
+}
+
+func TestTwoHandPunch(t *testing.T) {
+	r := NewTwoHand()
+	start := time.Unix(0, 0)
+	var got []Gesture
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * 10 * time.Millisecond) }
+	update := func(hand Hand, g wiimote.FVec3, i int) {
+		if gest, ok := r.UpdateHand(hand, g, at(i)); ok {
+			got = append(got, gest)
+		}
+	}
+	for i := range 20 {
+		update(LeftHand, rest, i)
+		update(RightHand, rest, i)
+	}
+	update(LeftHand, wiimote.FVec3{Y: 2.5, Z: 1}, 20)
+	update(LeftHand, wiimote.FVec3{Y: -2, Z: 1}, 25)
+	update(RightHand, wiimote.FVec3{Y: 2.5, Z: 1}, 30)
+
+	if len(got) != 3 ||
+		got[0].Kind != PunchLeft || got[0].Hand != LeftHand ||
+		got[1].Kind != Impact || got[1].Hand != LeftHand ||
+		got[2].Kind != PunchRight || got[2].Hand != RightHand {
+		t.Fatalf("expected left punch, impact and right punch, got %v", got)
+	}
+}
+
+func TestTwoHandBlock(t *testing.T) {
+	r := NewTwoHand()
+	r.GravitySmoothing = 1
+	start := time.Unix(0, 0)
+	up := wiimote.FVec3{Y: 1}
+
+	r.UpdateHand(LeftHand, rest, start)
+	r.UpdateHand(RightHand, rest, start)
+	if _, ok := r.UpdateHand(LeftHand, up, start.Add(time.Second)); ok {
+		t.Fatalf("expected no block with a single raised hand")
+	}
+	g, ok := r.UpdateHand(RightHand, up, start.Add(2*time.Second))
+	if !ok || g.Kind != Block {
+		t.Fatalf("expected block, got %v %v", g, ok)
+	}
+	if o := r.Orientation(LeftHand); math.Abs(o.Pitch-90) > 1e-9 {
+		t.Errorf("expected pitch of 90, got %v", o.Pitch)
+	}
+}
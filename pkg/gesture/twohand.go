@@ -0,0 +1,175 @@
+package gesture
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Hand describes the hand holding a controller.
+type Hand uint8
+
+const (
+	// NoHand is used for gestures which are not bound to a hand
+	NoHand Hand = iota
+	LeftHand
+	RightHand
+)
+
+var handNames = [...]string{"NoHand", "LeftHand", "RightHand"}
+
+func (h Hand) String() string {
+	if int(h) < len(handNames) {
+		return handNames[h]
+	}
+	return "Hand(" + strconv.Itoa(int(h)) + ")"
+}
+
+// Orientation is the orientation of a hand, derived from gravity.
+type Orientation struct {
+	// Pitch in degrees, positive if the controller points upwards
+	Pitch float64
+	// Roll in degrees, 0 if the controller is held flat with the buttons up
+	Roll float64
+}
+
+type handState struct {
+	gravity wiimote.FVec3
+	alive   bool
+	// the punch ends at punchEnd, an impact is only reported before
+	punchEnd   time.Time
+	punchUntil time.Time
+}
+
+// TwoHand recognizes boxing-style gestures from the accelerometers of the remote and a Nunchuk:
+// punches of either hand, blocks and the impacts at the end of punches. The remote is assumed
+// to be held in the right hand, set Swapped for left-handed players.
+type TwoHand struct {
+	// Calibration of the accelerometer of the remote
+	Calibration wiimote.AccelCalibration
+	// NunchukCalibration of the accelerometer of the Nunchuk
+	NunchukCalibration wiimote.AccelCalibration
+	// GravitySmoothing is the weight of a new sample for the gravity estimate (0..1)
+	GravitySmoothing float64
+	// Swapped holds the remote in the left and the Nunchuk in the right hand
+	Swapped bool
+
+	// PunchThreshold is the linear acceleration in g towards the screen which starts a punch
+	PunchThreshold float64
+	// ImpactThreshold is the linear deceleration in g which is reported as impact
+	ImpactThreshold float64
+	// ImpactWindow is the time after the start of a punch in which an impact is reported
+	ImpactWindow time.Duration
+	// BlockPitch is the pitch in degrees both hands must exceed for a block
+	BlockPitch float64
+	// Cooldown is the time after a punch in which no other punch of the same hand is reported
+	Cooldown time.Duration
+
+	hands    [2]handState
+	blocking bool
+}
+
+// NewTwoHand returns a recognizer with thresholds which work for casual boxing.
+func NewTwoHand() *TwoHand {
+	return &TwoHand{
+		Calibration:        wiimote.NominalAccel,
+		NunchukCalibration: wiimote.NominalNunchukAccel,
+		GravitySmoothing:   0.05,
+		PunchThreshold:     1.5,
+		ImpactThreshold:    1.5,
+		ImpactWindow:       300 * time.Millisecond,
+		BlockPitch:         60,
+		Cooldown:           300 * time.Millisecond,
+	}
+}
+
+// Reset drops the gravity estimates and all pending gestures.
+func (r *TwoHand) Reset() {
+	r.hands = [2]handState{}
+	r.blocking = false
+}
+
+// Update processes an accelerometer event of the remote or a movement event of the Nunchuk,
+// other events are ignored. It returns the recognized gesture and true, if any.
+func (r *TwoHand) Update(ev wiimote.Event) (Gesture, bool) {
+	remote, nunchuk := RightHand, LeftHand
+	if r.Swapped {
+		remote, nunchuk = nunchuk, remote
+	}
+	switch ev := ev.(type) {
+	case *wiimote.EventAccel:
+		return r.UpdateHand(remote, ev.GWith(r.Calibration), ev.Timestamp())
+	case *wiimote.EventNunchukMove:
+		return r.UpdateHand(nunchuk, r.NunchukCalibration.G(ev.Accel), ev.Timestamp())
+	}
+	return Gesture{}, false
+}
+
+// Orientation returns the orientation of hand, which is only valid after the first sample of
+// the hand.
+func (r *TwoHand) Orientation(hand Hand) Orientation {
+	if hand != LeftHand && hand != RightHand {
+		return Orientation{}
+	}
+	g := r.hands[hand-LeftHand].gravity
+	return Orientation{
+		Pitch: g.Pitch() * 180 / math.Pi,
+		Roll:  g.Roll() * 180 / math.Pi,
+	}
+}
+
+// UpdateHand is like Update but takes the acceleration of hand in g.
+func (r *TwoHand) UpdateHand(hand Hand, g wiimote.FVec3, t time.Time) (Gesture, bool) {
+	if hand != LeftHand && hand != RightHand {
+		return Gesture{}, false
+	}
+	h := &r.hands[hand-LeftHand]
+	if !h.alive {
+		h.alive = true
+		h.gravity = g
+		return Gesture{}, false
+	}
+	a := r.GravitySmoothing
+	h.gravity = wiimote.FVec3{
+		X: a*g.X + (1-a)*h.gravity.X,
+		Y: a*g.Y + (1-a)*h.gravity.Y,
+		Z: a*g.Z + (1-a)*h.gravity.Z,
+	}
+	// Y points towards the screen
+	forward := g.Y - h.gravity.Y
+
+	if t.Before(h.punchEnd) && -forward >= r.ImpactThreshold {
+		h.punchEnd = time.Time{}
+		return Gesture{Kind: Impact, Time: t, Strength: -forward, Hand: hand}, true
+	}
+	if !t.Before(h.punchUntil) && forward >= r.PunchThreshold {
+		h.punchUntil = t.Add(r.Cooldown)
+		h.punchEnd = t.Add(r.ImpactWindow)
+		kind := PunchRight
+		if hand == LeftHand {
+			kind = PunchLeft
+		}
+		return Gesture{Kind: kind, Time: t, Strength: forward, Hand: hand}, true
+	}
+	return r.updateBlock(t)
+}
+
+// updateBlock reports a block once both hands are raised, it is reported again after a hand
+// was lowered.
+func (r *TwoHand) updateBlock(t time.Time) (Gesture, bool) {
+	if !r.hands[0].alive || !r.hands[1].alive {
+		return Gesture{}, false
+	}
+	left, right := r.Orientation(LeftHand).Pitch, r.Orientation(RightHand).Pitch
+	raised := left >= r.BlockPitch && right >= r.BlockPitch
+	if raised == r.blocking {
+		return Gesture{}, false
+	}
+	r.blocking = raised
+	if !raised {
+		return Gesture{}, false
+	}
+	return Gesture{Kind: Block, Time: t, Strength: min(left, right)}, true
+}
@@ -0,0 +1,138 @@
+// Package guitar converts the raw movement data of guitar extensions into normalized values
+// and combines fret and strum bar events into notes.
+package guitar
+
+import (
+	"strconv"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// FretPosition describes the touched position on the touch-sensitive fret bar
+// (slider bar) of Guitar Hero World Tour guitars.
+type FretPosition uint8
+
+const (
+	// FretNone means the fret bar is not touched or not available
+	FretNone FretPosition = iota
+	FretGreen
+	FretGreenRed
+	FretRed
+	FretRedYellow
+	FretYellow
+	FretYellowBlue
+	FretBlue
+	FretBlueOrange
+	FretOrange
+)
+
+var fretNames = [...]string{"FretNone", "FretGreen", "FretGreenRed", "FretRed", "FretRedYellow", "FretYellow", "FretYellowBlue", "FretBlue", "FretBlueOrange", "FretOrange"}
+
+func (p FretPosition) String() string {
+	if int(p) < len(fretNames) {
+		return fretNames[p]
+	}
+	return "FretPosition(" + strconv.Itoa(int(p)) + ")"
+}
+
+// fretUntouched is the raw fret bar value if nothing is touched
+const fretUntouched = 0x0f
+
+// fretZones holds the raw value ranges of the fret positions, an untouched bar reports
+// fretUntouched. Values are taken from the guitar extension documentation on wiibrew.
+var fretZones = []struct {
+	min, max int32
+	pos      FretPosition
+}{
+	{0x00, 0x05, FretGreen},
+	{0x06, 0x08, FretGreenRed},
+	{0x09, 0x0b, FretRed},
+	{0x0c, 0x0e, FretRedYellow},
+	{0x10, 0x13, FretYellow},
+	{0x14, 0x15, FretYellowBlue},
+	{0x16, 0x18, FretBlue},
+	{0x19, 0x1b, FretBlueOrange},
+	{0x1c, 0x1f, FretOrange},
+}
+
+func fretZone(raw int32) (min, max int32, pos FretPosition) {
+	for _, z := range fretZones {
+		if raw >= z.min && raw <= z.max {
+			return z.min, z.max, z.pos
+		}
+	}
+	return fretUntouched, fretUntouched, FretNone
+}
+
+// Guitar tracks the movement events of a guitar and provides normalized values.
+type Guitar struct {
+	// WhammyMin is the raw whammy value at rest
+	WhammyMin int32
+	// WhammyMax is the raw whammy value when fully pressed. It is extended
+	// automatically if larger values are reported.
+	WhammyMax int32
+	// Hysteresis is the amount of raw units the fret bar value must leave the
+	// current position before a new position is reported.
+	Hysteresis int32
+
+	whammy float64
+	fret   FretPosition
+}
+
+// NewGuitar returns a Guitar with the default ranges of the kernel driver.
+func NewGuitar() *Guitar {
+	return &Guitar{
+		WhammyMin:  0,
+		WhammyMax:  0x0a,
+		Hysteresis: 1,
+	}
+}
+
+// Update processes a movement event and returns the normalized whammy in the range
+// 0..1 and the current fret bar position.
+func (g *Guitar) Update(ev *wiimote.EventGuitarMove) (whammy float64, fret FretPosition) {
+	return g.UpdateRaw(ev.WhammyBar, ev.FretBar)
+}
+
+// UpdateRaw is like Update but takes the raw whammy and fret bar values.
+func (g *Guitar) UpdateRaw(whammyRaw, fretRaw int32) (whammy float64, fret FretPosition) {
+	if whammyRaw > g.WhammyMax {
+		g.WhammyMax = whammyRaw
+	}
+	g.whammy = 0
+	if g.WhammyMax > g.WhammyMin {
+		g.whammy = float64(whammyRaw-g.WhammyMin) / float64(g.WhammyMax-g.WhammyMin)
+		g.whammy = min(max(g.whammy, 0), 1)
+	}
+
+	g.fret = g.nextFret(fretRaw)
+	return g.whammy, g.fret
+}
+
+func (g *Guitar) nextFret(raw int32) FretPosition {
+	// releasing and touching are applied immediately
+	if raw == fretUntouched || g.fret == FretNone {
+		_, _, pos := fretZone(raw)
+		return pos
+	}
+	for _, z := range fretZones {
+		if z.pos == g.fret {
+			if raw >= z.min-g.Hysteresis && raw <= z.max+g.Hysteresis {
+				return g.fret
+			}
+			break
+		}
+	}
+	_, _, pos := fretZone(raw)
+	return pos
+}
+
+// Whammy returns the last normalized whammy value in the range 0..1.
+func (g *Guitar) Whammy() float64 {
+	return g.whammy
+}
+
+// Fret returns the last fret bar position.
+func (g *Guitar) Fret() FretPosition {
+	return g.fret
+}
@@ -0,0 +1,75 @@
+package guitar
+
+import (
+	"testing"
+)
+
+func TestWhammyNormalized(t *testing.T) {
+	g := NewGuitar()
+	tests := []struct {
+		raw    int32
+		expect float64
+	}{
+		{0, 0},
+		{5, 0.5},
+		{10, 1},
+		{-3, 0},
+	}
+	for _, tc := range tests {
+		if got, _ := g.UpdateRaw(tc.raw, fretUntouched); got != tc.expect {
+			t.Errorf("whammy %d: expected %v, got %v", tc.raw, tc.expect, got)
+		}
+	}
+
+	// larger values extend the range
+	if got, _ := g.UpdateRaw(20, fretUntouched); got != 1 {
+		t.Errorf("expected 1, got %v", got)
+	}
+	if got, _ := g.UpdateRaw(10, fretUntouched); got != 0.5 {
+		t.Errorf("expected 0.5 after range extension, got %v", got)
+	}
+}
+
+func TestFretPositions(t *testing.T) {
+	g := NewGuitar()
+	g.Hysteresis = 0
+	tests := []struct {
+		raw    int32
+		expect FretPosition
+	}{
+		{0x0f, FretNone},
+		{0x04, FretGreen},
+		{0x07, FretGreenRed},
+		{0x0a, FretRed},
+		{0x0d, FretRedYellow},
+		{0x12, FretYellow},
+		{0x14, FretYellowBlue},
+		{0x17, FretBlue},
+		{0x1a, FretBlueOrange},
+		{0x1f, FretOrange},
+	}
+	for _, tc := range tests {
+		if _, got := g.UpdateRaw(0, tc.raw); got != tc.expect {
+			t.Errorf("fret %#x: expected %v, got %v", tc.raw, tc.expect, got)
+		}
+	}
+}
+
+func TestFretHysteresis(t *testing.T) {
+	g := NewGuitar()
+
+	if _, got := g.UpdateRaw(0, 0x0a); got != FretRed {
+		t.Fatalf("expected FretRed, got %v", got)
+	}
+	// one unit into the neighbouring zone is absorbed by the hysteresis
+	if _, got := g.UpdateRaw(0, 0x0c); got != FretRed {
+		t.Errorf("expected FretRed to be kept, got %v", got)
+	}
+	if _, got := g.UpdateRaw(0, 0x0d); got != FretRedYellow {
+		t.Errorf("expected FretRedYellow, got %v", got)
+	}
+	// releasing is immediate
+	if _, got := g.UpdateRaw(0, 0x0f); got != FretNone {
+		t.Errorf("expected FretNone, got %v", got)
+	}
+}
@@ -0,0 +1,171 @@
+package guitar
+
+import (
+	"math/bits"
+	"strings"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Frets is a set of pressed fret buttons.
+type Frets uint8
+
+const (
+	Green Frets = 1 << iota
+	Red
+	Yellow
+	Blue
+	Orange
+)
+
+var fretButtonNames = [...]string{"green", "red", "yellow", "blue", "orange"}
+
+// String returns the names of the frets joined by +, e.g. green+red, or open if no fret is set.
+func (f Frets) String() string {
+	if f == 0 {
+		return "open"
+	}
+	var names []string
+	for i, name := range fretButtonNames {
+		if f&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+// Highest returns the fret nearest to the body of the guitar, 0 if no fret is set.
+func (f Frets) Highest() Frets {
+	if f == 0 {
+		return 0
+	}
+	return 1 << (bits.Len8(uint8(f)) - 1)
+}
+
+// FretOf returns the fret of a fret key, from KeyFretFarUp (green) to KeyFretFarLow (orange).
+func FretOf(key wiimote.Key) (Frets, bool) {
+	if key < wiimote.KeyFretFarUp || key > wiimote.KeyFretFarLow {
+		return 0, false
+	}
+	return Green << (key - wiimote.KeyFretFarUp), true
+}
+
+// EventNoteOn is emitted when a note starts.
+type EventNoteOn struct {
+	wiimote.Event
+	// Frets is the chord of the note, 0 is an open note
+	Frets Frets
+	// Hopo is set if the note is a hammer-on or pull-off, which is played without strumming
+	Hopo bool
+}
+
+// EventNoteOff is emitted when a note ends.
+type EventNoteOff struct {
+	wiimote.Event
+	Frets Frets
+}
+
+// Strummer combines the fret and strum bar events of a guitar into notes, like rhythm games
+// do: the chord is captured when strumming, and is held until a fret of the chord is released.
+// It is not thread-safe.
+type Strummer struct {
+	// StrumWindow is the time after strumming in which pressed frets are added to the chord,
+	// frets are rarely pressed at the exact same time as the strum bar
+	StrumWindow time.Duration
+	// HopoWindow is the time after the start of a single note in which changing the highest
+	// fret plays a hammer-on or pull-off without strumming, 0 disables HOPOs
+	HopoWindow time.Duration
+
+	held     Frets
+	note     Frets
+	sounding bool
+	hopo     bool
+	strumAt  time.Time
+	noteAt   time.Time
+}
+
+// NewStrummer returns a strummer with the tolerances of common rhythm games.
+func NewStrummer() *Strummer {
+	return &Strummer{
+		StrumWindow: 30 * time.Millisecond,
+		HopoWindow:  150 * time.Millisecond,
+	}
+}
+
+// Held returns the frets which are currently pressed.
+func (s *Strummer) Held() Frets {
+	return s.held
+}
+
+// Update processes a guitar key event and returns the note events, other events are ignored.
+// The note events embed ev.
+func (s *Strummer) Update(ev wiimote.Event) []wiimote.Event {
+	key, ok := ev.(*wiimote.EventGuitarKey)
+	if !ok {
+		return nil
+	}
+	t := ev.Timestamp()
+
+	if key.Code == wiimote.KeyStrumBarUp || key.Code == wiimote.KeyStrumBarDown {
+		if !key.Pressed {
+			// the strum bar returning to the center is no strum
+			return nil
+		}
+		out := s.stop(ev)
+		s.strumAt = t
+		return append(out, s.start(ev, s.held, false))
+	}
+
+	fret, ok := FretOf(key.Code)
+	if !ok {
+		return nil
+	}
+	prev := s.held
+	if key.Pressed {
+		s.held |= fret
+	} else {
+		s.held &^= fret
+	}
+
+	switch {
+	case key.Pressed && s.sounding && !s.hopo && t.Sub(s.strumAt) <= s.StrumWindow:
+		// a late fret of the strummed chord
+		return append(s.stop(ev), s.start(ev, s.held, false))
+	case s.isHopo(t, prev):
+		return append(s.stop(ev), s.start(ev, s.held.Highest(), true))
+	case s.sounding && (s.note == 0 && key.Pressed || s.note&fret != 0 && !key.Pressed):
+		// releasing a fret of the chord or fretting after an open note ends the note
+		return s.stop(ev)
+	}
+	return nil
+}
+
+// isHopo reports whether the change of the frets from prev plays a hammer-on or pull-off.
+func (s *Strummer) isHopo(t time.Time, prev Frets) bool {
+	if s.HopoWindow <= 0 || !s.sounding || t.Sub(s.noteAt) > s.HopoWindow {
+		return false
+	}
+	// only single notes are played by fretting
+	if bits.OnesCount8(uint8(s.note)) != 1 || s.note != prev.Highest() {
+		return false
+	}
+	next := s.held.Highest()
+	return next != 0 && next != s.note
+}
+
+func (s *Strummer) start(ev wiimote.Event, frets Frets, hopo bool) wiimote.Event {
+	s.note = frets
+	s.sounding = true
+	s.hopo = hopo
+	s.noteAt = ev.Timestamp()
+	return &EventNoteOn{Event: ev, Frets: frets, Hopo: hopo}
+}
+
+func (s *Strummer) stop(ev wiimote.Event) []wiimote.Event {
+	if !s.sounding {
+		return nil
+	}
+	s.sounding = false
+	return []wiimote.Event{&EventNoteOff{Event: ev, Frets: s.note}}
+}
@@ -0,0 +1,107 @@
+package guitar
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+type testEvent struct {
+	ts time.Time
+}
+
+func (e testEvent) Feature() wiimote.Feature { return nil }
+func (e testEvent) Device() wiimote.Device   { return nil }
+func (e testEvent) Timestamp() time.Time     { return e.ts }
+
+func key(ms int, code wiimote.Key, pressed bool) *wiimote.EventGuitarKey {
+	return &wiimote.EventGuitarKey{EventKey: wiimote.EventKey{
+		Event:   testEvent{time.Unix(0, 0).Add(time.Duration(ms) * time.Millisecond)},
+		Code:    code,
+		Pressed: pressed,
+	}}
+}
+
+// notes returns the note events of the keys as strings, e.g. "on green" or "hopo red".
+func notes(s *Strummer, keys ...*wiimote.EventGuitarKey) []string {
+	var out []string
+	for _, k := range keys {
+		for _, ev := range s.Update(k) {
+			switch ev := ev.(type) {
+			case *EventNoteOn:
+				if ev.Hopo {
+					out = append(out, "hopo "+ev.Frets.String())
+				} else {
+					out = append(out, "on "+ev.Frets.String())
+				}
+			case *EventNoteOff:
+				out = append(out, "off "+ev.Frets.String())
+			}
+		}
+	}
+	return out
+}
+
+func TestStrumChord(t *testing.T) {
+	s := NewStrummer()
+	got := notes(s,
+		key(0, wiimote.KeyFretFarUp, true),
+		key(100, wiimote.KeyStrumBarDown, true),
+		// late fret within the strum window
+		key(110, wiimote.KeyFretUp, true),
+		key(150, wiimote.KeyStrumBarDown, false),
+		// releasing a fret of the chord
+		key(500, wiimote.KeyFretUp, false),
+		key(600, wiimote.KeyFretFarUp, false),
+		// open note ended by the next strum
+		key(700, wiimote.KeyStrumBarUp, true),
+		key(800, wiimote.KeyStrumBarUp, true),
+	)
+	want := []string{"on green", "off green", "on green+red", "off green+red", "on open", "off open", "on open"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestHopo(t *testing.T) {
+	s := NewStrummer()
+	got := notes(s,
+		key(0, wiimote.KeyFretFarUp, true),
+		key(100, wiimote.KeyStrumBarDown, true),
+		// hammer-on and pull-off
+		key(200, wiimote.KeyFretUp, true),
+		key(300, wiimote.KeyFretUp, false),
+		// too late for a hammer-on, the note is kept
+		key(600, wiimote.KeyFretMid, true),
+		key(700, wiimote.KeyFretFarUp, false),
+	)
+	want := []string{"on green", "off green", "hopo red", "off red", "hopo green", "off green"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	s = NewStrummer()
+	s.HopoWindow = 0
+	got = notes(s,
+		key(0, wiimote.KeyFretFarUp, true),
+		key(100, wiimote.KeyStrumBarDown, true),
+		key(200, wiimote.KeyFretUp, true),
+	)
+	if fmt.Sprint(got) != "[on green]" {
+		t.Fatalf("expected no hopo, got %v", got)
+	}
+}
+
+func TestFrets(t *testing.T) {
+	if f, ok := FretOf(wiimote.KeyFretFarLow); !ok || f != Orange {
+		t.Errorf("expected orange, got %v", f)
+	}
+	if _, ok := FretOf(wiimote.KeyStrumBarUp); ok {
+		t.Errorf("expected strum bar to be no fret")
+	}
+	if got := (Green | Yellow).Highest(); got != Yellow {
+		t.Errorf("expected yellow, got %v", got)
+	}
+}
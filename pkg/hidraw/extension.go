@@ -0,0 +1,29 @@
+package hidraw
+
+import "fmt"
+
+// ExtensionID is the identifier reported by an extension.
+type ExtensionID [6]byte
+
+var extensionNames = map[ExtensionID]string{
+	{0x00, 0x00, 0xa4, 0x20, 0x00, 0x00}: "nunchuk",
+	{0x00, 0x00, 0xa4, 0x20, 0x01, 0x01}: "classic",
+	{0x01, 0x00, 0xa4, 0x20, 0x01, 0x01}: "classic",
+	{0x00, 0x00, 0xa4, 0x20, 0x01, 0x03}: "guitar",
+	{0x01, 0x00, 0xa4, 0x20, 0x01, 0x03}: "drums",
+	{0x00, 0x00, 0xa4, 0x20, 0x04, 0x02}: "balanceboard",
+	{0x00, 0x00, 0xa4, 0x20, 0x01, 0x20}: "procontroller",
+	{0x00, 0x00, 0xa4, 0x20, 0x04, 0x05}: "motionp",
+	{0x00, 0x00, 0xa4, 0x20, 0x05, 0x05}: "motionp+nunchuk",
+	{0x00, 0x00, 0xa4, 0x20, 0x07, 0x05}: "motionp+classic",
+	{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}: "none",
+}
+
+// Name returns the name of the extension, using the same names as the kernel driver
+// (e.g. "nunchuk", "classic", "motionp"). Unknown identifiers are returned in hexadecimal.
+func (id ExtensionID) Name() string {
+	if name, ok := extensionNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%x)", id[:])
+}
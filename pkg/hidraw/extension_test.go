@@ -0,0 +1,21 @@
+package hidraw
+
+import "testing"
+
+func TestExtensionName(t *testing.T) {
+	tests := []struct {
+		id   ExtensionID
+		name string
+	}{
+		{ExtensionID{0x00, 0x00, 0xa4, 0x20, 0x00, 0x00}, "nunchuk"},
+		{ExtensionID{0x01, 0x00, 0xa4, 0x20, 0x01, 0x01}, "classic"},
+		{ExtensionID{0x00, 0x00, 0xa4, 0x20, 0x07, 0x05}, "motionp+classic"},
+		{ExtensionID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "none"},
+		{ExtensionID{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc}, "unknown(123456789abc)"},
+	}
+	for _, tc := range tests {
+		if got := tc.id.Name(); got != tc.name {
+			t.Errorf("%x: expected %q, got %q", tc.id[:], tc.name, got)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package hidraw provides report-level access to a wiimote through its hidraw node. This exposes
+// features the kernel driver does not, like the calibration EEPROM, the control registers and
+// direct initialization of extensions.
+package hidraw
+
+import (
+	"errors"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/driver/linuxhidraw"
+	"github.com/friedelschoen/go-wiimote/pkg/eeprom"
+)
+
+// Register addresses of the extension controller.
+const (
+	RegExtensionInit1 = 0xa400f0
+	RegExtensionInit2 = 0xa400fb
+	RegExtensionID    = 0xa400fa
+)
+
+// ErrNoRegisters is returned if the device does not provide access to its registers.
+var ErrNoRegisters = errors.New("device does not provide register access")
+
+// Path returns the hidraw device node (e.g. /dev/hidraw3) of the hid device info.
+func Path(info wiimote.DeviceInfo) (string, error) {
+	return linuxhidraw.Path(info)
+}
+
+// Device is a wiimote opened using its hidraw node.
+type Device struct {
+	wiimote.Device
+}
+
+// Open opens the hidraw node of info. The returned device can be used like any other device,
+// the kernel driver does not need to be bound.
+func Open(info wiimote.DeviceInfo) (*Device, error) {
+	dev, err := driver.NewDevice(info, driver.BackendHID)
+	if err != nil {
+		return nil, err
+	}
+	return &Device{dev}, nil
+}
+
+// EEPROM returns the EEPROM of the device, which contains calibration data and Mii's.
+func (d *Device) EEPROM() (wiimote.Memory, error) {
+	mf, ok := d.Feature(wiimote.FeatureCore).(wiimote.MemoryFeature)
+	if !ok {
+		return nil, ErrNoRegisters
+	}
+	return mf.Memory()
+}
+
+// Registers returns the control registers of the device.
+func (d *Device) Registers() (wiimote.Memory, error) {
+	rf, ok := d.Feature(wiimote.FeatureCore).(wiimote.RegisterFeature)
+	if !ok {
+		return nil, ErrNoRegisters
+	}
+	return rf.Registers()
+}
+
+// ReadRegister reads len(p) bytes from the register at addr.
+func (d *Device) ReadRegister(addr uint32, p []byte) error {
+	regs, err := d.Registers()
+	if err != nil {
+		return err
+	}
+	defer regs.Close()
+	_, err = regs.ReadAt(p, int64(addr))
+	return err
+}
+
+// WriteRegister writes p to the register at addr.
+func (d *Device) WriteRegister(addr uint32, p ...byte) error {
+	regs, err := d.Registers()
+	if err != nil {
+		return err
+	}
+	defer regs.Close()
+	_, err = regs.WriteAt(p, int64(addr))
+	return err
+}
+
+// AccelCalibration reads the accelerometer calibration from the EEPROM.
+func (d *Device) AccelCalibration() (wiimote.AccelCalibration, error) {
+	mem, err := d.EEPROM()
+	if err != nil {
+		return wiimote.AccelCalibration{}, err
+	}
+	defer mem.Close()
+	return eeprom.ReadAccelCalib(mem)
+}
+
+// InitExtension initializes the connected extension without encryption. This is required
+// before the extension reports data or its ID can be read.
+func (d *Device) InitExtension() error {
+	if err := d.WriteRegister(RegExtensionInit1, 0x55); err != nil {
+		return err
+	}
+	return d.WriteRegister(RegExtensionInit2, 0x00)
+}
+
+// ExtensionID reads the 6-byte identifier of the connected extension. The extension must be
+// initialized using InitExtension.
+func (d *Device) ExtensionID() (id ExtensionID, err error) {
+	err = d.ReadRegister(RegExtensionID, id[:])
+	return
+}
+
+// Extension returns the name of the connected extension, see ExtensionID.Name.
+func (d *Device) Extension() (string, error) {
+	id, err := d.ExtensionID()
+	if err != nil {
+		return "", err
+	}
+	return id.Name(), nil
+}
@@ -0,0 +1,70 @@
+package hidraw
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Register addresses of the IR camera.
+const (
+	RegIRControl = 0xb00030
+	RegIRBlock1  = 0xb00000
+	RegIRBlock2  = 0xb0001a
+)
+
+// IRSensitivity is a sensitivity level of the IR camera. Level 1 is the least sensitive and
+// works best in bright rooms, level 5 is the most sensitive. The Wii uses level 3 by default,
+// which is also the level configured by the kernel driver.
+type IRSensitivity uint8
+
+const (
+	IRSensitivity1 IRSensitivity = iota + 1
+	IRSensitivity2
+	IRSensitivity3
+	IRSensitivity4
+	IRSensitivity5
+	// IRSensitivityMax is the highest sensitivity the camera supports, as used by homebrew software.
+	IRSensitivityMax
+)
+
+// ErrInvalidSensitivity is returned if an unknown sensitivity level is configured.
+var ErrInvalidSensitivity = errors.New("invalid IR sensitivity")
+
+// sensitivity blocks written to RegIRBlock1 and RegIRBlock2
+var irSensitivityBlocks = map[IRSensitivity][2][]byte{
+	IRSensitivity1:   {{0x02, 0x00, 0x00, 0x71, 0x01, 0x00, 0x64, 0x00, 0xfe}, {0xfd, 0x05}},
+	IRSensitivity2:   {{0x02, 0x00, 0x00, 0x71, 0x01, 0x00, 0x96, 0x00, 0xb4}, {0xb3, 0x04}},
+	IRSensitivity3:   {{0x02, 0x00, 0x00, 0x71, 0x01, 0x00, 0xaa, 0x00, 0x64}, {0x63, 0x03}},
+	IRSensitivity4:   {{0x02, 0x00, 0x00, 0x71, 0x01, 0x00, 0xc8, 0x00, 0x36}, {0x35, 0x03}},
+	IRSensitivity5:   {{0x07, 0x00, 0x00, 0x71, 0x01, 0x00, 0x72, 0x00, 0x20}, {0x1f, 0x03}},
+	IRSensitivityMax: {{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x90, 0x00, 0xc0}, {0x40, 0x00}},
+}
+
+func (s IRSensitivity) String() string {
+	if s == IRSensitivityMax {
+		return "max"
+	}
+	return fmt.Sprintf("level %d", uint8(s))
+}
+
+// SetIRSensitivity configures the sensitivity of the IR camera. The camera must be enabled,
+// that is, the IR feature must be opened. The kernel driver resets the sensitivity when the IR
+// feature is opened, so it must be configured afterwards. The reporting mode of the camera is
+// not changed.
+func (d *Device) SetIRSensitivity(level IRSensitivity) error {
+	blocks, ok := irSensitivityBlocks[level]
+	if !ok {
+		return ErrInvalidSensitivity
+	}
+	// the camera only accepts configuration while in configuration-mode
+	if err := d.WriteRegister(RegIRControl, 0x01); err != nil {
+		return err
+	}
+	if err := d.WriteRegister(RegIRBlock1, blocks[0]...); err != nil {
+		return err
+	}
+	if err := d.WriteRegister(RegIRBlock2, blocks[1]...); err != nil {
+		return err
+	}
+	return d.WriteRegister(RegIRControl, 0x08)
+}
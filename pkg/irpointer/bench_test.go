@@ -0,0 +1,95 @@
+package irpointer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// benchSlots returns n dots of a slightly rotated sensor bar with ghost dots.
+func benchSlots(n int) [4]wiimote.IRSlot {
+	all := []wiimote.IRSlot{
+		mkSlotValid(400, 380),
+		mkSlotValid(624, 388),
+		mkSlotValid(300, 700),
+		mkSlotValid(800, 100),
+	}
+	return mkSlots(all[:n]...)
+}
+
+func BenchmarkRotateDots(b *testing.B) {
+	in := findDots(benchSlots(4))
+	out := make([]FVec2, len(in))
+	b.ReportAllocs()
+	for b.Loop() {
+		rotateDots(out, in, 0.3)
+	}
+}
+
+func BenchmarkFindCanditates(b *testing.B) {
+	for n := 2; n <= 4; n++ {
+		b.Run(fmt.Sprintf("dots=%d", n), func(b *testing.B) {
+			ir := NewIRPointer()
+			dots := findDots(benchSlots(n))
+			accDots := make([]FVec2, len(dots))
+			rotateDots(accDots, dots, 0.1)
+			b.ReportAllocs()
+			for b.Loop() {
+				ir.findCanditates(dots, accDots, 0.1)
+			}
+		})
+	}
+}
+
+func BenchmarkGuessSingle(b *testing.B) {
+	ir := NewIRPointer()
+	ir.Step(benchSlots(2), wiimote.Vec3{Z: 100})
+	dots := findDots(benchSlots(1))
+	accDots := make([]FVec2, len(dots))
+	b.ReportAllocs()
+	for b.Loop() {
+		ir.guessSingle(dots, accDots, 0)
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	for n := 1; n <= 4; n++ {
+		b.Run(fmt.Sprintf("dots=%d", n), func(b *testing.B) {
+			ir := NewIRPointer()
+			ir.Step(benchSlots(2), wiimote.Vec3{Z: 100})
+			slots := benchSlots(n)
+			b.ReportAllocs()
+			for b.Loop() {
+				ir.Step(slots, wiimote.Vec3{Z: 100})
+			}
+		})
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	p := NewPipeline(nil, NewDefaultFilters(), nil)
+	ir := &wiimote.EventIR{Event: stampEvent{}, Slots: benchSlots(2)}
+	accel := &wiimote.EventAccel{Accel: wiimote.Vec3{Z: 100}}
+	b.ReportAllocs()
+	for b.Loop() {
+		p.Handle(ir)
+		p.Handle(accel)
+	}
+}
+
+// The pointer runs at 100Hz on small boards, an update must not allocate.
+func TestStep_AllocationBudget(t *testing.T) {
+	p := NewPipeline(nil, NewDefaultFilters(), nil)
+	accel := &wiimote.EventAccel{Accel: wiimote.Vec3{Z: 100}}
+	for n := 1; n <= 4; n++ {
+		ir := &wiimote.EventIR{Event: stampEvent{}, Slots: benchSlots(n)}
+		allocs := testing.AllocsPerRun(100, func() {
+			p.Handle(ir)
+			p.Handle(accel)
+		})
+		if allocs != 0 {
+			t.Errorf("dots=%d: expected no allocations per update, got %v", n, allocs)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package irpointer
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Curve maps a normalized, non-negative input (an offset or a speed) to an output of the same
+// unit. A curve should be monotonic and map 0 to 0.
+type Curve func(v float64) float64
+
+// LinearCurve multiplies the input by gain.
+func LinearCurve(gain float64) Curve {
+	return func(v float64) float64 { return gain * v }
+}
+
+// PowerCurve raises the input to exponent and multiplies it by gain. An exponent above 1 makes
+// small movements more precise and large movements faster.
+func PowerCurve(exponent, gain float64) Curve {
+	return func(v float64) float64 { return gain * math.Pow(v, exponent) }
+}
+
+// PointsCurve interpolates linearly between control points, which are sorted by X. The curve
+// starts at (0, 0) and is extended beyond the last point with the slope of the last segment.
+func PointsCurve(points []FVec2) Curve {
+	points = slices.Clone(points)
+	slices.SortFunc(points, func(a, b FVec2) int { return cmpFloat(a.X, b.X) })
+	if len(points) == 0 || points[0].X > 0 {
+		points = append([]FVec2{{}}, points...)
+	}
+	return func(v float64) float64 {
+		if len(points) == 1 {
+			return points[0].Y
+		}
+		i := 1
+		for i < len(points)-1 && v > points[i].X {
+			i++
+		}
+		a, b := points[i-1], points[i]
+		if b.X == a.X {
+			return b.Y
+		}
+		return a.Y + (v-a.X)*(b.Y-a.Y)/(b.X-a.X)
+	}
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ParseCurve parses a curve as used on command lines: "linear[:GAIN]", "power:EXPONENT[:GAIN]"
+// or "points:X,Y X,Y ...", where points may also be separated by semicolons.
+func ParseCurve(s string) (Curve, error) {
+	name, args, _ := strings.Cut(s, ":")
+	switch name {
+	case "linear":
+		gain := 1.0
+		if args != "" {
+			var err error
+			if gain, err = strconv.ParseFloat(args, 64); err != nil {
+				return nil, fmt.Errorf("invalid gain: %w", err)
+			}
+		}
+		return LinearCurve(gain), nil
+	case "power":
+		expstr, gainstr, hasGain := strings.Cut(args, ":")
+		exponent, err := strconv.ParseFloat(expstr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		gain := 1.0
+		if hasGain {
+			if gain, err = strconv.ParseFloat(gainstr, 64); err != nil {
+				return nil, fmt.Errorf("invalid gain: %w", err)
+			}
+		}
+		return PowerCurve(exponent, gain), nil
+	case "points":
+		var points []FVec2
+		for _, field := range strings.FieldsFunc(args, func(r rune) bool { return r == ' ' || r == ';' }) {
+			xstr, ystr, ok := strings.Cut(field, ",")
+			if !ok {
+				return nil, fmt.Errorf("invalid point %q", field)
+			}
+			x, err := strconv.ParseFloat(xstr, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid point %q: %w", field, err)
+			}
+			y, err := strconv.ParseFloat(ystr, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid point %q: %w", field, err)
+			}
+			points = append(points, FVec2{X: x, Y: y})
+		}
+		if len(points) == 0 {
+			return nil, fmt.Errorf("curve without points")
+		}
+		return PointsCurve(points), nil
+	}
+	return nil, fmt.Errorf("unknown curve %q", name)
+}
+
+// signedCurve applies curve to the magnitude of v and keeps its sign.
+func signedCurve(curve Curve, v float64) float64 {
+	if v < 0 {
+		return -curve(-v)
+	}
+	return curve(v)
+}
+
+// SensitivityFilter applies a curve to the offset of the pointer from Center, per axis. The
+// offset is normalized by Scale, so a curve mapping 1 to 1 keeps the pointer at Center+Scale
+// in place.
+type SensitivityFilter struct {
+	Curve  Curve
+	Center FVec2
+	Scale  float64
+}
+
+func (f *SensitivityFilter) Reset() { /* stateless */ }
+
+func (f *SensitivityFilter) Apply(frame Frame) Frame {
+	if !frame.Valid || f.Curve == nil || f.Scale == 0 {
+		return frame
+	}
+	frame.Position = FVec2{
+		X: f.Center.X + f.Scale*signedCurve(f.Curve, (frame.Position.X-f.Center.X)/f.Scale),
+		Y: f.Center.Y + f.Scale*signedCurve(f.Curve, (frame.Position.Y-f.Center.Y)/f.Scale),
+	}
+	return frame
+}
+
+/*
+RelativeFilter turns the pointer into a relative device: the movement of the pointer moves a
+cursor, which is clamped to Bounds. The speed of the movement, normalized by Scale per
+second, is mapped by Curve to accelerate fast movements. If the pointer is lost, the cursor
+stays in place and does not jump when the pointer is found again.
+
+The position of the cursor is reported as Position, Delta is the last movement of the cursor.
+*/
+type RelativeFilter struct {
+	Curve  Curve
+	Scale  float64
+	Bounds FRect
+	// ClampMaxDt caps dt to avoid acceleration after pauses, 0 disables
+	ClampMaxDt time.Duration
+
+	Delta FVec2
+
+	cursor FVec2
+	last   FVec2
+	lastT  time.Time
+	alive  bool
+	placed bool
+}
+
+func (f *RelativeFilter) Reset() {
+	f.alive = false
+}
+
+// Recenter places the cursor at the center of the bounds.
+func (f *RelativeFilter) Recenter() {
+	f.cursor = FVec2{X: (f.Bounds.Min.X + f.Bounds.Max.X) / 2, Y: (f.Bounds.Min.Y + f.Bounds.Max.Y) / 2}
+	f.placed = true
+}
+
+func (f *RelativeFilter) Apply(frame Frame) Frame {
+	return f.apply(frame, frame.timestamp())
+}
+
+func (f *RelativeFilter) apply(frame Frame, now time.Time) Frame {
+	if !f.placed {
+		f.Recenter()
+	}
+	f.Delta = FVec2{}
+	if !frame.Valid {
+		// continue from the next valid frame without jumping
+		f.alive = false
+		frame.Position = f.cursor
+		return frame
+	}
+	if !f.alive {
+		f.alive = true
+		f.last = frame.Position
+		f.lastT = now
+		frame.Position = f.cursor
+		return frame
+	}
+
+	dx, dy := frame.Position.X-f.last.X, frame.Position.Y-f.last.Y
+	dtDur := now.Sub(f.lastT)
+	if f.ClampMaxDt > 0 && dtDur > f.ClampMaxDt {
+		dtDur = f.ClampMaxDt
+	}
+	f.last = frame.Position
+	f.lastT = now
+
+	gain := 1.0
+	if dt := dtDur.Seconds(); f.Curve != nil && f.Scale > 0 && dt > 0 {
+		if speed := math.Hypot(dx, dy) / dt / f.Scale; speed > 0 {
+			gain = f.Curve(speed) / speed
+		}
+	}
+	prev := f.cursor
+	f.cursor.X = min(max(f.cursor.X+gain*dx, f.Bounds.Min.X), f.Bounds.Max.X)
+	f.cursor.Y = min(max(f.cursor.Y+gain*dy, f.Bounds.Min.Y), f.Bounds.Max.Y)
+	f.Delta = FVec2{X: f.cursor.X - prev.X, Y: f.cursor.Y - prev.Y}
+
+	frame.Position = f.cursor
+	return frame
+}
@@ -1,8 +1,55 @@
 package irpointer
 
-import "time"
+import (
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Option modifies the defaults of NewIRPointer.
+type Option func(*IRPointer)
+
+// WithSensorBar sets the physical dimensions of the sensor bar in cm: width is the distance
+// between the centers of the emitters, dotWidth and dotHeight are the half-sizes of an emitter.
+func WithSensorBar(width, dotWidth, dotHeight float64) Option {
+	return func(ir *IRPointer) {
+		ir.SbWidth = width
+		ir.SbDotWidth = dotWidth
+		ir.SbDotHeight = dotHeight
+	}
+}
+
+// WithIgnoreSlots never tracks the slots of mask.
+func WithIgnoreSlots(mask wiimote.IRMask) Option {
+	return func(ir *IRPointer) { ir.IgnoreSlots = mask }
+}
+
+// WithConfidence ignores dots below min and prefers sensor bar candidates with confident dots.
+func WithConfidence(min float64) Option {
+	return func(ir *IRPointer) {
+		ir.MinConfidence = min
+		ir.Weighted = true
+	}
+}
+
+// WithParams replaces all parameters by the ones of params, e.g. loaded from a profile.
+func WithParams(params *IRPointer) Option {
+	return func(ir *IRPointer) {
+		*ir = *params
+		ir.Reset()
+	}
+}
+
+// NewIRPointer creates a pointer with the default parameters, modified by opts in order.
+func NewIRPointer(opts ...Option) *IRPointer {
+	ir := newDefaultIRPointer()
+	for _, opt := range opts {
+		opt(ir)
+	}
+	return ir
+}
 
-func NewIRPointer() *IRPointer {
+func newDefaultIRPointer() *IRPointer {
 	return &IRPointer{
 		Height: 384.0 / 512.0,
 
@@ -29,6 +76,18 @@ func NewIRPointer() *IRPointer {
 	}
 }
 
+// NewDefaultFilters returns the filters commonly applied to the frames of the pointer: errors
+// and glitches are dropped, the position is smoothed and unchanged frames are skipped. The
+// chain can be extended or modified before it is passed to NewPipeline.
+func NewDefaultFilters() FilterChain {
+	return FilterChain{
+		NewErrorFilter(),
+		NewGlitchFilter(),
+		NewOneEuroSmoothing(),
+		NewRepeatFilter(),
+	}
+}
+
 func NewErrorFilter() *ErrorFilter {
 	return &ErrorFilter{
 		ErrorMaxCount: 8,
@@ -59,40 +118,112 @@ func NewOneEuroSmoothing() *OneEuroSmoothingFilter {
 	}
 }
 
+func NewKalmanSmoothing() *KalmanSmoothingFilter {
+	return &KalmanSmoothingFilter{
+		ProcessNoise:     1e6,
+		MeasurementNoise: 4.0, // pixels²
+		ClampMaxDt:       100 * time.Millisecond,
+	}
+}
+
+// NewPredictor creates a predictor emitting frames at rate Hz to output.
+func NewPredictor(rate float64, output func(Frame)) *Predictor {
+	return &Predictor{
+		Interval:          time.Duration(float64(time.Second) / rate),
+		MaxExtrapolation:  50 * time.Millisecond,
+		VelocitySmoothing: 0.5,
+		Output:            output,
+	}
+}
+
 func NewRepeatFilter() *RepeatFilter {
 	return &RepeatFilter{
 		Deadzone: 1, // pixels
 	}
 }
 
-func NewSafeTopSensorNormalize() *TranslateFilter {
-	return &TranslateFilter{
-		Source:      FRect{FVec2{-340, -92}, FVec2{340, 290}},
-		Destination: FRect{FVec2{-1, -1}, FVec2{1, 1}},
-		Clamp:       true,
+// NewScreenFilter creates a filter mapping the pointer onto -1..1 for a sensor bar at placement.
+func NewScreenFilter(placement Placement, coverage Coverage) *ScreenFilter {
+	return &ScreenFilter{
+		Placement:         placement,
+		Coverage:          coverage,
+		Destination:       FRect{Min: FVec2{X: -1, Y: -1}, Max: FVec2{X: 1, Y: 1}},
+		CalibrationFrames: 200, // about 2 seconds
 	}
 }
 
-func NewSafeBottomSensorNormalize() *TranslateFilter {
+// newSensorNormalize creates a filter mapping the screen area of placement onto -1..1.
+func newSensorNormalize(placement Placement, coverage Coverage) *TranslateFilter {
 	return &TranslateFilter{
-		Source:      FRect{FVec2{-340, -290}, FVec2{340, 92}},
-		Destination: FRect{FVec2{-1, -1}, FVec2{1, 1}},
+		Source:      screenArea(placement, coverage),
+		Destination: FRect{Min: FVec2{X: -1, Y: -1}, Max: FVec2{X: 1, Y: 1}},
 		Clamp:       true,
 	}
 }
 
+// NewSafeTopSensorNormalize creates a filter mapping the safe area of a sensor bar on top of the screen onto -1..1.
+//
+// Deprecated: Use NewScreenFilter(PlacementAbove, CoverageSafe).
+func NewSafeTopSensorNormalize() *TranslateFilter {
+	return newSensorNormalize(PlacementAbove, CoverageSafe)
+}
+
+// NewSafeBottomSensorNormalize creates a filter mapping the safe area of a sensor bar below the screen onto -1..1.
+//
+// Deprecated: Use NewScreenFilter(PlacementBelow, CoverageSafe).
+func NewSafeBottomSensorNormalize() *TranslateFilter {
+	return newSensorNormalize(PlacementBelow, CoverageSafe)
+}
+
+// NewWideTopSensorNormalize creates a filter mapping the wide area of a sensor bar on top of the screen onto -1..1.
+//
+// Deprecated: Use NewScreenFilter(PlacementAbove, CoverageWide).
 func NewWideTopSensorNormalize() *TranslateFilter {
-	return &TranslateFilter{
-		Source:      FRect{FVec2{-430, -194}, FVec2{430, 290}},
-		Destination: FRect{FVec2{-1, -1}, FVec2{1, 1}},
-		Clamp:       true,
-	}
+	return newSensorNormalize(PlacementAbove, CoverageWide)
 }
 
+// NewWideBottomSensorNormalize creates a filter mapping the wide area of a sensor bar below the screen onto -1..1.
+//
+// Deprecated: Use NewScreenFilter(PlacementBelow, CoverageWide).
 func NewWideBottomSensorNormalize() *TranslateFilter {
-	return &TranslateFilter{
-		Source:      FRect{FVec2{-430, -290}, FVec2{430, 194}},
-		Destination: FRect{FVec2{-1, -1}, FVec2{1, 1}},
-		Clamp:       true,
+	return newSensorNormalize(PlacementBelow, CoverageWide)
+}
+
+// NewSensitivityFilter creates a filter applying curve to the pointer space of IRPointer.
+func NewSensitivityFilter(curve Curve) *SensitivityFilter {
+	return &SensitivityFilter{
+		Curve: curve,
+		Scale: 512,
+	}
+}
+
+// NewRelativeFilter creates a filter moving a cursor within bounds, the speed is normalized
+// to the width of bounds per second.
+func NewRelativeFilter(curve Curve, bounds FRect) *RelativeFilter {
+	return &RelativeFilter{
+		Curve:      curve,
+		Scale:      bounds.Width(),
+		Bounds:     bounds,
+		ClampMaxDt: 100 * time.Millisecond,
+	}
+}
+
+// NewRecenterFilter creates a filter recentering the pointer onto the center of screen.
+func NewRecenterFilter(screen FRect) *RecenterFilter {
+	return &RecenterFilter{
+		Center: FVec2{X: (screen.Min.X + screen.Max.X) / 2, Y: (screen.Min.Y + screen.Max.Y) / 2},
+	}
+}
+
+// NewHybridFilter creates a filter pointing with the Motion Plus while the sensor bar is not
+// fully visible. The camera covers about 41 degrees over 1024 units.
+func NewHybridFilter() *HybridFilter {
+	return &HybridFilter{
+		MinHealth:        IRGood,
+		UnitsPerDegree:   25,
+		MaxDeadReckoning: 10 * time.Second,
+		RestThreshold:    3,
+		BiasSmoothing:    0.01,
+		ClampMaxDt:       100 * time.Millisecond,
 	}
 }
@@ -116,22 +116,18 @@ func (f *MarcanSmoothingFilter) Apply(frame Frame) Frame {
 	}
 
 	pos := f.position
-	dx := raw.X - pos.X
-	dy := raw.Y - pos.Y
+	delta := raw.Sub(pos)
 
-	d := math.Sqrt(square(dx) + square(dy))
+	d := delta.Len()
 	if d <= f.SmootherDeadzone {
 		frame.Position = pos
 		return frame
 	}
 
 	if d < f.SmootherRadius {
-		pos.X += dx * f.SmootherSpeed
-		pos.Y += dy * f.SmootherSpeed
+		pos = pos.Add(delta.Scale(f.SmootherSpeed))
 	} else {
-		theta := math.Atan2(dy, dx)
-		pos.X = raw.X - math.Cos(theta)*f.SmootherRadius
-		pos.Y = raw.Y - math.Sin(theta)*f.SmootherRadius
+		pos = raw.Sub(delta.Normalize().Scale(f.SmootherRadius))
 	}
 
 	f.position = pos
@@ -204,7 +200,7 @@ func (f *OneEuroSmoothingFilter) Apply(frame Frame) Frame {
 		return frame
 	}
 
-	now := time.Now()
+	now := frame.timestamp()
 
 	if !f.alive {
 		f.alive = true
@@ -255,6 +251,133 @@ func (f *OneEuroSmoothingFilter) Apply(frame Frame) Frame {
 	return frame
 }
 
+/*
+KalmanSmoothingFilter is a constant-velocity Kalman filter, filtering both axes independently.
+Next to smoothing, it estimates the velocity of the pointer which is used by Predict to
+predict the position between IR frames.
+
+  - ProcessNoise: variance of the acceleration of the pointer (units/s²)². Higher = follows
+    fast movement better but smooths less.
+  - MeasurementNoise: variance of the measured position (units²). Higher = smoother but more lag.
+*/
+type KalmanSmoothingFilter struct {
+	ProcessNoise     float64
+	MeasurementNoise float64
+
+	ClampMaxDt time.Duration // optional: caps dt on long pauses (e.g. 100ms). 0 = off.
+
+	alive bool
+	lastT time.Time
+
+	x kalmanAxis
+	y kalmanAxis
+}
+
+type kalmanAxis struct {
+	pos float64
+	vel float64
+	cov [2][2]float64
+}
+
+func (a *kalmanAxis) init(pos, r float64) {
+	a.pos = pos
+	a.vel = 0
+	// velocity is unknown
+	a.cov = [2][2]float64{{r, 0}, {0, 1e6}}
+}
+
+func (a *kalmanAxis) predict(dt, q float64) {
+	a.pos += a.vel * dt
+
+	// P = F P F^T + Q with F = [[1 dt] [0 1]] and Q the white-acceleration noise
+	p := a.cov
+	p00 := p[0][0] + dt*(p[1][0]+p[0][1]) + dt*dt*p[1][1]
+	p01 := p[0][1] + dt*p[1][1]
+	p10 := p[1][0] + dt*p[1][1]
+	p11 := p[1][1]
+
+	dt2 := dt * dt
+	a.cov[0][0] = p00 + q*dt2*dt2/4
+	a.cov[0][1] = p01 + q*dt2*dt/2
+	a.cov[1][0] = p10 + q*dt2*dt/2
+	a.cov[1][1] = p11 + q*dt2
+}
+
+func (a *kalmanAxis) update(z, r float64) {
+	p := a.cov
+	s := p[0][0] + r
+	k0 := p[0][0] / s
+	k1 := p[1][0] / s
+
+	y := z - a.pos
+	a.pos += k0 * y
+	a.vel += k1 * y
+
+	a.cov[0][0] = (1 - k0) * p[0][0]
+	a.cov[0][1] = (1 - k0) * p[0][1]
+	a.cov[1][0] = p[1][0] - k1*p[0][0]
+	a.cov[1][1] = p[1][1] - k1*p[0][1]
+}
+
+func (f *KalmanSmoothingFilter) Reset() {
+	f.alive = false
+}
+
+func (f *KalmanSmoothingFilter) Apply(frame Frame) Frame {
+	return f.apply(frame, frame.timestamp())
+}
+
+func (f *KalmanSmoothingFilter) apply(frame Frame, now time.Time) Frame {
+	if !frame.Valid {
+		return frame
+	}
+
+	if !f.alive {
+		f.alive = true
+		f.lastT = now
+		f.x.init(frame.Position.X, f.MeasurementNoise)
+		f.y.init(frame.Position.Y, f.MeasurementNoise)
+		return frame
+	}
+
+	dtDur := now.Sub(f.lastT)
+	if dtDur < 0 {
+		dtDur = 0
+	}
+	if f.ClampMaxDt > 0 && dtDur > f.ClampMaxDt {
+		dtDur = f.ClampMaxDt
+	}
+	f.lastT = now
+	dt := dtDur.Seconds()
+
+	f.x.predict(dt, f.ProcessNoise)
+	f.y.predict(dt, f.ProcessNoise)
+	f.x.update(frame.Position.X, f.MeasurementNoise)
+	f.y.update(frame.Position.Y, f.MeasurementNoise)
+
+	frame.Position = FVec2{X: f.x.pos, Y: f.y.pos}
+	return frame
+}
+
+// Velocity returns the estimated velocity of the pointer in units per second.
+func (f *KalmanSmoothingFilter) Velocity() FVec2 {
+	return FVec2{X: f.x.vel, Y: f.y.vel}
+}
+
+// Predict returns the predicted position at t, which is usually between the last and the next
+// IR frame. ok is false if the filter has not received a valid frame yet.
+func (f *KalmanSmoothingFilter) Predict(t time.Time) (pos FVec2, ok bool) {
+	if !f.alive {
+		return FVec2{}, false
+	}
+	dtDur := t.Sub(f.lastT)
+	if f.ClampMaxDt > 0 && dtDur > f.ClampMaxDt {
+		dtDur = f.ClampMaxDt
+	}
+	dt := dtDur.Seconds()
+	return FVec2{X: f.x.pos + f.x.vel*dt, Y: f.y.pos + f.y.vel*dt}, true
+}
+
 type TranslateFilter struct {
 	// input viewpoint
 	Source FRect
@@ -298,10 +421,7 @@ func (f *RepeatFilter) Apply(frame Frame) Frame {
 		return frame
 	}
 
-	dx := frame.Position.X - f.position.X
-	dy := frame.Position.Y - f.position.Y
-
-	d := math.Sqrt(square(dx) + square(dy))
+	d := frame.Position.Sub(f.position).Len()
 	if d <= f.Deadzone {
 		frame.Valid = false
 		return frame
@@ -0,0 +1,134 @@
+package irpointer
+
+import (
+	"math"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// EventFilter is a filter which needs events next to the frames of the pointer. The pipeline
+// passes every event to the filters implementing EventFilter.
+type EventFilter interface {
+	Filter
+	HandleEvent(wiimote.Event)
+}
+
+/*
+HybridFilter continues pointing with the Motion Plus if the IR tracking is lost. While the
+health of the frames is below MinHealth, the last absolute position of the IR is moved by the
+integrated yaw and pitch of the Motion Plus, corrected for the roll of the remote. As soon as
+the health is good again, the pointer snaps back to the IR position.
+
+The filter must be the first in the chain and expects the pointer space of IRPointer. Frames
+moved by the Motion Plus keep their health, so later filters can tell them apart.
+
+The bias of the gyroscope is estimated while the remote is at rest, which removes most of the
+drift.
+*/
+type HybridFilter struct {
+	// MinHealth is the health from which the IR position is used
+	MinHealth Health
+	// UnitsPerDegree is the movement of the pointer per degree of rotation
+	UnitsPerDegree float64
+	// MaxDeadReckoning is the time after losing the IR after which the pointer is invalid,
+	// 0 disables the limit
+	MaxDeadReckoning time.Duration
+	// RestThreshold is the rotation speed in degree per second below which the remote is
+	// considered at rest, 0 disables the bias estimation
+	RestThreshold float64
+	// BiasSmoothing is the weight of a sample at rest for the bias estimate (0..1)
+	BiasSmoothing float64
+	// ClampMaxDt caps dt between Motion Plus samples, 0 disables
+	ClampMaxDt time.Duration
+
+	// Bias is the estimated rotation speed at rest in degree per second
+	Bias wiimote.FVec3
+
+	roll     float64
+	fix      bool
+	fixT     time.Time
+	position FVec2
+	lastMP   time.Time
+}
+
+func (f *HybridFilter) Reset() {
+	f.fix = false
+	f.lastMP = time.Time{}
+}
+
+// HandleEvent integrates Motion Plus events and tracks the roll using accelerometer events.
+func (f *HybridFilter) HandleEvent(ev wiimote.Event) {
+	switch ev := ev.(type) {
+	case *wiimote.EventAccel:
+		f.UpdateRoll(ev.G().Roll())
+	case *wiimote.EventMotionPlus:
+		f.UpdateMotionPlus(ev.Speed, ev.Timestamp())
+	}
+}
+
+// UpdateRoll sets the roll of the remote in radians, see wiimote.FVec3.Roll.
+func (f *HybridFilter) UpdateRoll(roll float64) {
+	f.roll = roll
+}
+
+// UpdateMotionPlus integrates the raw rotation speed of the Motion Plus at t.
+func (f *HybridFilter) UpdateMotionPlus(speed wiimote.Vec3, t time.Time) {
+	deg := wiimote.MPDegrees(speed)
+	if f.RestThreshold > 0 && math.Abs(deg.X) < f.RestThreshold && math.Abs(deg.Y) < f.RestThreshold && math.Abs(deg.Z) < f.RestThreshold {
+		a := f.BiasSmoothing
+		f.Bias = wiimote.FVec3{
+			X: a*deg.X + (1-a)*f.Bias.X,
+			Y: a*deg.Y + (1-a)*f.Bias.Y,
+			Z: a*deg.Z + (1-a)*f.Bias.Z,
+		}
+	}
+
+	prev := f.lastMP
+	f.lastMP = t
+	if prev.IsZero() || !f.fix {
+		return
+	}
+	dtDur := t.Sub(prev)
+	if f.ClampMaxDt > 0 && dtDur > f.ClampMaxDt {
+		dtDur = f.ClampMaxDt
+	}
+	dt := dtDur.Seconds()
+	if dt <= 0 {
+		return
+	}
+
+	// X points to the left and Z upwards: turning left (yaw) and up (pitch) are positive and move
+	// the pointer to the left and upwards
+	yaw := (deg.Z - f.Bias.Z) * dt
+	pitch := (deg.X - f.Bias.X) * dt
+	// the axes of the remote are rotated by the roll
+	sin, cos := math.Sincos(f.roll)
+	dx := -(yaw*cos + pitch*sin) * f.UnitsPerDegree
+	dy := -(pitch*cos - yaw*sin) * f.UnitsPerDegree
+	f.position.X += dx
+	f.position.Y += dy
+}
+
+func (f *HybridFilter) Apply(frame Frame) Frame {
+	return f.apply(frame, frame.timestamp())
+}
+
+func (f *HybridFilter) apply(frame Frame, now time.Time) Frame {
+	if frame.Valid && frame.Health >= f.MinHealth {
+		f.fix = true
+		f.fixT = now
+		f.position = frame.Position
+		return frame
+	}
+	if !f.fix {
+		return frame
+	}
+	if f.MaxDeadReckoning > 0 && now.Sub(f.fixT) > f.MaxDeadReckoning {
+		f.fix = false
+		return frame
+	}
+	frame.Position = f.position
+	frame.Valid = true
+	return frame
+}
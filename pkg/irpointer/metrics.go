@@ -0,0 +1,94 @@
+package irpointer
+
+import (
+	"math"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// RecoveryDistance is the distance to the position before an occlusion within which the pointer
+// is considered recovered, see Metrics.
+const RecoveryDistance = 10.0
+
+// Metrics describes the stability of the pointer over a recorded session, see Analyze.
+type Metrics struct {
+	// Frames is the number of frames, Valid the fraction of frames with a valid position
+	Frames int
+	Valid  float64
+	// Jitter is the root mean square distance between consecutive valid positions. For a
+	// resting remote this is the jitter of the pointer.
+	Jitter float64
+	// Gap is the longest time the pointer was lost, see IRLost
+	Gap time.Duration
+	// Recovery is the longest time from the end of a gap until the position is within
+	// RecoveryDistance of the position before the gap
+	Recovery time.Duration
+}
+
+// Replay handles the events with p and returns the processed frames, e.g. of a session
+// recorded with wiilog and read by ndjson.ReadAll.
+func Replay(p *Pipeline, events []wiimote.Event) []Frame {
+	var frames []Frame
+	for _, ev := range events {
+		if p.Handle(ev) {
+			frames = append(frames, p.Frame())
+		}
+	}
+	return frames
+}
+
+// Analyze computes the metrics of frames, the frames must carry their time. Frames skipped by
+// RepeatFilter are invalid, so sessions should be analyzed without it.
+func Analyze(frames []Frame) Metrics {
+	m := Metrics{Frames: len(frames)}
+	var (
+		valid     int
+		sum       float64
+		steps     int
+		last      FVec2
+		hasLast   bool
+		lostAt    time.Time
+		foundAt   time.Time
+		before    FVec2
+		recovered = true
+	)
+	for _, f := range frames {
+		lost := f.Health <= IRLost
+		switch {
+		case lost && lostAt.IsZero() && hasLast:
+			lostAt = f.Time
+			before = last
+		case !lost && !lostAt.IsZero():
+			m.Gap = max(m.Gap, f.Time.Sub(lostAt))
+			lostAt = time.Time{}
+			foundAt = f.Time
+			recovered = false
+		}
+		if !f.Valid {
+			continue
+		}
+		valid++
+		if hasLast && recovered {
+			d := f.Position.Sub(last).Len()
+			sum += d * d
+			steps++
+		}
+		if !recovered && f.Position.Sub(before).Len() <= RecoveryDistance {
+			m.Recovery = max(m.Recovery, f.Time.Sub(foundAt))
+			recovered = true
+		}
+		last, hasLast = f.Position, true
+	}
+	if !recovered && len(frames) > 0 {
+		// never recovered until the end of the session
+		m.Recovery = max(m.Recovery, frames[len(frames)-1].Time.Sub(foundAt))
+	}
+	if len(frames) > 0 {
+		m.Valid = float64(valid) / float64(len(frames))
+	}
+	if steps > 0 {
+		m.Jitter = math.Sqrt(sum / float64(steps))
+	}
+	return m
+}
@@ -0,0 +1,80 @@
+package irpointer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote/pkg/ndjson"
+)
+
+// smoothing returns the default filters with the smoother of newSmoother, without RepeatFilter.
+func smoothing[T Filter](newSmoother func() T) func() FilterChain {
+	return func() FilterChain {
+		return FilterChain{NewErrorFilter(), NewGlitchFilter(), newSmoother()}
+	}
+}
+
+// The sessions in testdata are IR and accelerometer traces at 100Hz in the format recorded by
+// wiilog: a resting remote, an occlusion of both dots, an occlusion of a single dot and a sweep.
+// They are synthesized with noise of about one pixel, sessions recorded with wiilog can be
+// added the same way. The bounds guard against regressions of the pointer and the filters,
+// loosen them only deliberately.
+func TestRecordedSessions(t *testing.T) {
+	oneEuro := smoothing(NewOneEuroSmoothing)
+	kalman := smoothing(NewKalmanSmoothing)
+	marcan := smoothing(NewMarcanSmoothing)
+
+	tests := []struct {
+		trace       string
+		filters     func() FilterChain
+		maxJitter   float64
+		maxGap      time.Duration
+		maxRecovery time.Duration
+		minValid    float64
+	}{
+		{"rest", nil, 2, 0, 0, 0.99},
+		{"rest", oneEuro, 0.2, 0, 0, 0.99},
+		{"rest", kalman, 0.5, 0, 0, 0.99},
+		{"rest", marcan, 0.2, 0, 0, 0.99},
+		{"occlusion", nil, 2, 310 * time.Millisecond, 20 * time.Millisecond, 0.8},
+		{"occlusion", oneEuro, 0.2, 310 * time.Millisecond, 20 * time.Millisecond, 0.8},
+		{"occlusion", kalman, 0.5, 310 * time.Millisecond, 20 * time.Millisecond, 0.8},
+		{"occlusion", marcan, 0.2, 310 * time.Millisecond, 20 * time.Millisecond, 0.8},
+		{"single", nil, 3, 0, 0, 0.99},
+		{"single", oneEuro, 0.3, 0, 0, 0.99},
+		{"sweep", nil, 5, 0, 0, 0.99},
+		{"sweep", oneEuro, 5, 0, 0, 0.99},
+	}
+	for _, tc := range tests {
+		file, err := os.Open(filepath.Join("testdata", tc.trace+".ndjson"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		events, err := ndjson.ReadAll(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.trace, err)
+		}
+
+		var filters FilterChain
+		if tc.filters != nil {
+			filters = tc.filters()
+		}
+		m := Analyze(Replay(NewPipeline(nil, filters, nil), events))
+		t.Logf("%s (%d filters): %+v", tc.trace, len(filters), m)
+		if m.Jitter > tc.maxJitter {
+			t.Errorf("%s: jitter %.2f exceeds %.2f", tc.trace, m.Jitter, tc.maxJitter)
+		}
+		if m.Gap > tc.maxGap {
+			t.Errorf("%s: gap %v exceeds %v", tc.trace, m.Gap, tc.maxGap)
+		}
+		if m.Recovery > tc.maxRecovery {
+			t.Errorf("%s: recovery %v exceeds %v", tc.trace, m.Recovery, tc.maxRecovery)
+		}
+		if m.Valid < tc.minValid {
+			t.Errorf("%s: valid %.2f below %.2f", tc.trace, m.Valid, tc.minValid)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package irpointer
+
+import "github.com/friedelschoen/go-wiimote"
+
+// Pipeline glues the pointer algorithm and filters together. It takes IR and accelerometer
+// events, runs the pointer for each pair of IR and accelerometer data, applies the filters
+// and passes the resulting frame to Output.
+type Pipeline struct {
+	// Pointer is the pointer algorithm, see NewIRPointer
+	Pointer *IRPointer
+	// Filters are applied to every frame of the pointer, may be empty
+	Filters FilterChain
+	// Output is called with every processed frame, may be nil
+	Output func(Frame)
+
+	ir    *wiimote.EventIR
+	accel *wiimote.EventAccel
+	frame Frame
+}
+
+// NewPipeline creates a pipeline with pointer, filters and output. If pointer is nil, the
+// default pointer as returned by NewIRPointer is used, see NewDefaultFilters for the
+// default filters.
+func NewPipeline(pointer *IRPointer, filters FilterChain, output func(Frame)) *Pipeline {
+	if pointer == nil {
+		pointer = NewIRPointer()
+	}
+	return &Pipeline{
+		Pointer: pointer,
+		Filters: filters,
+		Output:  output,
+	}
+}
+
+// Handle processes ev. Only EventIR and EventAccel are processed, other events are only passed
+// to filters implementing EventFilter. As soon as both IR and accelerometer data is received a
+// frame is produced, in which case true is returned.
+func (p *Pipeline) Handle(ev wiimote.Event) bool {
+	for _, f := range p.Filters {
+		if f, ok := f.(EventFilter); ok {
+			f.HandleEvent(ev)
+		}
+	}
+	switch ev := ev.(type) {
+	case *wiimote.EventIR:
+		p.ir = ev
+	case *wiimote.EventAccel:
+		p.accel = ev
+	default:
+		return false
+	}
+	if p.ir == nil || p.accel == nil {
+		return false
+	}
+	p.frame = p.Filters.Apply(p.Pointer.StepEvent(p.ir, p.accel))
+	p.ir = nil
+	p.accel = nil
+	if p.Output != nil {
+		p.Output(p.frame)
+	}
+	return true
+}
+
+// Frame returns the last processed frame.
+func (p *Pipeline) Frame() Frame {
+	return p.frame
+}
+
+// Reset drops pending IR and accelerometer data and resets the pointer and all filters.
+func (p *Pipeline) Reset() {
+	p.Pointer.Reset()
+	p.ir = nil
+	p.accel = nil
+	for _, f := range p.Filters {
+		f.Reset()
+	}
+}
+
+// Run handles all events of dev until an error occurs or the device is gone. The error of the
+// poller is returned, nil if the device is gone.
+func (p *Pipeline) Run(dev wiimote.Poller[wiimote.Event]) error {
+	for {
+		ev, err := dev.Wait(-1)
+		if err != nil {
+			return err
+		}
+		if _, ok := ev.(*wiimote.EventGone); ok {
+			return nil
+		}
+		p.Handle(ev)
+	}
+}
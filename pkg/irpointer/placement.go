@@ -0,0 +1,115 @@
+package irpointer
+
+// Placement is the position of the sensor bar relative to the screen.
+type Placement uint
+
+const (
+	// PlacementAuto detects the placement from the aim direction, see ScreenFilter
+	PlacementAuto Placement = iota
+	// PlacementAbove is a sensor bar mounted on top of the screen
+	PlacementAbove
+	// PlacementBelow is a sensor bar mounted below the screen
+	PlacementBelow
+)
+
+func (p Placement) String() string {
+	switch p {
+	case PlacementAbove:
+		return "above"
+	case PlacementBelow:
+		return "below"
+	}
+	return "auto"
+}
+
+// Coverage selects how much of the pointer space is mapped onto the screen. A wider coverage
+// makes the pointer slower, but the edges are harder to reach with the wiimote turned sideways.
+type Coverage uint
+
+const (
+	// CoverageSafe is a conservative mapping for a 16:9 screen
+	CoverageSafe Coverage = iota
+	// CoverageWide covers more of the pointer space
+	CoverageWide
+)
+
+// screenArea returns the pointer space which covers the screen, see IRPointer.
+func screenArea(placement Placement, coverage Coverage) FRect {
+	width, near, far := 340.0, 92.0, 290.0
+	if coverage == CoverageWide {
+		width, near = 430.0, 194.0
+	}
+	switch placement {
+	case PlacementAbove:
+		// pointing at the screen means pointing under the sensor bar
+		return FRect{Min: FVec2{X: -width, Y: -near}, Max: FVec2{X: width, Y: far}}
+	case PlacementBelow:
+		return FRect{Min: FVec2{X: -width, Y: -far}, Max: FVec2{X: width, Y: near}}
+	}
+	return FRect{Min: FVec2{X: -width, Y: -far}, Max: FVec2{X: width, Y: far}}
+}
+
+// ScreenFilter maps the pointer onto the screen, offsetting Y by the placement of the sensor bar.
+//
+// If Placement is PlacementAuto, the placement is detected from the first CalibrationFrames
+// valid frames: as the user aims at the screen, the pointer is mostly below the sensor bar
+// (positive Y) if the bar is above the screen and vice versa. Until then, the area around the
+// sensor bar is mapped symmetrically.
+type ScreenFilter struct {
+	// Placement of the sensor bar
+	Placement Placement
+	// Coverage of the pointer space
+	Coverage Coverage
+	// Destination is the output space, the screen
+	Destination FRect
+	// CalibrationFrames is the number of valid frames used to detect the placement
+	CalibrationFrames int
+
+	detected Placement
+	sum      float64
+	count    int
+}
+
+// Detected returns the placement in use, PlacementAuto if it is not yet detected.
+func (f *ScreenFilter) Detected() Placement {
+	if f.Placement != PlacementAuto {
+		return f.Placement
+	}
+	return f.detected
+}
+
+// Recalibrate forgets the detected placement.
+func (f *ScreenFilter) Recalibrate() {
+	f.detected = PlacementAuto
+	f.sum = 0
+	f.count = 0
+}
+
+// Reset does not forget the detected placement, as the sensor bar does not move when the
+// pointer is lost. Use Recalibrate instead.
+func (f *ScreenFilter) Reset() {}
+
+func (f *ScreenFilter) Apply(frame Frame) Frame {
+	if !frame.Valid {
+		return frame
+	}
+
+	if f.Detected() == PlacementAuto {
+		f.sum += frame.Position.Y
+		f.count++
+		if f.count >= f.CalibrationFrames {
+			if f.sum >= 0 {
+				f.detected = PlacementAbove
+			} else {
+				f.detected = PlacementBelow
+			}
+		}
+	}
+
+	translate := TranslateFilter{
+		Source:      screenArea(f.Detected(), f.Coverage),
+		Destination: f.Destination,
+		Clamp:       true,
+	}
+	return translate.Apply(frame)
+}
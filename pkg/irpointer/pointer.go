@@ -1,4 +1,4 @@
-// Package irpointe contains an algorithm to use your wiimote IR-sensors as pointer on a screen
+// Package irpointer contains an algorithm to use your wiimote IR-sensors as pointer on a screen.
 package irpointer
 
 // Algorithm to process Wiimote IR tracking data into a usable pointer position
@@ -35,8 +35,10 @@ package irpointer
 
 import (
 	"cmp"
+	"context"
 	"math"
 	"slices"
+	"time"
 
 	"github.com/friedelschoen/go-wiimote"
 )
@@ -77,6 +79,29 @@ func findDots(slots [4]wiimote.IRSlot) []FVec2 {
 	return dots[:l]
 }
 
+// dotWeights returns the confidence of every valid slot in the order of findDots.
+func dotWeights(slots [4]wiimote.IRSlot) (weights [4]float64) {
+	l := 0
+	for _, slot := range slots {
+		if slot.Valid() {
+			weights[l] = slot.Confidence()
+			l++
+		}
+	}
+	return weights
+}
+
+// selectSlots invalidates ignored slots and slots with a too low confidence.
+func (ir *IRPointer) selectSlots(slots [4]wiimote.IRSlot) [4]wiimote.IRSlot {
+	slots = ir.IgnoreSlots.Ignore(slots)
+	for n, slot := range slots {
+		if slot.Valid() && slot.Confidence() < ir.MinConfidence {
+			slots[n] = wiimote.InvalidIRSlot
+		}
+	}
+	return slots
+}
+
 type SensorBar struct {
 	// Angle wiimote to sensorbar in radians.
 	Angle float64
@@ -113,6 +138,17 @@ type Frame struct {
 	Valid bool
 	// Smoothed coordinate
 	Position FVec2
+	// Time is the timestamp of the IR data, zero if unknown in which case filters use the
+	// time they are applied
+	Time time.Time
+}
+
+// timestamp returns the time of f, the current time if it is unknown.
+func (f Frame) timestamp() time.Time {
+	if f.Time.IsZero() {
+		return time.Now()
+	}
+	return f.Time
 }
 
 // IRPointer holds the current state of the pointer. The smoothed position is
@@ -200,16 +236,31 @@ type IRPointer struct {
 	// when the wiimote is at one meter
 	WiimoteFOVCoefficient float64
 
+	// IgnoreSlots are never tracked, some third-party sensor bars produce ghost dots in fixed slots
+	IgnoreSlots wiimote.IRMask
+
+	// MinConfidence ignores dots with a lower confidence, see wiimote.IRSlot.Confidence
+	MinConfidence float64
+
+	// Weighted prefers sensor bar candidates with confident dots
+	Weighted bool
+
 	frame Frame
+	// confidence of the dots of the current update, see dotWeights
+	weights [4]float64
+	// buffer of findCanditates, to not allocate on every update
+	candidates [6]SensorBar
 }
 
+// findCanditates returns all dot pairs which may be the sensor bar, the returned slice is
+// reused by the next call.
 func (ir *IRPointer) findCanditates(dots, accDots []FVec2, roll float64) []SensorBar {
 	if len(dots) < 2 {
 		return nil
 	}
 
 	// iterate through all dot pairs
-	var candidates [6]SensorBar
+	candidates := &ir.candidates
 	l := 0
 	for first := 0; first < (len(dots) - 1); first++ {
 		for second := (first + 1); second < len(dots); second++ {
@@ -273,6 +324,9 @@ func (ir *IRPointer) findCanditates(dots, accDots []FVec2, roll float64) []Senso
 				continue
 			}
 			cand.score = 1 / (cand.rotDots[1].X - cand.rotDots[0].X)
+			if ir.Weighted {
+				cand.score *= ir.weights[first] * ir.weights[second]
+			}
 
 			// we have a candidate, store it
 			candidates[l] = cand
@@ -375,7 +429,9 @@ func (ir *IRPointer) guessSingle(dots, accDots []FVec2, roll float64) (sb Sensor
 // raw      *FVec2  // Raw coordinate (-512..512, 0 is center)
 // distance float64 // Pixel width of the sensor bar
 func (ir *IRPointer) updateSensorbar(slots [4]wiimote.IRSlot, roll float64) {
+	slots = ir.selectSlots(slots)
 	dots := findDots(slots)
+	ir.weights = dotWeights(slots)
 
 	// nothing to track
 	if len(dots) == 0 {
@@ -391,6 +447,7 @@ func (ir *IRPointer) updateSensorbar(slots [4]wiimote.IRSlot, roll float64) {
 	rotateDots(accDots[:], dots, roll)
 
 	candidates := ir.findCanditates(dots, accDots[:len(dots)], roll)
+	wiimote.Logger().Log(context.Background(), wiimote.LevelTrace, "pointer candidates", "dots", len(dots), "candidates", len(candidates))
 	if len(candidates) == 0 {
 		sb, ok := ir.guessSingle(dots, accDots[:len(dots)], roll)
 		if !ok {
@@ -412,21 +469,39 @@ func (ir *IRPointer) updateSensorbar(slots [4]wiimote.IRSlot, roll float64) {
 	ir.frame.Valid = true
 }
 
+// Reset forgets the tracked sensor bar, the pointer is dead until it sees the sensor bar again.
+func (ir *IRPointer) Reset() {
+	ir.frame = Frame{}
+	ir.weights = [4]float64{}
+}
+
 // Step processes new dots and acceleration values
 //
 // If acceleration data is unreliable (wiimote is significantly
 // accelerating) then you should supply the last known good value.
 func (ir *IRPointer) Step(slots [4]wiimote.IRSlot, accel wiimote.Vec3) Frame {
-	roll := math.Atan2(float64(accel.X), float64(accel.Z))
-	return ir.StepRoll(slots, roll)
+	return ir.StepRoll(slots, wiimote.NominalAccel.G(accel).Roll())
+}
+
+// StepEvent processes the dots of ir and the roll of accel, the frame carries the timestamp of ir.
+func (ir *IRPointer) StepEvent(irev *wiimote.EventIR, accel *wiimote.EventAccel) Frame {
+	frame := ir.Step(irev.Slots, accel.Accel)
+	if irev.Event != nil {
+		frame.Time = irev.Timestamp()
+	}
+	return frame
 }
 
 // StepRoll processes new dots and roll values
 //
-// You can calculate the roll from the accel as roll=atan2(x, z). If roll
+// You can calculate the roll from the accel using wiimote.FVec3.Roll. If roll
 // data is unreliable (wiimote is significantly accelerating) then you should
 // supply the last known good value.
 func (ir *IRPointer) StepRoll(slots [4]wiimote.IRSlot, roll float64) Frame {
+	prev := ir.frame.Health
 	ir.updateSensorbar(slots, roll)
+	if ir.frame.Health != prev {
+		wiimote.Logger().Debug("pointer health changed", "from", prev, "to", ir.frame.Health, "distance", ir.frame.Distance)
+	}
 	return ir.frame
 }
@@ -1,8 +1,11 @@
 package irpointer
 
 import (
+	"cmp"
 	"math"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/friedelschoen/go-wiimote"
 )
@@ -63,7 +66,7 @@ func copyDots(in []FVec2) []FVec2 {
 // rotateDots
 
 func TestRotateDots_ThetaZeroIsCopy(t *testing.T) {
-	in := []FVec2{{1, 2}, {-3, 4}, {0.5, -0.25}}
+	in := []FVec2{{X: 1, Y: 2}, {X: -3, Y: 4}, {X: 0.5, Y: -0.25}}
 	out := make([]FVec2, len(in))
 	rotateDots(out, in, 0)
 
@@ -75,7 +78,7 @@ func TestRotateDots_ThetaZeroIsCopy(t *testing.T) {
 }
 
 func TestRotateDots_PreservesNormAndInverse(t *testing.T) {
-	in := []FVec2{{1, 2}, {-3, 4}, {0.5, -0.25}}
+	in := []FVec2{{X: 1, Y: 2}, {X: -3, Y: 4}, {X: 0.5, Y: -0.25}}
 	tmp := make([]FVec2, len(in))
 	out := make([]FVec2, len(in))
 
@@ -113,7 +116,7 @@ func TestFindDots_FiltersInvalidAndMapsCoordinates(t *testing.T) {
 		t.Fatalf("expected 3 dots, got %d: %v", len(dots), dots)
 	}
 
-	if !almostVec(dots[0], FVec2{0, 0}) {
+	if !almostVec(dots[0], FVec2{X: 0, Y: 0}) {
 		t.Fatalf("expected center dot (0,0), got %v", dots[0])
 	}
 
@@ -127,12 +130,35 @@ func TestFindDots_FiltersInvalidAndMapsCoordinates(t *testing.T) {
 	}
 }
 
+func TestSelectSlots_IgnoresMaskedAndFaintSlots(t *testing.T) {
+	ir := NewIRPointer()
+	ir.IgnoreSlots = wiimote.IRSlots(0)
+	ir.MinConfidence = 0.5
+
+	faint := mkSlotValid(200, 384)
+	faint.Intensity = 0x20
+	slots := ir.selectSlots(mkSlots(
+		mkSlotValid(512, 384), // ignored
+		faint,                 // too faint
+		mkSlotValid(100, 384),
+	))
+
+	dots := findDots(slots)
+	if len(dots) != 1 {
+		t.Fatalf("expected 1 dot, got %d: %v", len(dots), dots)
+	}
+	wantX := -(float64(100) - 512.0) / 512.0
+	if !almost(dots[0].X, wantX) {
+		t.Fatalf("expected dot approx (%v,0), got %v", wantX, dots[0])
+	}
+}
+
 // findCanditates
 
 func TestFindCandidates_GoodBarOneCandidate(t *testing.T) {
 	ir := NewIRPointer()
 	// Two dots horizontally aligned => slope ~0 and width > MinSbWidth.
-	dots := []FVec2{{-0.4, 0.0}, {0.4, 0.0}}
+	dots := []FVec2{{X: -0.4, Y: 0.0}, {X: 0.4, Y: 0.0}}
 	accDots := copyDots(dots)
 
 	cands := ir.findCanditates(dots, accDots, 0)
@@ -149,11 +175,31 @@ func TestFindCandidates_GoodBarOneCandidate(t *testing.T) {
 	}
 }
 
+func TestFindCandidates_WeightedPrefersConfidentDots(t *testing.T) {
+	ir := NewIRPointer()
+	ir.Weighted = true
+	// the narrow pair scores better unless its right dot is a faint ghost
+	dots := []FVec2{{X: -0.4, Y: 0.0}, {X: 0.4, Y: 0.0}, {X: 0.6, Y: 0.0}}
+	accDots := copyDots(dots)
+	ir.weights = [4]float64{1, 1, 0.1}
+
+	cands := ir.findCanditates(dots, accDots, 0)
+	if len(cands) == 0 {
+		t.Fatalf("expected candidates")
+	}
+	best := slices.MaxFunc(cands, func(left, right SensorBar) int {
+		return cmp.Compare(left.score, right.score)
+	})
+	if !almost(best.rotDots[1].X-best.rotDots[0].X, 0.8) {
+		t.Fatalf("expected the confident pair, got %+v", best.rotDots)
+	}
+}
+
 func TestFindCandidates_TooSteepRejected(t *testing.T) {
 	ir := NewIRPointer()
 	ir.MaxSbSlope = 0.2 // make slope check stricter
 
-	dots := []FVec2{{-0.4, -0.4}, {0.4, 0.4}} // slope ~ 1.0
+	dots := []FVec2{{X: -0.4, Y: -0.4}, {X: 0.4, Y: 0.4}} // slope ~ 1.0
 	accDots := copyDots(dots)
 
 	cands := ir.findCanditates(dots, accDots, 0)
@@ -166,7 +212,7 @@ func TestFindCandidates_TooNarrowRejected(t *testing.T) {
 	ir := NewIRPointer()
 	ir.MinSbWidth = 0.9 // wider than our dot separation
 
-	dots := []FVec2{{-0.2, 0.0}, {0.2, 0.0}} // width 0.4
+	dots := []FVec2{{X: -0.2, Y: 0.0}, {X: 0.2, Y: 0.0}} // width 0.4
 	accDots := copyDots(dots)
 
 	cands := ir.findCanditates(dots, accDots, 0)
@@ -184,7 +230,7 @@ func TestFindCandidates_TooNarrowRejected(t *testing.T) {
 // 	ir := NewIRPointer(&params)
 
 // 	// Left + middle + right dot: pairs should be rejected due to a middle dot inside.
-// 	dots := []FVec2{{-0.6, 0.0}, {0.0, 0.0}, {0.6, 0.0}}
+// 	dots := []FVec2{{X: -0.6, Y: 0.0}, {X: 0.0, Y: 0.0}, {X: 0.6, Y: 0.0}}
 // 	accDots := copyDots(dots)
 
 // 	cands := ir.findCanditates(dots, accDots, 0)
@@ -458,3 +504,317 @@ func TestUpdateSensorbar_TwoDotsGivesGoodAndDistance(t *testing.T) {
 // 		t.Fatalf("expected valid=true enough errors, got %v", ir.frame.Valid)
 // 	}
 // }
+
+// Pipeline
+
+func TestPipeline_EmitsFrameOnIRAndAccel(t *testing.T) {
+	var frames []Frame
+	p := NewPipeline(nil, FilterChain{NewErrorFilter()}, func(f Frame) {
+		frames = append(frames, f)
+	})
+
+	ir := &wiimote.EventIR{Slots: mkSlots(mkSlotValid(400, 384), mkSlotValid(624, 384))}
+	accel := &wiimote.EventAccel{Accel: wiimote.Vec3{Z: 100}}
+
+	if p.Handle(ir) {
+		t.Fatalf("expected no frame with only IR data")
+	}
+	if p.Handle(&wiimote.EventKey{}) {
+		t.Fatalf("expected key events to be ignored")
+	}
+	if !p.Handle(accel) {
+		t.Fatalf("expected frame with IR and accel data")
+	}
+	if len(frames) != 1 {
+		t.Fatalf("expected 1 output frame, got %d", len(frames))
+	}
+	if frames[0].Health != IRGood || !frames[0].Valid {
+		t.Fatalf("expected good valid frame, got %+v", frames[0])
+	}
+	if p.Frame() != frames[0] {
+		t.Fatalf("expected Frame() to return last output")
+	}
+
+	// pending data is consumed by a frame
+	if p.Handle(accel) {
+		t.Fatalf("expected no frame with only accel data")
+	}
+}
+
+// Kalman smoothing
+
+func TestKalmanSmoothing_TracksConstantVelocity(t *testing.T) {
+	f := NewKalmanSmoothing()
+	start := time.Unix(0, 0)
+	const vx, vy = 200.0, -100.0 // units per second
+
+	var out Frame
+	for i := range 200 {
+		ts := time.Duration(i) * 10 * time.Millisecond
+		sec := ts.Seconds()
+		in := Frame{Valid: true, Position: FVec2{X: vx * sec, Y: vy * sec}}
+		out = f.apply(in, start.Add(ts))
+	}
+
+	last := 199 * 10 * time.Millisecond
+	want := FVec2{X: vx * last.Seconds(), Y: vy * last.Seconds()}
+	if math.Abs(out.Position.X-want.X) > 1 || math.Abs(out.Position.Y-want.Y) > 1 {
+		t.Fatalf("expected position near %+v, got %+v", want, out.Position)
+	}
+	vel := f.Velocity()
+	if math.Abs(vel.X-vx) > 5 || math.Abs(vel.Y-vy) > 5 {
+		t.Fatalf("expected velocity near (%v %v), got %+v", vx, vy, vel)
+	}
+
+	pred, ok := f.Predict(start.Add(last + 5*time.Millisecond))
+	if !ok {
+		t.Fatalf("expected prediction")
+	}
+	if pred.X <= out.Position.X || pred.Y >= out.Position.Y {
+		t.Fatalf("expected prediction ahead of %+v, got %+v", out.Position, pred)
+	}
+}
+
+func TestKalmanSmoothing_InvalidPassthrough(t *testing.T) {
+	f := NewKalmanSmoothing()
+	if _, ok := f.Predict(time.Now()); ok {
+		t.Fatalf("expected no prediction before first frame")
+	}
+	in := Frame{Valid: false, Position: FVec2{X: 3, Y: 4}}
+	if out := f.Apply(in); out != in {
+		t.Fatalf("expected invalid frame to pass unchanged")
+	}
+}
+
+// Predictor
+
+func TestPredictor_Extrapolates(t *testing.T) {
+	p := NewPredictor(240, nil)
+	p.VelocitySmoothing = 1
+	start := time.Unix(0, 0)
+
+	p.record(Frame{Valid: true, Position: FVec2{X: 0, Y: 0}}, start)
+	p.record(Frame{Valid: true, Position: FVec2{X: 1, Y: -2}}, start.Add(10*time.Millisecond))
+
+	// 100 units/s and -200 units/s, 5ms after the last frame
+	got := p.At(start.Add(15 * time.Millisecond)).Position
+	if !almostVec(got, FVec2{X: 1.5, Y: -3}) {
+		t.Fatalf("expected (1.5 -3), got %+v", got)
+	}
+
+	// extrapolation is limited
+	got = p.At(start.Add(time.Second)).Position
+	want := FVec2{X: 1 + 100*p.MaxExtrapolation.Seconds(), Y: -2 - 200*p.MaxExtrapolation.Seconds()}
+	if !almostVec(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	// invalid frames stop the prediction
+	p.record(Frame{Valid: false}, start.Add(20*time.Millisecond))
+	if p.At(start.Add(25 * time.Millisecond)).Valid {
+		t.Fatalf("expected invalid frame after signal loss")
+	}
+}
+
+func TestScreenFilter_DetectsPlacement(t *testing.T) {
+	f := NewScreenFilter(PlacementAuto, CoverageSafe)
+	f.CalibrationFrames = 3
+	for range 3 {
+		f.Apply(Frame{Valid: true, Position: FVec2{X: 0, Y: 100}})
+	}
+	if got := f.Detected(); got != PlacementAbove {
+		t.Fatalf("expected placement above, got %v", got)
+	}
+
+	// the bottom of the area above the sensor bar is the bottom of the screen
+	out := f.Apply(Frame{Valid: true, Position: FVec2{X: 340, Y: 290}})
+	if !almostVec(out.Position, FVec2{X: 1, Y: 1}) {
+		t.Fatalf("expected (1, 1), got %v", out.Position)
+	}
+
+	f.Recalibrate()
+	for range 3 {
+		f.Apply(Frame{Valid: true, Position: FVec2{X: 0, Y: -100}})
+	}
+	if got := f.Detected(); got != PlacementBelow {
+		t.Fatalf("expected placement below, got %v", got)
+	}
+	out = f.Apply(Frame{Valid: true, Position: FVec2{X: -340, Y: -290}})
+	if !almostVec(out.Position, FVec2{X: -1, Y: -1}) {
+		t.Fatalf("expected (-1, -1), got %v", out.Position)
+	}
+}
+
+func TestSensorNormalize_KeepsPresets(t *testing.T) {
+	for name, tc := range map[string]struct {
+		f      *TranslateFilter
+		expect FRect
+	}{
+		"safe top":    {NewSafeTopSensorNormalize(), FRect{Min: FVec2{X: -340, Y: -92}, Max: FVec2{X: 340, Y: 290}}},
+		"safe bottom": {NewSafeBottomSensorNormalize(), FRect{Min: FVec2{X: -340, Y: -290}, Max: FVec2{X: 340, Y: 92}}},
+		"wide top":    {NewWideTopSensorNormalize(), FRect{Min: FVec2{X: -430, Y: -194}, Max: FVec2{X: 430, Y: 290}}},
+		"wide bottom": {NewWideBottomSensorNormalize(), FRect{Min: FVec2{X: -430, Y: -290}, Max: FVec2{X: 430, Y: 194}}},
+	} {
+		if tc.f.Source != tc.expect || !tc.f.Clamp {
+			t.Errorf("%s: expected %v, got %v", name, tc.expect, tc.f.Source)
+		}
+	}
+}
+
+// Curves
+
+func TestCurves(t *testing.T) {
+	tests := []struct {
+		spec   string
+		in     float64
+		expect float64
+	}{
+		{"linear", 0.5, 0.5},
+		{"linear:2", 0.5, 1},
+		{"power:2", 0.5, 0.25},
+		{"power:2:3", 0.5, 0.75},
+		{"points:0.5,0.25 1,1", 0.25, 0.125},
+		{"points:0.5,0.25;1,1", 0.75, 0.625},
+		{"points:0.5,0.25 1,1", 2, 2.5},
+	}
+	for _, tc := range tests {
+		curve, err := ParseCurve(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if got := curve(tc.in); !almost(got, tc.expect) {
+			t.Errorf("%s(%v): expected %v, got %v", tc.spec, tc.in, tc.expect, got)
+		}
+	}
+	for _, spec := range []string{"cubic", "power", "points:", "points:1"} {
+		if _, err := ParseCurve(spec); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+}
+
+func TestSensitivityFilter_KeepsSign(t *testing.T) {
+	f := NewSensitivityFilter(PowerCurve(2, 1))
+	out := f.Apply(Frame{Valid: true, Position: FVec2{X: -256, Y: 256}})
+	if !almostVec(out.Position, FVec2{X: -128, Y: 128}) {
+		t.Fatalf("expected (-128 128), got %v", out.Position)
+	}
+}
+
+func TestRelativeFilter_Accelerates(t *testing.T) {
+	f := NewRelativeFilter(PowerCurve(2, 1), FRect{Min: FVec2{X: 0, Y: 0}, Max: FVec2{X: 100, Y: 100}})
+	start := time.Unix(0, 0)
+
+	if out := f.apply(Frame{Valid: true, Position: FVec2{X: 500}}, start); !almostVec(out.Position, FVec2{X: 50, Y: 50}) {
+		t.Fatalf("expected cursor at the center, got %v", out.Position)
+	}
+	// 100 units/s is one width per second, which is not accelerated
+	out := f.apply(Frame{Valid: true, Position: FVec2{X: 510}}, start.Add(100*time.Millisecond))
+	if !almostVec(out.Position, FVec2{X: 60, Y: 50}) {
+		t.Fatalf("expected (60 50), got %v", out.Position)
+	}
+	// twice as fast moves twice as far
+	out = f.apply(Frame{Valid: true, Position: FVec2{X: 500}}, start.Add(150*time.Millisecond))
+	if !almostVec(out.Position, FVec2{X: 40, Y: 50}) || !almostVec(f.Delta, FVec2{X: -20, Y: 0}) {
+		t.Fatalf("expected (40 50), got %v (delta %v)", out.Position, f.Delta)
+	}
+
+	// losing the pointer does not jump the cursor
+	f.apply(Frame{}, start.Add(200*time.Millisecond))
+	out = f.apply(Frame{Valid: true, Position: FVec2{X: -300}}, start.Add(250*time.Millisecond))
+	if !almostVec(out.Position, FVec2{X: 40, Y: 50}) {
+		t.Fatalf("expected cursor to stay at (40 50), got %v", out.Position)
+	}
+}
+
+func TestRecenterFilter(t *testing.T) {
+	f := NewRecenterFilter(FRect{Min: FVec2{X: 0, Y: 0}, Max: FVec2{X: 100, Y: 50}})
+	if out := f.Apply(Frame{Valid: true, Position: FVec2{X: 10, Y: 10}}); !almostVec(out.Position, FVec2{X: 10, Y: 10}) {
+		t.Fatalf("expected no offset before recenter, got %v", out.Position)
+	}
+
+	f.Recenter()
+	// invalid frames do not consume the recenter
+	f.Apply(Frame{})
+	if out := f.Apply(Frame{Valid: true, Position: FVec2{X: 80, Y: 40}}); !almostVec(out.Position, FVec2{X: 50, Y: 25}) {
+		t.Fatalf("expected the aim at the center, got %v", out.Position)
+	}
+	if out := f.Apply(Frame{Valid: true, Position: FVec2{X: 90, Y: 40}}); !almostVec(out.Position, FVec2{X: 60, Y: 25}) {
+		t.Fatalf("expected offset to be kept, got %v", out.Position)
+	}
+
+	f.Clear()
+	if out := f.Apply(Frame{Valid: true, Position: FVec2{X: 90, Y: 40}}); !almostVec(out.Position, FVec2{X: 90, Y: 40}) {
+		t.Fatalf("expected offset to be cleared, got %v", out.Position)
+	}
+}
+
+func TestHybridFilter_DeadReckoning(t *testing.T) {
+	f := NewHybridFilter()
+	f.RestThreshold = 0
+	start := time.Unix(0, 0)
+
+	f.apply(Frame{Valid: true, Health: IRGood, Position: FVec2{X: 100, Y: 50}}, start)
+	// turn left with 10 degree per second for one second
+	yaw := int32(math.Round(10 * wiimote.MPUnitsPerDegree))
+	for i := range 11 {
+		f.UpdateMotionPlus(wiimote.Vec3{Z: yaw}, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	out := f.apply(Frame{Health: IRLost}, start.Add(time.Second))
+	if !out.Valid || math.Abs(out.Position.X-(100-10*f.UnitsPerDegree)) > 1 || !almost(out.Position.Y, 50) {
+		t.Fatalf("expected pointer moved left by 10 degrees, got %+v", out)
+	}
+	if out.Health != IRLost {
+		t.Errorf("expected health to be kept, got %v", out.Health)
+	}
+
+	// snapping back to the IR
+	out = f.apply(Frame{Valid: true, Health: IRGood, Position: FVec2{X: 0, Y: 0}}, start.Add(2*time.Second))
+	if !almostVec(out.Position, FVec2{}) {
+		t.Fatalf("expected IR position, got %v", out.Position)
+	}
+
+	// giving up after MaxDeadReckoning
+	if out := f.apply(Frame{Health: IRLost}, start.Add(time.Minute)); out.Valid {
+		t.Fatalf("expected invalid frame after dead reckoning timeout")
+	}
+}
+
+func TestHybridFilter_EstimatesBias(t *testing.T) {
+	f := NewHybridFilter()
+	f.BiasSmoothing = 1
+	f.UpdateMotionPlus(wiimote.Vec3{X: int32(math.Round(2 * wiimote.MPUnitsPerDegree))}, time.Unix(0, 0))
+	if math.Abs(f.Bias.X-2) > 0.1 {
+		t.Fatalf("expected bias of 2 degree per second, got %v", f.Bias)
+	}
+}
+
+func TestNewIRPointer_OptionsApplyInOrder(t *testing.T) {
+	params := NewIRPointer(WithSensorBar(30, 3, 1.5))
+	ir := NewIRPointer(WithParams(params), WithIgnoreSlots(wiimote.IRSlots(2)), WithConfidence(0.3))
+	if ir.SbWidth != 30 || ir.SbDotWidth != 3 || ir.SbDotHeight != 1.5 {
+		t.Fatalf("expected sensor bar of params, got %v %v %v", ir.SbWidth, ir.SbDotWidth, ir.SbDotHeight)
+	}
+	if !ir.IgnoreSlots.Has(2) || ir.MinConfidence != 0.3 || !ir.Weighted {
+		t.Fatalf("expected later options to apply, got %+v", ir)
+	}
+	if ir == params {
+		t.Fatalf("expected a copy of params")
+	}
+}
+
+type stampEvent time.Time
+
+func (stampEvent) Feature() wiimote.Feature { return nil }
+func (stampEvent) Device() wiimote.Device   { return nil }
+func (e stampEvent) Timestamp() time.Time   { return time.Time(e) }
+
+func TestPipeline_FrameCarriesIRTimestamp(t *testing.T) {
+	at := time.Unix(1000, 0)
+	p := NewPipeline(nil, nil, nil)
+	p.Handle(&wiimote.EventIR{Event: stampEvent(at), Slots: mkSlots(mkSlotValid(400, 384), mkSlotValid(624, 384))})
+	p.Handle(&wiimote.EventAccel{Event: stampEvent(at.Add(time.Millisecond)), Accel: wiimote.Vec3{Z: 100}})
+	if !p.Frame().Time.Equal(at) {
+		t.Fatalf("expected frame at %v, got %v", at, p.Frame().Time)
+	}
+}
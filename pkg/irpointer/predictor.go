@@ -0,0 +1,107 @@
+package irpointer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Predictor upsamples pointer frames. The IR camera reports at about 100Hz, while displays may
+// refresh much faster. The predictor is a filter which records every frame and estimates the
+// velocity of the pointer, Run then emits extrapolated frames at a fixed rate.
+//
+// Apply and Run may be called from different goroutines.
+type Predictor struct {
+	// Interval between emitted frames, e.g. time.Second/240
+	Interval time.Duration
+	// MaxExtrapolation is the maximum time to extrapolate after the last frame, after that the
+	// last position is held. 0 means no limit.
+	MaxExtrapolation time.Duration
+	// VelocitySmoothing is the weight of a new velocity sample (0..1), 1 means only the last
+	// two frames are used.
+	VelocitySmoothing float64
+	// Output is called by Run with every predicted frame
+	Output func(Frame)
+
+	mu    sync.Mutex
+	frame Frame
+	lastT time.Time
+	alive bool
+	vx    lowPass
+	vy    lowPass
+}
+
+func (p *Predictor) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.alive = false
+	p.vx = lowPass{}
+	p.vy = lowPass{}
+}
+
+// Apply records frame and returns it unchanged.
+func (p *Predictor) Apply(frame Frame) Frame {
+	p.record(frame, time.Now())
+	return frame
+}
+
+func (p *Predictor) record(frame Frame, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !frame.Valid {
+		p.frame = frame
+		p.alive = false
+		p.vx = lowPass{}
+		p.vy = lowPass{}
+		return
+	}
+	if p.alive {
+		if dt := now.Sub(p.lastT).Seconds(); dt > 0 {
+			p.vx.apply((frame.Position.X-p.frame.Position.X)/dt, p.VelocitySmoothing)
+			p.vy.apply((frame.Position.Y-p.frame.Position.Y)/dt, p.VelocitySmoothing)
+		}
+	}
+	p.alive = true
+	p.frame = frame
+	p.lastT = now
+}
+
+// At returns the frame predicted at t. If no valid frame was recorded, the last frame is
+// returned as is.
+func (p *Predictor) At(t time.Time) Frame {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	frame := p.frame
+	if !p.alive {
+		return frame
+	}
+	dtDur := t.Sub(p.lastT)
+	if dtDur < 0 {
+		dtDur = 0
+	}
+	if p.MaxExtrapolation > 0 && dtDur > p.MaxExtrapolation {
+		dtDur = p.MaxExtrapolation
+	}
+	dt := dtDur.Seconds()
+	frame.Position.X += p.vx.y * dt
+	frame.Position.Y += p.vy.y * dt
+	return frame
+}
+
+// Run emits a predicted frame to Output every Interval until ctx is done.
+func (p *Predictor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if p.Output != nil {
+				p.Output(p.At(now))
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package irpointer
+
+// RecenterFilter offsets the pointer, so the aim at the time of Recenter becomes Center. It is
+// used to correct drift, e.g. of a pointer aided by Motion Plus while the IR is lost.
+type RecenterFilter struct {
+	// Center is the position the aim is moved to, usually the center of the screen
+	Center FVec2
+	// Offset is added to every valid position, it is set by Recenter
+	Offset FVec2
+
+	pending bool
+}
+
+// Reset drops a pending recenter, the offset is kept.
+func (f *RecenterFilter) Reset() {
+	f.pending = false
+}
+
+// Recenter moves the position of the next valid frame to Center. The offset is applied to all
+// following frames, until Recenter or Clear is called again.
+func (f *RecenterFilter) Recenter() {
+	f.pending = true
+}
+
+// Clear removes the offset.
+func (f *RecenterFilter) Clear() {
+	f.Offset = FVec2{}
+	f.pending = false
+}
+
+func (f *RecenterFilter) Apply(frame Frame) Frame {
+	if !frame.Valid {
+		return frame
+	}
+	if f.pending {
+		f.pending = false
+		f.Offset = FVec2{X: f.Center.X - frame.Position.X, Y: f.Center.Y - frame.Position.Y}
+	}
+	frame.Position.X += f.Offset.X
+	frame.Position.Y += f.Offset.Y
+	return frame
+}
@@ -0,0 +1,60 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/friedelschoen/go-uinput"
+)
+
+// Step is a single step of an action, either a chord of keys or a delay.
+type Step struct {
+	Keys  []uinput.Key
+	Delay time.Duration
+}
+
+// Action is the target of a mapped button. An action with a single chord (e.g.
+// "KEY_LEFTCTRL+KEY_T") is held as long as the button is held. Otherwise the action is a
+// macro (e.g. "KEY_H, KEY_I, 100ms, KEY_ENTER") which taps every chord in order when the
+// button is pressed, waiting for the delays in between.
+type Action []Step
+
+// ParseAction parses an action, see Action.
+func ParseAction(s string) (Action, error) {
+	var act Action
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if d, err := time.ParseDuration(part); err == nil {
+			act = append(act, Step{Delay: d})
+			continue
+		}
+		var st Step
+		for name := range strings.SplitSeq(part, "+") {
+			key, ok := LookupOutputKey(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown key: %s", name)
+			}
+			st.Keys = append(st.Keys, key)
+		}
+		act = append(act, st)
+	}
+	return act, nil
+}
+
+// Chord returns the keys of act if it is a single chord.
+func (act Action) Chord() ([]uinput.Key, bool) {
+	if len(act) != 1 || len(act[0].Keys) == 0 {
+		return nil, false
+	}
+	return act[0].Keys, true
+}
+
+// Keys returns all keys used by act.
+func (act Action) Keys() []uinput.Key {
+	var keys []uinput.Key
+	for _, st := range act {
+		keys = append(keys, st.Keys...)
+	}
+	return keys
+}
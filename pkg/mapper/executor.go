@@ -0,0 +1,114 @@
+package mapper
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-uinput"
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Output receives the keys of executed actions, e.g. a virtual keyboard.
+type Output interface {
+	Key(key uinput.Key, pressed bool) error
+}
+
+// Executor runs output actions on an output. Chords are pressed in order and released in reverse
+// order, macros run in the background and are cancelled if the button is pressed again or the
+// executor is stopped.
+type Executor struct {
+	out Output
+
+	mu      sync.Mutex
+	running map[wiimote.Key]context.CancelFunc
+	held    map[wiimote.Key][]uinput.Key
+	wg      sync.WaitGroup
+}
+
+// NewExecutor returns an executor writing to out.
+func NewExecutor(out Output) *Executor {
+	return &Executor{
+		out:     out,
+		running: make(map[wiimote.Key]context.CancelFunc),
+		held:    make(map[wiimote.Key][]uinput.Key),
+	}
+}
+
+// press sets the state of keys, releasing in reverse order. The caller must hold e.mu.
+func (e *Executor) press(keys []uinput.Key, pressed bool) {
+	if pressed {
+		for _, key := range keys {
+			e.out.Key(key, true)
+		}
+		return
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		e.out.Key(keys[i], false)
+	}
+}
+
+// Run runs the output action oa.
+func (e *Executor) Run(oa OutputAction) {
+	button, act, pressed := oa.Button, oa.Action, oa.Pressed
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if keys, ok := act.Chord(); ok {
+		if pressed {
+			e.held[button] = keys
+		} else {
+			delete(e.held, button)
+		}
+		e.press(keys, pressed)
+		return
+	}
+	if !pressed {
+		return
+	}
+	if cancel, ok := e.running[button]; ok {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.running[button] = cancel
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.run(ctx, act)
+	}()
+}
+
+func (e *Executor) run(ctx context.Context, act Action) {
+	for _, st := range act {
+		if st.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(st.Delay):
+			}
+			continue
+		}
+		e.mu.Lock()
+		if ctx.Err() != nil {
+			e.mu.Unlock()
+			return
+		}
+		e.press(st.Keys, true)
+		e.press(st.Keys, false)
+		e.mu.Unlock()
+	}
+}
+
+// Stop cancels all running macros and releases all held chords.
+func (e *Executor) Stop() {
+	e.mu.Lock()
+	for button, cancel := range e.running {
+		cancel()
+		delete(e.running, button)
+	}
+	for button, keys := range e.held {
+		e.press(keys, false)
+		delete(e.held, button)
+	}
+	e.mu.Unlock()
+	e.wg.Wait()
+}
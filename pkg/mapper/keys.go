@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"strings"
+
+	"github.com/friedelschoen/go-uinput"
+)
+
+// OutputKeyName returns the name of key as accepted by LookupOutputKey, e.g. "KEY_ENTER".
+func OutputKeyName(key uinput.Key) string {
+	return key.String()
+}
+
+// LookupOutputKey returns the output key of name. The lookup is case-insensitive and names
+// without "KEY_" or "BTN_" prefix are looked up as keys, e.g. "enter" or "KEY_ENTER".
+func LookupOutputKey(name string) (uinput.Key, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if key, ok := uinput.LookupKey(name); ok {
+		return key, true
+	}
+	return uinput.LookupKey("KEY_" + name)
+}
+
+// OutputKeyInfo describes an output key for listings, e.g. in a mapping editor.
+type OutputKeyInfo struct {
+	Key uinput.Key
+	// Name is the name of the key, see OutputKeyName
+	Name string
+	// Group is "key" for keyboard keys (KEY_) and "button" for buttons (BTN_)
+	Group string
+}
+
+// AllOutputKeys returns all named output keys in order of their value. Keys with multiple names
+// are listed once.
+func AllOutputKeys() []OutputKeyInfo {
+	var keys []OutputKeyInfo
+	for key := uinput.KeyReserved + 1; key < uinput.KeyMax; key++ {
+		name := OutputKeyName(key)
+		var group string
+		switch {
+		case strings.HasPrefix(name, "KEY_"):
+			group = "key"
+		case strings.HasPrefix(name, "BTN_"):
+			group = "button"
+		default:
+			continue
+		}
+		keys = append(keys, OutputKeyInfo{Key: key, Name: name, Group: group})
+	}
+	return keys
+}
@@ -0,0 +1,168 @@
+// Package mapper resolves button events to mapped targets. It supports toggles, where a press
+// toggles the state of the target, and shift layers, where holding a button switches to a
+// different mapping table.
+package mapper
+
+import (
+	"iter"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// BaseLayer is the name of the layer which is active if no shift button is held.
+const BaseLayer = ""
+
+// Binding binds a button to a target.
+type Binding[T any] struct {
+	Target T
+	// Toggle makes a press toggle the state of the target, releases are ignored
+	Toggle bool
+}
+
+// Transition is a state change of a target.
+type Transition[T any] struct {
+	// Button which caused the transition
+	Button  wiimote.Key
+	Target  T
+	Pressed bool
+}
+
+// Mapper is a state machine which resolves button events using layers of bindings. A button
+// without binding in the active layer falls back to the base layer. The release of a button
+// always resolves to the binding of its press, even if the layer changed in between.
+//
+// Mappers are not thread-safe.
+type Mapper[T any] struct {
+	layers map[string]map[wiimote.Key]Binding[T]
+	shifts map[wiimote.Key]string
+
+	// held shift layers, the last is active
+	active []string
+	// binding a held button was resolved to
+	held map[wiimote.Key]Binding[T]
+	// state of toggles per layer and button
+	toggled map[string]map[wiimote.Key]bool
+}
+
+// New returns a mapper without bindings.
+func New[T any]() *Mapper[T] {
+	return &Mapper[T]{
+		layers:  make(map[string]map[wiimote.Key]Binding[T]),
+		shifts:  make(map[wiimote.Key]string),
+		held:    make(map[wiimote.Key]Binding[T]),
+		toggled: make(map[string]map[wiimote.Key]bool),
+	}
+}
+
+// Bind binds button to b in layer, use BaseLayer for the base layer.
+func (m *Mapper[T]) Bind(layer string, button wiimote.Key, b Binding[T]) {
+	if m.layers[layer] == nil {
+		m.layers[layer] = make(map[wiimote.Key]Binding[T])
+	}
+	m.layers[layer][button] = b
+}
+
+// Shift makes button switch to layer while it is held. Shift buttons do not produce
+// transitions themselves.
+func (m *Mapper[T]) Shift(button wiimote.Key, layer string) {
+	m.shifts[button] = layer
+}
+
+// Targets returns the targets of all bindings in all layers.
+func (m *Mapper[T]) Targets() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, layer := range m.layers {
+			for _, b := range layer {
+				if !yield(b.Target) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Layer returns the active layer.
+func (m *Mapper[T]) Layer() string {
+	if len(m.active) == 0 {
+		return BaseLayer
+	}
+	return m.active[len(m.active)-1]
+}
+
+func (m *Mapper[T]) lookup(button wiimote.Key) (layer string, b Binding[T], ok bool) {
+	layer = m.Layer()
+	if b, ok = m.layers[layer][button]; ok {
+		return layer, b, true
+	}
+	b, ok = m.layers[BaseLayer][button]
+	return BaseLayer, b, ok
+}
+
+// Update processes a button event and returns the resulting transitions.
+func (m *Mapper[T]) Update(button wiimote.Key, pressed bool) []Transition[T] {
+	if layer, ok := m.shifts[button]; ok {
+		if pressed {
+			m.active = append(m.active, layer)
+		} else {
+			for i := len(m.active) - 1; i >= 0; i-- {
+				if m.active[i] == layer {
+					m.active = append(m.active[:i], m.active[i+1:]...)
+					break
+				}
+			}
+		}
+		return nil
+	}
+
+	if !pressed {
+		b, ok := m.held[button]
+		if !ok {
+			return nil
+		}
+		delete(m.held, button)
+		if b.Toggle {
+			return nil
+		}
+		return []Transition[T]{{Button: button, Target: b.Target, Pressed: false}}
+	}
+
+	if _, ok := m.held[button]; ok {
+		// repeated press without release
+		return nil
+	}
+	layer, b, ok := m.lookup(button)
+	if !ok {
+		return nil
+	}
+	m.held[button] = b
+	if !b.Toggle {
+		return []Transition[T]{{Button: button, Target: b.Target, Pressed: true}}
+	}
+	if m.toggled[layer] == nil {
+		m.toggled[layer] = make(map[wiimote.Key]bool)
+	}
+	state := !m.toggled[layer][button]
+	m.toggled[layer][button] = state
+	return []Transition[T]{{Button: button, Target: b.Target, Pressed: state}}
+}
+
+// Reset releases all held buttons and active toggles and returns the resulting transitions.
+func (m *Mapper[T]) Reset() []Transition[T] {
+	var trans []Transition[T]
+	for button, b := range m.held {
+		if !b.Toggle {
+			trans = append(trans, Transition[T]{Button: button, Target: b.Target, Pressed: false})
+		}
+		delete(m.held, button)
+	}
+	for layer, toggles := range m.toggled {
+		for button, state := range toggles {
+			if state {
+				trans = append(trans, Transition[T]{Button: button, Target: m.layers[layer][button].Target, Pressed: false})
+			}
+		}
+		delete(m.toggled, layer)
+	}
+	m.active = nil
+	return trans
+}
@@ -0,0 +1,252 @@
+package mapper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-uinput"
+	"github.com/friedelschoen/go-wiimote"
+)
+
+func expect(t *testing.T, got []Transition[string], want ...Transition[string]) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMapperPlain(t *testing.T) {
+	m := New[string]()
+	m.Bind(BaseLayer, wiimote.KeyA, Binding[string]{Target: "a"})
+
+	expect(t, m.Update(wiimote.KeyA, true), Transition[string]{wiimote.KeyA, "a", true})
+	expect(t, m.Update(wiimote.KeyA, true))
+	expect(t, m.Update(wiimote.KeyA, false), Transition[string]{wiimote.KeyA, "a", false})
+	expect(t, m.Update(wiimote.KeyB, true))
+}
+
+func TestMapperToggle(t *testing.T) {
+	m := New[string]()
+	m.Bind(BaseLayer, wiimote.KeyA, Binding[string]{Target: "caps", Toggle: true})
+
+	expect(t, m.Update(wiimote.KeyA, true), Transition[string]{wiimote.KeyA, "caps", true})
+	expect(t, m.Update(wiimote.KeyA, false))
+	expect(t, m.Update(wiimote.KeyA, true), Transition[string]{wiimote.KeyA, "caps", false})
+	expect(t, m.Update(wiimote.KeyA, false))
+
+	m.Update(wiimote.KeyA, true)
+	expect(t, m.Reset(), Transition[string]{wiimote.KeyA, "caps", false})
+}
+
+func TestMapperShift(t *testing.T) {
+	m := New[string]()
+	m.Bind(BaseLayer, wiimote.KeyA, Binding[string]{Target: "a"})
+	m.Bind(BaseLayer, wiimote.KeyOne, Binding[string]{Target: "1"})
+	m.Bind("fn", wiimote.KeyA, Binding[string]{Target: "fn-a"})
+	m.Shift(wiimote.KeyB, "fn")
+
+	expect(t, m.Update(wiimote.KeyB, true))
+	if m.Layer() != "fn" {
+		t.Fatalf("expected layer fn, got %q", m.Layer())
+	}
+	expect(t, m.Update(wiimote.KeyA, true), Transition[string]{wiimote.KeyA, "fn-a", true})
+	// falls back to the base layer
+	expect(t, m.Update(wiimote.KeyOne, true), Transition[string]{wiimote.KeyOne, "1", true})
+
+	// release resolves to the press, even after leaving the layer
+	expect(t, m.Update(wiimote.KeyB, false))
+	if m.Layer() != BaseLayer {
+		t.Fatalf("expected base layer, got %q", m.Layer())
+	}
+	expect(t, m.Update(wiimote.KeyA, false), Transition[string]{wiimote.KeyA, "fn-a", false})
+	expect(t, m.Update(wiimote.KeyA, true), Transition[string]{wiimote.KeyA, "a", true})
+}
+
+func TestLookupOutputKey(t *testing.T) {
+	for _, name := range []string{"KEY_ENTER", "enter", " Enter "} {
+		if key, ok := LookupOutputKey(name); !ok || key != uinput.KeyEnter {
+			t.Errorf("%q: expected %v, got %v %v", name, uinput.KeyEnter, key, ok)
+		}
+	}
+	if key, ok := LookupOutputKey(OutputKeyName(uinput.ButtonLeft)); !ok || key != uinput.ButtonLeft {
+		t.Errorf("expected %v to round-trip, got %v %v", uinput.ButtonLeft, key, ok)
+	}
+}
+
+func TestAllOutputKeys(t *testing.T) {
+	groups := map[string]int{}
+	for _, info := range AllOutputKeys() {
+		if key, ok := LookupOutputKey(info.Name); !ok || key != info.Key {
+			t.Errorf("%v: expected to round-trip, got %v %v", info.Name, key, ok)
+		}
+		groups[info.Group]++
+	}
+	if groups["key"] == 0 || groups["button"] == 0 {
+		t.Errorf("expected keys and buttons, got %v", groups)
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	act, err := ParseAction("KEY_LEFTCTRL+KEY_LEFTALT+KEY_T")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, ok := act.Chord()
+	if !ok || !slices.Equal(keys, []uinput.Key{uinput.KeyLeftctrl, uinput.KeyLeftalt, uinput.KeyT}) {
+		t.Fatalf("expected chord, got %v", act)
+	}
+
+	act, err = ParseAction("KEY_H, KEY_I, 100ms, KEY_ENTER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := act.Chord(); ok {
+		t.Fatalf("expected macro, got chord")
+	}
+	want := Action{{Keys: []uinput.Key{uinput.KeyH}}, {Keys: []uinput.Key{uinput.KeyI}}, {Delay: 100 * time.Millisecond}, {Keys: []uinput.Key{uinput.KeyEnter}}}
+	if !slices.EqualFunc(act, want, func(a, b Step) bool { return a.Delay == b.Delay && slices.Equal(a.Keys, b.Keys) }) {
+		t.Fatalf("expected %v, got %v", want, act)
+	}
+	if !slices.Equal(act.Keys(), []uinput.Key{uinput.KeyH, uinput.KeyI, uinput.KeyEnter}) {
+		t.Fatalf("unexpected keys %v", act.Keys())
+	}
+
+	if _, err := ParseAction("KEY_A+KEY_NOPE"); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+}
+
+const testMapping = `
+# comment
+KEY_A -> KEY_ENTER
+KEY_HOME -> KEY_LEFTCTRL+KEY_T
+one -> toggle:capslock
+KEY_B -> shift:fn
+invalid line
+KEY_NOPE -> KEY_A
+
+[fn]
+KEY_A -> KEY_ESC
+`
+
+func TestParseAndApply(t *testing.T) {
+	m, err := Parse(strings.NewReader(testMapping))
+	if err == nil || !strings.Contains(err.Error(), "line 7") || !strings.Contains(err.Error(), "line 8") {
+		t.Fatalf("expected errors for line 7 and 8, got %v", err)
+	}
+
+	press := func(ev wiimote.Event) []OutputAction {
+		return m.Apply(ev)
+	}
+	check := func(got []OutputAction, keys []uinput.Key, pressed bool) {
+		t.Helper()
+		if len(got) != 1 || got[0].Pressed != pressed || !slices.Equal(got[0].Action.Keys(), keys) {
+			t.Fatalf("expected %v pressed=%v, got %+v", keys, pressed, got)
+		}
+	}
+
+	check(press(&wiimote.EventKey{Code: wiimote.KeyA, Pressed: true}), []uinput.Key{uinput.KeyEnter}, true)
+	check(press(&wiimote.EventKey{Code: wiimote.KeyA, Pressed: false}), []uinput.Key{uinput.KeyEnter}, false)
+
+	// extension keys use the same mapping
+	check(press(&wiimote.EventClassicControllerKey{EventKey: wiimote.EventKey{Code: wiimote.KeyHome, Pressed: true}}), []uinput.Key{uinput.KeyLeftctrl, uinput.KeyT}, true)
+
+	check(press(&wiimote.EventKey{Code: wiimote.KeyOne, Pressed: true}), []uinput.Key{uinput.KeyCapslock}, true)
+	if got := press(&wiimote.EventKey{Code: wiimote.KeyOne, Pressed: false}); len(got) != 0 {
+		t.Fatalf("expected no action on toggle release, got %+v", got)
+	}
+
+	if got := press(&wiimote.EventKey{Code: wiimote.KeyB, Pressed: true}); len(got) != 0 {
+		t.Fatalf("expected no action for shift, got %+v", got)
+	}
+	check(press(&wiimote.EventKey{Code: wiimote.KeyA, Pressed: true}), []uinput.Key{uinput.KeyEsc}, true)
+
+	if got := press(&wiimote.EventAccel{}); len(got) != 0 {
+		t.Fatalf("expected non-key events to be ignored, got %+v", got)
+	}
+
+	keys := m.Keys()
+	for _, key := range []uinput.Key{uinput.KeyEnter, uinput.KeyLeftctrl, uinput.KeyT, uinput.KeyCapslock, uinput.KeyEsc} {
+		if !slices.Contains(keys, key) {
+			t.Errorf("expected %v in keys %v", key, keys)
+		}
+	}
+}
+
+type keyLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *keyLog) Key(key uinput.Key, pressed bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state := "up"
+	if pressed {
+		state = "down"
+	}
+	l.events = append(l.events, fmt.Sprintf("%v %s", key, state))
+	return nil
+}
+
+func (l *keyLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ev := l.events
+	l.events = nil
+	return ev
+}
+
+func TestExecutorChord(t *testing.T) {
+	var out keyLog
+	exec := NewExecutor(&out)
+	chord := Action{{Keys: []uinput.Key{uinput.KeyLeftctrl, uinput.KeyT}}}
+
+	exec.Run(OutputAction{Button: wiimote.KeyA, Action: chord, Pressed: true})
+	exec.Run(OutputAction{Button: wiimote.KeyA, Action: chord, Pressed: false})
+	want := []string{
+		fmt.Sprintf("%v down", uinput.KeyLeftctrl), fmt.Sprintf("%v down", uinput.KeyT),
+		fmt.Sprintf("%v up", uinput.KeyT), fmt.Sprintf("%v up", uinput.KeyLeftctrl),
+	}
+	if got := out.take(); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// held chords are released on stop
+	exec.Run(OutputAction{Button: wiimote.KeyA, Action: chord, Pressed: true})
+	out.take()
+	exec.Stop()
+	if got := out.take(); !slices.Equal(got, want[2:]) {
+		t.Fatalf("expected %v, got %v", want[2:], got)
+	}
+}
+
+func TestExecutorMacro(t *testing.T) {
+	var out keyLog
+	exec := NewExecutor(&out)
+	macro := Action{{Keys: []uinput.Key{uinput.KeyH}}, {Delay: time.Millisecond}, {Keys: []uinput.Key{uinput.KeyI}}}
+
+	exec.Run(OutputAction{Button: wiimote.KeyA, Action: macro, Pressed: true})
+	exec.Stop()
+	got := out.take()
+	want := []string{
+		fmt.Sprintf("%v down", uinput.KeyH), fmt.Sprintf("%v up", uinput.KeyH),
+		fmt.Sprintf("%v down", uinput.KeyI), fmt.Sprintf("%v up", uinput.KeyI),
+	}
+	// the macro may be cancelled by Stop before the delay
+	if !slices.Equal(got, want) && !slices.Equal(got, want[:2]) && len(got) != 0 {
+		t.Fatalf("expected %v or a prefix, got %v", want, got)
+	}
+
+	exec = NewExecutor(&out)
+	exec.Run(OutputAction{Button: wiimote.KeyA, Action: macro, Pressed: true})
+	time.Sleep(50 * time.Millisecond)
+	exec.Stop()
+	if got := out.take(); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,133 @@
+package mapper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/friedelschoen/go-uinput"
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// OutputAction is a state change of an action caused by a button.
+type OutputAction struct {
+	Button  wiimote.Key
+	Action  Action
+	Pressed bool
+}
+
+// Mapping maps buttons of a device to actions.
+type Mapping struct {
+	*Mapper[Action]
+}
+
+// NewMapping returns an empty mapping.
+func NewMapping() *Mapping {
+	return &Mapping{New[Action]()}
+}
+
+// Parse reads a mapping from r. Every line maps a button to an action (e.g. "A -> KEY_ENTER"),
+// see Action. An action prefixed with "toggle:" is toggled on every press.
+// "BUTTON -> shift:layer" switches to the bindings below "[layer]" while BUTTON is held.
+//
+// Invalid lines are skipped, the errors of all invalid lines are returned along with the
+// mapping of the valid lines.
+func Parse(r io.Reader) (*Mapping, error) {
+	m := NewMapping()
+	layer := BaseLayer
+	var errs []error
+	scan := bufio.NewScanner(r)
+	for lineno := 1; scan.Scan(); lineno++ {
+		line := strings.TrimSpace(scan.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			layer = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if err := m.parseLine(layer, line); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineno, err))
+		}
+	}
+	if err := scan.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return m, errors.Join(errs...)
+}
+
+func (m *Mapping) parseLine(layer, line string) error {
+	buttonstr, target, ok := strings.Cut(line, "->")
+	if !ok {
+		return fmt.Errorf("missing delimiter: %s", line)
+	}
+	button, ok := wiimote.ParseKey(buttonstr)
+	if !ok {
+		return fmt.Errorf("unknown button: %s", strings.TrimSpace(buttonstr))
+	}
+	target = strings.TrimSpace(target)
+	if shift, ok := strings.CutPrefix(target, "shift:"); ok {
+		m.Shift(button, strings.TrimSpace(shift))
+		return nil
+	}
+	toggle := false
+	if rest, ok := strings.CutPrefix(target, "toggle:"); ok {
+		toggle = true
+		target = rest
+	}
+	act, err := ParseAction(target)
+	if err != nil {
+		return err
+	}
+	m.Bind(layer, button, Binding[Action]{Target: act, Toggle: toggle})
+	return nil
+}
+
+// eventKey returns the key event of core and extension key events.
+func eventKey(ev wiimote.Event) (*wiimote.EventKey, bool) {
+	switch ev := ev.(type) {
+	case *wiimote.EventKey:
+		return ev, true
+	case *wiimote.EventClassicControllerKey:
+		return &ev.EventKey, true
+	case *wiimote.EventProControllerKey:
+		return &ev.EventKey, true
+	case *wiimote.EventNunchukKey:
+		return &ev.EventKey, true
+	case *wiimote.EventDrumsKey:
+		return &ev.EventKey, true
+	case *wiimote.EventGuitarKey:
+		return &ev.EventKey, true
+	}
+	return nil, false
+}
+
+// Apply processes the key event ev and returns the resulting output actions. Other events
+// are ignored.
+func (m *Mapping) Apply(ev wiimote.Event) []OutputAction {
+	key, ok := eventKey(ev)
+	if !ok {
+		return nil
+	}
+	var out []OutputAction
+	for _, tr := range m.Update(key.Code, key.Pressed) {
+		out = append(out, OutputAction{Button: tr.Button, Action: tr.Target, Pressed: tr.Pressed})
+	}
+	return out
+}
+
+// Keys returns all keys used by the actions of the mapping, without duplicates.
+func (m *Mapping) Keys() []uinput.Key {
+	var keys []uinput.Key
+	for act := range m.Targets() {
+		for _, key := range act.Keys() {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
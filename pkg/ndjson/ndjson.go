@@ -0,0 +1,103 @@
+// Package ndjson streams events as newline-delimited JSON, one object per line as encoded by
+// wiimote.MarshalEvent. This makes it easy to process events in other languages and tools, and
+// to replay recorded sessions, see Reader.
+package ndjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// Writer writes events as NDJSON. Writers are thread-safe, so the events of multiple devices
+// can be written to the same writer.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter returns a writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes ev as a single line.
+func (w *Writer) Write(ev wiimote.Event) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.w.Write(b)
+	return err
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// Open opens the output described by target: "-" is the standard output, "unix:PATH" and
+// "tcp:HOST:PORT" connect to a socket and any other target is a file which is created or
+// truncated.
+func Open(target string) (io.WriteCloser, error) {
+	switch {
+	case target == "-":
+		return nopCloser{os.Stdout}, nil
+	case strings.HasPrefix(target, "unix:"):
+		return net.Dial("unix", strings.TrimPrefix(target, "unix:"))
+	case strings.HasPrefix(target, "tcp:"):
+		return net.Dial("tcp", strings.TrimPrefix(target, "tcp:"))
+	}
+	return os.Create(target)
+}
+
+// Reader reads events written by Writer, e.g. a session recorded by wiilog.
+type Reader struct {
+	s *bufio.Scanner
+	// Inner creates the embedded event of the decoded events, see wiimote.UnmarshalEvent
+	Inner func(ts time.Time, kind wiimote.FeatureKind) wiimote.Event
+}
+
+// NewReader returns a reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{s: bufio.NewScanner(r)}
+}
+
+// Read returns the next event, empty lines are skipped. It returns io.EOF after the last event.
+func (r *Reader) Read() (wiimote.Event, error) {
+	for r.s.Scan() {
+		line := r.s.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		return wiimote.UnmarshalEvent(line, r.Inner)
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadAll returns all events of r.
+func ReadAll(r io.Reader) ([]wiimote.Event, error) {
+	var evs []wiimote.Event
+	rd := NewReader(r)
+	for {
+		ev, err := rd.Read()
+		if err == io.EOF {
+			return evs, nil
+		} else if err != nil {
+			return evs, err
+		}
+		evs = append(evs, ev)
+	}
+}
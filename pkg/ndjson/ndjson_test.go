@@ -0,0 +1,78 @@
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+func TestWriteUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer ln.Close()
+
+	out, err := Open("unix:" + path)
+	if err != nil {
+		t.Fatalf("unable to open output: %v", err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %v", err)
+	}
+	defer conn.Close()
+
+	w := NewWriter(out)
+	w.Write(&wiimote.EventAccel{Accel: wiimote.Vec3{X: 1}})
+	w.Write(&wiimote.EventBalanceBoard{Weights: [4]int32{1, 2, 3, 4}})
+	out.Close()
+
+	var types []string
+	scan := bufio.NewScanner(conn)
+	for scan.Scan() {
+		var v struct{ Type string }
+		if err := json.Unmarshal(scan.Bytes(), &v); err != nil {
+			t.Fatalf("invalid line %q: %v", scan.Text(), err)
+		}
+		types = append(types, v.Type)
+	}
+	if len(types) != 2 || types[0] != "accel" || types[1] != "balance_board" {
+		t.Errorf("expected accel and balance_board, got %v", types)
+	}
+}
+
+type testEvent struct {
+	ts time.Time
+}
+
+func (e testEvent) Feature() wiimote.Feature { return nil }
+func (e testEvent) Device() wiimote.Device   { return nil }
+func (e testEvent) Timestamp() time.Time     { return e.ts }
+
+func TestReadWritten(t *testing.T) {
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Write(&wiimote.EventAccel{Event: testEvent{ts}, Accel: wiimote.Vec3{X: 1}})
+	buf.WriteString("\n")
+	w.Write(&wiimote.EventKey{Event: testEvent{ts}, Code: wiimote.KeyA, Pressed: true})
+
+	evs, err := ReadAll(&buf)
+	if err != nil || len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %d: %v", len(evs), err)
+	}
+	if ev, ok := evs[0].(*wiimote.EventAccel); !ok || ev.Accel.X != 1 || !ev.Timestamp().Equal(ts) {
+		t.Errorf("unexpected accel event %+v", evs[0])
+	}
+	if ev, ok := evs[1].(*wiimote.EventKey); !ok || ev.Code != wiimote.KeyA || !ev.Pressed {
+		t.Errorf("unexpected key event %+v", evs[1])
+	}
+}
@@ -0,0 +1,298 @@
+// Package nudge turns the D-pad and the A and B buttons into navigation events for on-screen
+// keyboards. Held directions are repeated with increasing speed, so media-center frontends get
+// sensible text entry without handling timers.
+package nudge
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+	"golang.org/x/sys/unix"
+)
+
+// Action is a navigation action of an on-screen keyboard.
+type Action uint8
+
+const (
+	ActionUp Action = iota
+	ActionDown
+	ActionLeft
+	ActionRight
+	// ActionSelect enters the selected key
+	ActionSelect
+	// ActionBackspace deletes the last character
+	ActionBackspace
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionUp:
+		return "up"
+	case ActionDown:
+		return "down"
+	case ActionLeft:
+		return "left"
+	case ActionRight:
+		return "right"
+	case ActionSelect:
+		return "select"
+	case ActionBackspace:
+		return "backspace"
+	}
+	return "Action(" + strconv.Itoa(int(a)) + ")"
+}
+
+// Config configures the actions and the repeat of held keys.
+type Config struct {
+	// Keys maps core keys to actions
+	Keys map[wiimote.Key]Action
+	// Repeat are the actions which repeat while the key is held
+	Repeat []Action
+	// Delay is the time a key is held before it repeats, 0 disables repeating
+	Delay time.Duration
+	// Interval is the time until the first repeat after Delay
+	Interval time.Duration
+	// Accel multiplies the interval after every repeat, a value below 1 accelerates
+	Accel float64
+	// MinInterval is the shortest interval of the acceleration
+	MinInterval time.Duration
+}
+
+// DefaultConfig returns a configuration mapping the D-pad to directions, A to select and B to
+// backspace. Directions and backspace repeat after 400ms, every 150ms down to 40ms.
+func DefaultConfig() Config {
+	return Config{
+		Keys: map[wiimote.Key]Action{
+			wiimote.KeyUp:    ActionUp,
+			wiimote.KeyDown:  ActionDown,
+			wiimote.KeyLeft:  ActionLeft,
+			wiimote.KeyRight: ActionRight,
+			wiimote.KeyA:     ActionSelect,
+			wiimote.KeyB:     ActionBackspace,
+		},
+		Repeat:      []Action{ActionUp, ActionDown, ActionLeft, ActionRight, ActionBackspace},
+		Delay:       400 * time.Millisecond,
+		Interval:    150 * time.Millisecond,
+		Accel:       0.8,
+		MinInterval: 40 * time.Millisecond,
+	}
+}
+
+// repeats reports whether act repeats while held.
+func (c *Config) repeats(act Action) bool {
+	if c.Delay <= 0 {
+		return false
+	}
+	for _, r := range c.Repeat {
+		if r == act {
+			return true
+		}
+	}
+	return false
+}
+
+// EventNudge is emitted when a key of an action is pressed and on every repeat while it is held.
+type EventNudge struct {
+	wiimote.Event
+	Action Action
+	// Repeat is 0 for the press and counts the repeats
+	Repeat int
+}
+
+type nudgeEvent struct {
+	feature   wiimote.Feature
+	timestamp time.Time
+}
+
+func (e nudgeEvent) Feature() wiimote.Feature { return e.feature }
+func (e nudgeEvent) Timestamp() time.Time     { return e.timestamp }
+
+func (e nudgeEvent) Device() wiimote.Device {
+	if e.feature == nil {
+		return nil
+	}
+	return e.feature.Device()
+}
+
+// repeatState is the repeat of the last pressed key.
+type repeatState struct {
+	held     bool
+	key      wiimote.Key
+	action   Action
+	count    int
+	next     time.Time
+	interval time.Duration
+}
+
+// Processor passes all events of a source and inserts EventNudge events. Only core key events
+// are considered and only the last pressed key repeats, like on a keyboard. Repeats are
+// triggered by a timer, so no events of the source are required while the key is held.
+//
+// Processors are not thread-safe.
+type Processor struct {
+	wiimote.Poller[wiimote.Event]
+
+	cfg     Config
+	src     wiimote.Poller[wiimote.Event]
+	repeat  repeatState
+	feature wiimote.Feature
+	pending []wiimote.Event
+
+	efd int
+	tfd int
+}
+
+// New creates a processor of the events of src. If src provides a file descriptor (as all
+// devices do), the processor provides one which is readable when either src has events or a
+// key repeats.
+func New(src wiimote.Poller[wiimote.Event], cfg Config) (*Processor, error) {
+	p := &Processor{
+		cfg: cfg,
+		src: src,
+		efd: -1,
+	}
+	p.Poller = common.NewPoller(p)
+
+	var err error
+	p.tfd, err = unix.TimerfdCreate(unix.CLOCK_MONOTONIC, unix.TFD_NONBLOCK|unix.TFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	fds := []int{p.tfd}
+	if s, ok := src.(interface{ FD() int }); ok {
+		p.efd, err = unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+		if err != nil {
+			unix.Close(p.tfd)
+			return nil, err
+		}
+		fds = append(fds, p.efd)
+		for _, fd := range []int{p.tfd, s.FD()} {
+			ev := unix.EpollEvent{Events: unix.EPOLLIN, Fd: int32(fd)}
+			if err := unix.EpollCtl(p.efd, unix.EPOLL_CTL_ADD, fd, &ev); err != nil {
+				for _, fd := range fds {
+					unix.Close(fd)
+				}
+				return nil, err
+			}
+		}
+	}
+	runtime.AddCleanup(p, func(fds []int) {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+	}, fds)
+	return p, nil
+}
+
+// FD returns a file descriptor which is readable when Poll should be called, -1 if the source
+// does not provide one.
+func (p *Processor) FD() int {
+	return p.efd
+}
+
+// Poll returns the next event of the source or a pending EventNudge.
+func (p *Processor) Poll() (wiimote.Event, bool, error) {
+	if ev, ok := p.next(); ok {
+		return ev, true, nil
+	}
+
+	var buf [8]byte
+	unix.Read(p.tfd, buf[:])
+
+	ev, more, err := p.src.Poll()
+	if err != nil {
+		if !errors.Is(err, common.ErrWouldBlock) {
+			return nil, false, err
+		}
+		p.expire(time.Now())
+		p.arm()
+		if ev, ok := p.next(); ok {
+			return ev, len(p.pending) > 0, nil
+		}
+		return nil, false, err
+	}
+
+	key, isKey := ev.(*wiimote.EventKey)
+	if isKey {
+		p.feature = key.Feature()
+		p.update(key.Code, key.Pressed, key.Timestamp())
+	}
+	// other events may keep the source busy while a key is held
+	if p.expire(time.Now()) || isKey {
+		p.arm()
+	}
+	return ev, more || len(p.pending) > 0, nil
+}
+
+func (p *Processor) next() (wiimote.Event, bool) {
+	if len(p.pending) == 0 {
+		return nil, false
+	}
+	ev := p.pending[0]
+	p.pending = p.pending[1:]
+	return ev, true
+}
+
+func (p *Processor) emit(act Action, ts time.Time, repeat int) {
+	p.pending = append(p.pending, &EventNudge{
+		Event:  nudgeEvent{p.feature, ts},
+		Action: act,
+		Repeat: repeat,
+	})
+}
+
+// update processes a press or release of key at ts.
+func (p *Processor) update(key wiimote.Key, pressed bool, ts time.Time) {
+	act, ok := p.cfg.Keys[key]
+	if !ok {
+		return
+	}
+	if !pressed {
+		if p.repeat.key == key {
+			p.repeat = repeatState{}
+		}
+		return
+	}
+	if p.repeat.held && p.repeat.key == key {
+		// repeated press without release
+		return
+	}
+	p.emit(act, ts, 0)
+	p.repeat = repeatState{}
+	if p.cfg.repeats(act) {
+		p.repeat = repeatState{held: true, key: key, action: act, next: ts.Add(p.cfg.Delay), interval: p.cfg.Interval}
+	}
+}
+
+// expire emits the repeat of the held key if it is due at now and reports whether it did.
+// Missed repeats are dropped, so a late poll does not move the selection too far.
+func (p *Processor) expire(now time.Time) bool {
+	st := &p.repeat
+	if !st.held || now.Before(st.next) {
+		return false
+	}
+	st.count++
+	p.emit(st.action, st.next, st.count)
+	st.next = st.next.Add(max(st.interval, p.cfg.MinInterval, time.Millisecond))
+	if st.next.Before(now) {
+		st.next = now.Add(max(st.interval, p.cfg.MinInterval, time.Millisecond))
+	}
+	if p.cfg.Accel > 0 {
+		st.interval = time.Duration(float64(st.interval) * p.cfg.Accel)
+	}
+	return true
+}
+
+// arm sets the timer to the next repeat.
+func (p *Processor) arm() {
+	var spec unix.ItimerSpec
+	if p.repeat.held {
+		// an expired deadline must still arm the timer, a zero value disarms it
+		spec.Value = unix.NsecToTimespec(max(int64(time.Until(p.repeat.next)), 1))
+	}
+	unix.TimerfdSettime(p.tfd, 0, &spec, nil)
+}
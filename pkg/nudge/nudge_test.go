@@ -0,0 +1,88 @@
+package nudge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
+)
+
+func TestRepeatAcceleration(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Delay = 100 * time.Millisecond
+	cfg.Interval = 100 * time.Millisecond
+	cfg.Accel = 0.5
+	cfg.MinInterval = 20 * time.Millisecond
+	p := &Processor{cfg: cfg}
+
+	start := time.Unix(0, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	p.update(wiimote.KeyRight, true, at(0))
+	for ms := 0; ms <= 400; ms += 5 {
+		p.expire(at(ms))
+	}
+	p.update(wiimote.KeyRight, false, at(400))
+	p.expire(at(1000))
+
+	// repeats after 100ms, then every 100, 50, 25, 20, 20... ms
+	expect := []int{0, 100, 200, 250, 275, 295, 315, 335, 355, 375, 395}
+	if len(p.pending) != len(expect) {
+		t.Fatalf("expected %d events, got %d", len(expect), len(p.pending))
+	}
+	for i, ev := range p.pending {
+		ev := ev.(*EventNudge)
+		if ev.Action != ActionRight || ev.Repeat != i || !ev.Timestamp().Equal(at(expect[i])) {
+			t.Errorf("event %d: expected right repeat %d at %dms, got %v repeat %d at %v", i, i, expect[i], ev.Action, ev.Repeat, ev.Timestamp().Sub(start))
+		}
+	}
+
+	// select does not repeat
+	p.pending = nil
+	p.update(wiimote.KeyA, true, at(2000))
+	p.expire(at(3000))
+	if len(p.pending) != 1 || p.pending[0].(*EventNudge).Action != ActionSelect {
+		t.Errorf("expected a single select, got %v", p.pending)
+	}
+}
+
+func TestProcessor(t *testing.T) {
+	cfg := sim.DefaultConfig()
+	cfg.Keys = []sim.KeyPress{
+		{Key: wiimote.KeyB, At: 0, Duration: 300 * time.Millisecond},
+	}
+	dev, err := sim.NewDevice(cfg)
+	if err != nil {
+		t.Fatalf("unable to create device: %v", err)
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore, true); err != nil {
+		t.Fatalf("unable to open features: %v", err)
+	}
+
+	ncfg := DefaultConfig()
+	ncfg.Delay = 100 * time.Millisecond
+	proc, err := New(dev, ncfg)
+	if err != nil {
+		t.Fatalf("unable to create processor: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var got []*EventNudge
+	for len(got) < 2 {
+		ev, err := proc.WaitContext(ctx)
+		if err != nil {
+			t.Fatalf("expected nudges, got %d before: %v", len(got), err)
+		}
+		if ev, ok := ev.(*EventNudge); ok {
+			got = append(got, ev)
+		}
+	}
+	if got[0].Action != ActionBackspace || got[0].Repeat != 0 || got[1].Action != ActionBackspace || got[1].Repeat != 1 {
+		t.Errorf("expected backspace and its repeat, got %v %d, %v %d", got[0].Action, got[0].Repeat, got[1].Action, got[1].Repeat)
+	}
+	if got[1].Device() != dev {
+		t.Errorf("expected events of the device")
+	}
+}
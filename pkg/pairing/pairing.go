@@ -0,0 +1,113 @@
+// Package pairing pairs remotes when their sync button is pressed and assigns player numbers
+// to connected remotes, see Daemon. It is the core of wiipair and can be embedded by other
+// services.
+package pairing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote/driver"
+	"github.com/friedelschoen/go-wiimote/pkg/bluez"
+	"github.com/friedelschoen/go-wiimote/pkg/discover"
+)
+
+// DefaultRescan is the interval in which a Daemon restarts scanning.
+const DefaultRescan = time.Minute
+
+// Daemon pairs and trusts every remote of which the sync button is pressed.
+type Daemon struct {
+	Client *bluez.Client
+	// Rescan is the interval in which scanning is restarted, remotes which failed to pair are
+	// retried once they are seen again. DefaultRescan is used if Rescan is 0.
+	Rescan time.Duration
+	// Players shows a player number on the LEDs of every connected remote, see
+	// discover.WiimoteMonitor.AssignPlayers.
+	Players bool
+
+	// Paired is called after a remote is paired, err is set if pairing failed. It may be
+	// called concurrently.
+	Paired func(dev bluez.Device, err error)
+	// Connected is called after a player number is assigned to a connected remote, err is
+	// set if the LEDs cannot be set.
+	Connected func(info *discover.DeviceInfo, err error)
+}
+
+// NewDaemon returns a daemon using the first Bluetooth adapter, see bluez.NewClient.
+func NewDaemon() (*Daemon, error) {
+	client, err := bluez.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return &Daemon{Client: client}, nil
+}
+
+// Run scans for and pairs remotes until ctx is done. The error of ctx is returned once it is
+// done, unless scanning or assigning players failed before.
+func (d *Daemon) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if d.Players {
+		go func() {
+			cancel(d.assignPlayers(ctx))
+		}()
+	}
+
+	rescan := d.Rescan
+	if rescan <= 0 {
+		rescan = DefaultRescan
+	}
+	for {
+		var wg sync.WaitGroup
+		scanCtx, stop := context.WithTimeout(ctx, rescan)
+		err := d.Client.Scan(scanCtx, func(dev bluez.Device) {
+			if dev.Paired && dev.Trusted && dev.Connected {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := d.Client.Pair(ctx, dev)
+				if d.Paired != nil {
+					d.Paired(dev, err)
+				}
+			}()
+		})
+		stop()
+		wg.Wait()
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+	}
+}
+
+// assignPlayers shows the player number on every connected remote until ctx is done.
+func (d *Daemon) assignPlayers(ctx context.Context) error {
+	mon, err := discover.NewWiimoteMonitor()
+	if err != nil {
+		return err
+	}
+	mon.AssignPlayers(true)
+	for {
+		info, err := mon.WaitContext(ctx)
+		if err != nil {
+			return err
+		}
+		if info == nil || info.Player == 0 {
+			continue
+		}
+		dev, err := driver.NewDevice(info.Device, driver.BackendKernel)
+		if err == nil {
+			err = dev.SetPlayerLED(info.Player)
+			dev.Cleanup()
+		}
+		if d.Connected != nil {
+			d.Connected(info, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package pointerctl adjusts the output of a pointer per axis: the movement is scaled by a
+// sensitivity and axes are inverted, e.g. for a ceiling-mounted sensor bar or a left-handed
+// setup. The adjustments are filters at the end of an irpointer filter chain. Touch emulates a
+// touch screen with buttons.
+package pointerctl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/friedelschoen/go-wiimote/pkg/irpointer"
+)
+
+// Options are the adjustments of the pointer.
+type Options struct {
+	// SensX and SensY scale the movement of the X and Y axis, 1 keeps the movement
+	SensX, SensY float64
+	// InvertX and InvertY invert the X and Y axis
+	InvertX, InvertY bool
+}
+
+// DefaultOptions returns options which do not adjust the pointer.
+func DefaultOptions() Options {
+	return Options{SensX: 1, SensY: 1}
+}
+
+// Validate returns an error if a sensitivity is not positive.
+func (o Options) Validate() error {
+	var errs []error
+	if o.SensX <= 0 {
+		errs = append(errs, fmt.Errorf("invalid X sensitivity %v, must be positive", o.SensX))
+	}
+	if o.SensY <= 0 {
+		errs = append(errs, fmt.Errorf("invalid Y sensitivity %v, must be positive", o.SensY))
+	}
+	return errors.Join(errs...)
+}
+
+// Filters returns the filters applying o around center, usually the center of the screen. The
+// chain is empty if o does not adjust the pointer.
+//
+// For absolute pointers the filters are applied last. For relative pointers they are applied
+// before the irpointer.RelativeFilter, so the movement of the cursor is scaled and inverted.
+func (o Options) Filters(center irpointer.FVec2) irpointer.FilterChain {
+	var chain irpointer.FilterChain
+	if o.SensX != 1 || o.SensY != 1 {
+		chain = append(chain, &ScaleFilter{Center: center, Scale: irpointer.FVec2{X: o.SensX, Y: o.SensY}})
+	}
+	if o.InvertX || o.InvertY {
+		chain = append(chain, &InvertFilter{Center: center, X: o.InvertX, Y: o.InvertY})
+	}
+	return chain
+}
+
+// ScaleFilter scales the offset of the pointer from Center per axis.
+type ScaleFilter struct {
+	Center irpointer.FVec2
+	Scale  irpointer.FVec2
+}
+
+func (f *ScaleFilter) Reset() { /* stateless */ }
+
+func (f *ScaleFilter) Apply(frame irpointer.Frame) irpointer.Frame {
+	if !frame.Valid {
+		return frame
+	}
+	frame.Position = irpointer.FVec2{
+		X: f.Center.X + f.Scale.X*(frame.Position.X-f.Center.X),
+		Y: f.Center.Y + f.Scale.Y*(frame.Position.Y-f.Center.Y),
+	}
+	return frame
+}
+
+// InvertFilter mirrors the pointer at Center on the axes set.
+type InvertFilter struct {
+	Center irpointer.FVec2
+	X, Y   bool
+}
+
+func (f *InvertFilter) Reset() { /* stateless */ }
+
+func (f *InvertFilter) Apply(frame irpointer.Frame) irpointer.Frame {
+	if !frame.Valid {
+		return frame
+	}
+	if f.X {
+		frame.Position.X = 2*f.Center.X - frame.Position.X
+	}
+	if f.Y {
+		frame.Position.Y = 2*f.Center.Y - frame.Position.Y
+	}
+	return frame
+}
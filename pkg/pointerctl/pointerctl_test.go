@@ -0,0 +1,35 @@
+package pointerctl
+
+import (
+	"testing"
+
+	"github.com/friedelschoen/go-wiimote/pkg/irpointer"
+)
+
+func TestFilters(t *testing.T) {
+	center := irpointer.FVec2{X: 960, Y: 540}
+	if chain := DefaultOptions().Filters(center); len(chain) != 0 {
+		t.Errorf("expected no filters for the default options, got %d", len(chain))
+	}
+
+	opts := Options{SensX: 2, SensY: 0.5, InvertY: true}
+	frame := irpointer.Frame{Valid: true, Position: irpointer.FVec2{X: 1060, Y: 640}}
+	got := opts.Filters(center).Apply(frame).Position
+	if want := (irpointer.FVec2{X: 1160, Y: 490}); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	frame.Valid = false
+	if got := opts.Filters(center).Apply(frame).Position; got != frame.Position {
+		t.Errorf("expected invalid frame to be unchanged, got %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultOptions().Validate(); err != nil {
+		t.Errorf("expected default options to be valid, got %v", err)
+	}
+	if err := (Options{SensX: 0, SensY: -1}).Validate(); err == nil {
+		t.Errorf("expected error for non-positive sensitivity")
+	}
+}
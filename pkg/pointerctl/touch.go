@@ -0,0 +1,103 @@
+package pointerctl
+
+import (
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// TouchOptions configure the touch emulation of Touch.
+type TouchOptions struct {
+	// TapKey taps on a quick press, holding it longer than TapMax holds the touch
+	TapKey wiimote.Key
+	// DragKey touches the surface while it is held, e.g. to drag
+	DragKey wiimote.Key
+	// TapMax is the longest press of TapKey producing a tap
+	TapMax time.Duration
+	// DragHold is the time DragKey is held before the touch starts, 0 touches immediately
+	DragHold time.Duration
+}
+
+// DefaultTouchOptions returns options tapping with A and dragging with B.
+func DefaultTouchOptions() TouchOptions {
+	return TouchOptions{
+		TapKey:  wiimote.KeyA,
+		DragKey: wiimote.KeyB,
+		TapMax:  250 * time.Millisecond,
+	}
+}
+
+// Touch emulates the touch of a touch screen with buttons: a quick press taps and a held button
+// drags. The methods return the changes of the touch, true touches the surface and false
+// releases it, e.g. for vinput.Tablet.Touch.
+//
+// Touch is not thread-safe.
+type Touch struct {
+	TouchOptions
+
+	// time the keys are pressed, zero if released
+	tapSince, dragSince time.Time
+	// touches of the keys
+	hold, drag bool
+}
+
+// NewTouch returns a touch emulation using opts.
+func NewTouch(opts TouchOptions) *Touch {
+	return &Touch{TouchOptions: opts}
+}
+
+// Touching returns whether the surface is touched.
+func (t *Touch) Touching() bool {
+	return t.hold || t.drag
+}
+
+// set sets the touches of the keys and returns the change of the touch.
+func (t *Touch) set(hold, drag bool) []bool {
+	was := t.Touching()
+	t.hold, t.drag = hold, drag
+	if now := t.Touching(); now != was {
+		return []bool{now}
+	}
+	return nil
+}
+
+// Key processes a key event at now, other keys than TapKey and DragKey are ignored.
+func (t *Touch) Key(key wiimote.Key, pressed bool, now time.Time) []bool {
+	switch key {
+	case t.DragKey:
+		if pressed {
+			t.dragSince = now
+			return t.Tick(now)
+		}
+		t.dragSince = time.Time{}
+		return t.set(t.hold, false)
+	case t.TapKey:
+		if pressed {
+			t.tapSince = now
+			return nil
+		}
+		since := t.tapSince
+		t.tapSince = time.Time{}
+		if t.hold {
+			return t.set(false, t.drag)
+		}
+		if !since.IsZero() && now.Sub(since) <= t.TapMax && !t.Touching() {
+			return []bool{true, false}
+		}
+	}
+	return nil
+}
+
+// Tick starts the touches of held keys passing their threshold at now. It is called
+// periodically, e.g. on every frame of the pointer.
+func (t *Touch) Tick(now time.Time) []bool {
+	hold := t.hold || (!t.tapSince.IsZero() && now.Sub(t.tapSince) > t.TapMax)
+	drag := t.drag || (!t.dragSince.IsZero() && now.Sub(t.dragSince) >= t.DragHold)
+	return t.set(hold, drag)
+}
+
+// Reset releases the touch.
+func (t *Touch) Reset() []bool {
+	t.tapSince, t.dragSince = time.Time{}, time.Time{}
+	return t.set(false, false)
+}
@@ -0,0 +1,63 @@
+package pointerctl
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+func TestTouchTap(t *testing.T) {
+	touch := NewTouch(DefaultTouchOptions())
+	start := time.Unix(0, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	if got := touch.Key(wiimote.KeyA, true, at(0)); got != nil {
+		t.Errorf("expected no touch on press, got %v", got)
+	}
+	if got := touch.Tick(at(100)); got != nil {
+		t.Errorf("expected no touch before TapMax, got %v", got)
+	}
+	if got := touch.Key(wiimote.KeyA, false, at(150)); !slices.Equal(got, []bool{true, false}) {
+		t.Errorf("expected tap, got %v", got)
+	}
+
+	// a long press holds the touch
+	touch.Key(wiimote.KeyA, true, at(1000))
+	if got := touch.Tick(at(1300)); !slices.Equal(got, []bool{true}) {
+		t.Errorf("expected touch after TapMax, got %v", got)
+	}
+	if got := touch.Key(wiimote.KeyA, false, at(1500)); !slices.Equal(got, []bool{false}) {
+		t.Errorf("expected release, got %v", got)
+	}
+}
+
+func TestTouchDrag(t *testing.T) {
+	opts := DefaultTouchOptions()
+	opts.DragHold = 100 * time.Millisecond
+	touch := NewTouch(opts)
+	start := time.Unix(0, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	if got := touch.Key(wiimote.KeyB, true, at(0)); got != nil {
+		t.Errorf("expected no touch before DragHold, got %v", got)
+	}
+	if got := touch.Tick(at(100)); !slices.Equal(got, []bool{true}) {
+		t.Errorf("expected touch after DragHold, got %v", got)
+	}
+	// taps are ignored while dragging
+	touch.Key(wiimote.KeyA, true, at(150))
+	if got := touch.Key(wiimote.KeyA, false, at(200)); got != nil {
+		t.Errorf("expected no tap while dragging, got %v", got)
+	}
+	if got := touch.Key(wiimote.KeyB, false, at(300)); !slices.Equal(got, []bool{false}) {
+		t.Errorf("expected release, got %v", got)
+	}
+
+	touch.Key(wiimote.KeyB, true, at(400))
+	touch.Tick(at(500))
+	if got := touch.Reset(); !slices.Equal(got, []bool{false}) || touch.Touching() {
+		t.Errorf("expected reset to release, got %v", got)
+	}
+}
@@ -0,0 +1,162 @@
+// Package profile stores settings per physical device, so calibration and preferences
+// survive reconnects. Profiles are JSON-files located in the user's configuration
+// directory ($XDG_CONFIG_HOME/go-wiimote/profiles) and are keyed by the unique ID
+// (Bluetooth address) of the device.
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/pkg/irpointer"
+)
+
+// MPNormalization holds the Motion-Plus normalization values, see wiimote.MotionPlusFeature.
+type MPNormalization struct {
+	X      int32 `json:"x"`
+	Y      int32 `json:"y"`
+	Z      int32 `json:"z"`
+	Factor int32 `json:"factor"`
+}
+
+// StickCalibration describes the measured range of an analog stick.
+type StickCalibration = wiimote.StickCalibration
+
+// Settings are the per-device settings. Unset (nil) settings are left untouched by Apply.
+type Settings struct {
+	// LED is the LED state to restore
+	LED *wiimote.Led `json:"led,omitempty"`
+	// MotionPlus is the Motion-Plus normalization
+	MotionPlus *MPNormalization `json:"motionplus,omitempty"`
+	// Sticks holds stick calibrations by stick name (e.g. "nunchuk", "left", "right"), see Stick
+	Sticks map[string]StickCalibration `json:"sticks,omitempty"`
+	// IR holds the parameters of the IR pointer
+	IR *irpointer.IRPointer `json:"ir,omitempty"`
+}
+
+// Stick returns the calibration of the named stick or def if the stick is not calibrated.
+func (s *Settings) Stick(name string, def StickCalibration) StickCalibration {
+	if cal, ok := s.Sticks[name]; ok {
+		return cal
+	}
+	return def
+}
+
+// SetStick stores the calibration of the named stick.
+func (s *Settings) SetStick(name string, cal StickCalibration) {
+	if s.Sticks == nil {
+		s.Sticks = make(map[string]StickCalibration)
+	}
+	s.Sticks[name] = cal
+}
+
+// Dir returns the directory where profiles are stored.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-wiimote", "profiles"), nil
+}
+
+func profilePath(id string) (string, error) {
+	if id == "" {
+		return "", os.ErrInvalid
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, strings.ReplaceAll(strings.ToLower(id), ":", "")+".json"), nil
+}
+
+// LoadID loads the settings of the device with unique ID id. If no profile exists yet,
+// empty settings are returned.
+func LoadID(id string) (*Settings, error) {
+	path, err := profilePath(id)
+	if err != nil {
+		return nil, err
+	}
+	var s Settings
+	cont, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// parameters missing in the profile keep their defaults
+	s.IR = irpointer.NewIRPointer()
+	if err := json.Unmarshal(cont, &s); err != nil {
+		return nil, err
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(cont, &keys); err != nil {
+		return nil, err
+	}
+	if _, ok := keys["ir"]; !ok {
+		s.IR = nil
+	}
+	return &s, nil
+}
+
+// SaveID saves the settings of the device with unique ID id.
+func SaveID(id string, s *Settings) error {
+	path, err := profilePath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	cont, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so a crash does not leave a truncated profile
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, cont, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load loads the settings of dev. If no profile exists yet, empty settings are returned.
+func Load(dev wiimote.Device) (*Settings, error) {
+	id, err := dev.UniqueID()
+	if err != nil {
+		return nil, err
+	}
+	return LoadID(id)
+}
+
+// Save saves the settings of dev.
+func Save(dev wiimote.Device, s *Settings) error {
+	id, err := dev.UniqueID()
+	if err != nil {
+		return err
+	}
+	return SaveID(id, s)
+}
+
+// Apply applies the settings to dev. The LED state is written directly, Motion-Plus
+// normalization only if the Motion-Plus feature is opened.
+func Apply(dev wiimote.Device, s *Settings) error {
+	var errs []error
+	if s.LED != nil {
+		if err := dev.SetLED(*s.LED); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.MotionPlus != nil {
+		if mp, ok := dev.Feature(wiimote.FeatureMotionPlus).(wiimote.MotionPlusFeature); ok {
+			mp.SetMPNormalization(s.MotionPlus.X, s.MotionPlus.Y, s.MotionPlus.Z, s.MotionPlus.Factor)
+		}
+	}
+	return errors.Join(errs...)
+}
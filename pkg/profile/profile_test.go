@@ -0,0 +1,97 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/pkg/irpointer"
+)
+
+func TestLoadMissing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	s, err := LoadID("00:11:22:33:44:55")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if s.LED != nil || s.MotionPlus != nil || s.IR != nil || s.Sticks != nil {
+		t.Fatalf("expected empty settings, got %+v", s)
+	}
+}
+
+func TestSaveLoadRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	led := wiimote.Led1 | wiimote.Led4
+	in := &Settings{
+		LED:        &led,
+		MotionPlus: &MPNormalization{X: 1, Y: -2, Z: 3, Factor: 4},
+		Sticks: map[string]StickCalibration{
+			"left": {Center: wiimote.Vec2{X: 1, Y: 2}, Min: wiimote.Vec2{X: -100, Y: -90}, Max: wiimote.Vec2{X: 110, Y: 95}},
+		},
+		IR: irpointer.NewIRPointer(),
+	}
+	if err := SaveID("00:11:22:33:44:55", in); err != nil {
+		t.Fatalf("unable to save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go-wiimote", "profiles", "001122334455.json")); err != nil {
+		t.Fatalf("profile not written: %v", err)
+	}
+
+	out, err := LoadID("00:11:22:33:44:55")
+	if err != nil {
+		t.Fatalf("unable to load: %v", err)
+	}
+	if out.LED == nil || *out.LED != led {
+		t.Errorf("led mismatch: %v", out.LED)
+	}
+	if out.MotionPlus == nil || *out.MotionPlus != *in.MotionPlus {
+		t.Errorf("motionplus mismatch: %+v", out.MotionPlus)
+	}
+	if out.Sticks["left"] != in.Sticks["left"] {
+		t.Errorf("stick mismatch: %+v", out.Sticks)
+	}
+	if out.IR == nil || out.IR.SbWidth != in.IR.SbWidth {
+		t.Errorf("ir mismatch: %+v", out.IR)
+	}
+}
+
+func TestLoadPartialIR(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	path := filepath.Join(dir, "go-wiimote", "profiles", "001122334455.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"ir": {"SbWidth": 30}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadID("00:11:22:33:44:55")
+	if err != nil {
+		t.Fatalf("unable to load: %v", err)
+	}
+	def := irpointer.NewIRPointer()
+	if s.IR == nil || s.IR.SbWidth != 30 {
+		t.Fatalf("expected SbWidth of 30, got %+v", s.IR)
+	}
+	if s.IR.Height != def.Height || s.IR.MaxSbSlope != def.MaxSbSlope {
+		t.Errorf("expected unset parameters to keep their defaults, got %+v", s.IR)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"led": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := LoadID("00:11:22:33:44:55"); err != nil || s.IR != nil {
+		t.Errorf("expected no IR parameters, got %+v (%v)", s, err)
+	}
+}
+
+func TestInvalidID(t *testing.T) {
+	if _, err := LoadID(""); err == nil {
+		t.Errorf("expected error for empty id")
+	}
+}
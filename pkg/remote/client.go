@@ -0,0 +1,165 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+)
+
+// ErrClosed is returned by calls on a closed client.
+var ErrClosed = errors.New("connection closed")
+
+// Client is a connection to a Server. It is safe for concurrent use.
+type Client struct {
+	conn net.Conn
+
+	wmu sync.Mutex
+	mu  sync.Mutex
+	// pending requests by ID
+	pending map[uint64]chan *message
+	devices map[string]*device
+	nextID  uint64
+	err     error
+}
+
+// Dial connects to the server at addr, see net.Dial.
+func Dial(network, addr string) (*Client, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient creates a client using conn, which is closed by Close.
+func NewClient(conn net.Conn) *Client {
+	c := &Client{
+		conn:    conn,
+		pending: make(map[uint64]chan *message),
+		devices: make(map[string]*device),
+	}
+	go c.read()
+	return c
+}
+
+// Close closes the connection. All devices opened with Open report an EventGone.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) read() {
+	for {
+		msg, err := readMessage(c.conn)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		c.mu.Lock()
+		if msg.ID != 0 {
+			if ch, ok := c.pending[msg.ID]; ok {
+				delete(c.pending, msg.ID)
+				ch <- msg
+			}
+		} else if dev, ok := c.devices[msg.Device]; ok {
+			dev.receive(msg)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// fail aborts pending requests and notifies all devices that the connection is lost.
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	for id, dev := range c.devices {
+		dev.lost(err)
+		delete(c.devices, id)
+	}
+}
+
+// call sends a request and decodes the result into result, if result is not nil.
+func (c *Client) call(method, dev string, args, result any) error {
+	req := &message{Method: method, Device: dev}
+	if args != nil {
+		var err error
+		if req.Args, err = json.Marshal(args); err != nil {
+			return err
+		}
+	}
+
+	ch := make(chan *message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.nextID++
+	req.ID = c.nextID
+	c.pending[req.ID] = ch
+	c.mu.Unlock()
+
+	c.wmu.Lock()
+	err := writeMessage(c.conn, req)
+	c.wmu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, req.ID)
+		c.mu.Unlock()
+		return err
+	}
+
+	resp, ok := <-ch
+	if !ok {
+		return ErrClosed
+	}
+	if err := remoteError(resp); err != nil {
+		return err
+	}
+	if result != nil && resp.Result != nil {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// Devices returns the devices served by the server.
+func (c *Client) Devices() ([]DeviceDesc, error) {
+	var descs []DeviceDesc
+	err := c.call("devices", "", nil, &descs)
+	return descs, err
+}
+
+// Open subscribes to the events of the device with the given ID, as returned by Devices. The
+// device behaves like a local device, features have to be opened with OpenFeatures. Every
+// method is a round-trip to the server, except for State and ReadStats.
+func (c *Client) Open(id string) (wiimote.Device, error) {
+	d, err := newDevice(c, id)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.devices[id] = d
+	c.mu.Unlock()
+
+	if err := c.call("subscribe", id, nil, &d.desc); err != nil {
+		c.mu.Lock()
+		delete(c.devices, id)
+		c.mu.Unlock()
+		return nil, err
+	}
+	var opened wiimote.FeatureKind
+	if err := c.call("opened", id, nil, &opened); err != nil {
+		return nil, err
+	}
+	d.setOpened(opened)
+	d.OnCleanup(common.RestoreDevice(d))
+	return d, nil
+}
@@ -0,0 +1,352 @@
+package remote
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+	"golang.org/x/sys/unix"
+)
+
+type commonEvent struct {
+	dev       *device
+	iface     wiimote.Feature
+	timestamp time.Time
+}
+
+func (e commonEvent) Feature() wiimote.Feature { return e.iface }
+func (e commonEvent) Device() wiimote.Device   { return e.dev }
+func (e commonEvent) Timestamp() time.Time     { return e.timestamp }
+
+type feature struct {
+	kind wiimote.FeatureKind
+	dev  *device
+}
+
+func (f feature) Kind() wiimote.FeatureKind { return f.kind }
+func (f feature) Device() wiimote.Device    { return f.dev }
+func (f feature) Opened() bool              { return f.dev.Feature(f.kind) != nil }
+func (f feature) Close() error {
+	var opened wiimote.FeatureKind
+	err := f.dev.call("close_feature", intArgs{int(f.kind)}, &opened)
+	if err == nil {
+		f.dev.setOpened(opened)
+	}
+	return err
+}
+
+type coreFeature struct {
+	feature
+}
+
+func (f coreFeature) Rumble(state bool) error {
+	return f.dev.call("rumble", boolArgs{state}, nil)
+}
+
+// device is a device served by a Server, events are pushed by the reader of the client.
+type device struct {
+	wiimote.Poller[wiimote.Event]
+	common.Cleanups
+
+	client *Client
+	desc   DeviceDesc
+	// event file descriptor, signalled when events are queued
+	efd    int
+	policy wiimote.ErrorPolicy
+
+	mu     sync.Mutex
+	queue  []wiimote.Event
+	err    error
+	opened wiimote.FeatureKind
+	stats  wiimote.ReadStats
+	state  common.StateBuffer
+	limit  common.RateLimit
+}
+
+func newDevice(c *Client, id string) (*device, error) {
+	d := &device{client: c, desc: DeviceDesc{ID: id}}
+	d.Poller = common.NewPoller(d)
+
+	var err error
+	d.efd, err = unix.Eventfd(0, unix.EFD_NONBLOCK|unix.EFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	runtime.AddCleanup(d, func(fd int) { unix.Close(fd) }, d.efd)
+	return d, nil
+}
+
+func (d *device) call(method string, args, result any) error {
+	return d.client.call(method, d.desc.ID, args, result)
+}
+
+func (d *device) signal() {
+	var buf [8]byte
+	buf[0] = 1
+	unix.Write(d.efd, buf[:])
+}
+
+func (d *device) setOpened(kinds wiimote.FeatureKind) {
+	d.mu.Lock()
+	d.opened = kinds
+	d.mu.Unlock()
+}
+
+// receive queues the event of msg, it is called by the reader of the client.
+func (d *device) receive(msg *message) {
+	ev, err := wiimote.UnmarshalEvent(msg.Event, func(ts time.Time, kind wiimote.FeatureKind) wiimote.Event {
+		return commonEvent{dev: d, iface: d.feature(kind), timestamp: ts}
+	})
+	if err != nil {
+		wiimote.Logger().Error("unable to decode event", "device", d.desc.ID, "err", err)
+		return
+	}
+	d.mu.Lock()
+	d.opened = msg.Opened
+	d.stats.Reads++
+	d.stats.Events++
+	d.queue = append(d.queue, ev)
+	d.mu.Unlock()
+	d.signal()
+}
+
+// lost is called by the client if the connection is lost.
+func (d *device) lost(err error) {
+	d.mu.Lock()
+	d.err = err
+	d.mu.Unlock()
+	d.signal()
+}
+
+func (d *device) Capabilities() wiimote.Capabilities {
+	var kinds wiimote.FeatureKind
+	d.call("available", nil, &kinds)
+	devtype, _ := d.DevType()
+	return wiimote.CapabilitiesOf(devtype, kinds)
+}
+
+func (d *device) SetErrorPolicy(policy wiimote.ErrorPolicy) {
+	d.policy = policy
+}
+
+func (d *device) FD() int { return d.efd }
+
+func (d *device) Poll() (wiimote.Event, bool, error) {
+	// coalesced samples are kept back, the state is updated with every sample
+	return d.limit.Poll(d.poll, d.state.Update, nil)
+}
+
+// State returns the latest values of all opened features.
+func (d *device) State() wiimote.State {
+	return d.state.State()
+}
+
+func (d *device) poll() (wiimote.Event, bool, error) {
+	var buf [8]byte
+	if _, err := unix.Read(d.efd, buf[:]); err != nil && !errors.Is(err, unix.EAGAIN) {
+		return nil, false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.queue) > 0 {
+		ev := d.queue[0]
+		d.queue = d.queue[1:]
+		return ev, len(d.queue) > 0 || d.err != nil, nil
+	}
+	if d.err != nil {
+		err := d.err
+		// the connection is gone for good, so the device is closed regardless of the policy
+		d.err = nil
+		d.opened = 0
+		if d.policy == wiimote.ErrorReturn {
+			return nil, false, err
+		}
+		return &wiimote.EventGone{Event: commonEvent{dev: d, timestamp: time.Now()}}, false, nil
+	}
+	return nil, false, common.ErrWouldBlock
+}
+
+func (d *device) String() string {
+	return d.desc.Name + " (remote)"
+}
+
+func (d *device) Syspath() string { return d.desc.Syspath }
+
+func (d *device) OpenFeatures(ifaces wiimote.FeatureKind, wr bool) error {
+	var opened wiimote.FeatureKind
+	err := d.call("open_features", openArgs{ifaces, wr}, &opened)
+	d.setOpened(opened)
+	return err
+}
+
+func (d *device) AutoReopen(enable bool) {
+	if err := d.call("auto_reopen", boolArgs{enable}, nil); err != nil {
+		wiimote.Logger().Error("unable to set auto-reopen", "device", d.desc.ID, "err", err)
+	}
+}
+
+func (d *device) SetOpenPolicy(policy wiimote.OpenPolicy) {
+	var opened wiimote.FeatureKind
+	if err := d.call("set_open_policy", openArgs{policy.Kinds, policy.Writable}, &opened); err != nil {
+		wiimote.Logger().Error("unable to set open policy", "device", d.desc.ID, "err", err)
+		return
+	}
+	d.setOpened(opened)
+}
+
+func (d *device) feature(kind wiimote.FeatureKind) wiimote.Feature {
+	switch kind {
+	case 0:
+		return nil
+	case wiimote.FeatureCore:
+		return coreFeature{feature{kind, d}}
+	}
+	return feature{kind, d}
+}
+
+func (d *device) Feature(kind wiimote.FeatureKind) wiimote.Feature {
+	d.mu.Lock()
+	opened := d.opened
+	d.mu.Unlock()
+	if opened&kind == 0 {
+		return nil
+	}
+	return d.feature(kind)
+}
+
+func (d *device) Available(iface wiimote.FeatureKind) bool {
+	var kinds wiimote.FeatureKind
+	if err := d.call("available", nil, &kinds); err != nil {
+		return false
+	}
+	return kinds&iface != 0
+}
+
+func (d *device) IRFull() bool {
+	var full bool
+	d.call("ir_full", nil, &full)
+	return full
+}
+
+func (d *device) SetIRFull(fullreport bool) {
+	if err := d.call("set_ir_full", boolArgs{fullreport}, nil); err != nil {
+		wiimote.Logger().Error("unable to set IR mode", "device", d.desc.ID, "err", err)
+	}
+}
+
+func (d *device) RawEvents(kind wiimote.FeatureKind, cooked bool) (<-chan wiimote.RawEvent, error) {
+	return nil, wiimote.ErrUnsupported
+}
+
+func (d *device) StopRawEvents(kind wiimote.FeatureKind) {}
+
+func (d *device) SetMaxRate(kind wiimote.FeatureKind, hz float64) {
+	d.limit.SetMaxRate(kind, hz)
+}
+
+func (d *device) Pause(close wiimote.FeatureKind) error {
+	var opened wiimote.FeatureKind
+	err := d.call("pause", intArgs{int(close)}, &opened)
+	d.setOpened(opened)
+	return err
+}
+
+func (d *device) Resume() error {
+	var opened wiimote.FeatureKind
+	err := d.call("resume", nil, &opened)
+	d.setOpened(opened)
+	return err
+}
+
+func (d *device) ReadStats() wiimote.ReadStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+func (d *device) LED() (wiimote.Led, error) {
+	var leds wiimote.Led
+	err := d.call("led", nil, &leds)
+	return leds, err
+}
+
+func (d *device) SetLED(leds wiimote.Led) error {
+	return d.call("set_led", intArgs{int(leds)}, nil)
+}
+
+func (d *device) LEDs() wiimote.Led {
+	var leds wiimote.Led
+	d.call("leds", nil, &leds)
+	return leds
+}
+
+func (d *device) LEDState(index int) (bool, error) {
+	return common.LEDState(d, index)
+}
+
+func (d *device) SetLEDState(index int, on bool) error {
+	return common.SetLEDState(d, index, on)
+}
+
+func (d *device) SetPlayerLED(n int) error {
+	return d.call("set_player_led", intArgs{n}, nil)
+}
+
+func (d *device) Player() int {
+	var n int
+	d.call("player", nil, &n)
+	return n
+}
+
+func (d *device) Battery() (uint, error) {
+	var capacity uint
+	err := d.call("battery", nil, &capacity)
+	return capacity, err
+}
+
+func (d *device) describe() (DeviceDesc, error) {
+	var desc DeviceDesc
+	err := d.call("describe", nil, &desc)
+	return desc, err
+}
+
+func (d *device) DevType() (string, error) {
+	desc, err := d.describe()
+	if err != nil {
+		return "unknown", err
+	}
+	return desc.DevType, nil
+}
+
+func (d *device) Type() (wiimote.DevType, error) {
+	devtype, err := d.DevType()
+	return wiimote.ParseDevType(devtype), err
+}
+
+func (d *device) Extension() (string, error) {
+	desc, err := d.describe()
+	if err != nil {
+		return "none", err
+	}
+	return desc.Extension, nil
+}
+
+func (d *device) UniqueID() (string, error) {
+	desc, err := d.describe()
+	if err != nil {
+		return "", err
+	}
+	if desc.UniqueID == "" {
+		return "", os.ErrNotExist
+	}
+	return desc.UniqueID, nil
+}
+
+func (d *device) Disconnect() error {
+	return d.call("disconnect", nil, nil)
+}
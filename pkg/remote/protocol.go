@@ -0,0 +1,131 @@
+// Package remote exposes devices over a network connection, so a machine with the Bluetooth
+// adapter can serve its remotes to consumers running elsewhere (e.g. an emulator on a HTPC).
+//
+// The protocol is a stream of messages, each a 4-byte big-endian length followed by a JSON
+// object. The client sends requests with an ID, which the server answers with a response of
+// the same ID. Events of subscribed devices are pushed by the server without ID, they are
+// encoded by wiimote.MarshalEvent.
+package remote
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// maxMessage is the largest message accepted.
+const maxMessage = 1 << 20
+
+type message struct {
+	ID     uint64          `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Device string          `json:"device,omitempty"`
+	Args   json.RawMessage `json:"args,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Code   int             `json:"code,omitempty"`
+	Event  json.RawMessage `json:"event,omitempty"`
+	// Opened is sent with every event, the features opened on the device
+	Opened wiimote.FeatureKind `json:"opened,omitempty"`
+}
+
+func writeMessage(w io.Writer, msg *message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(buf, uint32(len(b)))
+	_, err = w.Write(append(buf, b...))
+	return err
+}
+
+func readMessage(r io.Reader) (*message, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n > maxMessage {
+		return nil, fmt.Errorf("message of %d bytes exceeds limit", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	var msg message
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// errorCodes are the errors which are recognized by errors.Is on the client, the code is the
+// index plus one.
+var errorCodes = []error{
+	wiimote.ErrFeatureUnavailable,
+	wiimote.ErrNotOpened,
+	wiimote.ErrPermission,
+	wiimote.ErrNoBattery,
+	wiimote.ErrNoLED,
+	os.ErrNotExist,
+	os.ErrInvalid,
+}
+
+func errorCode(err error) int {
+	for i, e := range errorCodes {
+		if errors.Is(err, e) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Error is an error returned by the server.
+type Error struct {
+	Msg string
+	err error
+}
+
+func (e *Error) Error() string { return e.Msg }
+func (e *Error) Unwrap() error { return e.err }
+
+func remoteError(msg *message) error {
+	if msg.Error == "" {
+		return nil
+	}
+	e := &Error{Msg: msg.Error}
+	if msg.Code > 0 && msg.Code <= len(errorCodes) {
+		e.err = errorCodes[msg.Code-1]
+	}
+	return e
+}
+
+// DeviceDesc describes a device served by a Server.
+type DeviceDesc struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Syspath   string `json:"syspath"`
+	DevType   string `json:"devtype"`
+	Extension string `json:"extension"`
+	UniqueID  string `json:"unique_id"`
+	Player    int    `json:"player"`
+}
+
+type openArgs struct {
+	Kinds    wiimote.FeatureKind `json:"kinds"`
+	Writable bool                `json:"writable"`
+}
+
+type boolArgs struct {
+	Value bool `json:"value"`
+}
+
+type intArgs struct {
+	Value int `json:"value"`
+}
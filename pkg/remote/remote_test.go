@@ -0,0 +1,88 @@
+package remote
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/driver/sim"
+)
+
+func TestRemoteDevice(t *testing.T) {
+	cfg := sim.DefaultConfig()
+	cfg.Keys = []sim.KeyPress{{Key: wiimote.KeyA, At: 0, Duration: time.Hour}}
+	cfg.UniqueID = "00:1f:32:aa:bb:cc"
+	simdev, err := sim.NewDevice(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer()
+	id := srv.Add(simdev)
+	sconn, cconn := net.Pipe()
+	go srv.ServeConn(sconn)
+	client := NewClient(cconn)
+	defer client.Close()
+
+	descs, err := client.Devices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descs) != 1 || descs[0].ID != id || descs[0].UniqueID != cfg.UniqueID {
+		t.Fatalf("unexpected devices: %+v", descs)
+	}
+
+	dev, err := client.Open(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.OpenFeatures(wiimote.FeatureCore, true); err != nil {
+		t.Fatal(err)
+	}
+	if dev.Feature(wiimote.FeatureCore) == nil {
+		t.Fatalf("expected core feature to be opened")
+	}
+
+	ev, err := dev.Wait(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, ok := ev.(*wiimote.EventKey)
+	if !ok || key.Code != wiimote.KeyA || !key.Pressed {
+		t.Fatalf("unexpected event: %#v", ev)
+	}
+	if key.Feature().Device() != dev {
+		t.Errorf("expected event to be bound to the remote device")
+	}
+	if !dev.State().Keys.Has(wiimote.KeyA) {
+		t.Errorf("expected state to contain pressed key")
+	}
+
+	if err := dev.SetPlayerLED(3); err != nil {
+		t.Fatal(err)
+	}
+	if leds, err := dev.LED(); err != nil || leds != wiimote.Led3 {
+		t.Errorf("expected LED3, got %v (err=%v)", leds, err)
+	}
+	if uniq, _ := dev.UniqueID(); uniq != cfg.UniqueID {
+		t.Errorf("expected unique ID %q, got %q", cfg.UniqueID, uniq)
+	}
+	if err := dev.SetPlayerLED(7); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("expected os.ErrInvalid for invalid player, got %v", err)
+	}
+
+	client.Close()
+	dev.SetErrorPolicy(wiimote.ErrorClose)
+	for {
+		ev, err := dev.Wait(time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := ev.(*wiimote.EventGone); ok {
+			break
+		}
+	}
+}
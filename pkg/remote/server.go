@@ -0,0 +1,371 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/friedelschoen/go-wiimote"
+)
+
+// errGone is returned for requests to a device which is gone.
+var errGone = errors.New("device is gone")
+
+// served is a device served by a Server. Devices are not thread-safe, so all calls to dev are
+// made by the goroutine polling it, see do.
+type served struct {
+	dev  wiimote.Device
+	desc DeviceDesc
+
+	mu sync.Mutex
+	// calls queued by do
+	calls []func()
+	// interrupts the current wait of watch, nil if not waiting
+	interrupt context.CancelFunc
+	gone      bool
+}
+
+// do runs fn on the goroutine polling the device and waits until it returned. It returns
+// errGone if the device is gone.
+func (sv *served) do(fn func()) error {
+	done := make(chan struct{})
+	sv.mu.Lock()
+	if sv.gone {
+		sv.mu.Unlock()
+		return errGone
+	}
+	sv.calls = append(sv.calls, func() {
+		fn()
+		close(done)
+	})
+	if sv.interrupt != nil {
+		sv.interrupt()
+	}
+	sv.mu.Unlock()
+	<-done
+	return nil
+}
+
+// runCalls runs the queued calls and returns a context which is cancelled when a new call
+// is queued.
+func (sv *served) runCalls() (context.Context, context.CancelFunc) {
+	for {
+		sv.mu.Lock()
+		calls := sv.calls
+		sv.calls = nil
+		if len(calls) == 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			sv.interrupt = cancel
+			sv.mu.Unlock()
+			return ctx, cancel
+		}
+		sv.interrupt = nil
+		sv.mu.Unlock()
+		for _, fn := range calls {
+			fn()
+		}
+	}
+}
+
+// close marks the device as gone and runs the calls queued in the meantime.
+func (sv *served) close() {
+	sv.mu.Lock()
+	sv.gone = true
+	calls := sv.calls
+	sv.calls, sv.interrupt = nil, nil
+	sv.mu.Unlock()
+	for _, fn := range calls {
+		fn()
+	}
+}
+
+// Server serves devices to clients. Devices are added with Add, they are polled by the server
+// and removed as soon as they are gone.
+type Server struct {
+	mu      sync.Mutex
+	devices map[string]*served
+	conns   map[*serverConn]struct{}
+	nextID  int
+}
+
+// NewServer creates a server without devices.
+func NewServer() *Server {
+	return &Server{
+		devices: make(map[string]*served),
+		conns:   make(map[*serverConn]struct{}),
+	}
+}
+
+// Add serves dev and returns its ID. The server polls dev until it is gone, dev must not be
+// polled by anyone else.
+func (s *Server) Add(dev wiimote.Device) string {
+	s.mu.Lock()
+	s.nextID++
+	sv := &served{dev: dev}
+	sv.desc.ID = strconv.Itoa(s.nextID)
+	s.devices[sv.desc.ID] = sv
+	s.mu.Unlock()
+
+	go s.watch(sv)
+	return sv.desc.ID
+}
+
+func (sv *served) describe() DeviceDesc {
+	desc := sv.desc
+	desc.Name = sv.dev.String()
+	desc.Syspath = sv.dev.Syspath()
+	desc.DevType, _ = sv.dev.DevType()
+	desc.Extension, _ = sv.dev.Extension()
+	desc.UniqueID, _ = sv.dev.UniqueID()
+	desc.Player = sv.dev.Player()
+	return desc
+}
+
+func (sv *served) opened() wiimote.FeatureKind {
+	var kinds wiimote.FeatureKind
+	for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+		if sv.dev.Feature(kind) != nil {
+			kinds |= kind
+		}
+	}
+	return kinds
+}
+
+func (s *Server) watch(sv *served) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.devices, sv.desc.ID)
+		s.mu.Unlock()
+		sv.close()
+	}()
+	for {
+		ctx, cancel := sv.runCalls()
+		ev, err := sv.dev.WaitContext(ctx)
+		cancel()
+		if errors.Is(err, context.Canceled) {
+			// interrupted by a request
+			continue
+		}
+		if err != nil {
+			// the error is persistent, report it and stop serving the device
+			wiimote.Logger().Error("unable to poll device", "device", sv.desc.ID, "err", err)
+			s.send(sv, &wiimote.EventError{Err: err})
+			s.send(sv, &wiimote.EventGone{})
+			return
+		}
+		s.send(sv, ev)
+		if _, ok := ev.(*wiimote.EventGone); ok {
+			return
+		}
+	}
+}
+
+// send broadcasts ev of sv to all subscribed connections.
+func (s *Server) send(sv *served, ev wiimote.Event) {
+	b, err := wiimote.MarshalEvent(ev)
+	if err != nil {
+		wiimote.Logger().Error("unable to encode event", "err", err)
+		return
+	}
+	s.broadcast(sv.desc.ID, &message{Device: sv.desc.ID, Event: b, Opened: sv.opened()})
+}
+
+func (s *Server) broadcast(id string, msg *message) {
+	s.mu.Lock()
+	conns := make([]*serverConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+	for _, c := range conns {
+		if c.subscribed(id) {
+			c.write(msg)
+		}
+	}
+}
+
+// Serve accepts connections on ln until it fails.
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+type serverConn struct {
+	conn net.Conn
+
+	wmu sync.Mutex
+	mu  sync.Mutex
+	// subscribed device IDs
+	subs map[string]bool
+}
+
+func (c *serverConn) subscribed(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subs[id]
+}
+
+func (c *serverConn) write(msg *message) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return writeMessage(c.conn, msg)
+}
+
+// ServeConn handles requests on conn until it is closed.
+func (s *Server) ServeConn(conn net.Conn) error {
+	c := &serverConn{conn: conn, subs: make(map[string]bool)}
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		req, err := readMessage(conn)
+		if err != nil {
+			return err
+		}
+		resp := &message{ID: req.ID}
+		result, err := s.handle(c, req)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Code = errorCode(err)
+		} else if result != nil {
+			if resp.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+		if err := c.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(c *serverConn, req *message) (any, error) {
+	if req.Method == "devices" {
+		s.mu.Lock()
+		devices := make([]*served, 0, len(s.devices))
+		for _, sv := range s.devices {
+			devices = append(devices, sv)
+		}
+		s.mu.Unlock()
+		descs := make([]DeviceDesc, 0, len(devices))
+		for _, sv := range devices {
+			var desc DeviceDesc
+			if sv.do(func() { desc = sv.describe() }) == nil {
+				descs = append(descs, desc)
+			}
+		}
+		return descs, nil
+	}
+
+	s.mu.Lock()
+	sv := s.devices[req.Device]
+	s.mu.Unlock()
+	if sv == nil {
+		return nil, fmt.Errorf("no device %q", req.Device)
+	}
+
+	if req.Method == "subscribe" {
+		c.mu.Lock()
+		c.subs[req.Device] = true
+		c.mu.Unlock()
+	}
+	var result any
+	var err error
+	if err := sv.do(func() { result, err = sv.call(req) }); err != nil {
+		return nil, err
+	}
+	return result, err
+}
+
+// call runs the request req on the device, it is called by the goroutine polling it.
+func (sv *served) call(req *message) (any, error) {
+	dev := sv.dev
+
+	var open openArgs
+	var b boolArgs
+	var n intArgs
+	switch req.Method {
+	case "open_features", "set_open_policy":
+		json.Unmarshal(req.Args, &open)
+	case "set_ir_full", "auto_reopen", "rumble":
+		json.Unmarshal(req.Args, &b)
+	case "close_feature", "set_led", "set_player_led", "pause":
+		json.Unmarshal(req.Args, &n)
+	}
+
+	switch req.Method {
+	case "subscribe", "describe":
+		return sv.describe(), nil
+	case "open_features":
+		err := dev.OpenFeatures(open.Kinds, open.Writable)
+		return sv.opened(), err
+	case "set_open_policy":
+		dev.SetOpenPolicy(wiimote.OpenPolicy{Kinds: open.Kinds, Writable: open.Writable})
+		return sv.opened(), nil
+	case "close_feature":
+		if f := dev.Feature(wiimote.FeatureKind(n.Value)); f != nil {
+			f.Close()
+		}
+		return sv.opened(), nil
+	case "pause":
+		err := dev.Pause(wiimote.FeatureKind(n.Value))
+		return sv.opened(), err
+	case "resume":
+		err := dev.Resume()
+		return sv.opened(), err
+	case "opened":
+		return sv.opened(), nil
+	case "available":
+		var kinds wiimote.FeatureKind
+		for kind := wiimote.FeatureCore; kind <= wiimote.FeatureGuitar; kind <<= 1 {
+			if dev.Available(kind) {
+				kinds |= kind
+			}
+		}
+		return kinds, nil
+	case "auto_reopen":
+		dev.AutoReopen(b.Value)
+		return nil, nil
+	case "ir_full":
+		return dev.IRFull(), nil
+	case "set_ir_full":
+		dev.SetIRFull(b.Value)
+		return nil, nil
+	case "led":
+		return dev.LED()
+	case "set_led":
+		return nil, dev.SetLED(wiimote.Led(n.Value))
+	case "leds":
+		return dev.LEDs(), nil
+	case "disconnect":
+		return nil, dev.Disconnect()
+	case "set_player_led":
+		return nil, dev.SetPlayerLED(n.Value)
+	case "battery":
+		return dev.Battery()
+	case "player":
+		return dev.Player(), nil
+	case "rumble":
+		f, ok := dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature)
+		if !ok {
+			return nil, wiimote.ErrNotOpened
+		}
+		return nil, f.Rumble(b.Value)
+	}
+	return nil, fmt.Errorf("unknown method %q", req.Method)
+}
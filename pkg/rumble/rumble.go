@@ -0,0 +1,257 @@
+// Package rumble relays force-feedback effects requested by games to the rumble motor of a
+// device. Games upload effects to a virtual gamepad created with vinput.WithForceFeedback, the
+// bridge plays them on the motor.
+package rumble
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+	"github.com/friedelschoen/go-wiimote/pkg/vinput"
+	"golang.org/x/sys/unix"
+)
+
+// DefaultPeriod is the length of a duty cycle used by NewBridge.
+const DefaultPeriod = 50 * time.Millisecond
+
+// play is a playing effect.
+type play struct {
+	start time.Time
+	// end is zero if the effect plays infinitely
+	end time.Time
+}
+
+// Bridge plays force-feedback effects on the rumble motor of a device. The motor only knows on and
+// off, so the intensity of the effects is approximated by switching the motor on for a part of
+// every period.
+//
+// Bridge passes all events of the device and switches the motor while it is polled, so the
+// device is only used by the goroutine polling the bridge. A timer wakes the poller when the
+// motor is to be switched.
+type Bridge struct {
+	wiimote.Poller[wiimote.Event]
+
+	// Period is the length of a duty cycle
+	Period time.Duration
+
+	dev     wiimote.Device
+	mu      sync.Mutex
+	effects map[int16]vinput.Effect
+	playing map[int16]play
+	gain    float64
+	// an effect was played or stopped, the duty cycle restarts
+	wake bool
+
+	// state of the motor, owned by the polling goroutine
+	on       bool
+	offAt    time.Time
+	cycleEnd time.Time
+	next     time.Time
+
+	efd int
+	tfd int
+}
+
+// NewBridge creates a bridge playing effects on the rumble motor of dev. If dev provides a file
+// descriptor (as all devices do), the bridge provides one which is readable when either dev has
+// events or the motor is to be switched.
+func NewBridge(dev wiimote.Device) (*Bridge, error) {
+	b := &Bridge{
+		Period:  DefaultPeriod,
+		dev:     dev,
+		effects: make(map[int16]vinput.Effect),
+		playing: make(map[int16]play),
+		gain:    1,
+		efd:     -1,
+	}
+	b.Poller = common.NewPoller(b)
+
+	var err error
+	b.tfd, err = unix.TimerfdCreate(unix.CLOCK_MONOTONIC, unix.TFD_NONBLOCK|unix.TFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	fds := []int{b.tfd}
+	if s, ok := dev.(interface{ FD() int }); ok {
+		b.efd, err = unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+		if err != nil {
+			unix.Close(b.tfd)
+			return nil, err
+		}
+		fds = append(fds, b.efd)
+		for _, fd := range []int{b.tfd, s.FD()} {
+			ev := unix.EpollEvent{Events: unix.EPOLLIN, Fd: int32(fd)}
+			if err := unix.EpollCtl(b.efd, unix.EPOLL_CTL_ADD, fd, &ev); err != nil {
+				for _, fd := range fds {
+					unix.Close(fd)
+				}
+				return nil, err
+			}
+		}
+	}
+	runtime.AddCleanup(b, func(fds []int) {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+	}, fds)
+	return b, nil
+}
+
+// FD returns a file descriptor which is readable when Poll should be called, -1 if the device
+// does not provide one.
+func (b *Bridge) FD() int {
+	return b.efd
+}
+
+// Poll switches the motor if due and returns the next event of the device.
+func (b *Bridge) Poll() (wiimote.Event, bool, error) {
+	var buf [8]byte
+	unix.Read(b.tfd, buf[:])
+	b.step(time.Now())
+	return b.dev.Poll()
+}
+
+// Stop switches the motor off, it is called by the polling goroutine when it stops polling.
+func (b *Bridge) Stop() {
+	b.set(false)
+	b.next = time.Time{}
+	b.mu.Lock()
+	b.arm()
+	b.mu.Unlock()
+}
+
+// Feedback returns the callbacks to pass to ReadEvents of the virtual device.
+func (b *Bridge) Feedback() vinput.Feedback {
+	return vinput.Feedback{
+		Upload: func(effect vinput.Effect) error {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.effects[effect.ID] = effect
+			return nil
+		},
+		Erase: func(id int16) error {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.effects, id)
+			delete(b.playing, id)
+			return nil
+		},
+		Play: func(id int16, count int32) {
+			b.play(id, count, time.Now())
+		},
+		Gain: func(gain uint16) {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.gain = float64(gain) / math.MaxUint16
+		},
+	}
+}
+
+func (b *Bridge) play(id int16, count int32, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.wake = true
+	defer b.arm()
+	effect, ok := b.effects[id]
+	if !ok || count <= 0 {
+		delete(b.playing, id)
+		return
+	}
+	p := play{start: now.Add(effect.Delay)}
+	if effect.Length > 0 {
+		p.end = p.start.Add(effect.Length * time.Duration(count))
+	}
+	b.playing[id] = p
+}
+
+// Intensity returns the strength of the playing effects at now in the range 0..1. The light motor
+// counts half as the heavy one, concurrent effects do not add up.
+func (b *Bridge) Intensity(now time.Time) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var level float64
+	for id, p := range b.playing {
+		if !p.end.IsZero() && !now.Before(p.end) {
+			delete(b.playing, id)
+			continue
+		}
+		if now.Before(p.start) {
+			continue
+		}
+		effect := b.effects[id]
+		level = max(level, (float64(effect.Strong)+float64(effect.Weak)/2)/math.MaxUint16)
+	}
+	return min(level*b.gain, 1)
+}
+
+// set switches the motor if its state changed.
+func (b *Bridge) set(on bool) {
+	if b.on == on {
+		return
+	}
+	rf, ok := b.dev.Feature(wiimote.FeatureCore).(wiimote.RumbleFeature)
+	if !ok {
+		return
+	}
+	if err := rf.Rumble(on); err != nil {
+		wiimote.Logger().Debug("unable to set rumble", "device", b.dev.Syspath(), "err", err)
+		return
+	}
+	b.on = on
+}
+
+// step switches the motor at now: every period starts with the motor on for the part of the
+// intensity, the motor is off for the rest. The timer is set to the next switch, it is disarmed
+// while no effects are playing.
+func (b *Bridge) step(now time.Time) {
+	b.mu.Lock()
+	wake := b.wake
+	b.wake = false
+	b.mu.Unlock()
+
+	switch {
+	case !wake && (b.next.IsZero() || now.Before(b.next)):
+		// no switch due
+	case wake || !now.Before(b.cycleEnd):
+		on := time.Duration(b.Intensity(now) * float64(b.Period))
+		// Intensity drops ended effects
+		b.mu.Lock()
+		active := len(b.playing) > 0
+		b.mu.Unlock()
+		b.set(on > 0)
+		b.offAt, b.cycleEnd = now.Add(on), now.Add(b.Period)
+		switch {
+		case !active:
+			b.next = time.Time{}
+		case on > 0 && on < b.Period:
+			b.next = b.offAt
+		default:
+			b.next = b.cycleEnd
+		}
+	default:
+		b.set(false)
+		b.next = b.cycleEnd
+	}
+
+	b.mu.Lock()
+	b.arm()
+	b.mu.Unlock()
+}
+
+// arm sets the timer to the next switch, a pending wake expires it immediately. The caller must
+// hold b.mu.
+func (b *Bridge) arm() {
+	var spec unix.ItimerSpec
+	switch {
+	case b.wake:
+		spec.Value = unix.NsecToTimespec(1)
+	case !b.next.IsZero():
+		// an expired deadline must still arm the timer, a zero value disarms it
+		spec.Value = unix.NsecToTimespec(max(int64(time.Until(b.next)), 1))
+	}
+	unix.TimerfdSettime(b.tfd, 0, &spec, nil)
+}
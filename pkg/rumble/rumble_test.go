@@ -0,0 +1,120 @@
+package rumble
+
+import (
+	"testing"
+	"time"
+
+	"github.com/friedelschoen/go-wiimote"
+	"github.com/friedelschoen/go-wiimote/internal/common"
+	"github.com/friedelschoen/go-wiimote/pkg/vinput"
+)
+
+// testDevice records the state of the rumble motor and has no events.
+type testDevice struct {
+	wiimote.Device
+	rumble []bool
+}
+
+type testRumble struct {
+	wiimote.Feature
+	dev *testDevice
+}
+
+func (f testRumble) Rumble(on bool) error {
+	f.dev.rumble = append(f.dev.rumble, on)
+	return nil
+}
+
+func (d *testDevice) Feature(kind wiimote.FeatureKind) wiimote.Feature { return testRumble{dev: d} }
+func (d *testDevice) Syspath() string                                  { return "test" }
+func (d *testDevice) Poll() (wiimote.Event, bool, error) {
+	return nil, false, common.ErrWouldBlock
+}
+
+func TestIntensity(t *testing.T) {
+	b, err := NewBridge(nil)
+	if err != nil {
+		t.Fatalf("unable to create bridge: %v", err)
+	}
+	fb := b.Feedback()
+	fb.Upload(vinput.Effect{ID: 1, Strong: 0xffff, Length: 100 * time.Millisecond})
+	fb.Upload(vinput.Effect{ID: 2, Weak: 0xffff, Delay: 50 * time.Millisecond})
+
+	now := time.Now()
+	if l := b.Intensity(now); l != 0 {
+		t.Errorf("expected no intensity before playing, got %v", l)
+	}
+
+	b.play(1, 2, now)
+	b.play(2, 1, now)
+	if l := b.Intensity(now.Add(10 * time.Millisecond)); l != 1 {
+		t.Errorf("expected full intensity, got %v", l)
+	}
+	// effect 1 ends after two repetitions, effect 2 plays infinitely with half the strength
+	if l := b.Intensity(now.Add(300 * time.Millisecond)); l < 0.49 || l > 0.51 {
+		t.Errorf("expected half intensity, got %v", l)
+	}
+
+	fb.Gain(0x7fff)
+	if l := b.Intensity(now.Add(300 * time.Millisecond)); l < 0.24 || l > 0.26 {
+		t.Errorf("expected quarter intensity with half gain, got %v", l)
+	}
+
+	fb.Play(2, 0)
+	if l := b.Intensity(now.Add(300 * time.Millisecond)); l != 0 {
+		t.Errorf("expected no intensity after stop, got %v", l)
+	}
+
+	b.play(2, 1, now)
+	fb.Erase(2)
+	if l := b.Intensity(now.Add(300 * time.Millisecond)); l != 0 {
+		t.Errorf("expected no intensity after erase, got %v", l)
+	}
+}
+
+func TestDutyCycle(t *testing.T) {
+	dev := &testDevice{}
+	b, err := NewBridge(dev)
+	if err != nil {
+		t.Fatalf("unable to create bridge: %v", err)
+	}
+	fb := b.Feedback()
+	fb.Upload(vinput.Effect{ID: 1, Weak: 0xffff, Length: 100 * time.Millisecond})
+
+	now := time.Now()
+	b.play(1, 1, now)
+	// half intensity: on for half of the period
+	b.step(now)
+	b.step(now.Add(10 * time.Millisecond))
+	b.step(now.Add(25 * time.Millisecond))
+	b.step(now.Add(50 * time.Millisecond))
+	// the effect ends, the motor is off and the timer is disarmed
+	b.step(now.Add(75 * time.Millisecond))
+	b.step(now.Add(150 * time.Millisecond))
+	expect := []bool{true, false, true, false}
+	if len(dev.rumble) != len(expect) {
+		t.Fatalf("expected motor states %v, got %v", expect, dev.rumble)
+	}
+	for i := range expect {
+		if dev.rumble[i] != expect[i] {
+			t.Fatalf("expected motor states %v, got %v", expect, dev.rumble)
+		}
+	}
+	if !b.next.IsZero() {
+		t.Errorf("expected timer to be disarmed, next switch at %v", b.next)
+	}
+
+	// playing wakes the poller, which switches the motor
+	fb.Upload(vinput.Effect{ID: 2, Strong: 0xffff})
+	fb.Play(2, 1)
+	if _, _, err := b.Poll(); err != common.ErrWouldBlock {
+		t.Errorf("expected %v, got %v", common.ErrWouldBlock, err)
+	}
+	if !dev.rumble[len(dev.rumble)-1] {
+		t.Errorf("expected motor to be on after play")
+	}
+	b.Stop()
+	if dev.rumble[len(dev.rumble)-1] {
+		t.Errorf("expected motor to be off after stop")
+	}
+}
@@ -0,0 +1,74 @@
+package systemdutil
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// listenFDsStart is the first file descriptor passed by the service manager.
+const listenFDsStart = 3
+
+// Files returns the file descriptors passed by socket activation, named by FileDescriptorName
+// of the socket unit. The environment variables are unset, so child processes do not inherit
+// them. It returns nil if the process was not socket activated.
+func Files() []*os.File {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	files := make([]*os.File, n)
+	for i := range files {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		files[i] = os.NewFile(uintptr(fd), name)
+	}
+	return files
+}
+
+// Listener returns the first listening socket passed by socket activation, ok is false if the
+// process was not socket activated.
+func Listener() (ln net.Listener, ok bool, err error) {
+	files := Files()
+	if len(files) == 0 {
+		return nil, false, nil
+	}
+	for _, f := range files[1:] {
+		f.Close()
+	}
+	ln, err = net.FileListener(files[0])
+	files[0].Close()
+	if err != nil {
+		return nil, true, err
+	}
+	return ln, true, nil
+}
+
+// Listen returns the socket passed by socket activation, or listens on address of network
+// if the process was not socket activated.
+func Listen(network, address string) (net.Listener, error) {
+	ln, ok, err := Listener()
+	if ok {
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket passed by systemd: %w", err)
+		}
+		return ln, nil
+	}
+	return net.Listen(network, address)
+}
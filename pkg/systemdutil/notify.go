@@ -0,0 +1,91 @@
+// Package systemdutil integrates the commands of this module with systemd: readiness and
+// watchdog notifications (sd_notify), socket activation (sd_listen_fds) and unit files to
+// run the commands as (user) services. It does not depend on libsystemd, all functions are
+// no-ops if the process is not started by systemd.
+package systemdutil
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notify sends state (e.g. "READY=1") to the service manager. It returns false without error
+// if the process was not started with a notification socket.
+func Notify(state string) (bool, error) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return false, nil
+	}
+	// abstract sockets start with a null byte
+	if addr[0] == '@' {
+		addr = "\x00" + addr[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Ready notifies the service manager that the service finished starting up.
+func Ready() error {
+	_, err := Notify("READY=1")
+	return err
+}
+
+// Stopping notifies the service manager that the service is shutting down.
+func Stopping() error {
+	_, err := Notify("STOPPING=1")
+	return err
+}
+
+// Status sends a free-form status of the service, shown by systemctl status.
+func Status(status string) error {
+	_, err := Notify("STATUS=" + status)
+	return err
+}
+
+// WatchdogInterval returns the watchdog timeout configured by WatchdogSec, ok is false if the
+// watchdog is not enabled for this process.
+func WatchdogInterval() (interval time.Duration, ok bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// RunWatchdog pings the watchdog at half its interval until ctx is done. alive is called
+// before every ping, the ping is skipped if it returns false, so a hung service is restarted.
+// A nil alive always pings. If the watchdog is not enabled, RunWatchdog returns immediately.
+func RunWatchdog(ctx context.Context, alive func() bool) error {
+	interval, ok := WatchdogInterval()
+	if !ok {
+		return nil
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if alive != nil && !alive() {
+			continue
+		}
+		if _, err := Notify("WATCHDOG=1"); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package systemdutil
+
+import (
+	"flag"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if ok, err := Notify("READY=1"); ok || err != nil {
+		t.Fatalf("expected no notification without socket, got %v %v", ok, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	if err := Ready(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "READY=1" {
+		t.Fatalf("expected READY=1, got %q %v", buf[:n], err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "2000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	if interval, ok := WatchdogInterval(); !ok || interval != 2*time.Second {
+		t.Errorf("expected 2s, got %v %v", interval, ok)
+	}
+	t.Setenv("WATCHDOG_PID", "1")
+	if _, ok := WatchdogInterval(); ok {
+		t.Errorf("expected the watchdog of another process to be ignored")
+	}
+}
+
+func TestListenerNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	if ln, ok, err := Listener(); ln != nil || ok || err != nil {
+		t.Fatalf("expected no listener, got %v %v %v", ln, ok, err)
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Errorf("expected environment to be unset")
+	}
+}
+
+func TestUnit(t *testing.T) {
+	u := Unit{
+		Description:  "Wii remote mapper",
+		ExecStart:    []string{"/usr/bin/wiimap", "-daemon", "-name", "my 100% keyboard"},
+		Watchdog:     10 * time.Second,
+		ListenStream: "localhost:9250",
+	}
+	service := u.Service()
+	for _, want := range []string{
+		"Type=notify\n",
+		`ExecStart=/usr/bin/wiimap -daemon -name "my 100%% keyboard"` + "\n",
+		"WatchdogSec=10000ms\n",
+		"Requires=wiimap.socket\n",
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("expected %q in service:\n%s", want, service)
+		}
+	}
+	if socket := u.Socket(); !strings.Contains(socket, "ListenStream=localhost:9250\n") {
+		t.Errorf("unexpected socket:\n%s", socket)
+	}
+}
+
+func TestFlagArgs(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("unit", false, "")
+	fs.String("listen", "localhost:8080", "")
+	fs.Bool("debug", false, "")
+	if err := fs.Parse([]string{"-unit", "-listen", ":9000"}); err != nil {
+		t.Fatal(err)
+	}
+	if args := FlagArgs(fs, "unit"); !slices.Equal(args, []string{"-listen=:9000"}) {
+		t.Errorf("unexpected arguments: %v", args)
+	}
+}
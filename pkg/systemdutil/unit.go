@@ -0,0 +1,118 @@
+package systemdutil
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unit describes a service, see systemd.service(5). It renders the unit files to install the
+// service, e.g. in ~/.config/systemd/user.
+type Unit struct {
+	Description string
+	// ExecStart is the command line of the service
+	ExecStart []string
+	// Watchdog is the timeout of the watchdog (WatchdogSec), 0 disables the watchdog
+	Watchdog time.Duration
+	// ListenStream is the address of the socket unit activating the service, empty if the
+	// service is not socket activated
+	ListenStream string
+	// WantedBy is the target starting the service, default.target if empty
+	WantedBy string
+}
+
+// NewUnit returns a unit running the current executable with args.
+func NewUnit(description string, args ...string) (Unit, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return Unit{}, err
+	}
+	return Unit{Description: description, ExecStart: append([]string{exe}, args...)}, nil
+}
+
+// FlagArgs returns the flags set on the command line of fs as arguments, except the flags
+// named by skip. It is used to create a unit running the command with the same flags.
+func FlagArgs(fs *flag.FlagSet, skip ...string) []string {
+	var args []string
+	fs.Visit(func(f *flag.Flag) {
+		if !slices.Contains(skip, f.Name) {
+			args = append(args, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	return args
+}
+
+// String returns the unit files to install, the service followed by the socket if
+// ListenStream is set, each preceded by a comment with its file name.
+func (u Unit) String() string {
+	s := "# " + u.name() + ".service\n" + u.Service()
+	if u.ListenStream != "" {
+		s += "\n# " + u.name() + ".socket\n" + u.Socket()
+	}
+	return s
+}
+
+// Service returns the service unit file. The service notifies systemd when it is ready, see
+// Ready.
+func (u Unit) Service() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\n", u.Description)
+	if u.ListenStream != "" {
+		b.WriteString("Requires=" + u.name() + ".socket\n")
+	}
+	b.WriteString("\n[Service]\nType=notify\n")
+	args := make([]string, len(u.ExecStart))
+	for i, arg := range u.ExecStart {
+		args[i] = quoteArg(arg)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(args, " "))
+	b.WriteString("Restart=on-failure\n")
+	if u.Watchdog > 0 {
+		fmt.Fprintf(&b, "WatchdogSec=%dms\n", u.Watchdog.Milliseconds())
+	}
+	wantedBy := u.WantedBy
+	if wantedBy == "" {
+		wantedBy = "default.target"
+	}
+	if u.ListenStream == "" {
+		fmt.Fprintf(&b, "\n[Install]\nWantedBy=%s\n", wantedBy)
+	}
+	return b.String()
+}
+
+// Socket returns the socket unit file, it is empty if ListenStream is not set. The socket is
+// installed instead of the service.
+func (u Unit) Socket() string {
+	if u.ListenStream == "" {
+		return ""
+	}
+	return fmt.Sprintf("[Unit]\nDescription=%s (socket)\n\n[Socket]\nListenStream=%s\n\n[Install]\nWantedBy=sockets.target\n",
+		u.Description, u.ListenStream)
+}
+
+// name returns the unit name derived from the executable.
+func (u Unit) name() string {
+	if len(u.ExecStart) == 0 {
+		return "service"
+	}
+	name := u.ExecStart[0]
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// quoteArg quotes arg for a command line of a unit file, specifiers (%) and variables ($) are
+// escaped.
+func quoteArg(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"';\\") {
+		return arg
+	}
+	return strconv.Quote(arg)
+}
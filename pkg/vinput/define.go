@@ -0,0 +1,114 @@
+//go:build linux && (386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+// The ioctl requests are encoded as in asm-generic/ioctl.h, other architectures (mips, ppc and
+// sparc) use different direction and size bits and are not supported.
+
+package vinput
+
+// The constants of linux/uinput.h are generated into zuinput_linux.go, so the package builds
+// without cgo and kernel headers.
+//go:generate sh -c "go tool cgo -godefs types_uinput.go | gofmt > zuinput_linux.go"
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// ioctl direction bits of asm-generic/ioctl.h
+const (
+	iocWrite = 1
+	iocRead  = 2
+
+	iocNrShift   = 0
+	iocTypeShift = 8
+	iocSizeShift = 16
+	iocDirShift  = 30
+
+	uinputIoctlBase = 0x55 // 'U'
+)
+
+const (
+	uiSysnameLen = 64
+
+	uiDevCreate  = uinputIoctlBase<<iocTypeShift | 1<<iocNrShift
+	uiDevDestroy = uinputIoctlBase<<iocTypeShift | 2<<iocNrShift
+	uiDevSetup   = iocWrite<<iocDirShift | unsafe.Sizeof(uinputSetup{})<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 3<<iocNrShift
+	uiAbsSetup   = iocWrite<<iocDirShift | unsafe.Sizeof(absSetup{})<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 4<<iocNrShift
+	uiSysname    = iocRead<<iocDirShift | uiSysnameLen<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 44<<iocNrShift
+
+	uiSetEvBit   = iocWrite<<iocDirShift | unsafe.Sizeof(int32(0))<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 100<<iocNrShift
+	uiSetKeyBit  = iocWrite<<iocDirShift | unsafe.Sizeof(int32(0))<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 101<<iocNrShift
+	uiSetRelBit  = iocWrite<<iocDirShift | unsafe.Sizeof(int32(0))<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 102<<iocNrShift
+	uiSetAbsBit  = iocWrite<<iocDirShift | unsafe.Sizeof(int32(0))<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 103<<iocNrShift
+	uiSetLedBit  = iocWrite<<iocDirShift | unsafe.Sizeof(int32(0))<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 105<<iocNrShift
+	uiSetFFBit   = iocWrite<<iocDirShift | unsafe.Sizeof(int32(0))<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 107<<iocNrShift
+	uiSetPropBit = iocWrite<<iocDirShift | unsafe.Sizeof(int32(0))<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 110<<iocNrShift
+
+	uiBeginFFUpload = (iocRead|iocWrite)<<iocDirShift | unsafe.Sizeof(ffUpload{})<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 200<<iocNrShift
+	uiEndFFUpload   = iocWrite<<iocDirShift | unsafe.Sizeof(ffUpload{})<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 201<<iocNrShift
+	uiBeginFFErase  = (iocRead|iocWrite)<<iocDirShift | unsafe.Sizeof(ffErase{})<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 202<<iocNrShift
+	uiEndFFErase    = iocWrite<<iocDirShift | unsafe.Sizeof(ffErase{})<<iocSizeShift | uinputIoctlBase<<iocTypeShift | 203<<iocNrShift
+)
+
+type inputID struct {
+	Bustype uint16
+	Vendor  uint16
+	Product uint16
+	Version uint16
+}
+
+type uinputSetup struct {
+	id           inputID
+	name         [uiMaxNameSize]byte
+	ffEffectsMax uint32
+}
+
+type absInfo struct {
+	value      int32
+	minimum    int32
+	maximum    int32
+	fuzz       int32
+	flat       int32
+	resolution int32
+}
+
+type absSetup struct {
+	code    uint16
+	absinfo absInfo
+}
+
+// translated to go from input.h
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+func (iev *inputEvent) buffer() []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(iev)), unsafe.Sizeof(*iev))
+}
+
+// ffEffect is struct ff_effect, the union holds 24 bytes and the pointer of ff_periodic_effect.
+type ffEffect struct {
+	typ       uint16
+	id        int16
+	direction uint16
+	trigger   struct{ button, interval uint16 }
+	replay    struct{ length, delay uint16 }
+	_         uint16
+	u         [24 + unsafe.Sizeof(uintptr(0))]byte
+}
+
+type ffUpload struct {
+	requestID uint32
+	retval    int32
+	effect    ffEffect
+	old       ffEffect
+}
+
+type ffErase struct {
+	requestID uint32
+	retval    int32
+	effectID  uint32
+}
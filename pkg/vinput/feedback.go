@@ -0,0 +1,153 @@
+package vinput
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Led is an LED of a virtual keyboard which is set by the host.
+type Led uint16
+
+// LEDs supported by Keyboard.
+const (
+	LedNumLock    Led = ledNumLock
+	LedCapsLock   Led = ledCapsLock
+	LedScrollLock Led = ledScrollLock
+)
+
+// Effect is a force-feedback rumble effect uploaded by the host.
+type Effect struct {
+	// ID identifies the effect in Play and Erase
+	ID int16
+	// Length is the duration of the effect, 0 means infinite
+	Length time.Duration
+	// Delay is the time before the effect starts after being played
+	Delay time.Duration
+	// Strong and Weak are the magnitudes of the heavy and the light motor
+	Strong, Weak uint16
+}
+
+// Feedback receives the events sent by the host to a virtual device. Nil callbacks are ignored.
+type Feedback struct {
+	// LED is called when the host sets an LED
+	LED func(led Led, on bool)
+	// Upload is called when the host uploads or updates an effect, a returned error is passed
+	// to the host
+	Upload func(effect Effect) error
+	// Erase is called when the host removes an effect
+	Erase func(id int16) error
+	// Play is called when the host plays an effect count times or stops it if count is 0
+	Play func(id int16, count int32)
+	// Gain is called when the host sets the overall strength in the range 0..0xffff
+	Gain func(gain uint16)
+}
+
+// WithForceFeedback enables rumble effects requested by the host, up to effects at once. The
+// requests are received using ReadEvents.
+func WithForceFeedback(effects int) Option {
+	return func(c *config) {
+		c.ffEffects = effects
+	}
+}
+
+// enableFF enables rumble effects.
+func (dev *device) enableFF() error {
+	if err := dev.enable(uiSetEvBit, evFF); err != nil {
+		return err
+	}
+	return dev.enable(uiSetFFBit, ffRumble, ffGain)
+}
+
+// ReadEvents reads the events sent by the host and dispatches them to fb until the device is
+// closed, in which case nil is returned.
+func (dev *device) ReadEvents(fb Feedback) error {
+	var ev inputEvent
+	buf := ev.buffer()
+	for {
+		if _, err := io.ReadFull(dev.file, buf); err != nil {
+			if errors.Is(err, os.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("unable to read event: %w", err)
+		}
+		switch ev.Type {
+		case evLed:
+			if fb.LED != nil {
+				fb.LED(Led(ev.Code), ev.Value != 0)
+			}
+		case evFF:
+			if ev.Code == ffGain {
+				if fb.Gain != nil {
+					fb.Gain(uint16(ev.Value))
+				}
+			} else if fb.Play != nil {
+				fb.Play(int16(ev.Code), ev.Value)
+			}
+		case evUinput:
+			var err error
+			switch ev.Code {
+			case uiFFUpload:
+				err = dev.upload(uint32(ev.Value), fb.Upload)
+			case uiFFErase:
+				err = dev.erase(uint32(ev.Value), fb.Erase)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// errno converts err to the negative errno passed to the kernel.
+func errno(err error) int32 {
+	if err == nil {
+		return 0
+	}
+	var e syscall.Errno
+	if errors.As(err, &e) {
+		return -int32(e)
+	}
+	return -int32(syscall.EINVAL)
+}
+
+func (dev *device) upload(request uint32, handle func(Effect) error) error {
+	up := ffUpload{requestID: request}
+	if err := dev.ioctl(uiBeginFFUpload, uintptr(unsafe.Pointer(&up))); err != nil {
+		return fmt.Errorf("unable to begin upload: %w", err)
+	}
+	if up.effect.typ != ffRumble {
+		up.retval = -int32(syscall.EINVAL)
+	} else if handle != nil {
+		up.retval = errno(handle(Effect{
+			ID:     up.effect.id,
+			Length: time.Duration(up.effect.replay.length) * time.Millisecond,
+			Delay:  time.Duration(up.effect.replay.delay) * time.Millisecond,
+			Strong: binary.NativeEndian.Uint16(up.effect.u[0:]),
+			Weak:   binary.NativeEndian.Uint16(up.effect.u[2:]),
+		}))
+	}
+	if err := dev.ioctl(uiEndFFUpload, uintptr(unsafe.Pointer(&up))); err != nil {
+		return fmt.Errorf("unable to end upload: %w", err)
+	}
+	return nil
+}
+
+func (dev *device) erase(request uint32, handle func(int16) error) error {
+	er := ffErase{requestID: request}
+	if err := dev.ioctl(uiBeginFFErase, uintptr(unsafe.Pointer(&er))); err != nil {
+		return fmt.Errorf("unable to begin erase: %w", err)
+	}
+	if handle != nil {
+		er.retval = errno(handle(int16(er.effectID)))
+	}
+	if err := dev.ioctl(uiEndFFErase, uintptr(unsafe.Pointer(&er))); err != nil {
+		return fmt.Errorf("unable to end erase: %w", err)
+	}
+	return nil
+}
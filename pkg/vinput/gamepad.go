@@ -0,0 +1,59 @@
+package vinput
+
+// Axis is an absolute axis of a gamepad.
+type Axis uint16
+
+// Gamepad axes as used by the Linux gamepad specification.
+const (
+	AxisLeftX        Axis = absX
+	AxisLeftY        Axis = absY
+	AxisRightX       Axis = absRX
+	AxisRightY       Axis = absRY
+	AxisLeftTrigger  Axis = absZ
+	AxisRightTrigger Axis = absRZ
+	AxisHatX         Axis = absHat0X
+	AxisHatY         Axis = absHat0Y
+)
+
+// Gamepad is a virtual gamepad with absolute axes and buttons.
+type Gamepad struct {
+	device
+}
+
+// CreateGamepad creates a new virtual gamepad with axes and buttons.
+func CreateGamepad(name string, axes map[Axis]Range, buttons []Key, opts ...Option) (*Gamepad, error) {
+	dev, err := create(name, opts, func(dev *device) error {
+		if err := dev.enableKeys(buttons); err != nil {
+			return err
+		}
+		for axis, rng := range axes {
+			if err := dev.enableAbs(uint16(axis), rng); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Gamepad{dev}, nil
+}
+
+// Axis sets the value of axis.
+func (g *Gamepad) Axis(axis Axis, value int32) error {
+	if err := g.emit(evAbs, uint16(axis), value); err != nil {
+		return err
+	}
+	return g.sync()
+}
+
+// Stick sets the values of the axes x and y of a stick at once.
+func (g *Gamepad) Stick(x, y Axis, xval, yval int32) error {
+	if err := g.emit(evAbs, uint16(x), xval); err != nil {
+		return err
+	}
+	if err := g.emit(evAbs, uint16(y), yval); err != nil {
+		return err
+	}
+	return g.sync()
+}
@@ -0,0 +1,28 @@
+package vinput
+
+// Keyboard is a virtual keyboard supporting all keys. The host sets the lock LEDs, which are
+// received using ReadEvents.
+type Keyboard struct {
+	device
+}
+
+// CreateKeyboard creates a new virtual keyboard.
+func CreateKeyboard(name string, opts ...Option) (*Keyboard, error) {
+	dev, err := create(name, opts, func(dev *device) error {
+		keys := make([]Key, 0, keyMax)
+		for k := Key(keyReserved + 1); k < keyMax; k++ {
+			keys = append(keys, k)
+		}
+		if err := dev.enableKeys(keys); err != nil {
+			return err
+		}
+		if err := dev.enable(uiSetEvBit, evLed); err != nil {
+			return err
+		}
+		return dev.enable(uiSetLedBit, ledNumLock, ledCapsLock, ledScrollLock)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Keyboard{dev}, nil
+}
@@ -0,0 +1,64 @@
+package vinput
+
+import "errors"
+
+// Mouse is a virtual pointer device with relative motion, both wheels and buttons. It may
+// additionally report absolute positions.
+type Mouse struct {
+	device
+}
+
+// CreateMouse creates a new virtual mouse which reports buttons. If xabs and yabs are not empty,
+// the mouse also reports absolute positions within these ranges using Set.
+func CreateMouse(name string, xabs, yabs Range, buttons []Key, opts ...Option) (*Mouse, error) {
+	dev, err := create(name, opts, func(dev *device) error {
+		if err := dev.enableKeys(buttons); err != nil {
+			return err
+		}
+		if err := dev.enable(uiSetEvBit, evRel); err != nil {
+			return err
+		}
+		if err := dev.enable(uiSetRelBit, relX, relY, relWheel, relHWheel); err != nil {
+			return err
+		}
+		if err := dev.enableAbs(absX, xabs); err != nil {
+			return err
+		}
+		return dev.enableAbs(absY, yabs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Mouse{dev}, nil
+}
+
+// Move moves the pointer relative to its current position. Positive values move right and down.
+func (m *Mouse) Move(dx, dy int32) error {
+	if err := errors.Join(m.emit(evRel, relX, dx), m.emit(evRel, relY, dy)); err != nil {
+		return err
+	}
+	return m.sync()
+}
+
+// Set moves the pointer to an absolute position.
+func (m *Mouse) Set(x, y int32) error {
+	if err := errors.Join(m.emit(evAbs, absX, x), m.emit(evAbs, absY, y)); err != nil {
+		return err
+	}
+	return m.sync()
+}
+
+// Scroll moves the horizontal and vertical wheel.
+func (m *Mouse) Scroll(dx, dy int32) error {
+	var errs [2]error
+	if dx != 0 {
+		errs[0] = m.emit(evRel, relHWheel, dx)
+	}
+	if dy != 0 {
+		errs[1] = m.emit(evRel, relWheel, dy)
+	}
+	if err := errors.Join(errs[:]...); err != nil {
+		return err
+	}
+	return m.sync()
+}
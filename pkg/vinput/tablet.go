@@ -0,0 +1,81 @@
+package vinput
+
+// Tablet is a virtual pen tablet. Positions are absolute and desktops map the tablet 1:1 onto a
+// monitor. The pen hovers while it is in proximity and clicks by touching the surface.
+type Tablet struct {
+	device
+	inRange  bool
+	touching bool
+}
+
+// CreateTablet creates a new virtual pen tablet reporting positions within x and y.
+func CreateTablet(name string, x, y Range, opts ...Option) (*Tablet, error) {
+	dev, err := create(name, opts, func(dev *device) error {
+		if err := dev.enableKeys([]Key{btnTouch, btnToolPen}); err != nil {
+			return err
+		}
+		if err := dev.enable(uiSetPropBit, propDirect); err != nil {
+			return err
+		}
+		if err := dev.enableAbs(absX, x); err != nil {
+			return err
+		}
+		return dev.enableAbs(absY, y)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Tablet{device: dev}, nil
+}
+
+// Set moves the pen to x, y and brings it into proximity if it was not.
+func (t *Tablet) Set(x, y int32) error {
+	if err := t.emit(evAbs, absX, x); err != nil {
+		return err
+	}
+	if err := t.emit(evAbs, absY, y); err != nil {
+		return err
+	}
+	if !t.inRange {
+		if err := t.emit(evKey, btnToolPen, 1); err != nil {
+			return err
+		}
+		t.inRange = true
+	}
+	return t.sync()
+}
+
+// Touch sets whether the pen touches the surface. It has no effect while the pen is out of
+// proximity.
+func (t *Tablet) Touch(down bool) error {
+	if !t.inRange || t.touching == down {
+		return nil
+	}
+	var value int32
+	if down {
+		value = 1
+	}
+	if err := t.emit(evKey, btnTouch, value); err != nil {
+		return err
+	}
+	t.touching = down
+	return t.sync()
+}
+
+// Leave takes the pen out of proximity, a touch is released first.
+func (t *Tablet) Leave() error {
+	if !t.inRange {
+		return nil
+	}
+	if t.touching {
+		if err := t.emit(evKey, btnTouch, 0); err != nil {
+			return err
+		}
+		t.touching = false
+	}
+	if err := t.emit(evKey, btnToolPen, 0); err != nil {
+		return err
+	}
+	t.inRange = false
+	return t.sync()
+}
@@ -0,0 +1,60 @@
+package vinput
+
+// Touch is a virtual single-touch screen. Positions are absolute and map directly onto the screen.
+type Touch struct {
+	device
+}
+
+// CreateTouch creates a new virtual touch screen reporting positions within x and y.
+func CreateTouch(name string, x, y Range, opts ...Option) (*Touch, error) {
+	dev, err := create(name, opts, func(dev *device) error {
+		if err := dev.enableKeys([]Key{btnTouch}); err != nil {
+			return err
+		}
+		if err := dev.enable(uiSetPropBit, propDirect); err != nil {
+			return err
+		}
+		if err := dev.enableAbs(absX, x); err != nil {
+			return err
+		}
+		return dev.enableAbs(absY, y)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Touch{dev}, nil
+}
+
+// Down touches the screen at x, y.
+func (t *Touch) Down(x, y int32) error {
+	if err := t.position(x, y); err != nil {
+		return err
+	}
+	if err := t.emit(evKey, btnTouch, 1); err != nil {
+		return err
+	}
+	return t.sync()
+}
+
+// Move moves a touch to x, y.
+func (t *Touch) Move(x, y int32) error {
+	if err := t.position(x, y); err != nil {
+		return err
+	}
+	return t.sync()
+}
+
+// Up releases the touch.
+func (t *Touch) Up() error {
+	if err := t.emit(evKey, btnTouch, 0); err != nil {
+		return err
+	}
+	return t.sync()
+}
+
+func (t *Touch) position(x, y int32) error {
+	if err := t.emit(evAbs, absX, x); err != nil {
+		return err
+	}
+	return t.emit(evAbs, absY, y)
+}
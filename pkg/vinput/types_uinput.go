@@ -0,0 +1,63 @@
+//go:build ignore
+
+// Input for cgo -godefs, see define.go. Only architecture-independent constants are
+// generated, the ioctl requests are computed from the Go structures.
+
+package vinput
+
+// #include <linux/uinput.h>
+import "C"
+
+const (
+	uiMaxNameSize = C.UINPUT_MAX_NAME_SIZE
+
+	uiFFUpload = C.UI_FF_UPLOAD
+	uiFFErase  = C.UI_FF_ERASE
+
+	busUSB = C.BUS_USB
+
+	evSyn = C.EV_SYN
+	evKey = C.EV_KEY
+	evRel = C.EV_REL
+	evAbs = C.EV_ABS
+	evLed = C.EV_LED
+	evFF  = C.EV_FF
+	evRep = C.EV_REP
+
+	evUinput = C.EV_UINPUT
+
+	synReport = C.SYN_REPORT
+
+	relX      = C.REL_X
+	relY      = C.REL_Y
+	relHWheel = C.REL_HWHEEL
+	relWheel  = C.REL_WHEEL
+
+	absX     = C.ABS_X
+	absY     = C.ABS_Y
+	absZ     = C.ABS_Z
+	absRX    = C.ABS_RX
+	absRY    = C.ABS_RY
+	absRZ    = C.ABS_RZ
+	absHat0X = C.ABS_HAT0X
+	absHat0Y = C.ABS_HAT0Y
+
+	ledNumLock    = C.LED_NUML
+	ledCapsLock   = C.LED_CAPSL
+	ledScrollLock = C.LED_SCROLLL
+
+	repDelay  = C.REP_DELAY
+	repPeriod = C.REP_PERIOD
+
+	ffRumble = C.FF_RUMBLE
+	ffGain   = C.FF_GAIN
+
+	propPointer = C.INPUT_PROP_POINTER
+	propDirect  = C.INPUT_PROP_DIRECT
+
+	keyReserved = C.KEY_RESERVED
+	keyMax      = C.KEY_MAX
+
+	btnTouch   = C.BTN_TOUCH
+	btnToolPen = C.BTN_TOOL_PEN
+)
@@ -0,0 +1,261 @@
+// Package vinput creates virtual input devices using uinput. It provides keyboards, mice,
+// gamepads, pen tablets and touch screens which are fed by the mapping tools of this module.
+package vinput
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Key is a key or button code as defined in input-event-codes.h. The codes are the same as the
+// ones of github.com/friedelschoen/go-uinput, its constants can be converted to Key.
+type Key uint16
+
+// Range describes an absolute axis. An empty range (Min == Max) disables the axis.
+type Range struct {
+	Min, Max int
+	// Fuzz filters noise, values within Fuzz of the previous value are dropped by the kernel
+	Fuzz int
+	// Flat is the size of the deadzone around the center
+	Flat int
+	// Res is the resolution in units per millimeter
+	Res int
+}
+
+func (rng Range) empty() bool {
+	return rng.Min == rng.Max
+}
+
+// ID identifies the virtual device towards userspace.
+type ID struct {
+	Bustype, Vendor, Product, Version uint16
+}
+
+type config struct {
+	path      string
+	id        ID
+	ffEffects int
+	// key repeat, disabled if repDelay is 0
+	repDelay, repPeriod time.Duration
+}
+
+var defaultConfig = config{
+	path: "/dev/uinput",
+	id: ID{
+		Bustype: busUSB,
+		Vendor:  0xbeef,
+		Product: 0xdead,
+	},
+}
+
+// Option configures the creation of a device.
+type Option func(*config)
+
+// WithPath sets the location of the uinput device, /dev/uinput by default.
+func WithPath(path string) Option {
+	return func(c *config) {
+		c.path = path
+	}
+}
+
+// WithID sets the bus, vendor, product and version reported by the device.
+func WithID(id ID) Option {
+	return func(c *config) {
+		c.id = id
+	}
+}
+
+// WithRepeat lets the kernel repeat held keys after delay and then every period, like a real
+// keyboard. Applications receive EV_KEY events with value 2 for the repeats.
+func WithRepeat(delay, period time.Duration) Option {
+	return func(c *config) {
+		c.repDelay = delay
+		c.repPeriod = period
+	}
+}
+
+// device is the common part of all virtual devices.
+type device struct {
+	file *os.File
+}
+
+// create opens a uinput device named name, lets setup register its capabilities and creates it.
+func create(name string, opts []Option, setup func(dev *device) error) (device, error) {
+	cfg := defaultConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if name == "" {
+		return device{}, errors.New("device name may not be empty")
+	}
+	if len(name) >= uiMaxNameSize {
+		return device{}, fmt.Errorf("device name %s is too long (maximum of %d characters allowed)", name, uiMaxNameSize-1)
+	}
+
+	file, err := os.OpenFile(cfg.path, os.O_RDWR|syscall.O_NONBLOCK, 0660)
+	if err != nil {
+		return device{}, fmt.Errorf("could not open uinput: %w", err)
+	}
+	dev := device{file}
+
+	if err := dev.enable(uiSetEvBit, evSyn); err != nil {
+		file.Close()
+		return device{}, err
+	}
+	if err := setup(&dev); err != nil {
+		file.Close()
+		return device{}, err
+	}
+
+	if cfg.ffEffects > 0 {
+		if err := dev.enableFF(); err != nil {
+			file.Close()
+			return device{}, err
+		}
+	}
+	if cfg.repDelay > 0 {
+		if err := dev.enable(uiSetEvBit, evRep); err != nil {
+			file.Close()
+			return device{}, err
+		}
+	}
+
+	us := uinputSetup{id: inputID(cfg.id), ffEffectsMax: uint32(cfg.ffEffects)}
+	copy(us.name[:], name)
+	if err := dev.ioctl(uiDevSetup, uintptr(unsafe.Pointer(&us))); err != nil {
+		file.Close()
+		return device{}, fmt.Errorf("failed to setup device: %w", err)
+	}
+	if err := dev.ioctl(uiDevCreate, 0); err != nil {
+		file.Close()
+		return device{}, fmt.Errorf("failed to create device: %w", err)
+	}
+	// give udev some time to pick up the device before events are emitted
+	time.Sleep(200 * time.Millisecond)
+
+	if cfg.repDelay > 0 {
+		// the kernel repeats with its default rate until it is set
+		if err := dev.emit(evRep, repDelay, int32(cfg.repDelay.Milliseconds())); err != nil {
+			dev.Close()
+			return device{}, err
+		}
+		if err := dev.emit(evRep, repPeriod, int32(cfg.repPeriod.Milliseconds())); err != nil {
+			dev.Close()
+			return device{}, err
+		}
+	}
+	return dev, nil
+}
+
+func (dev *device) ioctl(cmd, ptr uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dev.file.Fd(), cmd, ptr)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// enable sets the capability bits codes using cmd (one of uiSet*Bit).
+func (dev *device) enable(cmd uintptr, codes ...uint16) error {
+	for _, code := range codes {
+		if err := dev.ioctl(cmd, uintptr(code)); err != nil {
+			return fmt.Errorf("failed to enable code %d: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// enableKeys enables EV_KEY and all keys.
+func (dev *device) enableKeys(keys []Key) error {
+	if err := dev.enable(uiSetEvBit, evKey); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := dev.enable(uiSetKeyBit, uint16(k)); err != nil {
+			return fmt.Errorf("failed to enable key %v: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// enableAbs enables the absolute axis code with range rng, empty ranges are skipped.
+func (dev *device) enableAbs(code uint16, rng Range) error {
+	if rng.empty() {
+		return nil
+	}
+	if err := dev.enable(uiSetEvBit, evAbs); err != nil {
+		return err
+	}
+	if err := dev.enable(uiSetAbsBit, code); err != nil {
+		return err
+	}
+	s := absSetup{
+		code: code,
+		absinfo: absInfo{
+			minimum:    int32(rng.Min),
+			maximum:    int32(rng.Max),
+			fuzz:       int32(rng.Fuzz),
+			flat:       int32(rng.Flat),
+			resolution: int32(rng.Res),
+		},
+	}
+	if err := dev.ioctl(uiAbsSetup, uintptr(unsafe.Pointer(&s))); err != nil {
+		return fmt.Errorf("failed to setup axis %d: %w", code, err)
+	}
+	return nil
+}
+
+func (dev *device) emit(typ, code uint16, value int32) error {
+	ev := inputEvent{Type: typ, Code: code, Value: value}
+	if _, err := dev.file.Write(ev.buffer()); err != nil {
+		return fmt.Errorf("unable to write event: %w", err)
+	}
+	return nil
+}
+
+func (dev *device) sync() error {
+	return dev.emit(evSyn, synReport, 0)
+}
+
+// Key sets the state of key.
+func (dev *device) Key(key Key, pressed bool) error {
+	var value int32
+	if pressed {
+		value = 1
+	}
+	if err := dev.emit(evKey, uint16(key), value); err != nil {
+		return err
+	}
+	return dev.sync()
+}
+
+// Sysname returns the name of the device in sysfs, e.g. "input42".
+func (dev *device) Sysname() (string, error) {
+	var name [uiSysnameLen]byte
+	if err := dev.ioctl(uiSysname, uintptr(unsafe.Pointer(&name[0]))); err != nil {
+		return "", err
+	}
+	if n := bytes.IndexByte(name[:], 0); n >= 0 {
+		return string(name[:n]), nil
+	}
+	return string(name[:]), nil
+}
+
+// Syspath returns the sysfs path of the device, which lays at /sys/devices/virtual/input/<sysname>.
+func (dev *device) Syspath() (string, error) {
+	name, err := dev.Sysname()
+	if err != nil {
+		return "", err
+	}
+	return "/sys/devices/virtual/input/" + name, nil
+}
+
+// Close destroys the device.
+func (dev *device) Close() error {
+	return errors.Join(dev.ioctl(uiDevDestroy, 0), dev.file.Close())
+}
@@ -0,0 +1,51 @@
+package vinput
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"unsafe"
+)
+
+// values of linux/uinput.h
+func TestIoctlRequests(t *testing.T) {
+	ffEffectSize, ffUploadRequest := uintptr(44), uintptr(0xc06055c8)
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		ffEffectSize, ffUploadRequest = 48, 0xc06855c8
+	}
+	if size := unsafe.Sizeof(ffEffect{}); size != ffEffectSize {
+		t.Errorf("expected sizeof(struct ff_effect) = %d, got %d", ffEffectSize, size)
+	}
+
+	tests := []struct {
+		name      string
+		got, want uintptr
+	}{
+		{"UI_DEV_CREATE", uiDevCreate, 0x5501},
+		{"UI_DEV_SETUP", uiDevSetup, 0x405c5503},
+		{"UI_ABS_SETUP", uiAbsSetup, 0x401c5504},
+		{"UI_GET_SYSNAME(64)", uiSysname, 0x8040552c},
+		{"UI_SET_EVBIT", uiSetEvBit, 0x40045564},
+		{"UI_BEGIN_FF_UPLOAD", uiBeginFFUpload, ffUploadRequest},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("expected %s = %#x, got %#x", test.name, test.want, test.got)
+		}
+	}
+}
+
+func TestBuildWithoutCgo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	cmd := exec.Command(gobin, "build", "-o", os.DevNull, ".")
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm64")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("unable to build without cgo: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,123 @@
+package wiimote
+
+// Source describes the origin of an event. It is used to label events when events of
+// multiple devices are merged into a single stream.
+type Source struct {
+	// Device which emitted the event, may be nil if unknown
+	Device Device
+	// UniqueID is the Bluetooth address of the device, empty if unknown
+	UniqueID string
+	// Feature which emitted the event, 0 if the event is not bound to a feature
+	Feature FeatureKind
+	// Player is the player number assigned to the device, 0 if unassigned
+	Player int
+}
+
+type sourcedEvent struct {
+	Event
+	source Source
+}
+
+func (ev sourcedEvent) Source() Source {
+	return ev.source
+}
+
+func (ev sourcedEvent) Device() Device {
+	if ev.source.Device != nil {
+		return ev.source.Device
+	}
+	return ev.Event.Device()
+}
+
+// embeddedEvent returns a pointer to the Event embedded in ev or nil if ev is unknown.
+func embeddedEvent(ev Event) *Event {
+	switch ev := ev.(type) {
+	case *EventKey:
+		return &ev.Event
+	case *EventAccel:
+		return &ev.Event
+	case *EventIR:
+		return &ev.Event
+	case *EventBalanceBoard:
+		return &ev.Event
+	case *EventMotionPlus:
+		return &ev.Event
+	case *EventProControllerKey:
+		return &ev.Event
+	case *EventProControllerMove:
+		return &ev.Event
+	case *EventWatch:
+		return &ev.Event
+	case *EventExtensionConnected:
+		return &ev.Event
+	case *EventExtensionDisconnected:
+		return &ev.Event
+	case *EventClassicControllerKey:
+		return &ev.Event
+	case *EventClassicControllerMove:
+		return &ev.Event
+	case *EventNunchukKey:
+		return &ev.Event
+	case *EventNunchukMove:
+		return &ev.Event
+	case *EventDrumsKey:
+		return &ev.Event
+	case *EventDrumsMove:
+		return &ev.Event
+	case *EventGuitarKey:
+		return &ev.Event
+	case *EventGuitarMove:
+		return &ev.Event
+	case *EventFeature:
+		return &ev.Event
+	case *EventFeatureOpened:
+		return &ev.Event
+	case *EventError:
+		return &ev.Event
+	case *EventGone:
+		return &ev.Event
+	}
+	return nil
+}
+
+// WithSource attaches src to ev, which can be retrieved using EventSource. The event is
+// modified in-place and returned for convenience, its type is not changed so type-switches
+// keep working.
+func WithSource(ev Event, src Source) Event {
+	inner := embeddedEvent(ev)
+	if inner == nil {
+		return ev
+	}
+	if s, ok := (*inner).(sourcedEvent); ok {
+		*inner = s.Event
+	}
+	*inner = sourcedEvent{Event: *inner, source: src}
+	return ev
+}
+
+// EventSource returns the origin of ev. If no source was attached using WithSource,
+// the source is derived from the feature of the event.
+func EventSource(ev Event) Source {
+	if inner := embeddedEvent(ev); inner != nil {
+		if s, ok := (*inner).(sourcedEvent); ok {
+			return s.source
+		}
+	}
+
+	var src Source
+	if ev == nil {
+		return src
+	}
+	src.Device = ev.Device()
+	if f := ev.Feature(); f != nil {
+		src.Feature = f.Kind()
+		if src.Device == nil {
+			src.Device = f.Device()
+		}
+	}
+	if src.Device != nil {
+		src.UniqueID, _ = src.Device.UniqueID()
+		src.Player = src.Device.Player()
+	}
+	return src
+}
@@ -0,0 +1,42 @@
+package wiimote
+
+import (
+	"testing"
+	"time"
+)
+
+type testEvent struct{}
+
+func (testEvent) Feature() Feature     { return nil }
+func (testEvent) Device() Device       { return nil }
+func (testEvent) Timestamp() time.Time { return time.Time{} }
+
+func TestEventSourceDerived(t *testing.T) {
+	ev := &EventKey{Event: testEvent{}, Code: KeyA}
+	if src := EventSource(ev); src != (Source{}) {
+		t.Errorf("expected empty source, got %+v", src)
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	src := Source{UniqueID: "00:11:22:33:44:55", Feature: FeatureNunchuck, Player: 2}
+
+	var ev Event = &EventNunchukKey{EventKey{Event: testEvent{}, Code: KeyC}}
+	ev = WithSource(ev, src)
+	if _, ok := ev.(*EventNunchukKey); !ok {
+		t.Fatalf("WithSource changed event type to %T", ev)
+	}
+	if got := EventSource(ev); got != src {
+		t.Errorf("expected %+v, got %+v", src, got)
+	}
+
+	// tagging again replaces the source instead of nesting
+	src.Player = 3
+	WithSource(ev, src)
+	if got := EventSource(ev); got != src {
+		t.Errorf("expected %+v, got %+v", src, got)
+	}
+	if _, ok := ev.(*EventNunchukKey).Event.(sourcedEvent).Event.(testEvent); !ok {
+		t.Errorf("source is nested")
+	}
+}
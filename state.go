@@ -0,0 +1,166 @@
+package wiimote
+
+import "time"
+
+// KeySet is a set of keys.
+type KeySet uint64
+
+// Has returns whether k is in s.
+func (s KeySet) Has(k Key) bool {
+	return s&(1<<k) != 0
+}
+
+// Set adds k to s if pressed and removes it otherwise.
+func (s *KeySet) Set(k Key, pressed bool) {
+	if pressed {
+		*s |= 1 << k
+	} else {
+		*s &^= 1 << k
+	}
+}
+
+// State is a snapshot of the latest values of all opened features, see Device.State. Values of
+// features which never reported are zero.
+type State struct {
+	// Time of the last event
+	Time time.Time `json:"time"`
+	// Keys held on the core feature
+	Keys KeySet `json:"keys"`
+	// ExtensionKeys held on an extension or the pro controller
+	ExtensionKeys KeySet `json:"extension_keys"`
+	// Accel of the remote
+	Accel Vec3 `json:"accel"`
+	// IR slots of the camera
+	IR [4]IRSlot `json:"ir"`
+	// MotionPlus rotation speed
+	MotionPlus Vec3 `json:"motion_plus"`
+	// Weights of the balance board
+	Weights [4]int32 `json:"weights"`
+	// Sticks of an extension or the pro controller. Extensions with one stick (nunchuk, guitar
+	// or drums) report it as the first stick.
+	Sticks [2]Vec2 `json:"sticks"`
+	// Shoulders are the analog triggers of the classic controller
+	Shoulders [2]int32 `json:"shoulders"`
+	// NunchukAccel of the nunchuk
+	NunchukAccel Vec3 `json:"nunchuk_accel"`
+}
+
+// Update applies ev to s. Events without state are ignored.
+func (s *State) Update(ev Event) {
+	if ev == nil {
+		return
+	}
+	switch ev := ev.(type) {
+	case *EventKey:
+		s.Keys.Set(ev.Code, ev.Pressed)
+	case *EventProControllerKey:
+		s.ExtensionKeys.Set(ev.Code, ev.Pressed)
+	case *EventClassicControllerKey:
+		s.ExtensionKeys.Set(ev.Code, ev.Pressed)
+	case *EventNunchukKey:
+		s.ExtensionKeys.Set(ev.Code, ev.Pressed)
+	case *EventDrumsKey:
+		s.ExtensionKeys.Set(ev.Code, ev.Pressed)
+	case *EventGuitarKey:
+		s.ExtensionKeys.Set(ev.Code, ev.Pressed)
+	case *EventAccel:
+		s.Accel = ev.Accel
+	case *EventIR:
+		s.IR = ev.Slots
+	case *EventMotionPlus:
+		s.MotionPlus = ev.Speed
+	case *EventBalanceBoard:
+		s.Weights = ev.Weights
+	case *EventProControllerMove:
+		s.Sticks = ev.Sticks
+	case *EventClassicControllerMove:
+		s.Sticks = [2]Vec2{ev.StickLeft, ev.StickRight}
+		s.Shoulders = [2]int32{ev.ShoulderLeft, ev.ShoulderRight}
+	case *EventNunchukMove:
+		s.Sticks[0] = ev.Stick
+		s.NunchukAccel = ev.Accel
+	case *EventDrumsMove:
+		s.Sticks[0] = ev.Pad
+	case *EventGuitarMove:
+		s.Sticks[0] = ev.Stick
+	default:
+		return
+	}
+	s.Time = ev.Timestamp()
+}
+
+// KeyEvent returns the EventKey of a key event of any feature, ok is false if ev is no key
+// event.
+func KeyEvent(ev Event) (key *EventKey, ok bool) {
+	switch ev := ev.(type) {
+	case *EventKey:
+		return ev, true
+	case *EventProControllerKey:
+		return &ev.EventKey, true
+	case *EventClassicControllerKey:
+		return &ev.EventKey, true
+	case *EventNunchukKey:
+		return &ev.EventKey, true
+	case *EventDrumsKey:
+		return &ev.EventKey, true
+	case *EventGuitarKey:
+		return &ev.EventKey, true
+	}
+	return nil, false
+}
+
+// KeyTracker tracks held keys and detects presses and releases per frame of a game loop. Feed
+// all events of a frame to Update and call Next at the end of the frame. Keys of all features
+// are tracked in the same set.
+type KeyTracker struct {
+	held     KeySet
+	pressed  KeySet
+	released KeySet
+}
+
+// Update processes the key events of any feature and reports whether ev was a key event.
+func (t *KeyTracker) Update(ev Event) bool {
+	key, ok := KeyEvent(ev)
+	if !ok {
+		return false
+	}
+	t.held.Set(key.Code, key.Pressed)
+	if key.Pressed {
+		t.pressed.Set(key.Code, true)
+	} else {
+		t.released.Set(key.Code, true)
+	}
+	return true
+}
+
+// Next starts a new frame, dropping the presses and releases of the last frame.
+func (t *KeyTracker) Next() {
+	t.pressed = 0
+	t.released = 0
+}
+
+// Reset releases all keys.
+func (t *KeyTracker) Reset() {
+	*t = KeyTracker{}
+}
+
+// Held returns the held keys.
+func (t *KeyTracker) Held() KeySet {
+	return t.held
+}
+
+// IsPressed returns whether k is held.
+func (t *KeyTracker) IsPressed(k Key) bool {
+	return t.held.Has(k)
+}
+
+// JustPressed returns whether k was pressed in this frame. A key pressed and released within
+// the frame is reported as both pressed and released.
+func (t *KeyTracker) JustPressed(k Key) bool {
+	return t.pressed.Has(k)
+}
+
+// JustReleased returns whether k was released in this frame.
+func (t *KeyTracker) JustReleased(k Key) bool {
+	return t.released.Has(k)
+}
@@ -0,0 +1,53 @@
+package wiimote
+
+import "testing"
+
+func TestStateUpdate(t *testing.T) {
+	var s State
+	s.Update(&EventKey{Event: testEvent{}, Code: KeyA, Pressed: true})
+	s.Update(&EventKey{Event: testEvent{}, Code: KeyB, Pressed: true})
+	s.Update(&EventKey{Event: testEvent{}, Code: KeyA, Pressed: false})
+	s.Update(&EventNunchukKey{EventKey{Event: testEvent{}, Code: KeyC, Pressed: true}})
+	s.Update(&EventNunchukMove{Event: testEvent{}, Stick: Vec2{X: 10, Y: -5}, Accel: Vec3{Z: 100}})
+	s.Update(&EventAccel{Event: testEvent{}, Accel: Vec3{X: 1, Y: 2, Z: 3}})
+
+	if s.Keys.Has(KeyA) || !s.Keys.Has(KeyB) {
+		t.Errorf("expected only B held, got %b", s.Keys)
+	}
+	if !s.ExtensionKeys.Has(KeyC) || s.Keys.Has(KeyC) {
+		t.Errorf("expected C held on the extension, got %b and %b", s.Keys, s.ExtensionKeys)
+	}
+	if s.Sticks[0] != (Vec2{X: 10, Y: -5}) || s.NunchukAccel != (Vec3{Z: 100}) {
+		t.Errorf("unexpected nunchuk state %v %v", s.Sticks[0], s.NunchukAccel)
+	}
+	if s.Accel != (Vec3{X: 1, Y: 2, Z: 3}) {
+		t.Errorf("unexpected accel %v", s.Accel)
+	}
+}
+
+func TestKeyTracker(t *testing.T) {
+	var kt KeyTracker
+	kt.Update(&EventKey{Event: testEvent{}, Code: KeyA, Pressed: true})
+	kt.Update(&EventNunchukKey{EventKey{Event: testEvent{}, Code: KeyC, Pressed: true}})
+	kt.Update(&EventNunchukKey{EventKey{Event: testEvent{}, Code: KeyC, Pressed: false}})
+	if kt.Update(&EventAccel{Event: testEvent{}}) {
+		t.Errorf("expected accel event to be ignored")
+	}
+
+	if !kt.IsPressed(KeyA) || !kt.JustPressed(KeyA) || kt.JustReleased(KeyA) {
+		t.Errorf("expected A just pressed")
+	}
+	// a tap within a frame is not lost
+	if kt.IsPressed(KeyC) || !kt.JustPressed(KeyC) || !kt.JustReleased(KeyC) {
+		t.Errorf("expected C pressed and released")
+	}
+
+	kt.Next()
+	if !kt.IsPressed(KeyA) || kt.JustPressed(KeyA) {
+		t.Errorf("expected A held but not just pressed in the next frame")
+	}
+	kt.Update(&EventKey{Event: testEvent{}, Code: KeyA, Pressed: false})
+	if kt.IsPressed(KeyA) || !kt.JustReleased(KeyA) {
+		t.Errorf("expected A just released")
+	}
+}
@@ -0,0 +1,133 @@
+package wiimote
+
+import (
+	"math"
+
+	"github.com/friedelschoen/go-wiimote/pkg/geom"
+)
+
+// FVec2 represents a 2D floating point vector to X and Y, see geom.FVec2 for its arithmetic.
+type FVec2 = geom.FVec2
+
+// StickCalibration describes the measured range of an analog stick.
+type StickCalibration struct {
+	Center Vec2 `json:"center"`
+	Min    Vec2 `json:"min"`
+	Max    Vec2 `json:"max"`
+	// Deadzone is the radius around the center in the range 0..1 which is reported as 0
+	Deadzone float64 `json:"deadzone"`
+}
+
+// ProControllerStick is the nominal range of the Pro Controller sticks as reported by the kernel.
+var ProControllerStick = StickCalibration{
+	Min:      Vec2{X: -0x400, Y: -0x400},
+	Max:      Vec2{X: 0x400, Y: 0x400},
+	Deadzone: 0.1,
+}
+
+// NunchukStick is the nominal range of the Nunchuk stick as reported by the kernel.
+var NunchukStick = StickCalibration{
+	Min:      Vec2{X: -100, Y: -100},
+	Max:      Vec2{X: 100, Y: 100},
+	Deadzone: 0.1,
+}
+
+// ClassicControllerStick is the nominal range of the left stick of the Classic Controller as
+// reported by the kernel. The sticks of guitars and drums have the same range.
+var ClassicControllerStick = StickCalibration{
+	Min:      Vec2{X: -30, Y: -30},
+	Max:      Vec2{X: 30, Y: 30},
+	Deadzone: 0.1,
+}
+
+// ClassicControllerRightStick is the nominal range of the right stick of the Classic
+// Controller, which has half the resolution of the left stick.
+var ClassicControllerRightStick = StickCalibration{
+	Min:      Vec2{X: -15, Y: -15},
+	Max:      Vec2{X: 15, Y: 15},
+	Deadzone: 0.1,
+}
+
+func normalizeAxis(v, center, min, max int32) float64 {
+	switch {
+	case v > center && max > center:
+		return float64(v-center) / float64(max-center)
+	case v < center && center > min:
+		return float64(v-center) / float64(center-min)
+	}
+	return 0
+}
+
+// Normalize returns v relative to the center of the calibration in the range -1..1 per axis.
+// The deadzone is circular, values outside the deadzone are rescaled so they start at 0 at
+// its edge. The length of the result never exceeds 1.
+func (c StickCalibration) Normalize(v Vec2) FVec2 {
+	res := FVec2{
+		X: normalizeAxis(v.X, c.Center.X, c.Min.X, c.Max.X),
+		Y: normalizeAxis(v.Y, c.Center.Y, c.Min.Y, c.Max.Y),
+	}
+	mag := math.Hypot(res.X, res.Y)
+	if mag <= c.Deadzone || mag == 0 {
+		return FVec2{}
+	}
+	scale := (min(mag, 1) - c.Deadzone) / (1 - c.Deadzone) / mag
+	res.X *= scale
+	res.Y *= scale
+	return res
+}
+
+// StickCalibrator records a StickCalibration. Feed samples of the released stick to AddCenter
+// to measure the center drift and samples of the stick moved along its edges to AddExtent.
+type StickCalibrator struct {
+	sum      FVec2
+	count    int
+	min, max Vec2
+	extent   bool
+}
+
+// AddCenter adds a sample of the released stick.
+func (c *StickCalibrator) AddCenter(v Vec2) {
+	c.sum.X += float64(v.X)
+	c.sum.Y += float64(v.Y)
+	c.count++
+}
+
+// AddExtent adds a sample of the stick moved to its edge.
+func (c *StickCalibrator) AddExtent(v Vec2) {
+	if !c.extent {
+		c.min, c.max = v, v
+		c.extent = true
+		return
+	}
+	c.min = Vec2{X: min(c.min.X, v.X), Y: min(c.min.Y, v.Y)}
+	c.max = Vec2{X: max(c.max.X, v.X), Y: max(c.max.Y, v.Y)}
+}
+
+// Calibration returns the recorded calibration with the given deadzone. Values that were not
+// recorded are taken from def.
+func (c *StickCalibrator) Calibration(def StickCalibration, deadzone float64) StickCalibration {
+	cal := def
+	cal.Deadzone = deadzone
+	if c.count > 0 {
+		cal.Center = Vec2{
+			X: int32(math.Round(c.sum.X / float64(c.count))),
+			Y: int32(math.Round(c.sum.Y / float64(c.count))),
+		}
+	}
+	if c.extent {
+		cal.Min, cal.Max = c.min, c.max
+	}
+	return cal
+}
+
+// Normalized returns the position of both sticks in the range -1..1 using the nominal range
+// of the Pro Controller, see ProControllerStick.
+func (ev *EventProControllerMove) Normalized() [2]FVec2 {
+	return ev.NormalizedWith(ProControllerStick, ProControllerStick)
+}
+
+// NormalizedWith returns the position of both sticks in the range -1..1 using the calibrations
+// of the left and right stick.
+func (ev *EventProControllerMove) NormalizedWith(left, right StickCalibration) [2]FVec2 {
+	return [2]FVec2{left.Normalize(ev.Sticks[0]), right.Normalize(ev.Sticks[1])}
+}
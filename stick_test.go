@@ -0,0 +1,65 @@
+package wiimote
+
+import (
+	"math"
+	"testing"
+)
+
+func almostFVec2(a, b FVec2) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestStickNormalize(t *testing.T) {
+	cal := StickCalibration{
+		Center:   Vec2{X: 10, Y: 0},
+		Min:      Vec2{X: -90, Y: -100},
+		Max:      Vec2{X: 210, Y: 100},
+		Deadzone: 0.2,
+	}
+	tests := []struct {
+		in     Vec2
+		expect FVec2
+	}{
+		{Vec2{X: 10, Y: 0}, FVec2{}},
+		{Vec2{X: 20, Y: 10}, FVec2{}},         // inside deadzone
+		{Vec2{X: 210, Y: 0}, FVec2{X: 1}},     // positive extent
+		{Vec2{X: -90, Y: 0}, FVec2{X: -1}},    // negative extent
+		{Vec2{X: 110, Y: 0}, FVec2{X: 0.375}}, // (0.5-0.2)/0.8
+		{Vec2{X: 10, Y: 300}, FVec2{Y: 1}},    // clamped
+	}
+	for _, tc := range tests {
+		if got := cal.Normalize(tc.in); !almostFVec2(got, tc.expect) {
+			t.Errorf("normalize %v: expected %v, got %v", tc.in, tc.expect, got)
+		}
+	}
+
+	diag := cal.Normalize(Vec2{X: 210, Y: 100})
+	if mag := math.Hypot(diag.X, diag.Y); math.Abs(mag-1) > 1e-9 {
+		t.Errorf("expected diagonal to be limited to 1, got %v", mag)
+	}
+}
+
+func TestStickCalibrator(t *testing.T) {
+	var c StickCalibrator
+	c.AddCenter(Vec2{X: 10, Y: -4})
+	c.AddCenter(Vec2{X: 12, Y: -6})
+	for _, v := range []Vec2{{X: 900, Y: 0}, {X: 0, Y: -950}, {X: -980, Y: 0}, {X: 0, Y: 1000}} {
+		c.AddExtent(v)
+	}
+
+	cal := c.Calibration(ProControllerStick, 0.05)
+	expect := StickCalibration{
+		Center:   Vec2{X: 11, Y: -5},
+		Min:      Vec2{X: -980, Y: -950},
+		Max:      Vec2{X: 900, Y: 1000},
+		Deadzone: 0.05,
+	}
+	if cal != expect {
+		t.Errorf("expected %+v, got %+v", expect, cal)
+	}
+
+	var empty StickCalibrator
+	if cal := empty.Calibration(ProControllerStick, 0.1); cal != ProControllerStick {
+		t.Errorf("expected default calibration, got %+v", cal)
+	}
+}
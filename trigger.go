@@ -0,0 +1,74 @@
+package wiimote
+
+// ClassicTriggerMax is the raw value of a fully pressed analog trigger of the Classic Controller.
+const ClassicTriggerMax = 63
+
+// ClassicTriggers tracks the shoulder triggers of a Classic Controller. Not all Classic
+// Controllers have analog triggers (e.g. the Classic Controller Pro), these report only 0 or
+// ClassicTriggerMax. Analog triggers are detected as soon as an intermediate value is reported,
+// until then the digital TL/TR buttons are used. This provides consistent trigger data
+// regardless of the hardware revision.
+type ClassicTriggers struct {
+	// Max is the raw value of a fully pressed trigger
+	Max int32
+
+	analog  bool
+	raw     [2]int32
+	digital [2]bool
+}
+
+// NewClassicTriggers returns a ClassicTriggers with the nominal range of the kernel driver.
+func NewClassicTriggers() *ClassicTriggers {
+	return &ClassicTriggers{Max: ClassicTriggerMax}
+}
+
+// Update processes Classic Controller key and movement events, other events are ignored.
+func (t *ClassicTriggers) Update(ev Event) {
+	switch ev := ev.(type) {
+	case *EventClassicControllerMove:
+		t.UpdateRaw(ev.ShoulderLeft, ev.ShoulderRight)
+	case *EventClassicControllerKey:
+		switch ev.Code {
+		case KeyTL:
+			t.digital[0] = ev.Pressed
+		case KeyTR:
+			t.digital[1] = ev.Pressed
+		}
+	}
+}
+
+// UpdateRaw is like Update but takes the raw trigger values.
+func (t *ClassicTriggers) UpdateRaw(left, right int32) {
+	t.raw = [2]int32{left, right}
+	for _, v := range t.raw {
+		if v > 0 && v < t.Max {
+			t.analog = true
+		}
+	}
+}
+
+// Analog returns whether analog triggers were detected.
+func (t *ClassicTriggers) Analog() bool {
+	return t.analog
+}
+
+// Trigger returns the position of trigger key, which is either KeyTL or KeyTR, in the range
+// 0..1. Without analog triggers this is either 0 or 1 according to the digital button.
+func (t *ClassicTriggers) Trigger(key Key) float64 {
+	var i int
+	switch key {
+	case KeyTL:
+		i = 0
+	case KeyTR:
+		i = 1
+	default:
+		return 0
+	}
+	if !t.analog || t.Max <= 0 {
+		if t.digital[i] {
+			return 1
+		}
+		return 0
+	}
+	return min(max(float64(t.raw[i])/float64(t.Max), 0), 1)
+}
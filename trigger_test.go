@@ -0,0 +1,42 @@
+package wiimote
+
+import "testing"
+
+func TestClassicTriggersDigitalFallback(t *testing.T) {
+	tr := NewClassicTriggers()
+
+	// controller without analog triggers reports 0 or max
+	tr.UpdateRaw(ClassicTriggerMax, 0)
+	tr.Update(&EventClassicControllerKey{EventKey{Code: KeyTL, Pressed: true}})
+	if tr.Analog() {
+		t.Fatalf("expected no analog triggers")
+	}
+	if got := tr.Trigger(KeyTL); got != 1 {
+		t.Errorf("expected TL 1, got %v", got)
+	}
+	if got := tr.Trigger(KeyTR); got != 0 {
+		t.Errorf("expected TR 0, got %v", got)
+	}
+
+	tr.Update(&EventClassicControllerKey{EventKey{Code: KeyTL, Pressed: false}})
+	if got := tr.Trigger(KeyTL); got != 0 {
+		t.Errorf("expected TL 0 after release, got %v", got)
+	}
+}
+
+func TestClassicTriggersAnalog(t *testing.T) {
+	tr := NewClassicTriggers()
+	tr.Update(&EventClassicControllerMove{ShoulderLeft: 21, ShoulderRight: ClassicTriggerMax})
+	if !tr.Analog() {
+		t.Fatalf("expected analog triggers")
+	}
+	if got := tr.Trigger(KeyTL); got != 21.0/63 {
+		t.Errorf("expected TL %v, got %v", 21.0/63, got)
+	}
+	if got := tr.Trigger(KeyTR); got != 1 {
+		t.Errorf("expected TR 1, got %v", got)
+	}
+	if got := tr.Trigger(KeyA); got != 0 {
+		t.Errorf("expected 0 for non-trigger key, got %v", got)
+	}
+}
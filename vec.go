@@ -0,0 +1,77 @@
+package wiimote
+
+import "math"
+
+// ToF converts v to floating point.
+func (v Vec3) ToF() FVec3 {
+	return FVec3{X: float64(v.X), Y: float64(v.Y), Z: float64(v.Z)}
+}
+
+// Add returns v+w.
+func (v Vec3) Add(w Vec3) Vec3 {
+	return Vec3{X: v.X + w.X, Y: v.Y + w.Y, Z: v.Z + w.Z}
+}
+
+// Sub returns v-w.
+func (v Vec3) Sub(w Vec3) Vec3 {
+	return Vec3{X: v.X - w.X, Y: v.Y - w.Y, Z: v.Z - w.Z}
+}
+
+// Scale returns v multiplied by f, rounded to the nearest integer.
+func (v Vec3) Scale(f float64) Vec3 {
+	return v.ToF().Scale(f).Round()
+}
+
+// Dot returns the dot product of v and w.
+func (v Vec3) Dot(w Vec3) int64 {
+	return int64(v.X)*int64(w.X) + int64(v.Y)*int64(w.Y) + int64(v.Z)*int64(w.Z)
+}
+
+// Len returns the length of v.
+func (v Vec3) Len() float64 {
+	return v.ToF().Len()
+}
+
+// Normalize returns v scaled to a length of 1, see FVec3.Normalize.
+func (v Vec3) Normalize() FVec3 {
+	return v.ToF().Normalize()
+}
+
+// Round returns v rounded to the nearest integers.
+func (v FVec3) Round() Vec3 {
+	return Vec3{X: int32(math.Round(v.X)), Y: int32(math.Round(v.Y)), Z: int32(math.Round(v.Z))}
+}
+
+// Add returns v+w.
+func (v FVec3) Add(w FVec3) FVec3 {
+	return FVec3{X: v.X + w.X, Y: v.Y + w.Y, Z: v.Z + w.Z}
+}
+
+// Sub returns v-w.
+func (v FVec3) Sub(w FVec3) FVec3 {
+	return FVec3{X: v.X - w.X, Y: v.Y - w.Y, Z: v.Z - w.Z}
+}
+
+// Scale returns v multiplied by f.
+func (v FVec3) Scale(f float64) FVec3 {
+	return FVec3{X: v.X * f, Y: v.Y * f, Z: v.Z * f}
+}
+
+// Dot returns the dot product of v and w.
+func (v FVec3) Dot(w FVec3) float64 {
+	return v.X*w.X + v.Y*w.Y + v.Z*w.Z
+}
+
+// Len returns the length of v, it is the same as Magnitude.
+func (v FVec3) Len() float64 {
+	return v.Magnitude()
+}
+
+// Normalize returns v scaled to a length of 1. The zero vector is returned unchanged.
+func (v FVec3) Normalize() FVec3 {
+	l := v.Len()
+	if l == 0 {
+		return v
+	}
+	return v.Scale(1 / l)
+}
@@ -0,0 +1,22 @@
+package wiimote
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVecMath(t *testing.T) {
+	f := Vec3{X: 2, Y: 3, Z: 6}.ToF()
+	if got := f.Len(); got != 7 {
+		t.Errorf("Len = %v", got)
+	}
+	if got := f.Normalize().Len(); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Normalize().Len() = %v", got)
+	}
+	if got := f.Scale(-1).Add(f).Round(); got != (Vec3{}) {
+		t.Errorf("Scale(-1).Add = %v", got)
+	}
+	if got := (FVec3{}).Normalize(); got != (FVec3{}) {
+		t.Errorf("zero Normalize = %v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package wiimote
+
+import (
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+const modulePath = "github.com/friedelschoen/go-wiimote"
+
+// VersionInfo describes the versions of this module and the kernel driver.
+type VersionInfo struct {
+	// Module is the version of this module, "(devel)" if built from a checkout and
+	// "unknown" if no build information is available
+	Module string `json:"module"`
+	// Go is the version of the Go toolchain the binary was built with
+	Go string `json:"go"`
+	// Kernel is the release of the running kernel, empty if unknown
+	Kernel string `json:"kernel"`
+	// Driver is the version of the hid-wiimote kernel module, "builtin" if it is not loaded
+	// as module and empty if the driver is unavailable
+	Driver string `json:"driver"`
+}
+
+func readTrimmed(path string) string {
+	cont, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(cont))
+}
+
+// Version returns the version information of this module and the kernel driver found on this system.
+func Version() VersionInfo {
+	info := VersionInfo{
+		Module: "unknown",
+		Kernel: readTrimmed("/proc/sys/kernel/osrelease"),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Go = bi.GoVersion
+		if bi.Main.Path == modulePath {
+			info.Module = bi.Main.Version
+		}
+		for _, dep := range bi.Deps {
+			if dep.Path == modulePath {
+				info.Module = dep.Version
+				if dep.Replace != nil {
+					info.Module = dep.Replace.Version
+				}
+			}
+		}
+	}
+
+	// in-tree modules do not have a version, but a srcversion
+	const moddir = "/sys/module/hid_wiimote"
+	if v := readTrimmed(moddir + "/version"); v != "" {
+		info.Driver = v
+	} else if v := readTrimmed(moddir + "/srcversion"); v != "" {
+		info.Driver = v
+	} else if _, err := os.Stat("/sys/bus/hid/drivers/wiimote"); err == nil {
+		info.Driver = "builtin"
+	}
+	return info
+}
+
+func (v VersionInfo) String() string {
+	var w strings.Builder
+	w.WriteString("go-wiimote ")
+	w.WriteString(v.Module)
+	if v.Go != "" {
+		w.WriteString(" built with ")
+		w.WriteString(v.Go)
+	}
+	w.WriteString(", kernel ")
+	if v.Kernel != "" {
+		w.WriteString(v.Kernel)
+	} else {
+		w.WriteString("unknown")
+	}
+	w.WriteString(", hid-wiimote ")
+	if v.Driver != "" {
+		w.WriteString(v.Driver)
+	} else {
+		w.WriteString("unavailable")
+	}
+	return w.String()
+}